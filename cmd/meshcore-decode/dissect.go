@@ -0,0 +1,501 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/contact"
+)
+
+// keyring holds the secrets available for decryption. Every part is optional;
+// payloads no key opens are shown encrypted.
+type keyring struct {
+	priv     ed25519.PrivateKey // local identity, nil if none
+	self     core.MeshCoreID
+	contacts []*contact.ContactInfo
+	store    contact.ContactStore // contacts, for naming path hops; nil if none
+	channels []channel
+}
+
+// channel is a group channel secret.
+type channel struct {
+	name string
+	key  []byte
+	hash uint8
+}
+
+// setKey sets the local identity.
+func (k *keyring) setKey(priv ed25519.PrivateKey) {
+	k.priv = priv
+	copy(k.self[:], priv.Public().(ed25519.PublicKey))
+}
+
+// setContacts sets the known contacts. Call it after setKey, so the lookup
+// store shares the local identity.
+func (k *keyring) setContacts(cs []*contact.ContactInfo) {
+	k.contacts = cs
+	m := contact.NewManager(k.priv, contact.ManagerConfig{MaxContacts: len(cs)})
+	for _, c := range cs {
+		_, _ = m.AddContact(c)
+	}
+	k.store = m
+}
+
+// addChannel registers a channel secret under a display name.
+func (k *keyring) addChannel(name string, key []byte) {
+	k.channels = append(k.channels, channel{name: name, key: key, hash: crypto.ComputeChannelHash(key)})
+}
+
+// hashtagChannelKey derives the secret of a hashtag channel ("#name") the way
+// the MeshCore apps do: the first 16 bytes of SHA-256 of the name.
+func hashtagChannelKey(name string) []byte {
+	sum := sha256.Sum256([]byte(name))
+	return sum[:16]
+}
+
+// contactsByHash returns the contacts whose 1-byte hash is h.
+func (k *keyring) contactsByHash(h uint8) []*contact.ContactInfo {
+	var out []*contact.ContactInfo
+	for _, c := range k.contacts {
+		if c.ID.Hash() == h {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// decryptAddressed tries every contact that could be the other end of an
+// addressed payload to or from the local identity, returning the plaintext and
+// the contact whose shared secret verified the MAC.
+func (k *keyring) decryptAddressed(ap *codec.AddressedPayload) ([]byte, *contact.ContactInfo) {
+	if k.priv == nil {
+		return nil, nil
+	}
+	data := codec.PrependMAC(ap.MAC, ap.Ciphertext)
+	var peers []*contact.ContactInfo
+	if ap.DestHash == k.self.Hash() {
+		peers = append(peers, k.contactsByHash(ap.SrcHash)...)
+	}
+	if ap.SrcHash == k.self.Hash() {
+		peers = append(peers, k.contactsByHash(ap.DestHash)...)
+	}
+	for _, c := range peers {
+		secret, err := crypto.ComputeSharedSecret(k.priv, c.ID[:])
+		if err != nil {
+			continue
+		}
+		if plaintext, err := crypto.DecryptAddressedWithSecret(data, secret); err == nil {
+			return plaintext, c
+		}
+	}
+	return nil, nil
+}
+
+// decryptGroup tries every channel registered under the payload's hash.
+func (k *keyring) decryptGroup(gp *codec.GroupPayload) ([]byte, *channel) {
+	data := codec.PrependMAC(gp.MAC, gp.Ciphertext)
+	for i := range k.channels {
+		ch := &k.channels[i]
+		if ch.hash != gp.ChannelHash {
+			continue
+		}
+		if plaintext, err := crypto.DecryptGroupMessage(data, ch.key); err == nil {
+			return plaintext, ch
+		}
+	}
+	return nil, nil
+}
+
+// decryptAnonReq opens an anonymous request sent to the local identity, or one
+// it sent to a known contact.
+func (k *keyring) decryptAnonReq(ar *codec.AnonReqPayload) ([]byte, string) {
+	if k.priv == nil {
+		return nil, ""
+	}
+	data := codec.PrependMAC(ar.MAC, ar.Ciphertext)
+	if ar.DestHash == k.self.Hash() {
+		if plaintext, err := crypto.DecryptAnonymous(data, k.priv, ar.PubKey[:]); err == nil {
+			return plaintext, "local key"
+		}
+	}
+	if core.MeshCoreID(ar.PubKey) == k.self {
+		for _, c := range k.contactsByHash(ar.DestHash) {
+			if plaintext, err := crypto.DecryptAnonymous(data, k.priv, c.ID[:]); err == nil {
+				return plaintext, contactLabel(c)
+			}
+		}
+	}
+	return nil, ""
+}
+
+// contactLabel names a contact for output.
+func contactLabel(c *contact.ContactInfo) string {
+	id := c.ID.String()[:12]
+	if c.Name == "" {
+		return "contact " + id
+	}
+	return fmt.Sprintf("contact %s (%s)", c.Name, id)
+}
+
+// field is one named value in a dissected payload.
+type field struct {
+	Name  string
+	Value any
+}
+
+// fields is an ordered list of payload fields. It marshals to a JSON object
+// with its keys in order.
+type fields []field
+
+// add appends a field. Byte slices are shown as hex.
+func (f *fields) add(name string, v any) {
+	if b, ok := v.([]byte); ok {
+		v = hex.EncodeToString(b)
+	}
+	*f = append(*f, field{Name: name, Value: v})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f fields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, fl := range f {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(fl.Name)
+		value, err := json.Marshal(fl.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// dissection is the decoded form of one packet.
+type dissection struct {
+	Raw          string      `json:"raw"`
+	Error        string      `json:"error,omitempty"` // the packet itself did not parse
+	Packet       *packetInfo `json:"packet,omitempty"`
+	Payload      fields      `json:"payload,omitempty"`
+	PayloadError string      `json:"payload_error,omitempty"`
+	Decrypted    *decrypted  `json:"decrypted,omitempty"`
+}
+
+// packetInfo is the header, route, and path of a packet.
+type packetInfo struct {
+	Header         uint8     `json:"header"`
+	Route          string    `json:"route"`
+	PayloadType    string    `json:"payload_type"`
+	PayloadVersion uint8     `json:"payload_version"`
+	TransportCodes []uint16  `json:"transport_codes,omitempty"`
+	PathHashSize   uint8     `json:"path_hash_size"`
+	Hops           int       `json:"hops"`
+	Path           []string  `json:"path,omitempty"`       // relay hashes, hex
+	PathNames      []string  `json:"path_names,omitempty"` // relays by contact name
+	PathSNR        []float32 `json:"path_snr,omitempty"`   // TRACE: per-hop SNR in dB
+}
+
+// decrypted is the content of an encrypted payload that a key opened.
+type decrypted struct {
+	Key    string `json:"key"` // what decrypted it
+	Fields fields `json:"fields,omitempty"`
+	Error  string `json:"error,omitempty"` // the plaintext did not parse
+}
+
+// dissect decodes one raw packet, decrypting its payload if kr holds a key
+// for it.
+func dissect(raw []byte, kr *keyring) *dissection {
+	d := &dissection{Raw: hex.EncodeToString(raw)}
+	var pkt codec.Packet
+	if err := pkt.ReadFrom(raw); err != nil {
+		d.Error = err.Error()
+		return d
+	}
+
+	info := &packetInfo{
+		Header:         pkt.Header,
+		Route:          codec.RouteTypeName(pkt.RouteType()),
+		PayloadType:    codec.PayloadTypeName(pkt.PayloadType()),
+		PayloadVersion: pkt.PayloadVersion() + 1,
+		PathHashSize:   pkt.PathHashSize,
+		Hops:           pkt.HopCount(),
+	}
+	if pkt.HasTransportCodes() {
+		info.TransportCodes = pkt.TransportCodes[:]
+	}
+	if pkt.PayloadType() == codec.PayloadTypeTrace {
+		// TRACE carries per-hop SNR in the path; the hashes are in the payload.
+		for _, b := range pkt.Path {
+			info.PathSNR = append(info.PathSNR, float32(int8(b))/4)
+		}
+	} else {
+		info.Path = splitHashes(pkt.Path, int(pkt.PathHashSize))
+		if kr.store != nil {
+			info.PathNames = hopNames(contact.ResolvePath(kr.store, pkt.PathLen, pkt.Path))
+		}
+	}
+	d.Packet = info
+
+	if err := dissectPayload(d, &pkt, kr); err != nil {
+		d.PayloadError = err.Error()
+	}
+	return d
+}
+
+// hopNames names each hop of a resolved path: the contact's name, "?" for an
+// unknown hash, or "?N" for a hash N contacts share.
+func hopNames(hops []contact.PathHop) []string {
+	out := make([]string, len(hops))
+	for i, h := range hops {
+		switch {
+		case h.Contact != nil && h.Contact.Name != "":
+			out[i] = h.Contact.Name
+		case h.Contact != nil:
+			out[i] = h.Contact.ID.String()[:8]
+		case h.Matches > 1:
+			out[i] = fmt.Sprintf("?%d", h.Matches)
+		default:
+			out[i] = "?"
+		}
+	}
+	return out
+}
+
+// splitHashes splits a path into hex hashes of size bytes each.
+func splitHashes(path []byte, size int) []string {
+	if size <= 0 {
+		size = 1
+	}
+	var out []string
+	for i := 0; i+size <= len(path); i += size {
+		out = append(out, hex.EncodeToString(path[i:i+size]))
+	}
+	return out
+}
+
+// dissectPayload fills in d.Payload, and d.Decrypted where possible, from the
+// packet's payload.
+func dissectPayload(d *dissection, pkt *codec.Packet, kr *keyring) error {
+	payload, err := codec.DecodePayload(pkt)
+	if err != nil {
+		return err
+	}
+	f := &d.Payload
+	switch p := payload.(type) {
+	case *codec.ReqPayload:
+		addressedFields(d, kr, p.Type(), &p.AddressedPayload)
+	case *codec.ResponsePayload:
+		addressedFields(d, kr, p.Type(), &p.AddressedPayload)
+	case *codec.TxtMsgPayload:
+		addressedFields(d, kr, p.Type(), &p.AddressedPayload)
+	case *codec.PathPayload:
+		addressedFields(d, kr, p.Type(), &p.AddressedPayload)
+
+	case *codec.AckPayload:
+		f.add("checksum", fmt.Sprintf("%08x", p.Checksum))
+
+	case *codec.AdvertPayload:
+		f.add("public_key", p.PubKey[:])
+		f.add("timestamp", p.Timestamp)
+		f.add("signature_valid", crypto.VerifyAdvert(p))
+		if a := p.AppData; a != nil {
+			f.add("node_type", codec.NodeTypeName(a.NodeType))
+			if a.Name != "" {
+				f.add("name", a.Name)
+			}
+			if a.HasLocation() {
+				f.add("lat", *a.Lat)
+				f.add("lon", *a.Lon)
+			}
+		}
+
+	case *codec.GrpTxtPayload:
+		groupFields(d, kr, p.Type(), &p.GroupPayload)
+	case *codec.GrpDataPayload:
+		groupFields(d, kr, p.Type(), &p.GroupPayload)
+
+	case *codec.AnonReqPayload:
+		f.add("dest_hash", fmt.Sprintf("%02x", p.DestHash))
+		f.add("public_key", p.PubKey[:])
+		f.add("mac", fmt.Sprintf("%04x", p.MAC))
+		f.add("ciphertext", p.Ciphertext)
+		if plaintext, key := kr.decryptAnonReq(p); key != "" {
+			d.Decrypted = decodeAnonReq(plaintext, key)
+		}
+
+	case *codec.TracePayload:
+		f.add("tag", fmt.Sprintf("%08x", p.Tag))
+		f.add("auth_code", fmt.Sprintf("%08x", p.AuthCode))
+		f.add("flags", p.Flags)
+		f.add("hash_size", p.HashSize)
+		f.add("hashes", splitHashes(p.PathHashes, p.HashSize))
+
+	case *codec.MultipartPayload:
+		f.add("remaining", p.Remaining)
+		f.add("inner_type", codec.PayloadTypeName(p.InnerType))
+		f.add("data", p.Data)
+
+	case *codec.DiscoverReqPayload:
+		controlFields(f, pkt.Payload[0])
+		f.add("type_filter", fmt.Sprintf("%02x", p.TypeFilter))
+		f.add("tag", fmt.Sprintf("%08x", p.Tag))
+		f.add("since", p.Since)
+		f.add("prefix_only", p.PrefixOnly)
+
+	case *codec.DiscoverRespPayload:
+		controlFields(f, pkt.Payload[0])
+		f.add("node_type", codec.NodeTypeName(p.NodeType))
+		f.add("snr", p.GetSNR())
+		f.add("tag", fmt.Sprintf("%08x", p.Tag))
+		f.add("public_key", p.PubKey)
+
+	case *codec.ControlPayload:
+		controlFields(f, p.Flags)
+		f.add("data", p.Data)
+
+	default:
+		f.add("data", pkt.Payload)
+	}
+	return nil
+}
+
+// addressedFields adds the fields of an addressed payload, decrypting it if
+// the keyring holds a key for either end.
+func addressedFields(d *dissection, kr *keyring, pt uint8, ap *codec.AddressedPayload) {
+	f := &d.Payload
+	f.add("dest_hash", fmt.Sprintf("%02x", ap.DestHash))
+	f.add("src_hash", fmt.Sprintf("%02x", ap.SrcHash))
+	f.add("mac", fmt.Sprintf("%04x", ap.MAC))
+	f.add("ciphertext", ap.Ciphertext)
+	if plaintext, c := kr.decryptAddressed(ap); c != nil {
+		d.Decrypted = decodeAddressed(pt, plaintext, contactLabel(c))
+	}
+}
+
+// groupFields adds the fields of a group payload, decrypting it if the
+// keyring holds its channel.
+func groupFields(d *dissection, kr *keyring, pt uint8, gp *codec.GroupPayload) {
+	f := &d.Payload
+	f.add("channel_hash", fmt.Sprintf("%02x", gp.ChannelHash))
+	f.add("mac", fmt.Sprintf("%04x", gp.MAC))
+	f.add("ciphertext", gp.Ciphertext)
+	if plaintext, ch := kr.decryptGroup(gp); ch != nil {
+		d.Decrypted = decodeGroup(pt, plaintext, "channel "+ch.name)
+	}
+}
+
+// controlFields adds the fields common to every control payload.
+func controlFields(f *fields, flags uint8) {
+	f.add("flags", fmt.Sprintf("%02x", flags))
+	f.add("subtype", codec.ControlSubtypeName(flags>>4))
+	f.add("node_discover", flags&codec.ControlFlagNodeDiscover != 0)
+}
+
+// decodeAddressed parses the plaintext of an addressed payload.
+func decodeAddressed(pt uint8, plaintext []byte, key string) *decrypted {
+	d := &decrypted{Key: key}
+	f := &d.Fields
+	switch pt {
+	case codec.PayloadTypeTxtMsg:
+		txt, err := codec.ParseTxtMsgContent(plaintext)
+		if err != nil {
+			d.Error = err.Error()
+			break
+		}
+		f.add("timestamp", txt.Timestamp)
+		f.add("txt_type", codec.TxtTypeName(txt.TxtType))
+		f.add("attempt", txt.Attempt)
+		if len(txt.SenderPubKeyPrefix) > 0 {
+			f.add("sender_prefix", txt.SenderPubKeyPrefix)
+		}
+		if txt.TxtType == codec.TxtTypeFederated {
+			f.add("origin_prefix", txt.OriginPubKeyPrefix)
+			f.add("origin_timestamp", txt.OriginTimestamp)
+		}
+		f.add("message", txt.Message)
+
+	case codec.PayloadTypeReq:
+		req, err := codec.ParseRequestContent(plaintext)
+		if err != nil {
+			d.Error = err.Error()
+			break
+		}
+		trimmed := codec.TrimRequestContent(plaintext, req)
+		f.add("timestamp", req.Timestamp)
+		f.add("request_type", codec.RequestTypeName(req.RequestType))
+		f.add("data", trimmed[min(len(trimmed), 5):])
+
+	case codec.PayloadTypeResponse:
+		resp, err := codec.ParseResponseContent(plaintext)
+		if err != nil {
+			d.Error = err.Error()
+			break
+		}
+		f.add("tag", fmt.Sprintf("%08x", resp.Tag))
+		f.add("content", resp.Content)
+
+	case codec.PayloadTypePath:
+		pc, err := codec.ParsePathContent(plaintext)
+		if err != nil {
+			d.Error = err.Error()
+			break
+		}
+		info := codec.PathInfoFromWireByte(pc.PathLen)
+		f.add("path_hash_size", info.HashSize)
+		f.add("path", splitHashes(pc.Path, int(info.HashSize)))
+		f.add("extra_type", codec.PayloadTypeName(pc.ExtraType))
+		f.add("extra", pc.Extra)
+	}
+	return d
+}
+
+// decodeGroup parses the plaintext of a group payload.
+func decodeGroup(pt uint8, plaintext []byte, key string) *decrypted {
+	d := &decrypted{Key: key}
+	f := &d.Fields
+	if pt == codec.PayloadTypeGrpTxt {
+		ts, txtType, msg, err := crypto.ParseGrpTxtPlaintext(plaintext)
+		if err != nil {
+			d.Error = err.Error()
+			return d
+		}
+		f.add("timestamp", ts)
+		f.add("txt_type", codec.TxtTypeName(txtType))
+		f.add("message", msg)
+		return d
+	}
+	gd, err := crypto.ParseGrpDataContent(plaintext)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	f.add("data_type", gd.DataType)
+	f.add("data", gd.Data)
+	return d
+}
+
+// decodeAnonReq parses the plaintext of an anonymous request: a timestamp
+// followed by request-specific data.
+func decodeAnonReq(plaintext []byte, key string) *decrypted {
+	d := &decrypted{Key: key}
+	if len(plaintext) < 4 {
+		d.Error = fmt.Sprintf("anonymous request too short: %d bytes", len(plaintext))
+		return d
+	}
+	d.Fields.add("timestamp", binary.LittleEndian.Uint32(plaintext[0:4]))
+	d.Fields.add("data", bytes.TrimRight(plaintext[4:], "\x00"))
+	return d
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/contact"
+)
+
+func newKey(t *testing.T) (ed25519.PrivateKey, core.MeshCoreID) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id core.MeshCoreID
+	copy(id[:], pub)
+	return priv, id
+}
+
+// get returns the value of the named field, or nil.
+func get(f fields, name string) any {
+	for _, fl := range f {
+		if fl.Name == name {
+			return fl.Value
+		}
+	}
+	return nil
+}
+
+func TestDissect_TxtMsg(t *testing.T) {
+	localPriv, localID := newKey(t)
+	peerPriv, peerID := newKey(t)
+
+	content := codec.BuildTxtMsgContent(1700000000, codec.TxtTypePlain, 1, "hello", nil)
+	enc, err := crypto.EncryptAddressed(content, peerPriv, localID[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac, ct := codec.SplitMAC(enc)
+	pkt := codec.NewPacket(codec.PayloadTypeTxtMsg, codec.RouteTypeFlood,
+		codec.BuildAddressedPayload(localID.Hash(), peerID.Hash(), mac, ct))
+	pkt.PathLen = codec.PathInfo{HashSize: 2, HopCount: 2}.ToWireByte()
+	pkt.Path = []byte{0xa1, 0xa2, 0xb1, 0xb2}
+	raw := pkt.WriteTo()
+
+	// Without keys only the envelope is shown.
+	d := dissect(raw, &keyring{})
+	if d.Error != "" || d.Decrypted != nil {
+		t.Fatalf("got error %q, decrypted %+v", d.Error, d.Decrypted)
+	}
+	if p := d.Packet; p.Route != "FLOOD" || p.PayloadType != "TXT_MSG" || p.Hops != 2 ||
+		strings.Join(p.Path, " ") != "a1a2 b1b2" {
+		t.Errorf("packet = %+v", p)
+	}
+	if get(d.Payload, "src_hash") != fmt.Sprintf("%02x", peerID.Hash()) {
+		t.Errorf("payload = %v", d.Payload)
+	}
+
+	kr := &keyring{contacts: []*contact.ContactInfo{{ID: peerID, Name: "Bob"}}}
+	kr.setKey(localPriv)
+	d = dissect(raw, kr)
+	if d.Decrypted == nil {
+		t.Fatal("not decrypted")
+	}
+	if !strings.Contains(d.Decrypted.Key, "Bob") || get(d.Decrypted.Fields, "message") != "hello" ||
+		get(d.Decrypted.Fields, "attempt") != uint8(1) {
+		t.Errorf("decrypted = %+v", d.Decrypted)
+	}
+
+	// The sender's key opens it too.
+	kr = &keyring{contacts: []*contact.ContactInfo{{ID: localID}}}
+	kr.setKey(peerPriv)
+	if d := dissect(raw, kr); d.Decrypted == nil || get(d.Decrypted.Fields, "message") != "hello" {
+		t.Errorf("sender side: decrypted = %+v", d.Decrypted)
+	}
+}
+
+func TestDissect_PathNames(t *testing.T) {
+	pkt := codec.NewPacket(codec.PayloadTypeAck, codec.RouteTypeFlood, codec.BuildAckPayload(1))
+	pkt.PathLen = codec.PathInfo{HashSize: 2, HopCount: 3}.ToWireByte()
+	pkt.Path = []byte{0xa1, 0xa2, 0xb1, 0xb2, 0xc1, 0xc2}
+
+	kr := &keyring{}
+	kr.setContacts([]*contact.ContactInfo{
+		{ID: core.MeshCoreID{0xa1, 0xa2, 0x01}, Name: "Hill"},
+		{ID: core.MeshCoreID{0xc1, 0xc2, 0x01}, Name: "Ridge"},
+		{ID: core.MeshCoreID{0xc1, 0xc2, 0x02}, Name: "Ridge2"},
+	})
+	d := dissect(pkt.WriteTo(), kr)
+	if got := strings.Join(d.Packet.PathNames, " "); got != "Hill ? ?2" {
+		t.Errorf("path names = %q", got)
+	}
+
+	// A favourite in the contact list, with or without a local key.
+	fav := &contact.ContactInfo{ID: core.MeshCoreID{0xb1, 0xb2, 0x01}, Name: "Peak", Flags: contact.FlagFavorite}
+	kr = &keyring{}
+	kr.setContacts([]*contact.ContactInfo{fav})
+	if got := strings.Join(dissect(pkt.WriteTo(), kr).Packet.PathNames, " "); got != "? Peak ?" {
+		t.Errorf("path names with a favourite = %q", got)
+	}
+	kr = &keyring{}
+	kr.setKey(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	kr.setContacts([]*contact.ContactInfo{fav})
+	if got := strings.Join(dissect(pkt.WriteTo(), kr).Packet.PathNames, " "); got != "? Peak ?" {
+		t.Errorf("path names with a key and a favourite = %q", got)
+	}
+
+	// Without contacts the path is left unnamed.
+	if d := dissect(pkt.WriteTo(), &keyring{}); d.Packet.PathNames != nil {
+		t.Errorf("path names = %v", d.Packet.PathNames)
+	}
+}
+
+func TestDissect_GroupHashtag(t *testing.T) {
+	key := hashtagChannelKey("#test")
+	enc, err := crypto.EncryptGroupMessage(crypto.BuildGrpTxtPlaintext(1700000000, "alice: hi all"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac, ct := codec.SplitMAC(enc)
+	raw := codec.NewPacket(codec.PayloadTypeGrpTxt, codec.RouteTypeFlood,
+		codec.BuildGroupPayload(crypto.ComputeChannelHash(key), mac, ct)).WriteTo()
+
+	kr := &keyring{}
+	kr.addChannel("Public", crypto.DefaultChannelKey)
+	if d := dissect(raw, kr); d.Decrypted != nil {
+		t.Fatalf("decrypted with the wrong channel: %+v", d.Decrypted)
+	}
+	if err := parseChannel(kr, "#test"); err != nil {
+		t.Fatal(err)
+	}
+	d := dissect(raw, kr)
+	if d.Decrypted == nil || d.Decrypted.Key != "channel #test" ||
+		get(d.Decrypted.Fields, "message") != "alice: hi all" {
+		t.Fatalf("decrypted = %+v", d.Decrypted)
+	}
+}
+
+func TestDissect_AnonReq(t *testing.T) {
+	localPriv, localID := newKey(t)
+	peerPriv, peerID := newKey(t)
+
+	plaintext := append([]byte{0x10, 0x20, 0x30, 0x40}, "secret"...)
+	secret, err := crypto.ComputeSharedSecret(peerPriv, localID[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := crypto.EncryptAddressedWithSecret(plaintext, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac, ct := codec.SplitMAC(enc)
+	raw := codec.NewPacket(codec.PayloadTypeAnonReq, codec.RouteTypeDirect,
+		codec.BuildAnonReqPayload(localID.Hash(), peerID, mac, ct)).WriteTo()
+
+	kr := &keyring{}
+	kr.setKey(localPriv)
+	d := dissect(raw, kr)
+	if d.Decrypted == nil || get(d.Decrypted.Fields, "timestamp") != uint32(0x40302010) ||
+		get(d.Decrypted.Fields, "data") != "736563726574" {
+		t.Fatalf("decrypted = %+v", d.Decrypted)
+	}
+}
+
+func TestDissect_Malformed(t *testing.T) {
+	d := dissect([]byte{0x11}, &keyring{})
+	if d.Error == "" || d.Packet != nil {
+		t.Fatalf("got %+v", d)
+	}
+	// A valid envelope with a truncated payload keeps the header.
+	d = dissect([]byte{codec.PayloadTypeAck<<codec.PHTypeShift | codec.RouteTypeDirect, 0, 1, 2}, &keyring{})
+	if d.Packet == nil || d.PayloadError == "" {
+		t.Fatalf("got %+v", d)
+	}
+}
+
+func TestFieldsJSONOrder(t *testing.T) {
+	var f fields
+	f.add("b", 1)
+	f.add("a", []byte{0xab})
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"b":1,"a":"ab"}` {
+		t.Errorf("got %s", data)
+	}
+}
+
+func TestReadRS232(t *testing.T) {
+	ack := codec.NewPacket(codec.PayloadTypeAck, codec.RouteTypeDirect, codec.BuildAckPayload(0xdeadbeef)).WriteTo()
+	frame, err := codec.EncodeRS232Frame(ack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stream []byte
+	stream = append(stream, 0x00, 0xc0) // noise, including a false magic start
+	stream = append(stream, frame...)
+	stream = append(stream, frame...)
+	stream = append(stream, frame[:5]...) // truncated capture
+
+	var got [][]byte
+	skipped, err := readRS232(stream, func(b []byte) error {
+		got = append(got, b)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !bytes.Equal(got[0], ack) || skipped != 7 {
+		t.Fatalf("got %d packets, skipped %d", len(got), skipped)
+	}
+}
+
+func TestReadHexLines(t *testing.T) {
+	in := "# capture\n\n0x0c00ef\n0c:00 ef be\nzz\n"
+	var got []string
+	err := readHexLines(strings.NewReader(in), "in", func(b []byte) error {
+		got = append(got, string(b))
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "in:5") {
+		t.Errorf("err = %v, want error at line 5", err)
+	}
+	if len(got) != 2 || got[0] != "\x0c\x00\xef" || got[1] != "\x0c\x00\xef\xbe" {
+		t.Errorf("got %q", got)
+	}
+}
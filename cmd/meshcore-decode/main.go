@@ -0,0 +1,316 @@
+// Command meshcore-decode dissects MeshCore packets: header, route, path, and
+// payload fields, printed as text or as one JSON object per packet.
+//
+// Input is read from the files named on the command line, or stdin if there
+// are none ("-" also means stdin). By default each non-blank line is one
+// packet in hex; lines starting with '#' are skipped. With -rs232 the input is
+// instead a binary capture of RS232 bridge frames, as a serial bridge emits
+// them.
+//
+//	echo 1100... | meshcore-decode
+//	meshcore-decode -json packets.txt | jq .
+//	meshcore-decode -rs232 -key companion.key -contacts contacts.json capture.bin
+//
+// Encrypted payloads are decrypted when a key fits. -key takes the local
+// node's private key (hex seed or full key, or a file holding one, such as the
+// companion example's key file) and opens addressed messages exchanged with
+// the contacts in -contacts and anonymous requests to or from the node.
+// -contacts reads a node's contact file or a contact export (JSON or CSV); its
+// contacts also name the relays in each packet's path.
+// Group messages on the Public channel always decrypt; add other channels with
+// -channel, given as "#name" for a hashtag channel or "[name=]hex" for a
+// secret.
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/contact"
+	"github.com/kabili207/meshcore-go/device/contact/exchange"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "meshcore-decode:", err)
+		os.Exit(1)
+	}
+}
+
+// channelFlags collects repeated -channel flags.
+type channelFlags []string
+
+func (c *channelFlags) String() string     { return strings.Join(*c, ",") }
+func (c *channelFlags) Set(s string) error { *c = append(*c, s); return nil }
+
+func run() error {
+	var channels channelFlags
+	var (
+		asJSON   = flag.Bool("json", false, "print one JSON object per packet")
+		rs232    = flag.Bool("rs232", false, "input is a binary capture of RS232 bridge frames")
+		keyArg   = flag.String("key", "", "local private key: hex seed or key, or a file holding one")
+		contacts = flag.String("contacts", "", "contacts file (node contact file, or JSON/CSV export)")
+	)
+	flag.Var(&channels, "channel", `group channel: "#name" or "[name=]hex" secret (repeatable)`)
+	flag.Parse()
+
+	kr := &keyring{}
+	kr.addChannel("Public", crypto.DefaultChannelKey)
+	for _, s := range channels {
+		if err := parseChannel(kr, s); err != nil {
+			return err
+		}
+	}
+	if *keyArg != "" {
+		priv, err := parseKey(*keyArg)
+		if err != nil {
+			return err
+		}
+		kr.setKey(priv)
+	}
+	if *contacts != "" {
+		cs, err := loadContacts(*contacts)
+		if err != nil {
+			return fmt.Errorf("contacts %s: %w", *contacts, err)
+		}
+		kr.setContacts(cs)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	n := 0
+	emit := func(raw []byte) error {
+		n++
+		d := dissect(raw, kr)
+		if *asJSON {
+			return json.NewEncoder(out).Encode(d)
+		}
+		return writeText(out, n, d)
+	}
+
+	inputs := flag.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	for _, name := range inputs {
+		if err := decodeInput(name, *rs232, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeInput reads packets from the named file (or stdin for "-") and passes
+// each to emit.
+func decodeInput(name string, rs232 bool, emit func([]byte) error) error {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	if rs232 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		skipped, err := readRS232(data, emit)
+		if skipped > 0 {
+			fmt.Fprintf(os.Stderr, "meshcore-decode: %s: skipped %d bytes outside valid frames\n", name, skipped)
+		}
+		return err
+	}
+	return readHexLines(r, name, emit)
+}
+
+// readHexLines passes each hex line of r to emit. Whitespace, colons, and a
+// leading "0x" are ignored, so hex dumps paste as they are.
+func readHexLines(r io.Reader, name string, emit func([]byte) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+		s = strings.Map(func(r rune) rune {
+			if r == ':' || r == ' ' || r == '\t' {
+				return -1
+			}
+			return r
+		}, s)
+		raw, err := hex.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		if err := emit(raw); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// readRS232 passes the payload of each RS232 frame in data to emit. Bytes that
+// do not start a valid frame are skipped one at a time until the stream
+// resynchronizes; it returns how many were skipped, including a trailing
+// partial frame.
+func readRS232(data []byte, emit func([]byte) error) (skipped int, err error) {
+	for len(data) > 0 {
+		frame, rest, err := codec.DecodeRS232Frame(data)
+		switch {
+		case err == nil:
+			if err := emit(frame.Payload); err != nil {
+				return skipped, err
+			}
+			data = rest
+		case errors.Is(err, codec.ErrFrameTooShort), errors.Is(err, codec.ErrIncompleteFrame):
+			return skipped + len(data), nil
+		default:
+			skipped++
+			data = data[1:]
+		}
+	}
+	return skipped, nil
+}
+
+// parseKey reads a private key given as hex, or as the path of a file holding
+// it: a 32-byte seed or a 64-byte Ed25519 private key.
+func parseKey(s string) (ed25519.PrivateKey, error) {
+	if data, err := os.ReadFile(s); err == nil {
+		s = string(data)
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("key: %w", err)
+	}
+	switch len(b) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(b), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(b), nil
+	default:
+		return nil, fmt.Errorf("key: want %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(b))
+	}
+}
+
+// parseChannel adds a -channel value to kr.
+func parseChannel(kr *keyring, s string) error {
+	if strings.HasPrefix(s, "#") {
+		kr.addChannel(s, hashtagChannelKey(s))
+		return nil
+	}
+	name, secret, ok := strings.Cut(s, "=")
+	if !ok {
+		secret = s
+	}
+	key, err := hex.DecodeString(secret)
+	if err != nil || (len(key) != 16 && len(key) != 32) {
+		return fmt.Errorf("channel %q: want a 16- or 32-byte hex secret", s)
+	}
+	if !ok {
+		name = fmt.Sprintf("%02x", crypto.ComputeChannelHash(key))
+	}
+	kr.addChannel(name, key)
+	return nil
+}
+
+// loadContacts reads contacts from a CSV export, a JSON export, or a node's
+// contact file, whichever path holds.
+func loadContacts(path string) ([]*contact.ContactInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return exchange.ReadCSV(f)
+	}
+	cs, err := exchange.ReadJSON(f)
+	if err == nil {
+		return cs, nil
+	}
+	// Not an export; try the node contact file format.
+	if stored, ferr := contact.NewFileContactStore(path).Load(); ferr == nil && len(stored) > 0 {
+		return stored, nil
+	}
+	return nil, err
+}
+
+// writeText prints a dissection as an indented block of aligned fields.
+func writeText(w io.Writer, n int, d *dissection) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	if d.Packet == nil {
+		fmt.Fprintf(tw, "#%d malformed: %s\n", n, d.Error)
+		fmt.Fprintf(tw, "  raw:\t%s\n", d.Raw)
+		fmt.Fprintln(tw)
+		return tw.Flush()
+	}
+
+	p := d.Packet
+	fmt.Fprintf(tw, "#%d %s %s v%d, %d hops\n", n, p.Route, p.PayloadType, p.PayloadVersion, p.Hops)
+	fmt.Fprintf(tw, "  raw:\t%s\n", d.Raw)
+	if p.TransportCodes != nil {
+		fmt.Fprintf(tw, "  transport_codes:\t%04x %04x\n", p.TransportCodes[0], p.TransportCodes[1])
+	}
+	if p.PathSNR != nil {
+		fmt.Fprintf(tw, "  path_snr:\t%s\n", formatValue(p.PathSNR))
+	} else if p.Path != nil {
+		fmt.Fprintf(tw, "  path:\t%s (%d-byte hashes)\n", strings.Join(p.Path, " "), p.PathHashSize)
+		if p.PathNames != nil {
+			fmt.Fprintf(tw, "  via:\t%s\n", strings.Join(p.PathNames, " "))
+		}
+	}
+	for _, f := range d.Payload {
+		fmt.Fprintf(tw, "  %s:\t%s\n", f.Name, formatValue(f.Value))
+	}
+	if d.PayloadError != "" {
+		fmt.Fprintf(tw, "  payload_error:\t%s\n", d.PayloadError)
+	}
+	if dec := d.Decrypted; dec != nil {
+		fmt.Fprintf(tw, "  decrypted with %s:\n", dec.Key)
+		for _, f := range dec.Fields {
+			fmt.Fprintf(tw, "    %s:\t%s\n", f.Name, formatValue(f.Value))
+		}
+		if dec.Error != "" {
+			fmt.Fprintf(tw, "    error:\t%s\n", dec.Error)
+		}
+	}
+	fmt.Fprintln(tw)
+	return tw.Flush()
+}
+
+// formatValue renders a field value for text output.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, " ")
+	case []float32:
+		parts := make([]string, len(v))
+		for i, f := range v {
+			parts[i] = fmt.Sprintf("%.2f", f)
+		}
+		return strings.Join(parts, " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
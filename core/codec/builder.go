@@ -37,8 +37,11 @@ func PrependMAC(mac uint16, ciphertext []byte) []byte {
 // BuildAdvertPayload builds a wire-format ADVERT payload.
 // appData may be nil for a minimal advertisement (100 bytes).
 func BuildAdvertPayload(pubKey [32]byte, timestamp uint32, signature [64]byte, appData *AdvertAppData) []byte {
-	appDataBytes := BuildAdvertAppData(appData)
+	return buildAdvertPayload(pubKey, timestamp, signature, BuildAdvertAppData(appData))
+}
 
+// buildAdvertPayload builds an ADVERT payload around encoded app data.
+func buildAdvertPayload(pubKey [32]byte, timestamp uint32, signature [64]byte, appDataBytes []byte) []byte {
 	size := AdvertMinSize + len(appDataBytes)
 	data := make([]byte, size)
 
@@ -59,8 +62,11 @@ func BuildAdvertAppData(appData *AdvertAppData) []byte {
 	if appData == nil {
 		return nil
 	}
+	return buildAdvertAppData(appData, advertAppDataFlags(appData))
+}
 
-	// Compute flags from the struct fields
+// advertAppDataFlags computes the app data flags byte from the struct fields.
+func advertAppDataFlags(appData *AdvertAppData) uint8 {
 	flags := appData.NodeType & 0x0F
 	if appData.Lat != nil && appData.Lon != nil {
 		flags |= FlagHasLocation
@@ -74,7 +80,12 @@ func BuildAdvertAppData(appData *AdvertAppData) []byte {
 	if appData.Name != "" {
 		flags |= FlagHasName
 	}
+	return flags
+}
 
+// buildAdvertAppData encodes app data with the given flags byte. A section
+// whose flag is set but whose field is nil is written as zeros.
+func buildAdvertAppData(appData *AdvertAppData, flags uint8) []byte {
 	// Calculate size
 	size := 1 // flags byte
 	if flags&FlagHasLocation != 0 {
@@ -95,20 +106,26 @@ func BuildAdvertAppData(appData *AdvertAppData) []byte {
 	offset := 1
 
 	if flags&FlagHasLocation != 0 {
-		latRaw := int32(math.Round(*appData.Lat * CoordScale))
-		lonRaw := int32(math.Round(*appData.Lon * CoordScale))
-		binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(latRaw))
-		binary.LittleEndian.PutUint32(data[offset+4:offset+8], uint32(lonRaw))
+		if appData.Lat != nil && appData.Lon != nil {
+			latRaw := int32(math.Round(*appData.Lat * CoordScale))
+			lonRaw := int32(math.Round(*appData.Lon * CoordScale))
+			binary.LittleEndian.PutUint32(data[offset:offset+4], uint32(latRaw))
+			binary.LittleEndian.PutUint32(data[offset+4:offset+8], uint32(lonRaw))
+		}
 		offset += 8
 	}
 
 	if flags&FlagHasFeature1 != 0 {
-		binary.LittleEndian.PutUint16(data[offset:offset+2], *appData.Feature1)
+		if appData.Feature1 != nil {
+			binary.LittleEndian.PutUint16(data[offset:offset+2], *appData.Feature1)
+		}
 		offset += 2
 	}
 
 	if flags&FlagHasFeature2 != 0 {
-		binary.LittleEndian.PutUint16(data[offset:offset+2], *appData.Feature2)
+		if appData.Feature2 != nil {
+			binary.LittleEndian.PutUint16(data[offset:offset+2], *appData.Feature2)
+		}
 		offset += 2
 	}
 
@@ -292,6 +309,19 @@ func BuildNodeDiscoverRespPayload(nodeType uint8, snr int8, tag uint32, pubKey [
 // Content Builders (decrypted inner content)
 // -----------------------------------------------------------------------------
 
+// BuildFederatedTxtMsgContent builds TxtTypeFederated content. senderPrefix and
+// originPrefix must be at least 4 bytes; only the first 4 are used.
+func BuildFederatedTxtMsgContent(timestamp uint32, attempt uint8, senderPrefix, originPrefix []byte, originTimestamp uint32, message string) []byte {
+	data := make([]byte, FederatedTxtHeaderSize+len(message))
+	binary.LittleEndian.PutUint32(data[0:4], timestamp)
+	data[4] = (TxtTypeFederated << 2) | (attempt & 0x03)
+	copy(data[5:9], senderPrefix[:4])
+	copy(data[9:13], originPrefix[:4])
+	binary.LittleEndian.PutUint32(data[13:17], originTimestamp)
+	copy(data[FederatedTxtHeaderSize:], message)
+	return data
+}
+
 // BuildTxtMsgContent builds decrypted text message content.
 // For signed messages (txtType == TxtTypeSigned), senderPrefix must be 4 bytes.
 func BuildTxtMsgContent(timestamp uint32, txtType, attempt uint8, message string, senderPrefix []byte) []byte {
@@ -506,6 +506,33 @@ func TestBuildTxtMsgContentSignedRoundTrip(t *testing.T) {
 	}
 }
 
+func TestBuildFederatedTxtMsgContentRoundTrip(t *testing.T) {
+	sender := []byte{0xAA, 0xBB, 0xCC, 0xDD}
+	origin := []byte{0x11, 0x22, 0x33, 0x44}
+	data := BuildFederatedTxtMsgContent(1704067200, 1, sender, origin, 1704067100, "From afar")
+
+	parsed, err := ParseTxtMsgContent(append(data, 0, 0, 0)) // block padding
+	if err != nil {
+		t.Fatalf("ParseTxtMsgContent() error = %v", err)
+	}
+	if parsed.TxtType != TxtTypeFederated || parsed.Attempt != 1 || parsed.Timestamp != 1704067200 {
+		t.Errorf("header = type %d attempt %d ts %d", parsed.TxtType, parsed.Attempt, parsed.Timestamp)
+	}
+	if !bytes.Equal(parsed.SenderPubKeyPrefix, sender) || !bytes.Equal(parsed.OriginPubKeyPrefix, origin) {
+		t.Errorf("prefixes = %x, %x", parsed.SenderPubKeyPrefix, parsed.OriginPubKeyPrefix)
+	}
+	if parsed.OriginTimestamp != 1704067100 || parsed.Message != "From afar" {
+		t.Errorf("origin ts = %d, message = %q", parsed.OriginTimestamp, parsed.Message)
+	}
+	if got := TrimTxtMsgContent(append(data, 0, 0), parsed); !bytes.Equal(got, data) {
+		t.Errorf("TrimTxtMsgContent = %x, want %x", got, data)
+	}
+
+	if _, err := ParseTxtMsgContent(data[:FederatedTxtHeaderSize-1]); err == nil {
+		t.Error("expected an error for a truncated federated header")
+	}
+}
+
 func TestBuildTxtMsgContentEmptyMessage(t *testing.T) {
 	data := BuildTxtMsgContent(1704067200, TxtTypePlain, 0, "", nil)
 
@@ -825,3 +852,23 @@ func TestFullStackControlDiscoverRoundTrip(t *testing.T) {
 		t.Errorf("Tag = %08x, want AABBCCDD", discReq.Tag)
 	}
 }
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"}, // é is two bytes
+		{"héllo", 3, "hé"},
+		{"日本", 4, "日"},
+		{"日本", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := TruncateUTF8(tt.s, tt.n); got != tt.want {
+			t.Errorf("TruncateUTF8(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
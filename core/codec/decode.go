@@ -0,0 +1,651 @@
+package codec
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Payload is a decoded packet payload. DecodePayload returns one for every
+// payload type, so consumers can type-switch on the result instead of calling
+// the Parse* function for each type.
+//
+// Encode returns the payload's wire form: decoding a well-formed payload and
+// encoding it gives back the original bytes. MarshalJSON produces an object whose
+// "type" field is the PayloadTypeName, with keys, hashes, and MACs in hex.
+type Payload interface {
+	// Type returns the payload type (PayloadType*).
+	Type() uint8
+	// String returns a one-line summary for logs.
+	String() string
+	// Encode returns the wire-format payload.
+	Encode() []byte
+
+	json.Marshaler
+}
+
+// Compile-time interface checks.
+var (
+	_ Payload = (*ReqPayload)(nil)
+	_ Payload = (*ResponsePayload)(nil)
+	_ Payload = (*TxtMsgPayload)(nil)
+	_ Payload = (*PathPayload)(nil)
+	_ Payload = (*AckPayload)(nil)
+	_ Payload = (*AdvertPayload)(nil)
+	_ Payload = (*GrpTxtPayload)(nil)
+	_ Payload = (*GrpDataPayload)(nil)
+	_ Payload = (*AnonReqPayload)(nil)
+	_ Payload = (*TracePayload)(nil)
+	_ Payload = (*MultipartPayload)(nil)
+	_ Payload = (*ControlPayload)(nil)
+	_ Payload = (*DiscoverReqPayload)(nil)
+	_ Payload = (*DiscoverRespPayload)(nil)
+	_ Payload = (*RawPayload)(nil)
+)
+
+// DecodePayload decodes a packet's payload into its typed form:
+//
+//   - REQ, RESPONSE, TXT_MSG, PATH: *ReqPayload, *ResponsePayload,
+//     *TxtMsgPayload, *PathPayload
+//   - ACK: *AckPayload
+//   - ADVERT: *AdvertPayload
+//   - GRP_TXT, GRP_DATA: *GrpTxtPayload, *GrpDataPayload
+//   - ANON_REQ: *AnonReqPayload
+//   - TRACE: *TracePayload (the per-hop SNRs are in the packet's Path)
+//   - MULTIPART: *MultipartPayload
+//   - CONTROL: *DiscoverReqPayload or *DiscoverRespPayload (mesh and node
+//     discovery share a layout), or *ControlPayload for other subtypes
+//   - RAW_CUSTOM and unassigned types: *RawPayload
+//
+// The result may share memory with pkt.Payload.
+func DecodePayload(pkt *Packet) (Payload, error) {
+	return decodePayload(pkt.PayloadType(), pkt.Payload)
+}
+
+func decodePayload(t uint8, data []byte) (Payload, error) {
+	switch t {
+	case PayloadTypeReq, PayloadTypeResponse, PayloadTypeTxtMsg, PayloadTypePath:
+		ap, err := ParseAddressedPayload(data)
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case PayloadTypeReq:
+			return &ReqPayload{*ap}, nil
+		case PayloadTypeResponse:
+			return &ResponsePayload{*ap}, nil
+		case PayloadTypeTxtMsg:
+			return &TxtMsgPayload{*ap}, nil
+		default:
+			return &PathPayload{*ap}, nil
+		}
+	case PayloadTypeAck:
+		return payloadOrErr(ParseAckPayload(data))
+	case PayloadTypeAdvert:
+		return payloadOrErr(ParseAdvertPayload(data))
+	case PayloadTypeGrpTxt, PayloadTypeGrpData:
+		gp, err := ParseGroupPayload(data)
+		if err != nil {
+			return nil, err
+		}
+		if t == PayloadTypeGrpTxt {
+			return &GrpTxtPayload{*gp}, nil
+		}
+		return &GrpDataPayload{*gp}, nil
+	case PayloadTypeAnonReq:
+		return payloadOrErr(ParseAnonReqPayload(data))
+	case PayloadTypeTrace:
+		return payloadOrErr(ParseTracePayload(data))
+	case PayloadTypeMultipart:
+		return payloadOrErr(ParseMultipartPayload(data))
+	case PayloadTypeControl:
+		ctrl, err := ParseControlPayload(data)
+		if err != nil {
+			return nil, err
+		}
+		switch ctrl.Subtype {
+		case ControlSubtypeDiscoverReq:
+			return payloadOrErr(ParseDiscoverReqFromControl(ctrl))
+		case ControlSubtypeDiscoverResp:
+			return payloadOrErr(ParseDiscoverRespFromControl(ctrl))
+		}
+		return ctrl, nil
+	default:
+		return &RawPayload{PayloadType: t, Data: data}, nil
+	}
+}
+
+// payloadOrErr returns a parser's result as a Payload, keeping a failed
+// parse's nil pointer from becoming a non-nil interface.
+func payloadOrErr[T Payload](p T, err error) (Payload, error) {
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// hexBytes marshals to JSON as a hex string.
+type hexBytes []byte
+
+// MarshalJSON encodes the bytes as a hex string.
+func (h hexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(h))
+}
+
+// keyPrefix abbreviates a public key for String output.
+func keyPrefix(key []byte) string {
+	if len(key) > 8 {
+		key = key[:8]
+	}
+	return hex.EncodeToString(key)
+}
+
+// -----------------------------------------------------------------------------
+// Addressed Payloads (REQ, RESPONSE, TXT_MSG, PATH)
+// -----------------------------------------------------------------------------
+
+// ReqPayload is a decoded REQ payload.
+type ReqPayload struct{ AddressedPayload }
+
+// ResponsePayload is a decoded RESPONSE payload.
+type ResponsePayload struct{ AddressedPayload }
+
+// TxtMsgPayload is a decoded TXT_MSG payload.
+type TxtMsgPayload struct{ AddressedPayload }
+
+// PathPayload is a decoded PATH payload.
+type PathPayload struct{ AddressedPayload }
+
+// Type returns PayloadTypeReq.
+func (p *ReqPayload) Type() uint8 { return PayloadTypeReq }
+
+// Type returns PayloadTypeResponse.
+func (p *ResponsePayload) Type() uint8 { return PayloadTypeResponse }
+
+// Type returns PayloadTypeTxtMsg.
+func (p *TxtMsgPayload) Type() uint8 { return PayloadTypeTxtMsg }
+
+// Type returns PayloadTypePath.
+func (p *PathPayload) Type() uint8 { return PayloadTypePath }
+
+// String returns a one-line summary of the REQ.
+func (p *ReqPayload) String() string { return p.describe(p.Type()) }
+
+// String returns a one-line summary of the RESPONSE.
+func (p *ResponsePayload) String() string { return p.describe(p.Type()) }
+
+// String returns a one-line summary of the TXT_MSG.
+func (p *TxtMsgPayload) String() string { return p.describe(p.Type()) }
+
+// String returns a one-line summary of the PATH.
+func (p *PathPayload) String() string { return p.describe(p.Type()) }
+
+// Encode builds the wire-format REQ.
+func (p *ReqPayload) Encode() []byte { return p.encode() }
+
+// Encode builds the wire-format RESPONSE.
+func (p *ResponsePayload) Encode() []byte { return p.encode() }
+
+// Encode builds the wire-format TXT_MSG.
+func (p *TxtMsgPayload) Encode() []byte { return p.encode() }
+
+// Encode builds the wire-format PATH.
+func (p *PathPayload) Encode() []byte { return p.encode() }
+
+// MarshalJSON encodes the REQ with its hashes, MAC, and ciphertext in hex.
+func (p *ReqPayload) MarshalJSON() ([]byte, error) { return p.marshal(p.Type()) }
+
+// MarshalJSON encodes the RESPONSE with its hashes, MAC, and ciphertext in hex.
+func (p *ResponsePayload) MarshalJSON() ([]byte, error) { return p.marshal(p.Type()) }
+
+// MarshalJSON encodes the TXT_MSG with its hashes, MAC, and ciphertext in hex.
+func (p *TxtMsgPayload) MarshalJSON() ([]byte, error) { return p.marshal(p.Type()) }
+
+// MarshalJSON encodes the PATH with its hashes, MAC, and ciphertext in hex.
+func (p *PathPayload) MarshalJSON() ([]byte, error) { return p.marshal(p.Type()) }
+
+func (a *AddressedPayload) describe(t uint8) string {
+	return fmt.Sprintf("%s dest=%02x src=%02x mac=%04x len=%d",
+		PayloadTypeName(t), a.DestHash, a.SrcHash, a.MAC, len(a.Ciphertext))
+}
+
+func (a *AddressedPayload) encode() []byte {
+	return BuildAddressedPayload(a.DestHash, a.SrcHash, a.MAC, a.Ciphertext)
+}
+
+func (a *AddressedPayload) marshal(t uint8) ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string   `json:"type"`
+		DestHash   string   `json:"dest_hash"`
+		SrcHash    string   `json:"src_hash"`
+		MAC        string   `json:"mac"`
+		Ciphertext hexBytes `json:"ciphertext"`
+	}{
+		Type:       PayloadTypeName(t),
+		DestHash:   fmt.Sprintf("%02x", a.DestHash),
+		SrcHash:    fmt.Sprintf("%02x", a.SrcHash),
+		MAC:        fmt.Sprintf("%04x", a.MAC),
+		Ciphertext: a.Ciphertext,
+	})
+}
+
+// -----------------------------------------------------------------------------
+// ACK
+// -----------------------------------------------------------------------------
+
+// Type returns PayloadTypeAck.
+func (a *AckPayload) Type() uint8 { return PayloadTypeAck }
+
+// String returns the checksum, and the attempt and random byte if Extended.
+func (a *AckPayload) String() string {
+	if a.Extended {
+		return fmt.Sprintf("ACK checksum=%08x attempt=%d rnd=%02x", a.Checksum, a.Attempt, a.Rnd)
+	}
+	return fmt.Sprintf("ACK checksum=%08x", a.Checksum)
+}
+
+// Encode builds the 4-byte ACK, or the extended form if Extended is set.
+func (a *AckPayload) Encode() []byte {
+	if a.Extended {
+		return BuildAckPayloadExt(a.Checksum, a.Attempt, a.Rnd)
+	}
+	return BuildAckPayload(a.Checksum)
+}
+
+// MarshalJSON encodes the checksum in hex, with attempt and rnd if Extended.
+func (a *AckPayload) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Type     string `json:"type"`
+		Checksum string `json:"checksum"`
+		Attempt  *uint8 `json:"attempt,omitempty"`
+		Rnd      *uint8 `json:"rnd,omitempty"`
+	}{
+		Type:     PayloadTypeName(PayloadTypeAck),
+		Checksum: fmt.Sprintf("%08x", a.Checksum),
+	}
+	if a.Extended {
+		v.Attempt, v.Rnd = &a.Attempt, &a.Rnd
+	}
+	return json.Marshal(v)
+}
+
+// -----------------------------------------------------------------------------
+// ADVERT
+// -----------------------------------------------------------------------------
+
+// Type returns PayloadTypeAdvert.
+func (a *AdvertPayload) Type() uint8 { return PayloadTypeAdvert }
+
+// String returns the key prefix, timestamp, and the node type, name, and
+// location from the app data.
+func (a *AdvertPayload) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ADVERT key=%s ts=%d", keyPrefix(a.PubKey[:]), a.Timestamp)
+	if ad := a.AppData; ad != nil {
+		fmt.Fprintf(&b, " %s", NodeTypeName(ad.NodeType))
+		if ad.Name != "" {
+			fmt.Fprintf(&b, " %q", ad.Name)
+		}
+		if ad.HasLocation() {
+			fmt.Fprintf(&b, " loc=%.6f,%.6f", *ad.Lat, *ad.Lon)
+		}
+	}
+	return b.String()
+}
+
+// Encode builds the wire-format advert. The app data's node type and
+// sections come from its fields, but the flag bits set in AppData.Flags are
+// kept, so a decoded advert whose flags claim an empty section re-encodes to
+// the signed bytes.
+func (a *AdvertPayload) Encode() []byte {
+	var appData []byte
+	if ad := a.AppData; ad != nil {
+		appData = buildAdvertAppData(ad, advertAppDataFlags(ad)|ad.Flags&^0x0F)
+	}
+	return buildAdvertPayload(a.PubKey, a.Timestamp, a.Signature, appData)
+}
+
+// MarshalJSON encodes the key and signature in hex, and the app data fields
+// with the node type by name.
+func (a *AdvertPayload) MarshalJSON() ([]byte, error) {
+	type appData struct {
+		NodeType string   `json:"node_type"`
+		Name     string   `json:"name,omitempty"`
+		Lat      *float64 `json:"lat,omitempty"`
+		Lon      *float64 `json:"lon,omitempty"`
+		Feature1 *uint16  `json:"feature1,omitempty"`
+		Feature2 *uint16  `json:"feature2,omitempty"`
+	}
+	v := struct {
+		Type      string   `json:"type"`
+		PubKey    hexBytes `json:"public_key"`
+		Timestamp uint32   `json:"timestamp"`
+		Signature hexBytes `json:"signature"`
+		AppData   *appData `json:"app_data,omitempty"`
+	}{
+		Type:      PayloadTypeName(PayloadTypeAdvert),
+		PubKey:    a.PubKey[:],
+		Timestamp: a.Timestamp,
+		Signature: a.Signature[:],
+	}
+	if ad := a.AppData; ad != nil {
+		v.AppData = &appData{
+			NodeType: NodeTypeName(ad.NodeType),
+			Name:     ad.Name,
+			Lat:      ad.Lat,
+			Lon:      ad.Lon,
+			Feature1: ad.Feature1,
+			Feature2: ad.Feature2,
+		}
+	}
+	return json.Marshal(v)
+}
+
+// -----------------------------------------------------------------------------
+// Group Payloads (GRP_TXT, GRP_DATA)
+// -----------------------------------------------------------------------------
+
+// GrpTxtPayload is a decoded GRP_TXT payload.
+type GrpTxtPayload struct{ GroupPayload }
+
+// GrpDataPayload is a decoded GRP_DATA payload.
+type GrpDataPayload struct{ GroupPayload }
+
+// Type returns PayloadTypeGrpTxt.
+func (p *GrpTxtPayload) Type() uint8 { return PayloadTypeGrpTxt }
+
+// Type returns PayloadTypeGrpData.
+func (p *GrpDataPayload) Type() uint8 { return PayloadTypeGrpData }
+
+// String returns a one-line summary of the GRP_TXT.
+func (p *GrpTxtPayload) String() string { return p.describe(p.Type()) }
+
+// String returns a one-line summary of the GRP_DATA.
+func (p *GrpDataPayload) String() string { return p.describe(p.Type()) }
+
+// Encode builds the wire-format GRP_TXT.
+func (p *GrpTxtPayload) Encode() []byte { return p.encode() }
+
+// Encode builds the wire-format GRP_DATA.
+func (p *GrpDataPayload) Encode() []byte { return p.encode() }
+
+// MarshalJSON encodes the GRP_TXT with its channel hash, MAC, and ciphertext in hex.
+func (p *GrpTxtPayload) MarshalJSON() ([]byte, error) { return p.marshal(p.Type()) }
+
+// MarshalJSON encodes the GRP_DATA with its channel hash, MAC, and ciphertext in hex.
+func (p *GrpDataPayload) MarshalJSON() ([]byte, error) { return p.marshal(p.Type()) }
+
+func (g *GroupPayload) describe(t uint8) string {
+	return fmt.Sprintf("%s channel=%02x mac=%04x len=%d",
+		PayloadTypeName(t), g.ChannelHash, g.MAC, len(g.Ciphertext))
+}
+
+func (g *GroupPayload) encode() []byte {
+	return BuildGroupPayload(g.ChannelHash, g.MAC, g.Ciphertext)
+}
+
+func (g *GroupPayload) marshal(t uint8) ([]byte, error) {
+	return json.Marshal(struct {
+		Type        string   `json:"type"`
+		ChannelHash string   `json:"channel_hash"`
+		MAC         string   `json:"mac"`
+		Ciphertext  hexBytes `json:"ciphertext"`
+	}{
+		Type:        PayloadTypeName(t),
+		ChannelHash: fmt.Sprintf("%02x", g.ChannelHash),
+		MAC:         fmt.Sprintf("%04x", g.MAC),
+		Ciphertext:  g.Ciphertext,
+	})
+}
+
+// -----------------------------------------------------------------------------
+// ANON_REQ
+// -----------------------------------------------------------------------------
+
+// Type returns PayloadTypeAnonReq.
+func (a *AnonReqPayload) Type() uint8 { return PayloadTypeAnonReq }
+
+// String returns the destination hash, key prefix, MAC, and ciphertext length.
+func (a *AnonReqPayload) String() string {
+	return fmt.Sprintf("ANON_REQ dest=%02x key=%s mac=%04x len=%d",
+		a.DestHash, keyPrefix(a.PubKey[:]), a.MAC, len(a.Ciphertext))
+}
+
+// Encode builds the wire-format anonymous request.
+func (a *AnonReqPayload) Encode() []byte {
+	return BuildAnonReqPayload(a.DestHash, a.PubKey, a.MAC, a.Ciphertext)
+}
+
+// MarshalJSON encodes the hashes, key, MAC, and ciphertext in hex.
+func (a *AnonReqPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string   `json:"type"`
+		DestHash   string   `json:"dest_hash"`
+		PubKey     hexBytes `json:"public_key"`
+		MAC        string   `json:"mac"`
+		Ciphertext hexBytes `json:"ciphertext"`
+	}{
+		Type:       PayloadTypeName(PayloadTypeAnonReq),
+		DestHash:   fmt.Sprintf("%02x", a.DestHash),
+		PubKey:     a.PubKey[:],
+		MAC:        fmt.Sprintf("%04x", a.MAC),
+		Ciphertext: a.Ciphertext,
+	})
+}
+
+// -----------------------------------------------------------------------------
+// TRACE
+// -----------------------------------------------------------------------------
+
+// Type returns PayloadTypeTrace.
+func (tp *TracePayload) Type() uint8 { return PayloadTypeTrace }
+
+// String returns the tag, auth code, and hop count.
+func (tp *TracePayload) String() string {
+	return fmt.Sprintf("TRACE tag=%08x auth=%08x hops=%d", tp.Tag, tp.AuthCode, tp.HopCount())
+}
+
+// Encode builds the wire-format trace payload.
+func (tp *TracePayload) Encode() []byte {
+	return BuildTracePayload(tp.Tag, tp.AuthCode, tp.Flags, tp.PathHashes)
+}
+
+// MarshalJSON encodes the tag, auth code, and each path hash in hex.
+func (tp *TracePayload) MarshalJSON() ([]byte, error) {
+	hashes := make([]string, tp.HopCount())
+	for i := range hashes {
+		hashes[i] = hex.EncodeToString(tp.HashAt(i))
+	}
+	return json.Marshal(struct {
+		Type     string   `json:"type"`
+		Tag      string   `json:"tag"`
+		AuthCode string   `json:"auth_code"`
+		Flags    uint8    `json:"flags"`
+		HashSize int      `json:"hash_size"`
+		Hashes   []string `json:"hashes"`
+	}{
+		Type:     PayloadTypeName(PayloadTypeTrace),
+		Tag:      fmt.Sprintf("%08x", tp.Tag),
+		AuthCode: fmt.Sprintf("%08x", tp.AuthCode),
+		Flags:    tp.Flags,
+		HashSize: tp.HashSize,
+		Hashes:   hashes,
+	})
+}
+
+// -----------------------------------------------------------------------------
+// MULTIPART
+// -----------------------------------------------------------------------------
+
+// Type returns PayloadTypeMultipart.
+func (m *MultipartPayload) Type() uint8 { return PayloadTypeMultipart }
+
+// String returns the remaining count, inner type, and data length.
+func (m *MultipartPayload) String() string {
+	return fmt.Sprintf("MULTIPART remaining=%d inner=%s len=%d",
+		m.Remaining, PayloadTypeName(m.InnerType), len(m.Data))
+}
+
+// Encode builds the wire-format multipart payload.
+func (m *MultipartPayload) Encode() []byte {
+	return BuildMultipartPayload(m.Remaining, m.InnerType, m.Data)
+}
+
+// Inner decodes the inner payload as its InnerType.
+func (m *MultipartPayload) Inner() (Payload, error) {
+	return decodePayload(m.InnerType, m.Data)
+}
+
+// MarshalJSON encodes the data in hex, with the inner type by name.
+func (m *MultipartPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type      string   `json:"type"`
+		Remaining uint8    `json:"remaining"`
+		InnerType string   `json:"inner_type"`
+		Data      hexBytes `json:"data"`
+	}{
+		Type:      PayloadTypeName(PayloadTypeMultipart),
+		Remaining: m.Remaining,
+		InnerType: PayloadTypeName(m.InnerType),
+		Data:      m.Data,
+	})
+}
+
+// -----------------------------------------------------------------------------
+// CONTROL
+// -----------------------------------------------------------------------------
+
+// Type returns PayloadTypeControl.
+func (c *ControlPayload) Type() uint8 { return PayloadTypeControl }
+
+// String returns the subtype, flags, and data length.
+func (c *ControlPayload) String() string {
+	return fmt.Sprintf("CONTROL %s flags=%02x len=%d", ControlSubtypeName(c.Subtype), c.Flags, len(c.Data))
+}
+
+// Encode builds the wire-format control payload.
+func (c *ControlPayload) Encode() []byte {
+	return BuildControlPayload(c.Flags, c.Data)
+}
+
+// MarshalJSON encodes the flags and data in hex, with the subtype by name.
+func (c *ControlPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type    string   `json:"type"`
+		Subtype string   `json:"subtype"`
+		Flags   string   `json:"flags"`
+		Data    hexBytes `json:"data"`
+	}{
+		Type:    PayloadTypeName(PayloadTypeControl),
+		Subtype: ControlSubtypeName(c.Subtype),
+		Flags:   fmt.Sprintf("%02x", c.Flags),
+		Data:    c.Data,
+	})
+}
+
+// Type returns PayloadTypeControl.
+func (d *DiscoverReqPayload) Type() uint8 { return PayloadTypeControl }
+
+// String returns the tag, type filter, since, and prefix-only flag.
+func (d *DiscoverReqPayload) String() string {
+	return fmt.Sprintf("CONTROL DISCOVER_REQ tag=%08x filter=%02x since=%d prefix_only=%t",
+		d.Tag, d.TypeFilter, d.Since, d.PrefixOnly)
+}
+
+// Encode builds the wire-format DISCOVER_REQ, including the since field if
+// HasSince is set or Since is non-zero.
+func (d *DiscoverReqPayload) Encode() []byte {
+	data := BuildDiscoverReqPayload(d.PrefixOnly, d.TypeFilter, d.Tag, d.Since)
+	if d.HasSince && d.Since == 0 {
+		data = append(data, 0, 0, 0, 0)
+	}
+	return data
+}
+
+// MarshalJSON encodes the tag and type filter in hex.
+func (d *DiscoverReqPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string `json:"type"`
+		Subtype    string `json:"subtype"`
+		TypeFilter string `json:"type_filter"`
+		Tag        string `json:"tag"`
+		Since      uint32 `json:"since"`
+		PrefixOnly bool   `json:"prefix_only"`
+	}{
+		Type:       PayloadTypeName(PayloadTypeControl),
+		Subtype:    ControlSubtypeName(ControlSubtypeDiscoverReq),
+		TypeFilter: fmt.Sprintf("%02x", d.TypeFilter),
+		Tag:        fmt.Sprintf("%08x", d.Tag),
+		Since:      d.Since,
+		PrefixOnly: d.PrefixOnly,
+	})
+}
+
+// Type returns PayloadTypeControl.
+func (d *DiscoverRespPayload) Type() uint8 { return PayloadTypeControl }
+
+// String returns the tag, node type, key prefix, and SNR.
+func (d *DiscoverRespPayload) String() string {
+	return fmt.Sprintf("CONTROL DISCOVER_RESP tag=%08x %s key=%s snr=%.2f",
+		d.Tag, NodeTypeName(d.NodeType), keyPrefix(d.PubKey), d.GetSNR())
+}
+
+// Encode builds the wire-format DISCOVER_RESP.
+func (d *DiscoverRespPayload) Encode() []byte {
+	return BuildDiscoverRespPayload(d.NodeType, d.SNR, d.Tag, d.PubKey)
+}
+
+// MarshalJSON encodes the tag and key in hex, with the node type by name.
+func (d *DiscoverRespPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type     string   `json:"type"`
+		Subtype  string   `json:"subtype"`
+		NodeType string   `json:"node_type"`
+		SNR      float32  `json:"snr"`
+		Tag      string   `json:"tag"`
+		PubKey   hexBytes `json:"public_key"`
+	}{
+		Type:     PayloadTypeName(PayloadTypeControl),
+		Subtype:  ControlSubtypeName(ControlSubtypeDiscoverResp),
+		NodeType: NodeTypeName(d.NodeType),
+		SNR:      d.GetSNR(),
+		Tag:      fmt.Sprintf("%08x", d.Tag),
+		PubKey:   d.PubKey,
+	})
+}
+
+// -----------------------------------------------------------------------------
+// RAW_CUSTOM and unassigned types
+// -----------------------------------------------------------------------------
+
+// RawPayload is a payload with no defined structure: RAW_CUSTOM, or a type
+// this package does not know.
+type RawPayload struct {
+	PayloadType uint8
+	Data        []byte
+}
+
+// Type returns the packet's payload type.
+func (r *RawPayload) Type() uint8 { return r.PayloadType }
+
+// String returns the type name and data length.
+func (r *RawPayload) String() string {
+	return fmt.Sprintf("%s len=%d", PayloadTypeName(r.PayloadType), len(r.Data))
+}
+
+// Encode returns a copy of the payload bytes.
+func (r *RawPayload) Encode() []byte {
+	return append([]byte(nil), r.Data...)
+}
+
+// MarshalJSON encodes the data in hex.
+func (r *RawPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string   `json:"type"`
+		Data hexBytes `json:"data"`
+	}{
+		Type: PayloadTypeName(r.PayloadType),
+		Data: r.Data,
+	})
+}
@@ -0,0 +1,185 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodePayload_RoundTrip(t *testing.T) {
+	var pubKey [32]byte
+	var sig [64]byte
+	for i := range pubKey {
+		pubKey[i] = byte(i)
+	}
+	lat, lon := 47.606209, -122.332071
+	appData := &AdvertAppData{NodeType: NodeTypeRepeater, Name: "Hill", Lat: &lat, Lon: &lon}
+	ct := []byte{0xc1, 0xc2, 0xc3, 0xc4}
+
+	tests := []struct {
+		name     string
+		pt       uint8
+		payload  []byte
+		wantType any
+	}{
+		{"req", PayloadTypeReq, BuildAddressedPayload(0xaa, 0xbb, 0x1234, ct), &ReqPayload{}},
+		{"response", PayloadTypeResponse, BuildAddressedPayload(0xaa, 0xbb, 0x1234, ct), &ResponsePayload{}},
+		{"txt_msg", PayloadTypeTxtMsg, BuildAddressedPayload(0xaa, 0xbb, 0x1234, ct), &TxtMsgPayload{}},
+		{"path", PayloadTypePath, BuildAddressedPayload(0xaa, 0xbb, 0x1234, ct), &PathPayload{}},
+		{"ack", PayloadTypeAck, BuildAckPayload(0xdeadbeef), &AckPayload{}},
+		{"ack_ext", PayloadTypeAck, BuildAckPayloadExt(0xdeadbeef, 2, 0x7f), &AckPayload{}},
+		{"advert", PayloadTypeAdvert, BuildAdvertPayload(pubKey, 1700000000, sig, appData), &AdvertPayload{}},
+		{"advert_bare", PayloadTypeAdvert, BuildAdvertPayload(pubKey, 1700000000, sig, nil), &AdvertPayload{}},
+		{"grp_txt", PayloadTypeGrpTxt, BuildGroupPayload(0x11, 0x2222, ct), &GrpTxtPayload{}},
+		{"grp_data", PayloadTypeGrpData, BuildGroupPayload(0x11, 0x2222, ct), &GrpDataPayload{}},
+		{"anon_req", PayloadTypeAnonReq, BuildAnonReqPayload(0xaa, pubKey, 0x3333, ct), &AnonReqPayload{}},
+		{"trace", PayloadTypeTrace, BuildTracePayload(1, 2, 0, []byte{0xa1, 0xb2}), &TracePayload{}},
+		{"multipart", PayloadTypeMultipart, BuildMultipartPayload(3, PayloadTypeAck, BuildAckPayload(1)), &MultipartPayload{}},
+		{"discover_req", PayloadTypeControl, BuildDiscoverReqPayload(true, 0x04, 0x55667788, 1700000000), &DiscoverReqPayload{}},
+		{"discover_req_no_since", PayloadTypeControl, BuildDiscoverReqPayload(false, 0x04, 0x55667788, 0), &DiscoverReqPayload{}},
+		{"node_discover_req", PayloadTypeControl, BuildNodeDiscoverReqPayload(0x04, 0x55667788, 0), &DiscoverReqPayload{}},
+		{"discover_resp", PayloadTypeControl, BuildDiscoverRespPayload(NodeTypeRepeater, -22, 0x55667788, pubKey[:8]), &DiscoverRespPayload{}},
+		{"control_other", PayloadTypeControl, BuildControlPayload(0x30, []byte{1, 2}), &ControlPayload{}},
+		{"raw_custom", PayloadTypeRawCustom, []byte{9, 8, 7}, &RawPayload{}},
+		{"unassigned", 0x0C, []byte{1}, &RawPayload{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkt := NewPacket(tt.pt, RouteTypeFlood, tt.payload)
+			p, err := DecodePayload(pkt)
+			if err != nil {
+				t.Fatalf("DecodePayload: %v", err)
+			}
+			if reflect.TypeOf(p) != reflect.TypeOf(tt.wantType) {
+				t.Fatalf("got %T, want %T", p, tt.wantType)
+			}
+			if p.Type() != tt.pt {
+				t.Errorf("Type() = %d, want %d", p.Type(), tt.pt)
+			}
+			if got := p.Encode(); !bytes.Equal(got, tt.payload) {
+				t.Errorf("Encode() = %x, want %x", got, tt.payload)
+			}
+			if !strings.HasPrefix(p.String(), PayloadTypeName(tt.pt)) {
+				t.Errorf("String() = %q", p.String())
+			}
+
+			data, err := json.Marshal(p)
+			if err != nil {
+				t.Fatalf("MarshalJSON: %v", err)
+			}
+			var obj map[string]any
+			if err := json.Unmarshal(data, &obj); err != nil {
+				t.Fatalf("invalid JSON %s: %v", data, err)
+			}
+			if obj["type"] != PayloadTypeName(tt.pt) {
+				t.Errorf("JSON type = %v, want %s", obj["type"], PayloadTypeName(tt.pt))
+			}
+		})
+	}
+}
+
+// TestAdvertPayload_EncodeKeepsFlags re-encodes adverts whose flags byte
+// carries bits the fields alone don't imply: an unknown node type, or a
+// section flag with an empty section. The signature covers the flags, so
+// Encode must give back the original bytes.
+func TestAdvertPayload_EncodeKeepsFlags(t *testing.T) {
+	var pubKey [32]byte
+	var sig [64]byte
+	for i := range pubKey {
+		pubKey[i] = byte(0x40 + i)
+	}
+	header := BuildAdvertPayload(pubKey, 1700000000, sig, nil)
+
+	tests := []struct {
+		name    string
+		appData []byte
+	}{
+		{"empty_name_unknown_type", []byte{0x8c}},
+		{"empty_name", []byte{FlagHasName | NodeTypeChat}},
+		{"feature1_unknown_type", []byte{0x2c, 0x34, 0x12}},
+		{"all_sections", []byte{0xf5,
+			0x40, 0x42, 0x0f, 0x00, 0xc0, 0xbd, 0xf0, 0xff, // lat 1.0, lon -1.0
+			0x01, 0x00, 0x02, 0x00, 'N', 'o', 'd', 'e'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := append(append([]byte(nil), header...), tt.appData...)
+			p, err := DecodePayload(NewPacket(PayloadTypeAdvert, RouteTypeFlood, payload))
+			if err != nil {
+				t.Fatalf("DecodePayload: %v", err)
+			}
+			if got := p.Encode(); !bytes.Equal(got, payload) {
+				t.Errorf("Encode() app data = %x, want %x", got[AdvertMinSize:], tt.appData)
+			}
+		})
+	}
+}
+
+func TestDecodePayload_Errors(t *testing.T) {
+	for _, pt := range []uint8{
+		PayloadTypeReq, PayloadTypeAck, PayloadTypeAdvert, PayloadTypeGrpTxt,
+		PayloadTypeAnonReq, PayloadTypeTrace, PayloadTypeMultipart, PayloadTypeControl,
+	} {
+		p, err := DecodePayload(NewPacket(pt, RouteTypeFlood, nil))
+		if err == nil || p != nil {
+			t.Errorf("%s: got %v, %v; want nil payload and an error", PayloadTypeName(pt), p, err)
+		}
+	}
+
+	// A truncated discover request fails rather than falling back to
+	// ControlPayload.
+	p, err := DecodePayload(NewPacket(PayloadTypeControl, RouteTypeFlood,
+		BuildControlPayload(ControlSubtypeDiscoverReq<<4, []byte{1})))
+	if err == nil || p != nil {
+		t.Errorf("truncated DISCOVER_REQ: got %v, %v", p, err)
+	}
+
+	_, err = DecodePayload(NewPacket(PayloadTypeAck, RouteTypeFlood, []byte{1}))
+	if !errors.Is(err, ErrAckTooShort) {
+		t.Errorf("err = %v, want ErrAckTooShort", err)
+	}
+}
+
+func TestDecodePayload_Fields(t *testing.T) {
+	p, err := DecodePayload(NewPacket(PayloadTypeAck, RouteTypeDirect, BuildAckPayloadExt(0xdeadbeef, 2, 0x7f)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ack := p.(*AckPayload)
+	if !ack.Extended || ack.Checksum != 0xdeadbeef || ack.Attempt != 2 || ack.Rnd != 0x7f {
+		t.Errorf("ack = %+v", ack)
+	}
+	if s := ack.String(); s != "ACK checksum=deadbeef attempt=2 rnd=7f" {
+		t.Errorf("String() = %q", s)
+	}
+	data, _ := json.Marshal(ack)
+	if string(data) != `{"type":"ACK","checksum":"deadbeef","attempt":2,"rnd":127}` {
+		t.Errorf("JSON = %s", data)
+	}
+
+	p, err = DecodePayload(NewPacket(PayloadTypeTxtMsg, RouteTypeFlood,
+		BuildAddressedPayload(0xaa, 0xbb, 0x1234, []byte{0xc1, 0xc2})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = json.Marshal(p)
+	if string(data) != `{"type":"TXT_MSG","dest_hash":"aa","src_hash":"bb","mac":"1234","ciphertext":"c1c2"}` {
+		t.Errorf("JSON = %s", data)
+	}
+
+	p, err = DecodePayload(NewPacket(PayloadTypeMultipart, RouteTypeFlood,
+		BuildMultipartPayload(1, PayloadTypeAck, BuildAckPayload(0x01020304))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := p.(*MultipartPayload).Inner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := inner.(*AckPayload); !ok || a.Checksum != 0x01020304 {
+		t.Errorf("inner = %v", inner)
+	}
+}
@@ -1,6 +1,9 @@
 package codec
 
-import "bytes"
+import (
+	"bytes"
+	"unicode/utf8"
+)
 
 // NewPacket creates a packet with the header correctly constructed from
 // the payload type and route type, avoiding manual bit shifting.
@@ -46,8 +49,11 @@ func ReverseFloodPath(pkt *Packet) []byte {
 // For signed messages:    9 + len(message text before first null byte)
 func TrimTxtMsgContent(plaintext []byte, content *TxtMsgContent) []byte {
 	headerSize := 5
-	if content.TxtType == TxtTypeSigned {
+	switch content.TxtType {
+	case TxtTypeSigned:
 		headerSize = 9
+	case TxtTypeFederated:
+		headerSize = FederatedTxtHeaderSize
 	}
 
 	if len(plaintext) <= headerSize {
@@ -76,3 +82,15 @@ func TrimRequestContent(plaintext []byte, content *RequestContent) []byte {
 	}
 	return plaintext
 }
+
+// TruncateUTF8 cuts s to at most n bytes without splitting a UTF-8 rune, for
+// text fields with a byte limit on the wire such as MaxTextLen.
+func TruncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
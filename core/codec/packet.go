@@ -68,6 +68,11 @@ type Packet struct {
 	Path           []byte    // Actual path bytes: HopCount * HashSize bytes
 	Payload        []byte    // Up to 184 bytes
 	SNR            int8      // Signal-to-noise ratio (raw value, multiply by 0.25 for dB)
+
+	// Rx describes how the packet was received: time, transport, and signal
+	// data where the transport reports it. Not part of the wire format; nil
+	// for packets built locally.
+	Rx *RxMeta
 }
 
 // RouteType returns the routing type from the header (2-bit field).
@@ -142,6 +147,10 @@ func (p *Packet) Clone() *Packet {
 		PathHashSize:   p.PathHashSize,
 		SNR:            p.SNR,
 	}
+	if p.Rx != nil {
+		rx := *p.Rx
+		clone.Rx = &rx
+	}
 	if len(p.Path) > 0 {
 		clone.Path = make([]byte, len(p.Path))
 		copy(clone.Path, p.Path)
@@ -154,7 +163,7 @@ func (p *Packet) Clone() *Packet {
 }
 
 // ReadFrom decodes a packet from raw bytes.
-// The SNR field is not included in the wire format and must be set separately.
+// The SNR and Rx fields are not included in the wire format and must be set separately.
 func (p *Packet) ReadFrom(data []byte) error {
 	if len(data) < 2 {
 		return ErrPacketTooShort
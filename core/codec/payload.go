@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 const (
@@ -73,6 +74,16 @@ const (
 	TxtTypeCLI    = 0x01 // CLI command
 	TxtTypeSigned = 0x02 // Signed plain text message
 
+	// TxtTypeFederated is a room post relayed between federated room servers:
+	// a signed message that also carries the origin room's key prefix and
+	// the post's origin timestamp. It is a meshcore-go extension.
+	TxtTypeFederated = 0x03
+
+	// FederatedTxtHeaderSize is the TxtTypeFederated content header:
+	// timestamp(4) + type/attempt(1) + sender_prefix(4) + origin_prefix(4) +
+	// origin_timestamp(4).
+	FederatedTxtHeaderSize = 17
+
 	// Request types (inner type byte in decrypted REQ content)
 	ReqTypeLogin         = 0x00
 	ReqTypeGetStats      = 0x01
@@ -83,6 +94,10 @@ const (
 	ReqTypeGetNeighbors  = 0x06
 	ReqTypeGetOwnerInfo  = 0x07
 
+	// ReqTypeGetPostHistory requests a page of older room posts. It is a
+	// meshcore-go extension; firmware room servers do not answer it.
+	ReqTypeGetPostHistory = 0x40
+
 	// Anonymous request types (inner type byte in decrypted ANON_REQ content)
 	AnonReqTypeRegions = 0x01
 	AnonReqTypeOwner   = 0x02
@@ -118,6 +133,7 @@ var (
 	ErrControlTooShort   = errors.New("control payload too short")
 	ErrTxtMsgTooShort    = errors.New("text message too short")
 	ErrRequestTooShort   = errors.New("request payload too short")
+	ErrHistoryTooShort   = errors.New("post history too short")
 )
 
 // AdvertPayload represents a parsed node advertisement payload.
@@ -266,6 +282,11 @@ func (a *AdvertAppData) GetNodeTypeName() string {
 // AckPayload represents an acknowledgment payload.
 type AckPayload struct {
 	Checksum uint32 // CRC checksum of message timestamp, text, and sender pubkey
+
+	// For extended ACKs (AckSizeExt bytes)
+	Extended bool  // Attempt and Rnd are present
+	Attempt  uint8 // Sender's attempt byte
+	Rnd      uint8 // Random byte
 }
 
 // ParseAckPayload parses an ACK payload.
@@ -273,9 +294,15 @@ func ParseAckPayload(data []byte) (*AckPayload, error) {
 	if len(data) < AckSize {
 		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrAckTooShort, AckSize, len(data))
 	}
-	return &AckPayload{
+	ack := &AckPayload{
 		Checksum: binary.LittleEndian.Uint32(data[0:4]),
-	}, nil
+	}
+	if len(data) >= AckSizeExt {
+		ack.Extended = true
+		ack.Attempt = data[4]
+		ack.Rnd = data[5]
+	}
+	return ack, nil
 }
 
 // -----------------------------------------------------------------------------
@@ -316,7 +343,11 @@ type TxtMsgContent struct {
 	Attempt   uint8  // Attempt number (lower 2 bits): 0-3
 	Message   string // Message content
 	// For signed messages (TxtType == TxtTypeSigned)
-	SenderPubKeyPrefix []byte // First 4 bytes of sender's public key (only for signed)
+	SenderPubKeyPrefix []byte // First 4 bytes of sender's public key (signed and federated)
+
+	// For federated messages (TxtType == TxtTypeFederated)
+	OriginPubKeyPrefix []byte // First 4 bytes of the origin room's public key
+	OriginTimestamp    uint32 // Time the post was stored on the origin room
 }
 
 // ParseTxtMsgContent parses decrypted text message content.
@@ -338,6 +369,14 @@ func ParseTxtMsgContent(data []byte) (*TxtMsgContent, error) {
 		}
 		content.SenderPubKeyPrefix = data[5:9]
 		messageStart = 9
+	} else if content.TxtType == TxtTypeFederated {
+		if len(data) < FederatedTxtHeaderSize {
+			return nil, fmt.Errorf("%w: federated message needs origin header", ErrTxtMsgTooShort)
+		}
+		content.SenderPubKeyPrefix = data[5:9]
+		content.OriginPubKeyPrefix = data[9:13]
+		content.OriginTimestamp = binary.LittleEndian.Uint32(data[13:17])
+		messageStart = FederatedTxtHeaderSize
 	}
 
 	if messageStart < len(data) {
@@ -362,6 +401,8 @@ func TxtTypeName(t uint8) string {
 		return "cli"
 	case TxtTypeSigned:
 		return "signed"
+	case TxtTypeFederated:
+		return "federated"
 	default:
 		return fmt.Sprintf("unknown(%d)", t)
 	}
@@ -409,11 +450,142 @@ func RequestTypeName(t uint8) string {
 		return "get_neighbors"
 	case ReqTypeGetOwnerInfo:
 		return "get_owner_info"
+	case ReqTypeGetPostHistory:
+		return "get_post_history"
 	default:
 		return fmt.Sprintf("unknown(%d)", t)
 	}
 }
 
+// OwnerInfo is the body of a REQ_TYPE_GET_OWNER_INFO response.
+type OwnerInfo struct {
+	FirmwareVersion string // e.g. "v1.16.0"
+	Name            string // node name
+	Owner           string // free-form owner/contact text; may contain newlines
+}
+
+// BuildOwnerInfo encodes an owner-info response body as
+// "<firmware version>\n<name>\n<owner info>" (firmware sprintf "%s\n%s\n%s").
+func BuildOwnerInfo(info OwnerInfo) []byte {
+	return []byte(info.FirmwareVersion + "\n" + info.Name + "\n" + info.Owner)
+}
+
+// ParseOwnerInfo decodes an owner-info response body. Trailing NUL padding (from
+// block encryption) is dropped. Missing fields are left empty.
+func ParseOwnerInfo(data []byte) OwnerInfo {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	parts := strings.SplitN(string(data), "\n", 3)
+	var info OwnerInfo
+	info.FirmwareVersion = parts[0]
+	if len(parts) > 1 {
+		info.Name = parts[1]
+	}
+	if len(parts) > 2 {
+		info.Owner = parts[2]
+	}
+	return info
+}
+
+const (
+	// PostHistoryHeaderSize is the size of a post history response body
+	// header: flags(1) + count(1).
+	PostHistoryHeaderSize = 2
+
+	// PostHistoryEntryHeaderSize is the size of one post history entry before
+	// its text: timestamp(4) + sender_prefix(4) + text_len(1).
+	PostHistoryEntryHeaderSize = 9
+
+	// PostHistoryFlagMore is set when older posts remain after the page.
+	PostHistoryFlagMore = 0x01
+)
+
+// PostHistoryEntry is one post in a post history response.
+type PostHistoryEntry struct {
+	Timestamp    uint32  // post timestamp (room clock)
+	SenderPrefix [4]byte // first 4 bytes of the author's public key
+	Text         string  // message text
+}
+
+// PostHistory is the body of a REQ_TYPE_GET_POST_HISTORY response: a page of
+// posts, newest first.
+type PostHistory struct {
+	More  bool // older posts remain; request again with Before = oldest timestamp
+	Posts []PostHistoryEntry
+}
+
+// BuildPostHistoryRequest encodes the request data of a post history request:
+// before(4) + count(1). Before is exclusive; zero pages from the newest post.
+// A zero count asks for as many posts as fit in one response.
+func BuildPostHistoryRequest(before uint32, count uint8) []byte {
+	data := make([]byte, 5)
+	binary.LittleEndian.PutUint32(data[0:4], before)
+	data[4] = count
+	return data
+}
+
+// ParsePostHistoryRequest decodes post history request data. Missing bytes
+// read as zero.
+func ParsePostHistoryRequest(data []byte) (before uint32, count uint8) {
+	var buf [5]byte
+	copy(buf[:], data)
+	return binary.LittleEndian.Uint32(buf[0:4]), buf[4]
+}
+
+// BuildPostHistory encodes a post history response body. Each entry's text
+// is cut to 255 bytes.
+func BuildPostHistory(h PostHistory) []byte {
+	size := PostHistoryHeaderSize
+	for _, p := range h.Posts {
+		size += PostHistoryEntryHeaderSize + min(len(p.Text), 255)
+	}
+	data := make([]byte, PostHistoryHeaderSize, size)
+	if h.More {
+		data[0] |= PostHistoryFlagMore
+	}
+	data[1] = uint8(len(h.Posts))
+	for _, p := range h.Posts {
+		text := p.Text
+		if len(text) > 255 {
+			text = text[:255]
+		}
+		data = binary.LittleEndian.AppendUint32(data, p.Timestamp)
+		data = append(data, p.SenderPrefix[:]...)
+		data = append(data, uint8(len(text)))
+		data = append(data, text...)
+	}
+	return data
+}
+
+// ParsePostHistory decodes a post history response body. Trailing bytes after
+// the counted entries (block encryption padding) are ignored.
+func ParsePostHistory(data []byte) (*PostHistory, error) {
+	if len(data) < PostHistoryHeaderSize {
+		return nil, ErrHistoryTooShort
+	}
+	h := &PostHistory{More: data[0]&PostHistoryFlagMore != 0}
+	n := int(data[1])
+	off := PostHistoryHeaderSize
+	for i := 0; i < n; i++ {
+		if len(data)-off < PostHistoryEntryHeaderSize {
+			return nil, ErrHistoryTooShort
+		}
+		var e PostHistoryEntry
+		e.Timestamp = binary.LittleEndian.Uint32(data[off : off+4])
+		copy(e.SenderPrefix[:], data[off+4:off+8])
+		textLen := int(data[off+8])
+		off += PostHistoryEntryHeaderSize
+		if len(data)-off < textLen {
+			return nil, ErrHistoryTooShort
+		}
+		e.Text = string(data[off : off+textLen])
+		off += textLen
+		h.Posts = append(h.Posts, e)
+	}
+	return h, nil
+}
+
 // AnonReqTypeName returns a human-readable name for the anonymous request type.
 func AnonReqTypeName(t uint8) string {
 	switch t {
@@ -577,6 +749,7 @@ type DiscoverReqPayload struct {
 	TypeFilter uint8  // Bit mask for ADV_TYPE_* filtering
 	Tag        uint32 // Randomly generated by sender
 	Since      uint32 // Optional: epoch timestamp (0 by default)
+	HasSince   bool   // Since was present on the wire
 }
 
 // ParseDiscoverReqPayload parses a DISCOVER_REQ from control data.
@@ -594,6 +767,7 @@ func ParseDiscoverReqPayload(data []byte) (*DiscoverReqPayload, error) {
 	// Optional since field
 	if len(data) >= 9 {
 		payload.Since = binary.LittleEndian.Uint32(data[5:9])
+		payload.HasSince = true
 	}
 
 	return payload, nil
@@ -2,6 +2,7 @@ package codec
 
 import (
 	"encoding/binary"
+	"reflect"
 	"testing"
 )
 
@@ -418,6 +419,47 @@ func TestRequestTypeName(t *testing.T) {
 	}
 }
 
+func TestOwnerInfoRoundTrip(t *testing.T) {
+	in := OwnerInfo{FirmwareVersion: "v1.16.0", Name: "Hilltop", Owner: "Jo\nMaintained by the club"}
+	data := append(BuildOwnerInfo(in), 0, 0, 0) // block padding
+	if got := ParseOwnerInfo(data); got != in {
+		t.Errorf("ParseOwnerInfo = %+v, want %+v", got, in)
+	}
+	if got := ParseOwnerInfo([]byte("v1.16.0")); got.FirmwareVersion != "v1.16.0" || got.Name != "" {
+		t.Errorf("ParseOwnerInfo(short) = %+v", got)
+	}
+}
+
+func TestPostHistoryRoundTrip(t *testing.T) {
+	in := PostHistory{
+		More: true,
+		Posts: []PostHistoryEntry{
+			{Timestamp: 2000, SenderPrefix: [4]byte{1, 2, 3, 4}, Text: "newer"},
+			{Timestamp: 1000, SenderPrefix: [4]byte{5, 6, 7, 8}, Text: ""},
+		},
+	}
+	data := append(BuildPostHistory(in), make([]byte, 7)...) // block padding
+	got, err := ParsePostHistory(data)
+	if err != nil {
+		t.Fatalf("ParsePostHistory: %v", err)
+	}
+	if !reflect.DeepEqual(*got, in) {
+		t.Errorf("ParsePostHistory = %+v, want %+v", *got, in)
+	}
+
+	if _, err := ParsePostHistory(data[:PostHistoryHeaderSize+4]); err != ErrHistoryTooShort {
+		t.Errorf("truncated entry: err = %v, want ErrHistoryTooShort", err)
+	}
+
+	before, count := ParsePostHistoryRequest(BuildPostHistoryRequest(1234, 5))
+	if before != 1234 || count != 5 {
+		t.Errorf("request round trip = %d, %d", before, count)
+	}
+	if before, count := ParsePostHistoryRequest(nil); before != 0 || count != 0 {
+		t.Errorf("empty request = %d, %d", before, count)
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Group Payload Tests
 // -----------------------------------------------------------------------------
@@ -0,0 +1,49 @@
+package codec
+
+import (
+	"math"
+	"time"
+)
+
+// RxMeta is what a transport knows about how it received a packet. Every
+// transport sets the time and its name; signal fields are filled in only where
+// the transport reports them (a bridge that forwards bare packets cannot), so
+// check HasSNR and HasRSSI before using them.
+type RxMeta struct {
+	// Time is the local time the packet was received.
+	Time time.Time
+
+	// Transport names the transport instance it arrived on, such as
+	// "serial:/dev/ttyUSB0" or "mqtt:meshcore/bridge". The transport kind is
+	// also passed alongside the packet as a transport.PacketSource.
+	Transport string
+
+	// SNR is the signal-to-noise ratio in dB, valid if HasSNR.
+	SNR    float32
+	HasSNR bool
+
+	// RSSI is the received signal strength in dBm, valid if HasRSSI.
+	RSSI    int16
+	HasRSSI bool
+
+	// FreqMHz is the radio frequency it was heard on, or 0 if unknown.
+	FreqMHz float64
+}
+
+// SetSNR records an SNR reading in dB, in both the metadata and the packet's
+// raw SNR field that TRACE and discovery responses echo back.
+func (p *Packet) SetSNR(db float32) {
+	q := math.Round(float64(db) * 4)
+	p.SNR = int8(max(math.MinInt8, min(math.MaxInt8, q)))
+	if p.Rx == nil {
+		p.Rx = &RxMeta{}
+	}
+	p.Rx.SNR = db
+	p.Rx.HasSNR = true
+}
+
+// SNRKnown reports whether the packet's SNR was measured. Packets without
+// receive metadata are taken at their SNR field, as before RxMeta existed.
+func (p *Packet) SNRKnown() bool {
+	return p.Rx == nil || p.Rx.HasSNR
+}
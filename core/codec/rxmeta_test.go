@@ -0,0 +1,42 @@
+package codec
+
+import "testing"
+
+func TestPacketSetSNR(t *testing.T) {
+	tests := []struct {
+		db   float32
+		want int8
+	}{
+		{-6.25, -25},
+		{12.1, 48},
+		{40, 127}, // clamped to the raw field's range
+		{-40, -128},
+	}
+	for _, tt := range tests {
+		var p Packet
+		p.SetSNR(tt.db)
+		if p.SNR != tt.want || p.Rx == nil || !p.Rx.HasSNR || p.Rx.SNR != tt.db {
+			t.Errorf("SetSNR(%v): SNR=%d Rx=%+v, want SNR=%d", tt.db, p.SNR, p.Rx, tt.want)
+		}
+	}
+}
+
+func TestPacketSNRKnown(t *testing.T) {
+	p := &Packet{SNR: 8}
+	if !p.SNRKnown() {
+		t.Error("packet without metadata should trust its SNR field")
+	}
+	p.Rx = &RxMeta{Transport: "serial"}
+	if p.SNRKnown() {
+		t.Error("SNRKnown true without a measurement")
+	}
+}
+
+func TestPacketCloneRx(t *testing.T) {
+	p := &Packet{Rx: &RxMeta{RSSI: -90, HasRSSI: true}}
+	c := p.Clone()
+	c.Rx.RSSI = -50
+	if p.Rx.RSSI != -90 {
+		t.Error("Clone shares Rx with the original")
+	}
+}
@@ -178,9 +178,67 @@ type Contact struct {
 	GPSLat     int32
 	GPSLon     int32
 	LastMod    uint32
+	// Stats, if set, is appended after the fixed frame as link-statistics
+	// extras. Only the CMD_GET_CONTACT_BY_KEY reply carries them.
+	Stats *ContactStats
 }
 
-// Encode serializes the contact payload as a RESP_CODE_CONTACT frame (148 bytes).
+// ContactStatsVersion is the layout version leading the ContactStats extras.
+const ContactStatsVersion = 1
+
+// contactStatsSize is the ContactStats extras length: version + 6x uint32 +
+// 2x int8.
+const contactStatsSize = 1 + 6*4 + 2 // 27
+
+// ContactStats is a meshcore-go extension to the RESP_CODE_CONTACT frame: the
+// link statistics kept for the contact, appended after the 148 fixed bytes as
+// [version][sent u32][acked u32][failed u32][rtt_avg_ms u32][received u32]
+// [last_heard u32][snr_avg int8][snr_last int8]. Clients that read only the
+// fixed layout ignore it.
+type ContactStats struct {
+	Sent, Acked, Failed uint32
+	RTTAvgMs            uint32
+	Received            uint32
+	LastHeard           uint32 // epoch seconds
+	// SNRAvg and SNRLast are scaled x4 (0.25 dB units), like LastSNR elsewhere.
+	SNRAvg, SNRLast int8
+}
+
+// ParseContactStats reads the ContactStats extras from a RESP_CODE_CONTACT
+// frame. ok is false if the frame has none or an unknown version.
+func ParseContactStats(frame []byte) (stats *ContactStats, ok bool) {
+	if len(frame) < contactFrameSize+contactStatsSize || frame[contactFrameSize] != ContactStatsVersion {
+		return nil, false
+	}
+	b := frame[contactFrameSize+1:]
+	return &ContactStats{
+		Sent:      binary.LittleEndian.Uint32(b[0:4]),
+		Acked:     binary.LittleEndian.Uint32(b[4:8]),
+		Failed:    binary.LittleEndian.Uint32(b[8:12]),
+		RTTAvgMs:  binary.LittleEndian.Uint32(b[12:16]),
+		Received:  binary.LittleEndian.Uint32(b[16:20]),
+		LastHeard: binary.LittleEndian.Uint32(b[20:24]),
+		SNRAvg:    int8(b[24]),
+		SNRLast:   int8(b[25]),
+	}, true
+}
+
+func (st *ContactStats) appendTo(buf []byte) []byte {
+	var b [contactStatsSize]byte
+	b[0] = ContactStatsVersion
+	binary.LittleEndian.PutUint32(b[1:5], st.Sent)
+	binary.LittleEndian.PutUint32(b[5:9], st.Acked)
+	binary.LittleEndian.PutUint32(b[9:13], st.Failed)
+	binary.LittleEndian.PutUint32(b[13:17], st.RTTAvgMs)
+	binary.LittleEndian.PutUint32(b[17:21], st.Received)
+	binary.LittleEndian.PutUint32(b[21:25], st.LastHeard)
+	b[25] = byte(st.SNRAvg)
+	b[26] = byte(st.SNRLast)
+	return append(buf, b[:]...)
+}
+
+// Encode serializes the contact payload as a RESP_CODE_CONTACT frame (148 bytes,
+// plus the ContactStats extras when Stats is set).
 func (c *Contact) Encode() []byte { return c.EncodeWithCode(RespCodeContact) }
 
 // EncodeWithCode serializes the contact payload with an explicit leading code.
@@ -198,6 +256,9 @@ func (c *Contact) EncodeWithCode(code uint8) []byte {
 	binary.LittleEndian.PutUint32(buf[136:140], uint32(c.GPSLat))
 	binary.LittleEndian.PutUint32(buf[140:144], uint32(c.GPSLon))
 	binary.LittleEndian.PutUint32(buf[144:148], c.LastMod)
+	if c.Stats != nil {
+		buf = c.Stats.appendTo(buf)
+	}
 	return buf
 }
 
@@ -481,6 +542,65 @@ func (c *ChannelInfo) Encode() []byte {
 	return b
 }
 
+// MaxBlockedPerFrame is how many keys one RESP_CODE_BLOCKED frame carries.
+const MaxBlockedPerFrame = 4
+
+// EncodeBlocked builds a RESP_CODE_BLOCKED payload (reply to CMD_GET_BLOCKED):
+// [code][total u16 LE][count u8][count x pubkey 32]. total is the size of the
+// whole blocklist; the app pages through it by offset. At most
+// MaxBlockedPerFrame keys are encoded.
+func EncodeBlocked(total int, keys [][32]byte) []byte {
+	if len(keys) > MaxBlockedPerFrame {
+		keys = keys[:MaxBlockedPerFrame]
+	}
+	b := make([]byte, 4, 4+32*len(keys))
+	b[0] = RespCodeBlocked
+	binary.LittleEndian.PutUint16(b[1:], uint16(total))
+	b[3] = uint8(len(keys))
+	for _, k := range keys {
+		b = append(b, k[:]...)
+	}
+	return b
+}
+
+// EncodeChangesStart builds a RESP_CODE_CHANGES_START payload, the first reply
+// to CMD_GET_CHANGES: [code][reset u8][count u32 LE]. count frames follow, each
+// a RESP_CODE_CONTACT with a contact's current state or a
+// RESP_CODE_CONTACT_REMOVED, then RESP_CODE_END_OF_CHANGES. reset means the
+// requested changes are no longer retained and none follow: the app should
+// list all contacts with CMD_GET_CONTACTS and continue from the END seq.
+func EncodeChangesStart(reset bool, count uint32) []byte {
+	b := make([]byte, 6)
+	b[0] = RespCodeChangesStart
+	if reset {
+		b[1] = 1
+	}
+	binary.LittleEndian.PutUint32(b[2:], count)
+	return b
+}
+
+// EncodeContactRemoved builds a RESP_CODE_CONTACT_REMOVED payload, a
+// CMD_GET_CHANGES entry for a contact that no longer exists.
+func EncodeContactRemoved(pubKey [32]byte) []byte { return pubKeyPush(RespCodeContactRemoved, pubKey) }
+
+// EncodeEndOfChanges builds a RESP_CODE_END_OF_CHANGES payload. seq is the
+// journal position the app should send as its next "since".
+func EncodeEndOfChanges(seq uint32) []byte {
+	b := make([]byte, 5)
+	b[0] = RespCodeEndOfChanges
+	binary.LittleEndian.PutUint32(b[1:], seq)
+	return b
+}
+
+// EncodeContactsChanged builds a PUSH_CODE_CONTACTS_CHANGED payload: the
+// contact journal has advanced to seq.
+func EncodeContactsChanged(seq uint32) []byte {
+	b := make([]byte, 5)
+	b[0] = PushCodeContactsChanged
+	binary.LittleEndian.PutUint32(b[1:], seq)
+	return b
+}
+
 // EncodeAdvert builds a PUSH_CODE_ADVERT payload ([code][pubkey 32]): a known
 // contact was re-heard. A first-seen contact instead uses the full contact
 // frame via (*Contact).EncodeWithCode(PushCodeNewAdvert).
@@ -43,6 +43,24 @@ func ParseGetContactsSince(payload []byte) (since uint32, hasSince bool) {
 	return 0, false
 }
 
+// ParseGetBlocked reads the optional starting offset from a CMD_GET_BLOCKED
+// frame. A bare request starts at 0.
+func ParseGetBlocked(payload []byte) (offset int) {
+	if len(payload) >= 3 {
+		return int(binary.LittleEndian.Uint16(payload[1:3]))
+	}
+	return 0
+}
+
+// ParseGetChanges reads the journal sequence number from a CMD_GET_CHANGES
+// frame: [cmd][since u32 LE].
+func ParseGetChanges(payload []byte) (since uint32, err error) {
+	if len(payload) < 5 || payload[0] != CmdGetChanges {
+		return 0, ErrShortFrame
+	}
+	return binary.LittleEndian.Uint32(payload[1:5]), nil
+}
+
 // ParseSendTracePath parses a CMD_SEND_TRACE_PATH frame:
 // [code][tag u32][auth u32][flags u8][path]. path is the concatenated relay
 // hashes; it aliases the payload.
@@ -133,6 +133,23 @@ func TestContactEncode(t *testing.T) {
 	}
 }
 
+func TestContactStatsExtras(t *testing.T) {
+	c := &Contact{Name: "Bob"}
+	if _, ok := ParseContactStats(c.Encode()); ok {
+		t.Error("plain contact frame should carry no stats")
+	}
+	want := ContactStats{Sent: 10, Acked: 8, Failed: 1, RTTAvgMs: 2400, Received: 42, LastHeard: 1_700_000_000, SNRAvg: 26, SNRLast: -12}
+	c.Stats = &want
+	b := c.Encode()
+	if len(b) != 148+27 {
+		t.Fatalf("length = %d, want 175", len(b))
+	}
+	got, ok := ParseContactStats(b)
+	if !ok || *got != want {
+		t.Errorf("ParseContactStats = %+v, %v; want %+v", got, ok, want)
+	}
+}
+
 func TestContactOutPathTruncated(t *testing.T) {
 	// An over-long out-path must be truncated to 64 bytes, not overrun the frame.
 	c := &Contact{OutPath: bytes.Repeat([]byte{0x7F}, 100)}
@@ -68,6 +68,29 @@ const (
 	CmdSendRawPacket        = 65 // Send a raw mesh packet (v1.16.0+)
 )
 
+// meshcore-go extension commands. The firmware has no blocklist, so these are
+// numbered from 0x70, clear of its sequentially assigned command codes, and
+// answered below the 0x80 push range. Apps that do not know them never send
+// them.
+const (
+	CmdBlockContact   = 0x70 // Block a peer: [cmd][pubkey 32][reason...]
+	CmdUnblockContact = 0x71 // Lift a block: [cmd][pubkey 32]
+	CmdGetBlocked     = 0x72 // List blocks: [cmd][offset u16 LE, optional]
+
+	RespCodeBlocked = 0x70 // Reply to CmdGetBlocked; see EncodeBlocked
+
+	CmdGetChanges = 0x73 // Contact changes since a journal seq: [cmd][since u32 LE]
+
+	RespCodeChangesStart   = 0x71 // [code][reset u8][count u32]; see EncodeChangesStart
+	RespCodeContactRemoved = 0x72 // [code][pubkey 32]
+	RespCodeEndOfChanges   = 0x73 // [code][seq u32]; the app's next "since"
+
+	// PushCodeContactsChanged tells an app that has used CmdGetChanges that
+	// new changes are waiting: [code][seq u32]. It sits at the top of the push
+	// range, clear of the firmware's codes.
+	PushCodeContactsChanged = 0xF0
+)
+
 // Response codes sent from companion radio to host.
 const (
 	RespCodeOK                = 0  // Success
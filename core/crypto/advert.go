@@ -28,8 +28,9 @@ func SignAdvert(privateKey ed25519.PrivateKey, pubKey [32]byte, timestamp uint32
 // VerifyAdvert verifies the Ed25519 signature of a parsed ADVERT payload.
 // The signed message is reconstructed from the payload fields.
 func VerifyAdvert(advert *codec.AdvertPayload) bool {
-	// Reconstruct the appdata bytes from the parsed payload
-	appDataBytes := codec.BuildAdvertAppData(advert.AppData)
+	// Reconstruct the appdata bytes from the parsed payload, keeping its
+	// flag bits as the sender signed them
+	appDataBytes := advert.Encode()[codec.AdvertMinSize:]
 
 	msg := buildAdvertSignedMessage(advert.PubKey, advert.Timestamp, appDataBytes)
 
@@ -109,6 +109,36 @@ func TestSignVerifyAdvertMinimal(t *testing.T) {
 	}
 }
 
+// TestVerifyAdvert_FlagsKept checks that an advert whose flags byte claims a
+// name but carries none still verifies: the signed app data is rebuilt with
+// the received flags, not ones recomputed from the fields.
+func TestVerifyAdvert_FlagsKept(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	var pubKey [32]byte
+	copy(pubKey[:], kp.PublicKey)
+	timestamp := uint32(1704067200)
+	appDataBytes := []byte{0x8c}
+
+	sig, err := SignAdvert(kp.PrivateKey, pubKey, timestamp, appDataBytes)
+	if err != nil {
+		t.Fatalf("SignAdvert() error = %v", err)
+	}
+
+	payload := append(codec.BuildAdvertPayload(pubKey, timestamp, sig, nil), appDataBytes...)
+	parsed, err := codec.ParseAdvertPayload(payload)
+	if err != nil {
+		t.Fatalf("ParseAdvertPayload() error = %v", err)
+	}
+
+	if !VerifyAdvert(parsed) {
+		t.Error("VerifyAdvert() = false for advert with an empty name flag, want true")
+	}
+}
+
 func TestVerifyAdvertBadSignature(t *testing.T) {
 	kp, _ := GenerateKeyPair()
 
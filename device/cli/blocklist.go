@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/device/contact"
+)
+
+// RegisterBlocklist registers the "block", "unblock", and "blocked" commands,
+// which manage list:
+//
+//	block <pubkey-hex> [reason]   block a peer
+//	unblock <pubkey-hex>          lift a block
+//	blocked                       list blocks as "<id> since=<ts> [reason]"
+//
+// block takes a full 64-character public key, or a hex prefix matching exactly
+// one contact in store; unblock takes a prefix matching exactly one blocked
+// key. now supplies the time recorded with a new block. Returns the dispatcher
+// for chaining.
+func RegisterBlocklist(d *Dispatcher, list *contact.Blocklist, store contact.ContactStore, now func() uint32) *Dispatcher {
+	d.Command("block", func(args []string) string {
+		if len(args) < 1 {
+			return "Error: usage: block <pubkey-hex> [reason]"
+		}
+		prefix, err := hex.DecodeString(args[0])
+		if err != nil || len(prefix) == 0 {
+			return "ERR: bad pubkey"
+		}
+		var id core.MeshCoreID
+		if len(prefix) == len(id) {
+			copy(id[:], prefix)
+		} else if c := contact.ResolvePrefix(store, prefix); c != nil {
+			id = c.ID
+		} else {
+			return "ERR: no unique contact with that prefix"
+		}
+		if err := list.Block(id, now(), strings.Join(args[1:], " ")); err != nil {
+			return "ERR: " + err.Error()
+		}
+		return "OK"
+	})
+	d.Command("unblock", func(args []string) string {
+		if len(args) < 1 {
+			return "Error: usage: unblock <pubkey-hex>"
+		}
+		prefix, err := hex.DecodeString(args[0])
+		if err != nil || len(prefix) == 0 {
+			return "ERR: bad pubkey"
+		}
+		var match *contact.BlockEntry
+		for _, e := range list.List() {
+			if !e.ID.IsHashMatch(prefix) {
+				continue
+			}
+			if match != nil {
+				return "ERR: prefix matches more than one block"
+			}
+			match = &e
+		}
+		if match == nil {
+			return "ERR: not blocked"
+		}
+		if _, err := list.Unblock(match.ID); err != nil {
+			return "ERR: " + err.Error()
+		}
+		return "OK"
+	})
+	d.Command("blocked", func([]string) string {
+		entries := list.List()
+		if len(entries) == 0 {
+			return "(no blocks)"
+		}
+		var b strings.Builder
+		for _, e := range entries {
+			fmt.Fprintf(&b, "%s since=%d", e.ID.String()[:12], e.Since)
+			if e.Reason != "" {
+				b.WriteString(" " + e.Reason)
+			}
+			b.WriteByte('\n')
+		}
+		return strings.TrimRight(b.String(), "\n")
+	})
+	return d
+}
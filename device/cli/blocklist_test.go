@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/device/contact"
+)
+
+func TestRegisterBlocklist(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := contact.NewManager(priv, contact.ManagerConfig{MaxContacts: 8})
+	var known, twin core.MeshCoreID
+	known[0], known[1] = 0xAB, 0x01
+	twin[0], twin[1] = 0xAB, 0x02
+	for _, id := range []core.MeshCoreID{known, twin} {
+		if _, err := store.AddContact(&contact.ContactInfo{ID: id, OutPathLen: contact.PathUnknown}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := contact.NewBlocklist()
+	d := RegisterBlocklist(New(), list, store, func() uint32 { return 500 })
+
+	if got := d.Execute("blocked"); got != "(no blocks)" {
+		t.Errorf("empty list = %q", got)
+	}
+	if got := d.Execute("block ab"); !strings.HasPrefix(got, "ERR") {
+		t.Errorf("ambiguous prefix = %q, want ERR", got)
+	}
+	if got := d.Execute("block ab01 too chatty"); got != "OK" {
+		t.Fatalf("block by prefix = %q, want OK", got)
+	}
+	if !list.IsBlocked(known) || list.IsBlocked(twin) {
+		t.Fatal("wrong peer blocked")
+	}
+	if got := d.Execute("blocked"); got != known.String()[:12]+" since=500 too chatty" {
+		t.Errorf("blocked = %q", got)
+	}
+
+	var stranger core.MeshCoreID
+	stranger[0] = 0x42
+	if got := d.Execute("block " + stranger.String()); got != "OK" {
+		t.Errorf("block by full key = %q, want OK", got)
+	}
+	if got := d.Execute("unblock 99"); got != "ERR: not blocked" {
+		t.Errorf("unblock miss = %q", got)
+	}
+	if got := d.Execute("unblock 42"); got != "OK" || list.IsBlocked(stranger) {
+		t.Errorf("unblock = %q, blocked=%v", got, list.IsBlocked(stranger))
+	}
+
+	// Like block, unblock refuses a prefix that matches more than one entry.
+	if got := d.Execute("block " + twin.String()); got != "OK" {
+		t.Fatalf("block twin = %q, want OK", got)
+	}
+	if got := d.Execute("unblock ab"); !strings.HasPrefix(got, "ERR") || !list.IsBlocked(known) || !list.IsBlocked(twin) {
+		t.Errorf("ambiguous unblock = %q", got)
+	}
+	if got := d.Execute("unblock ab02"); got != "OK" || list.IsBlocked(twin) || !list.IsBlocked(known) {
+		t.Errorf("unblock twin = %q", got)
+	}
+}
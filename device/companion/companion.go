@@ -13,13 +13,13 @@
 // Implemented: the connect handshake, contacts (list/add/remove/import/export),
 // device state (time, battery, channels, radio config, stats, auto-add),
 // messaging (direct and channel, incoming and outgoing), live contact-update
-// pushes, and the remote-admin gateway (login, CLI, status, telemetry). See the
-// README for the full command list. Unimplemented commands return RESP_CODE_ERR
-// so the app degrades gracefully.
+// pushes, the remote-admin gateway (login, CLI, status, telemetry), and
+// blocklist and contact change-sync extensions that stock firmware lacks. See
+// the README for the full command list. Unimplemented commands return
+// RESP_CODE_ERR so the app degrades gracefully.
 package companion
 
 import (
-	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
@@ -52,6 +52,14 @@ type Node interface {
 	AddChannel(key []byte) uint8
 }
 
+// BlocklistNode is implemented by nodes that keep a blocklist (a
+// *node.BaseNode does). The server manages it with the CMD_BLOCK_CONTACT,
+// CMD_UNBLOCK_CONTACT, and CMD_GET_BLOCKED extension commands; for a Node
+// without one they return UNSUPPORTED_CMD.
+type BlocklistNode interface {
+	Blocklist() *contact.Blocklist
+}
+
 // Identity is the static device description the server reports in SELF_INFO and
 // DEVICE_INFO. The public key comes from the Node; everything the node does not
 // model (radio params, firmware strings) is supplied here.
@@ -337,6 +345,8 @@ func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
 // Serve runs the companion protocol over rw until the stream closes or ctx is
 // cancelled. Use it directly to serve a pty (the serial path) instead of TCP.
 func (s *Server) Serve(ctx context.Context, rw io.ReadWriter) error {
+	ctx, cancel := context.WithCancel(ctx) // ends the session's change watcher
+	defer cancel()
 	sess := &session{srv: s, w: rw, ctx: ctx}
 	s.addSession(sess)
 	defer s.removeSession(sess)
@@ -373,6 +383,7 @@ type session struct {
 	ctx          context.Context
 	mu           sync.Mutex // serializes frame writes (loop replies and async pushes)
 	appTargetVer uint8
+	watching     bool // a contact-change watcher is running (see getChanges)
 }
 
 // send frames a payload as a device->app frame and writes it.
@@ -500,6 +511,18 @@ func (s *Server) dispatch(ss *session, payload []byte) error {
 	case serial.CmdSyncNextMessage:
 		return s.sendNextMessage(ss)
 
+	case serial.CmdBlockContact:
+		return s.blockContact(ss, payload)
+
+	case serial.CmdUnblockContact:
+		return s.unblockContact(ss, payload)
+
+	case serial.CmdGetBlocked:
+		return s.getBlocked(ss, payload)
+
+	case serial.CmdGetChanges:
+		return s.getChanges(ss, payload)
+
 	case serial.CmdSetAdvertName:
 		if name, err := serial.ParseSetAdvertName(payload); err == nil {
 			s.setName(name)
@@ -700,17 +723,13 @@ func (s *Server) sendChannelMsg(ss *session, payload []byte) error {
 }
 
 // resolveContact finds the stored contact whose public key starts with prefix
-// (the 6-byte prefix the app sends), or nil if none match.
+// (the 6-byte prefix the app sends), or nil if none match. As in firmware, the
+// first match wins; at six bytes a collision is vanishingly unlikely.
 func (s *Server) resolveContact(prefix []byte) *contact.ContactInfo {
-	var found *contact.ContactInfo
-	s.node.Contacts().ForEach(func(c *contact.ContactInfo) bool {
-		if bytes.HasPrefix(c.ID[:], prefix) {
-			found = c
-			return false
-		}
-		return true
-	})
-	return found
+	if matches := s.node.Contacts().SearchByPrefix(prefix); len(matches) > 0 {
+		return matches[0]
+	}
+	return nil
 }
 
 // sendNextMessage drains one queued incoming message, encoded for the session's
@@ -1034,16 +1053,21 @@ func (s *Server) addUpdateContact(ss *session, payload []byte) error {
 
 	store := s.node.Contacts()
 	if existing := store.GetByPubKey(id); existing != nil {
-		existing.Name = c.Name
-		existing.Type = c.Type
-		existing.Flags = c.Flags
-		existing.OutPathLen = c.OutPathLen
-		existing.OutPath = c.OutPath
-		existing.LastAdvertTimestamp = c.LastAdvert
-		existing.GPSLat = c.GPSLat
-		existing.GPSLon = c.GPSLon
-		existing.LastMod = lastMod
-		if err := store.UpdateContact(existing); err != nil {
+		// Update through a copy, so the store sees the old and new values.
+		updated := &contact.ContactInfo{
+			ID:                  id,
+			Name:                c.Name,
+			Type:                c.Type,
+			Flags:               c.Flags,
+			OutPathLen:          c.OutPathLen,
+			OutPath:             c.OutPath,
+			LastAdvertTimestamp: c.LastAdvert,
+			LastMod:             lastMod,
+			GPSLat:              c.GPSLat,
+			GPSLon:              c.GPSLon,
+			SyncSince:           existing.SyncSince,
+		}
+		if err := store.UpdateContact(updated); err != nil {
 			return ss.send(serial.EncodeErr(serial.ErrCodeTableFull))
 		}
 		return ss.send(serial.EncodeOK())
@@ -1103,7 +1127,8 @@ func (s *Server) resetPath(ss *session, payload []byte) error {
 	return ss.send(serial.EncodeOK())
 }
 
-// getContactByKey handles CMD_GET_CONTACT_BY_KEY.
+// getContactByKey handles CMD_GET_CONTACT_BY_KEY. The reply carries the
+// contact's link statistics as ContactStats extras.
 func (s *Server) getContactByKey(ss *session, payload []byte) error {
 	id, ok := parseContactKey(payload)
 	if !ok {
@@ -1113,7 +1138,161 @@ func (s *Server) getContactByKey(ss *session, payload []byte) error {
 	if ct == nil {
 		return ss.send(serial.EncodeErr(serial.ErrCodeNotFound))
 	}
-	return ss.send(contactToWire(ct).Encode())
+	wc := contactToWire(ct)
+	wc.Stats = statsToWire(ct.Stats())
+	return ss.send(wc.Encode())
+}
+
+// blocklist returns the node's blocklist, or nil if it has none.
+func (s *Server) blocklist() *contact.Blocklist {
+	if bn, ok := s.node.(BlocklistNode); ok {
+		return bn.Blocklist()
+	}
+	return nil
+}
+
+// blockContact handles CMD_BLOCK_CONTACT. The peer need not be a contact; any
+// bytes after the key are recorded as the reason.
+func (s *Server) blockContact(ss *session, payload []byte) error {
+	list := s.blocklist()
+	if list == nil {
+		return ss.send(serial.EncodeErr(serial.ErrCodeUnsupportedCmd))
+	}
+	id, ok := parseContactKey(payload)
+	if !ok {
+		return ss.send(serial.EncodeErr(serial.ErrCodeIllegalArg))
+	}
+	if err := list.Block(id, s.node.Clock().GetCurrentTime(), string(payload[33:])); err != nil {
+		s.log.Warn("failed to save blocklist", "error", err)
+		return ss.send(serial.EncodeErr(serial.ErrCodeFileIOError))
+	}
+	return ss.send(serial.EncodeOK())
+}
+
+// unblockContact handles CMD_UNBLOCK_CONTACT.
+func (s *Server) unblockContact(ss *session, payload []byte) error {
+	list := s.blocklist()
+	if list == nil {
+		return ss.send(serial.EncodeErr(serial.ErrCodeUnsupportedCmd))
+	}
+	id, ok := parseContactKey(payload)
+	if !ok {
+		return ss.send(serial.EncodeErr(serial.ErrCodeIllegalArg))
+	}
+	found, err := list.Unblock(id)
+	if err != nil {
+		s.log.Warn("failed to save blocklist", "error", err)
+		return ss.send(serial.EncodeErr(serial.ErrCodeFileIOError))
+	}
+	if !found {
+		return ss.send(serial.EncodeErr(serial.ErrCodeNotFound))
+	}
+	return ss.send(serial.EncodeOK())
+}
+
+// getBlocked handles CMD_GET_BLOCKED, replying with one page of blocked keys
+// (oldest block first) starting at the requested offset.
+func (s *Server) getBlocked(ss *session, payload []byte) error {
+	list := s.blocklist()
+	if list == nil {
+		return ss.send(serial.EncodeErr(serial.ErrCodeUnsupportedCmd))
+	}
+	entries := list.List()
+	offset := min(serial.ParseGetBlocked(payload), len(entries))
+	page := entries[offset:]
+	keys := make([][32]byte, 0, serial.MaxBlockedPerFrame)
+	for _, e := range page[:min(len(page), serial.MaxBlockedPerFrame)] {
+		keys = append(keys, e.ID)
+	}
+	return ss.send(serial.EncodeBlocked(len(entries), keys))
+}
+
+// journal returns the contact store's change journal, or nil if it keeps none.
+func (s *Server) journal() *contact.Journal {
+	if j, ok := s.node.Contacts().(contact.Journaled); ok {
+		return j.Journal()
+	}
+	return nil
+}
+
+// getChanges handles CMD_GET_CHANGES: the contacts added, updated, or removed
+// since the app's journal seq, one frame per contact in the order of its latest
+// change. Added and updated contacts are sent as they are now; contacts that
+// are gone as CONTACT_REMOVED. If the changes are no longer retained, the reply
+// is a reset and the app resyncs with GET_CONTACTS. The first request also
+// subscribes the session to CONTACTS_CHANGED pushes.
+func (s *Server) getChanges(ss *session, payload []byte) error {
+	j := s.journal()
+	if j == nil {
+		return ss.send(serial.EncodeErr(serial.ErrCodeUnsupportedCmd))
+	}
+	since, err := serial.ParseGetChanges(payload)
+	if err != nil {
+		return ss.send(serial.EncodeErr(serial.ErrCodeIllegalArg))
+	}
+	if !ss.watching {
+		ss.watching = true
+		go s.watchChanges(ss, j, j.Watch(j.Seq()))
+	}
+
+	seq := j.Seq()
+	changes, err := j.Since(uint64(since))
+	if err != nil {
+		if err := ss.send(serial.EncodeChangesStart(true, 0)); err != nil {
+			return err
+		}
+		return ss.send(serial.EncodeEndOfChanges(uint32(seq)))
+	}
+
+	// Keep each contact's latest change only.
+	latest := make(map[core.MeshCoreID]int, len(changes))
+	for i, c := range changes {
+		latest[c.ID] = i
+	}
+	frames := make([][]byte, 0, len(latest))
+	store := s.node.Contacts()
+	for i, c := range changes {
+		if latest[c.ID] != i {
+			continue
+		}
+		if ct := store.GetByPubKey(c.ID); ct != nil {
+			frames = append(frames, contactToWire(ct).Encode())
+		} else {
+			frames = append(frames, serial.EncodeContactRemoved([32]byte(c.ID)))
+		}
+		seq = c.Seq
+	}
+	if err := ss.send(serial.EncodeChangesStart(false, uint32(len(frames)))); err != nil {
+		return err
+	}
+	for _, f := range frames {
+		if err := ss.send(f); err != nil {
+			return err
+		}
+	}
+	return ss.send(serial.EncodeEndOfChanges(uint32(seq)))
+}
+
+// watchChanges pushes CONTACTS_CHANGED to ss whenever w sees the journal
+// advance, until the session ends.
+func (s *Server) watchChanges(ss *session, j *contact.Journal, w *contact.Watcher) {
+	for {
+		var seq uint64
+		changes, err := w.Next(ss.ctx)
+		switch {
+		case err == nil:
+			seq = changes[len(changes)-1].Seq
+		case errors.Is(err, contact.ErrJournalGap):
+			// Fell behind; the app's own GET_CHANGES will see the reset.
+			seq = j.Seq()
+			w = j.Watch(seq)
+		default:
+			return
+		}
+		if err := ss.send(serial.EncodeContactsChanged(uint32(seq))); err != nil {
+			return
+		}
+	}
 }
 
 // setRadioParams handles CMD_SET_RADIO_PARAMS, validating and storing the radio
@@ -1283,6 +1462,26 @@ func contactToWire(c *contact.ContactInfo) *serial.Contact {
 	return wc
 }
 
+// statsToWire converts a contact's link statistics to the GET_CONTACT_BY_KEY
+// extras, scaling SNR to the wire's 0.25 dB units.
+func statsToWire(st contact.LinkStats) *serial.ContactStats {
+	return &serial.ContactStats{
+		Sent:      st.Sent,
+		Acked:     st.Acked,
+		Failed:    st.Failed,
+		RTTAvgMs:  st.RTTAvgMs,
+		Received:  st.Received,
+		LastHeard: st.LastHeard,
+		SNRAvg:    snrToWire(st.SNRAvg),
+		SNRLast:   snrToWire(st.SNRLast),
+	}
+}
+
+// snrToWire converts an SNR in dB to the wire's int8 0.25 dB units, clamped.
+func snrToWire(db float32) int8 {
+	return int8(max(math.MinInt8, min(math.MaxInt8, math.Round(float64(db)*4))))
+}
+
 // degToFixed converts degrees to the firmware's fixed-point degrees x1e6.
 func degToFixed(deg float64) int32 {
 	return int32(math.Round(deg * 1e6))
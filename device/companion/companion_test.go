@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"encoding/binary"
 
@@ -24,15 +25,17 @@ import (
 // --- test doubles -----------------------------------------------------------
 
 type fakeNode struct {
-	pk       [32]byte
-	clk      *clock.Clock
-	contacts contact.ContactStore
+	pk        [32]byte
+	clk       *clock.Clock
+	contacts  contact.ContactStore
+	blocklist *contact.Blocklist
 }
 
 func (f *fakeNode) PublicKey() [32]byte            { return f.pk }
 func (f *fakeNode) Contacts() contact.ContactStore { return f.contacts }
 func (f *fakeNode) Clock() *clock.Clock            { return f.clk }
 func (f *fakeNode) AddChannel([]byte) uint8        { return 0 }
+func (f *fakeNode) Blocklist() *contact.Blocklist  { return f.blocklist }
 
 // stubStore is a small in-memory ContactStore for tests, backed by a slice.
 type stubStore struct{ list []*contact.ContactInfo }
@@ -69,6 +72,15 @@ func (s *stubStore) GetByPubKey(id core.MeshCoreID) *contact.ContactInfo {
 }
 func (s *stubStore) SearchByHash(uint8) []*contact.ContactInfo       { return nil }
 func (s *stubStore) GetSharedSecret(core.MeshCoreID) ([]byte, error) { return nil, nil }
+func (s *stubStore) SearchByPrefix(p []byte) []*contact.ContactInfo {
+	var out []*contact.ContactInfo
+	for _, c := range s.list {
+		if c.ID.IsHashMatch(p) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
 
 // rw adapts a reader and writer into an io.ReadWriter for Serve.
 type rw struct {
@@ -563,8 +575,11 @@ func TestAddAndGetContact(t *testing.T) {
 
 	// GET_CONTACT_BY_KEY returns the stored contact.
 	resp = collectResponses(t, s, cmd(append([]byte{serial.CmdGetContactByKey}, id[:]...)...))
-	if resp[0][0] != serial.RespCodeContact || len(resp[0]) != 148 {
-		t.Fatalf("get by key: expected 148-byte Contact, got %v", resp[0][:1])
+	if resp[0][0] != serial.RespCodeContact || len(resp[0]) != 148+27 {
+		t.Fatalf("get by key: expected 148-byte Contact with stats extras, got %v", resp[0][:1])
+	}
+	if _, ok := serial.ParseContactStats(resp[0]); !ok {
+		t.Error("get by key: missing link stats extras")
 	}
 	if !bytes.Equal(resp[0][1:33], id[:]) || string(resp[0][100:105]) != "Alice" {
 		t.Errorf("contact roundtrip mismatch: key=%x name=%q", resp[0][1:33], resp[0][100:132])
@@ -1203,3 +1218,152 @@ func TestTraceDataPush(t *testing.T) {
 		t.Errorf("hashes/snrs/lastSnr wrong: %x", f[12:])
 	}
 }
+
+func TestBlockCommands(t *testing.T) {
+	list := contact.NewBlocklist()
+	s := NewServer(Config{Node: &fakeNode{clk: clock.New(), contacts: &stubStore{}, blocklist: list}})
+
+	keys := make([]core.MeshCoreID, 6)
+	var in []byte
+	for i := range keys {
+		keys[i][0] = byte(i + 1)
+		in = append(in, cmd(append(append([]byte{serial.CmdBlockContact}, keys[i][:]...), "noisy"...)...)...)
+	}
+	in = append(in, cmd(serial.CmdGetBlocked)...)
+	in = append(in, cmd(serial.CmdGetBlocked, 4, 0)...)
+	in = append(in, cmd(append([]byte{serial.CmdUnblockContact}, keys[0][:]...)...)...)
+	in = append(in, cmd(append([]byte{serial.CmdUnblockContact}, keys[0][:]...)...)...)
+
+	resp := collectResponses(t, s, in)
+	if len(resp) != 10 {
+		t.Fatalf("got %d responses, want 10", len(resp))
+	}
+	for i := range keys {
+		if resp[i][0] != serial.RespCodeOK {
+			t.Fatalf("block %d: %v", i, resp[i])
+		}
+	}
+	// Serve has run every command, including the unblock.
+	if e := list.List(); len(e) != 5 || e[0].Reason != "noisy" {
+		t.Fatalf("blocklist = %+v", e)
+	}
+
+	page := resp[6]
+	if page[0] != serial.RespCodeBlocked || binary.LittleEndian.Uint16(page[1:]) != 6 || page[3] != serial.MaxBlockedPerFrame {
+		t.Fatalf("first page header = %v", page[:4])
+	}
+	if len(page) != 4+32*serial.MaxBlockedPerFrame {
+		t.Errorf("first page len = %d", len(page))
+	}
+	if page = resp[7]; page[3] != 2 || len(page) != 4+64 {
+		t.Errorf("second page = count %d, len %d; want 2 keys", page[3], len(page))
+	}
+
+	if resp[8][0] != serial.RespCodeOK {
+		t.Errorf("unblock: %v", resp[8])
+	}
+	if resp[9][0] != serial.RespCodeErr || resp[9][1] != serial.ErrCodeNotFound {
+		t.Errorf("second unblock: %v, want NOT_FOUND", resp[9])
+	}
+}
+
+func TestBlockCommands_NoBlocklist(t *testing.T) {
+	s := NewServer(Config{Node: &fakeNode{clk: clock.New(), contacts: &stubStore{}}})
+	resp := collectResponses(t, s, cmd(serial.CmdGetBlocked))
+	if resp[0][0] != serial.RespCodeErr || resp[0][1] != serial.ErrCodeUnsupportedCmd {
+		t.Errorf("got %v, want UNSUPPORTED_CMD", resp[0])
+	}
+}
+
+func TestGetChanges(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	store := contact.NewManager(priv, contact.ManagerConfig{MaxContacts: 8})
+	s := NewServer(Config{Node: &fakeNode{clk: clock.New(), contacts: store}})
+
+	var a, b, c core.MeshCoreID
+	a[0], b[0], c[0] = 0x0A, 0x0B, 0x0C
+	store.AddContact(&contact.ContactInfo{ID: a, Name: "A", OutPathLen: contact.PathUnknown})
+	store.AddContact(&contact.ContactInfo{ID: b, Name: "B", OutPathLen: contact.PathUnknown})
+	synced := uint32(store.Journal().Seq())
+	store.RemoveContact(a)
+	store.UpdateContact(&contact.ContactInfo{ID: b, Name: "B2", OutPathLen: contact.PathUnknown})
+	store.AddContact(&contact.ContactInfo{ID: c, Name: "C", OutPathLen: contact.PathUnknown})
+	store.UpdateContact(&contact.ContactInfo{ID: b, Name: "B3", OutPathLen: contact.PathUnknown})
+
+	getChanges := func(since uint32) []byte {
+		return cmd(binary.LittleEndian.AppendUint32([]byte{serial.CmdGetChanges}, since)...)
+	}
+	resp := collectResponses(t, s, bytes.Join([][]byte{getChanges(synced), getChanges(99)}, nil))
+	if len(resp) != 7 {
+		t.Fatalf("got %d responses, want 7", len(resp))
+	}
+	if !bytes.Equal(resp[0], serial.EncodeChangesStart(false, 3)) {
+		t.Errorf("start = %v", resp[0])
+	}
+	// One frame per contact, in the order of its latest change.
+	if !bytes.Equal(resp[1], serial.EncodeContactRemoved(a)) {
+		t.Errorf("frame 1 = %v, want A removed", resp[1])
+	}
+	if ct, err := serial.ParseContact(resp[2]); err != nil || resp[2][0] != serial.RespCodeContact || ct.PublicKey != c {
+		t.Errorf("frame 2 = %v, want contact C", resp[2])
+	}
+	if ct, err := serial.ParseContact(resp[3]); err != nil || ct.PublicKey != b || ct.Name != "B3" {
+		t.Errorf("frame 3 = %+v, want contact B3", ct)
+	}
+	if !bytes.Equal(resp[4], serial.EncodeEndOfChanges(6)) {
+		t.Errorf("end = %v", resp[4])
+	}
+
+	// A seq the journal does not know asks the app to resync.
+	if !bytes.Equal(resp[5], serial.EncodeChangesStart(true, 0)) || !bytes.Equal(resp[6], serial.EncodeEndOfChanges(6)) {
+		t.Errorf("reset = %v %v", resp[5], resp[6])
+	}
+}
+
+// lockedBuffer is a bytes.Buffer written by a session and read by the test.
+type lockedBuffer struct {
+	ss  *session
+	buf bytes.Buffer
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) { return l.buf.Write(p) } // under ss.mu
+
+func (l *lockedBuffer) frames() [][]byte {
+	l.ss.mu.Lock()
+	defer l.ss.mu.Unlock()
+	return decodeFrames(bytes.NewBuffer(l.buf.Bytes()))
+}
+
+func TestGetChanges_Push(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	store := contact.NewManager(priv, contact.ManagerConfig{MaxContacts: 8})
+	s := NewServer(Config{Node: &fakeNode{clk: clock.New(), contacts: store}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &lockedBuffer{}
+	sess := &session{srv: s, w: out, ctx: ctx}
+	out.ss = sess
+	if err := s.dispatch(sess, binary.LittleEndian.AppendUint32([]byte{serial.CmdGetChanges}, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	var id core.MeshCoreID
+	id[0] = 0x42
+	store.AddContact(&contact.ContactInfo{ID: id, Name: "new", OutPathLen: contact.PathUnknown})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		frames := out.frames()
+		if len(frames) == 3 {
+			if !bytes.Equal(frames[2], serial.EncodeContactsChanged(1)) {
+				t.Fatalf("push = %v", frames[2])
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no CONTACTS_CHANGED push; frames = %v", frames)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,157 @@
+package contact
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/kabili207/meshcore-go/core"
+)
+
+// BlockEntry is one peer in a Blocklist.
+type BlockEntry struct {
+	ID     core.MeshCoreID
+	Since  uint32 // local clock time the block was added
+	Reason string
+}
+
+// persistedBlock is the on-disk JSON form of a BlockEntry.
+type persistedBlock struct {
+	ID     string `json:"id"` // hex-encoded 32-byte public key
+	Since  uint32 `json:"since,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Blocklist is the set of peers a node ignores: their adverts are not used to
+// add or update contacts, their direct messages are dropped without an ACK,
+// and room servers refuse their posts. Blocking is by full public key and is
+// independent of the contact store, so a blocked peer stays blocked after its
+// contact is removed or evicted.
+//
+// It is safe for concurrent use. A Blocklist opened with OpenBlocklist is
+// persisted to a JSON file on every change (atomically, via temp file +
+// rename); blocks change rarely, so writes are not debounced.
+type Blocklist struct {
+	path string
+
+	mu      sync.RWMutex
+	entries map[core.MeshCoreID]BlockEntry
+}
+
+// NewBlocklist creates an empty, in-memory blocklist.
+func NewBlocklist() *Blocklist {
+	return &Blocklist{entries: make(map[core.MeshCoreID]BlockEntry)}
+}
+
+// OpenBlocklist loads a blocklist from path, which need not exist yet. Changes
+// are written back to it.
+func OpenBlocklist(path string) (*Blocklist, error) {
+	l := NewBlocklist()
+	l.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, err
+	}
+	var records []persistedBlock
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range records {
+		id, err := core.ParseMeshCoreID(r.ID)
+		if err != nil {
+			continue // skip malformed entry
+		}
+		l.entries[id] = BlockEntry{ID: id, Since: r.Since, Reason: r.Reason}
+	}
+	return l, nil
+}
+
+// Block adds or updates a block.
+func (l *Blocklist) Block(id core.MeshCoreID, since uint32, reason string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[id] = BlockEntry{ID: id, Since: since, Reason: reason}
+	return l.saveLocked()
+}
+
+// Unblock removes a block. Returns false if id was not blocked.
+func (l *Blocklist) Unblock(id core.MeshCoreID) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.entries[id]; !ok {
+		return false, nil
+	}
+	delete(l.entries, id)
+	return true, l.saveLocked()
+}
+
+// IsBlocked reports whether id is blocked. A nil Blocklist blocks nobody.
+func (l *Blocklist) IsBlocked(id core.MeshCoreID) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.entries[id]
+	return ok
+}
+
+// Len returns the number of blocked peers.
+func (l *Blocklist) Len() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.entries)
+}
+
+// List returns all blocks, oldest first.
+func (l *Blocklist) List() []BlockEntry {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]BlockEntry, 0, len(l.entries))
+	for _, e := range l.entries {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Since != out[j].Since {
+			return out[i].Since < out[j].Since
+		}
+		return out[i].ID.String() < out[j].ID.String()
+	})
+	return out
+}
+
+// saveLocked writes the list to its file, if it has one. Must be called with
+// l.mu held.
+func (l *Blocklist) saveLocked() error {
+	if l.path == "" {
+		return nil
+	}
+	records := make([]persistedBlock, 0, len(l.entries))
+	for _, e := range l.entries {
+		records = append(records, persistedBlock{ID: hex.EncodeToString(e.ID[:]), Since: e.Since, Reason: e.Reason})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
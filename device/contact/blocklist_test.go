@@ -0,0 +1,73 @@
+package contact
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+func TestBlocklist_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.json")
+	l, err := OpenBlocklist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a, b core.MeshCoreID
+	a[0], b[0] = 0x01, 0x02
+	if err := l.Block(a, 20, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Block(b, 10, ""); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := l.Unblock(core.MeshCoreID{}); ok || err != nil {
+		t.Errorf("Unblock(unknown) = %v, %v", ok, err)
+	}
+
+	reopened, err := OpenBlocklist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reopened.List()
+	if len(got) != 2 || got[0].ID != b || got[1].ID != a || got[1].Reason != "spam" {
+		t.Fatalf("List() = %+v", got)
+	}
+	if ok, err := reopened.Unblock(a); !ok || err != nil {
+		t.Fatalf("Unblock = %v, %v", ok, err)
+	}
+	reopened, err = OpenBlocklist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.IsBlocked(a) || !reopened.IsBlocked(b) {
+		t.Error("unblock not persisted")
+	}
+
+	var nilList *Blocklist
+	if nilList.IsBlocked(a) {
+		t.Error("nil blocklist blocks")
+	}
+}
+
+func TestProcessAdvert_Blocked(t *testing.T) {
+	store := newTestManager(t, 8, false)
+	kp := generateTestKeyPair(t)
+	advert := makeSignedAdvert(t, kp, "Pest", codec.NodeTypeChat, 1000)
+
+	var id core.MeshCoreID
+	copy(id[:], advert.PubKey[:])
+	list := NewBlocklist()
+	if err := list.Block(id, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	res := ProcessAdvert(store, advert, 1, true, AdvertOptions{Blocklist: list})
+	if !res.Rejected || res.RejectReason != "blocked" {
+		t.Fatalf("result = %+v, want blocked rejection", res)
+	}
+	if store.GetByPubKey(id) != nil {
+		t.Error("blocked peer added")
+	}
+}
@@ -0,0 +1,112 @@
+package contact
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+// ConflictKind classifies how an advert clashes with a stored contact.
+type ConflictKind uint8
+
+const (
+	// ConflictNameReuse: another key already uses the advertised name
+	// (compared case-insensitively). This is what an impersonator looks like.
+	ConflictNameReuse ConflictKind = iota + 1
+
+	// ConflictHashCollision: another contact shares the advertiser's 1-byte
+	// public key hash, so 1-byte path hashes cannot tell the two apart.
+	ConflictHashCollision
+)
+
+// String returns "name_reuse" or "hash_collision".
+func (k ConflictKind) String() string {
+	switch k {
+	case ConflictNameReuse:
+		return "name_reuse"
+	case ConflictHashCollision:
+		return "hash_collision"
+	default:
+		return "unknown"
+	}
+}
+
+// Conflict is a stored contact that a new or renamed advert clashes with.
+type Conflict struct {
+	Kind  ConflictKind
+	Other *ContactInfo
+}
+
+// DetectConflicts returns the stored contacts, other than id itself, that an
+// advert from id with the given name and node type clashes with.
+//
+// Every other key using the same name is reported. Hash collisions are common
+// on a busy mesh and only matter where they can misroute, so they are reported
+// only when the other contact is a favourite or both sides relay packets
+// (repeaters and room servers), the nodes whose hashes make up paths.
+func DetectConflicts(store ContactStore, id core.MeshCoreID, name string, nodeType uint8) []Conflict {
+	var out []Conflict
+	if name != "" {
+		store.ForEach(func(c *ContactInfo) bool {
+			if c.ID != id && strings.EqualFold(c.Name, name) {
+				out = append(out, Conflict{Kind: ConflictNameReuse, Other: c})
+			}
+			return true
+		})
+	}
+	for _, c := range store.SearchByHash(id.Hash()) {
+		if c.ID == id {
+			continue
+		}
+		if c.IsFavorite() || (relays(nodeType) && relays(c.Type)) {
+			out = append(out, Conflict{Kind: ConflictHashCollision, Other: c})
+		}
+	}
+	return out
+}
+
+// relays reports whether nodes of type t forward packets and so appear in
+// paths.
+func relays(t uint8) bool {
+	return t == codec.NodeTypeRepeater || t == codec.NodeTypeRoom
+}
+
+// HashCollision is a set of keys sharing one 1-byte hash.
+type HashCollision struct {
+	Hash uint8
+	IDs  []core.MeshCoreID
+}
+
+// HashCollisions groups the keys of every stored contact, plus any extra keys
+// (such as neighbours not kept as contacts), by 1-byte hash and returns the
+// groups of two or more, in hash order. Keys within a group are sorted.
+func HashCollisions(store ContactStore, extra ...core.MeshCoreID) []HashCollision {
+	seen := make(map[core.MeshCoreID]bool)
+	var buckets [256][]core.MeshCoreID
+	add := func(id core.MeshCoreID) {
+		if !seen[id] {
+			seen[id] = true
+			buckets[id.Hash()] = append(buckets[id.Hash()], id)
+		}
+	}
+	store.ForEach(func(c *ContactInfo) bool {
+		add(c.ID)
+		return true
+	})
+	for _, id := range extra {
+		add(id)
+	}
+
+	var out []HashCollision
+	for h, ids := range buckets {
+		if len(ids) < 2 {
+			continue
+		}
+		sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+		out = append(out, HashCollision{Hash: uint8(h), IDs: ids})
+	}
+	return out
+}
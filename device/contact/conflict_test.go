@@ -0,0 +1,138 @@
+package contact
+
+import (
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+func TestDetectConflicts_NameReuse(t *testing.T) {
+	m := newTestManager(t, 10, false)
+	alice := makeContactWithID(makeIDWithHash(0x10), "Alice", 100)
+	m.AddContact(alice)
+
+	got := DetectConflicts(m, makeIDWithHash(0x20), "ALICE", codec.NodeTypeChat)
+	if len(got) != 1 || got[0].Kind != ConflictNameReuse || got[0].Other.ID != alice.ID {
+		t.Fatalf("got %+v, want name reuse of Alice", got)
+	}
+
+	// A contact never conflicts with itself.
+	if got := DetectConflicts(m, alice.ID, "Alice", codec.NodeTypeChat); len(got) != 0 {
+		t.Errorf("self conflict: %+v", got)
+	}
+}
+
+func TestDetectConflicts_HashCollision(t *testing.T) {
+	m := newTestManager(t, 10, false)
+
+	repeater := makeContactWithID(makeIDWithHash(0x30), "Hill", 100)
+	repeater.Type = codec.NodeTypeRepeater
+	m.AddContact(repeater)
+
+	fav := makeContactWithID(makeIDWithHash(0x40), "Bob", 100)
+	fav.SetFavorite(true)
+	m.AddContact(fav)
+
+	chat := makeContactWithID(makeIDWithHash(0x50), "Carol", 100)
+	m.AddContact(chat)
+
+	newID := func(hash byte) core.MeshCoreID {
+		var id core.MeshCoreID
+		id[0], id[31] = hash, 0xEE
+		return id
+	}
+
+	tests := []struct {
+		name     string
+		id       core.MeshCoreID
+		nodeType uint8
+		want     int
+	}{
+		{"repeater vs repeater", newID(0x30), codec.NodeTypeRepeater, 1},
+		{"chat vs repeater", newID(0x30), codec.NodeTypeChat, 0},
+		{"chat vs favorite", newID(0x40), codec.NodeTypeChat, 1},
+		{"chat vs chat", newID(0x50), codec.NodeTypeChat, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectConflicts(m, tt.id, "Unique", tt.nodeType)
+			if len(got) != tt.want {
+				t.Fatalf("got %d conflicts, want %d: %+v", len(got), tt.want, got)
+			}
+			if tt.want > 0 && got[0].Kind != ConflictHashCollision {
+				t.Errorf("kind = %v, want hash_collision", got[0].Kind)
+			}
+		})
+	}
+}
+
+func TestHashCollisions(t *testing.T) {
+	m := newTestManager(t, 10, false)
+	a := makeIDWithHash(0x11)
+	b := a
+	b[31] = 1
+	m.AddContact(makeContactWithID(a, "A", 100))
+	m.AddContact(makeContactWithID(b, "B", 100))
+	m.AddContact(makeContactWithID(makeIDWithHash(0x22), "C", 100))
+
+	neighbor := makeIDWithHash(0x22)
+	neighbor[31] = 9
+
+	// The duplicate a in extra is counted once.
+	got := HashCollisions(m, neighbor, a)
+	if len(got) != 2 {
+		t.Fatalf("got %d groups, want 2: %+v", len(got), got)
+	}
+	if got[0].Hash != 0x11 || len(got[0].IDs) != 2 || got[0].IDs[0] != a || got[0].IDs[1] != b {
+		t.Errorf("group 0 = %+v", got[0])
+	}
+	if got[1].Hash != 0x22 || len(got[1].IDs) != 2 {
+		t.Errorf("group 1 = %+v", got[1])
+	}
+}
+
+func TestProcessAdvert_Conflicts(t *testing.T) {
+	m := newTestManager(t, 10, false)
+	kp := generateTestKeyPair(t)
+
+	m.AddContact(makeContactWithID(makeIDWithHash(0x60), "Base", 100))
+
+	res := ProcessAdvert(m, makeSignedAdvert(t, kp, "Base", codec.NodeTypeChat, 1000), 2000, true)
+	if res.Rejected || len(res.Conflicts) != 1 || res.Conflicts[0].Kind != ConflictNameReuse {
+		t.Fatalf("new advert: %+v", res)
+	}
+
+	// Repeating the same name is not reported again.
+	res = ProcessAdvert(m, makeSignedAdvert(t, kp, "Base", codec.NodeTypeChat, 1001), 2001, true)
+	if len(res.Conflicts) != 0 {
+		t.Errorf("unchanged name re-reported: %+v", res.Conflicts)
+	}
+
+	// Nor is a rename to a free name.
+	res = ProcessAdvert(m, makeSignedAdvert(t, kp, "Other", codec.NodeTypeChat, 1002), 2002, true)
+	if len(res.Conflicts) != 0 {
+		t.Errorf("rename conflicts: %+v", res.Conflicts)
+	}
+
+	// Adverts that are not added are not checked, however often they are
+	// rebroadcast.
+	kp2 := generateTestKeyPair(t)
+	advert := makeSignedAdvert(t, kp2, "other", codec.NodeTypeChat, 1000)
+	for i := 0; i < 2; i++ {
+		res = ProcessAdvert(m, advert, 2000, false)
+		if !res.Rejected || len(res.Conflicts) != 0 {
+			t.Errorf("rejected advert, try %d: %+v", i, res)
+		}
+	}
+	res = ProcessAdvert(m, advert, 2000, true, AdvertOptions{HopCount: 5, MaxAutoAddHops: 2})
+	if !res.Rejected || len(res.Conflicts) != 0 {
+		t.Errorf("advert beyond max hops: %+v", res)
+	}
+
+	// Once it is added, the clash is reported.
+	res = ProcessAdvert(m, advert, 2000, true)
+	if res.Rejected || len(res.Conflicts) != 1 {
+		t.Errorf("added advert: %+v", res)
+	}
+}
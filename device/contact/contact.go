@@ -14,7 +14,6 @@ import (
 
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
-	"github.com/kabili207/meshcore-go/core/crypto"
 )
 
 const (
@@ -55,10 +54,12 @@ type ContactInfo struct {
 	// Sync tracking
 	SyncSince uint32
 
-	// Shared secret cache (lazy ECDH, protected by its own mutex)
-	mu                sync.Mutex
-	sharedSecret      [32]byte
-	sharedSecretValid bool
+	// Link statistics and the shared secret cache, protected by their own
+	// mutex. A stored contact shares its ContactManager's SecretCache; a
+	// standalone one creates its own on first use.
+	mu      sync.Mutex
+	stats   LinkStats
+	secrets SecretCache
 }
 
 // IsFavorite returns true if the contact is marked as a favorite.
@@ -76,45 +77,41 @@ func (c *ContactInfo) SetFavorite(fav bool) {
 	}
 }
 
-// HasDirectPath returns true if a direct routing path is known for this contact.
-func (c *ContactInfo) HasDirectPath() bool {
-	return c.OutPathLen != PathUnknown
+// GetSharedSecret returns the ECDH shared secret between the local node's
+// private key and this contact's public key, computing it on first use and
+// caching it for subsequent calls. Thread-safe.
+//
+// The secret is computed via X25519 ECDH (Ed25519 keys transposed to X25519).
+// Use InvalidateSharedSecret to force recomputation.
+func (c *ContactInfo) GetSharedSecret(localPrivKey ed25519.PrivateKey) ([]byte, error) {
+	return c.secretCache().SharedSecret(localPrivKey, c.ID)
 }
 
-// IsTransient returns true if the contact is transient/anonymous
-// (firmware ADV_TYPE_NONE). Transient contacts live in a small separate pool
-// and are evicted independently of regular contacts.
-func (c *ContactInfo) IsTransient() bool {
-	return c.Type == codec.NodeTypeNone
+// InvalidateSharedSecret drops the cached shared secret, forcing
+// recomputation on the next GetSharedSecret call.
+func (c *ContactInfo) InvalidateSharedSecret() {
+	c.secretCache().Forget(c.ID)
 }
 
-// GetSharedSecret lazily computes and caches the ECDH shared secret between
-// the local node's private key and this contact's public key. Thread-safe.
-//
-// The secret is computed via X25519 ECDH (Ed25519 keys transposed to X25519)
-// and cached for subsequent calls. Use InvalidateSharedSecret to force
-// recomputation.
-func (c *ContactInfo) GetSharedSecret(localPrivKey ed25519.PrivateKey) ([]byte, error) {
+// secretCache returns the cache GetSharedSecret uses, creating a single-entry
+// one for a contact not held by a ContactManager.
+func (c *ContactInfo) secretCache() SecretCache {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-
-	if c.sharedSecretValid {
-		return c.sharedSecret[:], nil
+	if c.secrets == nil {
+		c.secrets = NewSecretCache(1)
 	}
+	return c.secrets
+}
 
-	secret, err := crypto.ComputeSharedSecret(localPrivKey, c.ID[:])
-	if err != nil {
-		return nil, err
-	}
-	copy(c.sharedSecret[:], secret)
-	c.sharedSecretValid = true
-	return c.sharedSecret[:], nil
+// HasDirectPath returns true if a direct routing path is known for this contact.
+func (c *ContactInfo) HasDirectPath() bool {
+	return c.OutPathLen != PathUnknown
 }
 
-// InvalidateSharedSecret marks the cached shared secret as stale,
-// forcing recomputation on the next GetSharedSecret call.
-func (c *ContactInfo) InvalidateSharedSecret() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.sharedSecretValid = false
+// IsTransient returns true if the contact is transient/anonymous
+// (firmware ADV_TYPE_NONE). Transient contacts live in a small separate pool
+// and are evicted independently of regular contacts.
+func (c *ContactInfo) IsTransient() bool {
+	return c.Type == codec.NodeTypeNone
 }
@@ -0,0 +1,378 @@
+// Package exchange moves contacts in and out of a contact.ContactStore in
+// bulk, as JSON or CSV, exports them as GeoJSON for mapping, and encodes and
+// decodes the meshcore:// contact share URIs the MeshCore apps put in QR codes.
+//
+// The JSON and CSV forms carry what is worth copying between nodes: key,
+// name, type, location, favourite flag, direct path, and last advert time.
+// Link statistics, sync points, and cached secrets stay behind.
+package exchange
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/contact"
+)
+
+// csvHeader is the CSV column layout, written as the first row.
+var csvHeader = []string{"public_key", "name", "type", "favorite", "lat", "lon", "out_path_len", "out_path", "last_advert"}
+
+// record is the JSON form of one contact. Locations are decimal degrees; a
+// contact with no known path has no out_path_len.
+type record struct {
+	PublicKey  string   `json:"public_key"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Favorite   bool     `json:"favorite,omitempty"`
+	Lat        *float64 `json:"lat,omitempty"`
+	Lon        *float64 `json:"lon,omitempty"`
+	OutPathLen *uint8   `json:"out_path_len,omitempty"`
+	OutPath    string   `json:"out_path,omitempty"`
+	LastAdvert uint32   `json:"last_advert,omitempty"`
+}
+
+// Snapshot returns copies of every contact in store, in list order, for
+// export.
+func Snapshot(store contact.ContactStore) []*contact.ContactInfo {
+	var out []*contact.ContactInfo
+	store.ForEach(func(c *contact.ContactInfo) bool {
+		out = append(out, &contact.ContactInfo{
+			ID:                  c.ID,
+			Name:                c.Name,
+			Type:                c.Type,
+			Flags:               c.Flags,
+			OutPathLen:          c.OutPathLen,
+			OutPath:             append([]byte(nil), c.OutPath...),
+			LastAdvertTimestamp: c.LastAdvertTimestamp,
+			LastMod:             c.LastMod,
+			GPSLat:              c.GPSLat,
+			GPSLon:              c.GPSLon,
+		})
+		return true
+	})
+	return out
+}
+
+// WriteJSON writes contacts as an indented JSON array.
+func WriteJSON(w io.Writer, contacts []*contact.ContactInfo) error {
+	records := make([]record, len(contacts))
+	for i, c := range contacts {
+		records[i] = toRecord(c)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// ReadJSON reads contacts written by WriteJSON.
+func ReadJSON(r io.Reader) ([]*contact.ContactInfo, error) {
+	var records []record
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, err
+	}
+	out := make([]*contact.ContactInfo, len(records))
+	for i, rec := range records {
+		c, err := fromRecord(rec)
+		if err != nil {
+			return nil, fmt.Errorf("contact %d: %w", i, err)
+		}
+		out[i] = c
+	}
+	return out, nil
+}
+
+// WriteCSV writes contacts as CSV with a header row.
+func WriteCSV(w io.Writer, contacts []*contact.ContactInfo) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, c := range contacts {
+		rec := toRecord(c)
+		row := []string{rec.PublicKey, rec.Name, rec.Type, "", "", "", "", rec.OutPath, ""}
+		if rec.Favorite {
+			row[3] = "1"
+		}
+		if rec.Lat != nil {
+			row[4] = strconv.FormatFloat(*rec.Lat, 'f', 6, 64)
+			row[5] = strconv.FormatFloat(*rec.Lon, 'f', 6, 64)
+		}
+		if rec.OutPathLen != nil {
+			row[6] = strconv.Itoa(int(*rec.OutPathLen))
+		}
+		if rec.LastAdvert != 0 {
+			row[8] = strconv.FormatUint(uint64(rec.LastAdvert), 10)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads contacts from CSV. The first row must be a header naming the
+// columns written by WriteCSV; they may come in any order, and only
+// public_key is required.
+func ReadCSV(r io.Reader) ([]*contact.ContactInfo, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := col["public_key"]; !ok {
+		return nil, errors.New("csv header: missing public_key column")
+	}
+
+	var out []*contact.ContactInfo
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		rec := record{
+			PublicKey: field("public_key"),
+			Name:      field("name"),
+			Type:      field("type"),
+			OutPath:   field("out_path"),
+		}
+		switch strings.ToLower(field("favorite")) {
+		case "", "0", "false", "no":
+		default:
+			rec.Favorite = true
+		}
+		if lat, lon := field("lat"), field("lon"); lat != "" || lon != "" {
+			la, err1 := strconv.ParseFloat(lat, 64)
+			lo, err2 := strconv.ParseFloat(lon, 64)
+			if err := errors.Join(err1, err2); err != nil {
+				return nil, fmt.Errorf("line %d: location: %w", line, err)
+			}
+			rec.Lat, rec.Lon = &la, &lo
+		}
+		if v := field("out_path_len"); v != "" {
+			n, err := strconv.ParseUint(v, 0, 8)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: out_path_len: %w", line, err)
+			}
+			pl := uint8(n)
+			rec.OutPathLen = &pl
+		}
+		if v := field("last_advert"); v != "" {
+			n, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: last_advert: %w", line, err)
+			}
+			rec.LastAdvert = uint32(n)
+		}
+
+		c, err := fromRecord(rec)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		out = append(out, c)
+	}
+}
+
+// ImportOptions controls how Import merges contacts into a store.
+type ImportOptions struct {
+	// Overwrite replaces the name, type, location, and path of contacts
+	// already in the store. Without it they are left alone. The favourite
+	// flag is only ever set, never cleared.
+	Overwrite bool
+
+	// Now is the local clock time recorded as the LastMod of added and
+	// updated contacts.
+	Now uint32
+}
+
+// ImportResult counts what Import did.
+type ImportResult struct {
+	Added   int
+	Updated int
+	Skipped int // already present, and Overwrite not set
+}
+
+// Import adds contacts to store. It stops at the first contact the store
+// cannot take (contact.ErrContactsFull) and returns the counts so far.
+func Import(store contact.ContactStore, contacts []*contact.ContactInfo, opts ImportOptions) (ImportResult, error) {
+	var res ImportResult
+	for _, c := range contacts {
+		existing := store.GetByPubKey(c.ID)
+		if existing == nil {
+			add := *copyContact(c)
+			add.LastMod = opts.Now
+			if _, err := store.AddContact(&add); err != nil {
+				return res, fmt.Errorf("add %s: %w", c.ID, err)
+			}
+			res.Added++
+			continue
+		}
+
+		upd := copyContact(existing)
+		changed := false
+		if opts.Overwrite {
+			upd.Name, upd.Type = c.Name, c.Type
+			upd.GPSLat, upd.GPSLon = c.GPSLat, c.GPSLon
+			upd.OutPathLen, upd.OutPath = c.OutPathLen, c.OutPath
+			if c.LastAdvertTimestamp > upd.LastAdvertTimestamp {
+				upd.LastAdvertTimestamp = c.LastAdvertTimestamp
+			}
+			changed = true
+		}
+		if c.IsFavorite() && !upd.IsFavorite() {
+			upd.SetFavorite(true)
+			changed = true
+		}
+		if !changed {
+			res.Skipped++
+			continue
+		}
+		upd.LastMod = opts.Now
+		if err := store.UpdateContact(upd); err != nil {
+			return res, fmt.Errorf("update %s: %w", c.ID, err)
+		}
+		res.Updated++
+	}
+	return res, nil
+}
+
+// copyContact copies the exchanged fields of c, plus its sync point.
+func copyContact(c *contact.ContactInfo) *contact.ContactInfo {
+	return &contact.ContactInfo{
+		ID:                  c.ID,
+		Name:                c.Name,
+		Type:                c.Type,
+		Flags:               c.Flags,
+		OutPathLen:          c.OutPathLen,
+		OutPath:             append([]byte(nil), c.OutPath...),
+		LastAdvertTimestamp: c.LastAdvertTimestamp,
+		LastMod:             c.LastMod,
+		GPSLat:              c.GPSLat,
+		GPSLon:              c.GPSLon,
+		SyncSince:           c.SyncSince,
+	}
+}
+
+func toRecord(c *contact.ContactInfo) record {
+	rec := record{
+		PublicKey:  hex.EncodeToString(c.ID[:]),
+		Name:       c.Name,
+		Type:       typeName(c.Type),
+		Favorite:   c.IsFavorite(),
+		LastAdvert: c.LastAdvertTimestamp,
+	}
+	if loc, ok := c.Location(); ok {
+		rec.Lat, rec.Lon = &loc.Lat, &loc.Lon
+	}
+	if c.HasDirectPath() {
+		pl := c.OutPathLen
+		rec.OutPathLen = &pl
+		rec.OutPath = hex.EncodeToString(c.OutPath)
+	}
+	return rec
+}
+
+func fromRecord(rec record) (*contact.ContactInfo, error) {
+	id, err := core.ParseMeshCoreID(rec.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("public_key: %w", err)
+	}
+	typ, err := parseType(rec.Type)
+	if err != nil {
+		return nil, err
+	}
+	if len(rec.Name) > contact.MaxNameLen {
+		return nil, fmt.Errorf("name longer than %d bytes", contact.MaxNameLen)
+	}
+	c := &contact.ContactInfo{
+		ID:                  id,
+		Name:                rec.Name,
+		Type:                typ,
+		OutPathLen:          contact.PathUnknown,
+		LastAdvertTimestamp: rec.LastAdvert,
+	}
+	c.SetFavorite(rec.Favorite)
+	if (rec.Lat == nil) != (rec.Lon == nil) {
+		return nil, errors.New("location needs both lat and lon")
+	}
+	if rec.Lat != nil {
+		if math.Abs(*rec.Lat) > 90 || math.Abs(*rec.Lon) > 180 {
+			return nil, fmt.Errorf("location %v,%v out of range", *rec.Lat, *rec.Lon)
+		}
+		c.GPSLat = int32(math.Round(*rec.Lat * codec.CoordScale))
+		c.GPSLon = int32(math.Round(*rec.Lon * codec.CoordScale))
+	}
+	if rec.OutPathLen != nil && *rec.OutPathLen != contact.PathUnknown {
+		path, err := hex.DecodeString(rec.OutPath)
+		if err != nil {
+			return nil, fmt.Errorf("out_path: %w", err)
+		}
+		if want := codec.PathInfoFromWireByte(*rec.OutPathLen).ByteLen(); len(path) != want {
+			return nil, fmt.Errorf("out_path is %d bytes, out_path_len says %d", len(path), want)
+		}
+		c.OutPathLen = *rec.OutPathLen
+		if len(path) > 0 {
+			c.OutPath = path
+		}
+	}
+	return c, nil
+}
+
+// typeName names a node type as codec.NodeTypeName does, with "none" for
+// transient contacts and the number for unknown types.
+func typeName(t uint8) string {
+	switch t {
+	case codec.NodeTypeNone:
+		return "none"
+	case codec.NodeTypeChat, codec.NodeTypeRepeater, codec.NodeTypeRoom, codec.NodeTypeSensor:
+		return codec.NodeTypeName(t)
+	default:
+		return strconv.Itoa(int(t))
+	}
+}
+
+// parseType reads a type written by typeName; a number is also accepted.
+// Empty means chat.
+func parseType(s string) (uint8, error) {
+	switch strings.ToLower(s) {
+	case "", "chat":
+		return codec.NodeTypeChat, nil
+	case "none":
+		return codec.NodeTypeNone, nil
+	case "repeater":
+		return codec.NodeTypeRepeater, nil
+	case "room":
+		return codec.NodeTypeRoom, nil
+	case "sensor":
+		return codec.NodeTypeSensor, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("unknown type %q", s)
+	}
+	return uint8(n), nil
+}
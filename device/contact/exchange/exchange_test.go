@@ -0,0 +1,211 @@
+package exchange
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/contact"
+)
+
+func newTestManager(t *testing.T) *contact.ContactManager {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contact.NewManager(priv, contact.ManagerConfig{MaxContacts: 16})
+}
+
+func testContacts() []*contact.ContactInfo {
+	var a, b, c core.MeshCoreID
+	a[0], a[31] = 0xA1, 1
+	b[0], b[31] = 0xB2, 2
+	c[0], c[31] = 0xC3, 3
+	fav := &contact.ContactInfo{
+		ID:                  a,
+		Name:                "Alice, \"the\" node",
+		Type:                codec.NodeTypeChat,
+		OutPathLen:          codec.PathInfo{HashSize: 2, HopCount: 2}.ToWireByte(),
+		OutPath:             []byte{1, 2, 3, 4},
+		LastAdvertTimestamp: 1700000000,
+		GPSLat:              47606200,
+		GPSLon:              -122332100,
+	}
+	fav.SetFavorite(true)
+	return []*contact.ContactInfo{
+		fav,
+		{ID: b, Name: "Hilltop", Type: codec.NodeTypeRepeater, OutPathLen: 0},
+		{ID: c, Name: "Lobby", Type: codec.NodeTypeRoom, OutPathLen: contact.PathUnknown},
+	}
+}
+
+func checkContacts(t *testing.T, got, want []*contact.ContactInfo) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d contacts, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.ID != w.ID || g.Name != w.Name || g.Type != w.Type || g.Flags != w.Flags ||
+			g.OutPathLen != w.OutPathLen || !bytes.Equal(g.OutPath, w.OutPath) ||
+			g.LastAdvertTimestamp != w.LastAdvertTimestamp ||
+			g.GPSLat != w.GPSLat || g.GPSLon != w.GPSLon {
+			t.Errorf("contact %d:\n got %+v\nwant %+v", i, g, w)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	want := testContacts()
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"type": "repeater"`) {
+		t.Errorf("type not written by name:\n%s", buf.String())
+	}
+	got, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContacts(t, got, want)
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	want := testContacts()
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkContacts(t, got, want)
+}
+
+func TestReadCSV_PartialColumns(t *testing.T) {
+	in := "name,public_key,type\n" +
+		"Bob," + strings.Repeat("ab", 32) + ",sensor\n"
+	got, err := ReadCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Name != "Bob" || got[0].Type != codec.NodeTypeSensor ||
+		got[0].OutPathLen != contact.PathUnknown {
+		t.Fatalf("got %+v", got[0])
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	key := strings.Repeat("ab", 32)
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"bad key", `[{"public_key":"abcd"}]`},
+		{"bad type", `[{"public_key":"` + key + `","type":"toaster"}]`},
+		{"lat only", `[{"public_key":"` + key + `","lat":1}]`},
+		{"lat range", `[{"public_key":"` + key + `","lat":91,"lon":0}]`},
+		{"path length", `[{"public_key":"` + key + `","out_path_len":2,"out_path":"01"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadJSON(strings.NewReader(tt.json)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+	if _, err := ReadCSV(strings.NewReader("name\nBob\n")); err == nil {
+		t.Fatal("expected error for CSV without public_key")
+	}
+}
+
+func TestImport(t *testing.T) {
+	store := newTestManager(t)
+	contacts := testContacts()
+
+	res, err := Import(store, contacts, ImportOptions{Now: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Added != 3 || res.Updated != 0 || res.Skipped != 0 {
+		t.Fatalf("first import: %+v", res)
+	}
+	checkContacts(t, Snapshot(store), contacts)
+
+	renamed := testContacts()
+	renamed[1].Name = "Hilltop 2"
+	renamed[2].SetFavorite(true)
+
+	res, err = Import(store, renamed, ImportOptions{Now: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Added != 0 || res.Updated != 1 || res.Skipped != 2 {
+		t.Fatalf("merge import: %+v", res)
+	}
+	if got := store.GetByPubKey(renamed[1].ID).Name; got != "Hilltop" {
+		t.Errorf("name overwritten without Overwrite: %q", got)
+	}
+	if !store.GetByPubKey(renamed[2].ID).IsFavorite() {
+		t.Error("favorite flag not merged")
+	}
+
+	res, err = Import(store, renamed, ImportOptions{Overwrite: true, Now: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Updated != 3 {
+		t.Fatalf("overwrite import: %+v", res)
+	}
+	got := store.GetByPubKey(renamed[1].ID)
+	if got.Name != "Hilltop 2" || got.LastMod != 300 {
+		t.Errorf("overwrite: got %+v", got)
+	}
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	var buf bytes.Buffer
+	self := contact.Location{Lat: 47.6, Lon: -122.3}
+	if err := WriteGeoJSON(&buf, testContacts(), GeoJSONOptions{Self: &self, SelfName: "base"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string     `json:"type"`
+				Coordinates [2]float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]any `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &fc); err != nil {
+		t.Fatal(err)
+	}
+	// Self plus the one located contact.
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 2 {
+		t.Fatalf("got %s with %d features", fc.Type, len(fc.Features))
+	}
+	if fc.Features[0].Properties["self"] != true {
+		t.Errorf("first feature = %v, want self", fc.Features[0].Properties)
+	}
+	f := fc.Features[1]
+	if f.Geometry.Type != "Point" || f.Geometry.Coordinates != [2]float64{-122.3321, 47.6062} {
+		t.Errorf("geometry = %+v", f.Geometry)
+	}
+	p := f.Properties
+	if p["name"] != "Alice, \"the\" node" || p["type"] != "chat" || p["favorite"] != true || p["hops"] != 2.0 {
+		t.Errorf("properties = %v", p)
+	}
+	if d, _ := p["distance_km"].(float64); d < 0.5 || d > 5 {
+		t.Errorf("distance_km = %v", p["distance_km"])
+	}
+}
@@ -0,0 +1,79 @@
+package exchange
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/contact"
+)
+
+// geoFeature is a GeoJSON Feature with a Point geometry.
+type geoFeature struct {
+	Type       string         `json:"type"`
+	Geometry   geoPoint       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type geoPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"` // longitude, latitude
+}
+
+// GeoJSONOptions adjusts WriteGeoJSON output.
+type GeoJSONOptions struct {
+	// Self, if set, adds this node as a feature with "self": true, and gives
+	// every contact a "distance_km" from it.
+	Self *contact.Location
+
+	// SelfName names the Self feature.
+	SelfName string
+}
+
+// WriteGeoJSON writes the contacts that have a location as a GeoJSON
+// FeatureCollection of points, for mapping tools. Each feature's properties
+// are public_key, name, type, favorite, last_advert, and hops (the length of
+// the known direct path, omitted when there is none).
+func WriteGeoJSON(w io.Writer, contacts []*contact.ContactInfo, opts GeoJSONOptions) error {
+	features := []geoFeature{}
+	if opts.Self != nil {
+		features = append(features, geoFeature{
+			Type:       "Feature",
+			Geometry:   geoPoint{Type: "Point", Coordinates: [2]float64{opts.Self.Lon, opts.Self.Lat}},
+			Properties: map[string]any{"name": opts.SelfName, "self": true},
+		})
+	}
+	for _, c := range contacts {
+		loc, ok := c.Location()
+		if !ok {
+			continue
+		}
+		props := map[string]any{
+			"public_key": hex.EncodeToString(c.ID[:]),
+			"name":       c.Name,
+			"type":       typeName(c.Type),
+			"favorite":   c.IsFavorite(),
+		}
+		if c.LastAdvertTimestamp != 0 {
+			props["last_advert"] = c.LastAdvertTimestamp
+		}
+		if c.HasDirectPath() {
+			props["hops"] = codec.PathInfoFromWireByte(c.OutPathLen).HopCount
+		}
+		if opts.Self != nil {
+			props["distance_km"] = opts.Self.DistanceKm(loc)
+		}
+		features = append(features, geoFeature{
+			Type:       "Feature",
+			Geometry:   geoPoint{Type: "Point", Coordinates: [2]float64{loc.Lon, loc.Lat}},
+			Properties: props,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Type     string       `json:"type"`
+		Features []geoFeature `json:"features"`
+	}{"FeatureCollection", features})
+}
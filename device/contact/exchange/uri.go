@@ -0,0 +1,163 @@
+package exchange
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/contact"
+)
+
+// URIScheme is the scheme of contact share URIs.
+const URIScheme = "meshcore://"
+
+var (
+	// ErrNotShareURI is returned for a string that is not a meshcore://
+	// contact share.
+	ErrNotShareURI = errors.New("not a meshcore contact share URI")
+
+	// ErrNotAdvert is returned for an advert share whose packet is not an
+	// ADVERT.
+	ErrNotAdvert = errors.New("shared packet is not an advert")
+
+	// ErrBadSignature is returned for an advert share whose signature does not
+	// verify.
+	ErrBadSignature = errors.New("advert signature invalid")
+)
+
+// Share is a decoded contact share URI.
+type Share struct {
+	// Contact is the shared contact, ready to add to a store.
+	Contact *contact.ContactInfo
+
+	// Advert is the verified signed advert of an advert share (the form
+	// CMD_EXPORT_CONTACT produces), or nil for the unsigned contact/add form,
+	// whose name and type are only the sharer's word.
+	Advert *codec.AdvertPayload
+}
+
+// AdvertURI encodes a raw advert packet, as returned by CMD_EXPORT_CONTACT or
+// advert.BuildSelfAdvert, as a share URI: the scheme and the packet in hex.
+func AdvertURI(packet []byte) string {
+	return URIScheme + hex.EncodeToString(packet)
+}
+
+// ContactURI encodes a contact in the apps' unsigned
+// meshcore://contact/add?name=…&public_key=…&type=… form, for contacts whose
+// signed advert is not at hand.
+func ContactURI(c *contact.ContactInfo) string {
+	q := url.Values{}
+	q.Set("name", c.Name)
+	q.Set("public_key", hex.EncodeToString(c.ID[:]))
+	q.Set("type", strconv.Itoa(int(c.Type)))
+	return URIScheme + "contact/add?" + q.Encode()
+}
+
+// ParseURI decodes a share URI of either form. An advert share must carry a
+// valid signature; nowTimestamp becomes the contact's LastMod.
+func ParseURI(uri string, nowTimestamp uint32) (*Share, error) {
+	rest, ok := cutPrefixFold(strings.TrimSpace(uri), URIScheme)
+	if !ok {
+		return nil, ErrNotShareURI
+	}
+	if q, ok := strings.CutPrefix(rest, "contact/add?"); ok {
+		return parseContactAdd(q, nowTimestamp)
+	}
+
+	data, err := hex.DecodeString(rest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotShareURI, err)
+	}
+	var pkt codec.Packet
+	if err := pkt.ReadFrom(data); err != nil {
+		return nil, fmt.Errorf("shared packet: %w", err)
+	}
+	if pkt.PayloadType() != codec.PayloadTypeAdvert {
+		return nil, ErrNotAdvert
+	}
+	advert, err := codec.ParseAdvertPayload(pkt.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("shared advert: %w", err)
+	}
+	if advert.AppData == nil || advert.AppData.Name == "" {
+		return nil, fmt.Errorf("shared advert: missing name")
+	}
+	if !crypto.VerifyAdvert(advert) {
+		return nil, ErrBadSignature
+	}
+
+	c := &contact.ContactInfo{
+		Name:                advert.AppData.Name,
+		Type:                advert.AppData.NodeType,
+		OutPathLen:          contact.PathUnknown,
+		LastAdvertTimestamp: advert.Timestamp,
+		LastMod:             nowTimestamp,
+	}
+	copy(c.ID[:], advert.PubKey[:])
+	if advert.AppData.HasLocation() {
+		c.GPSLat = int32(math.Round(*advert.AppData.Lat * codec.CoordScale))
+		c.GPSLon = int32(math.Round(*advert.AppData.Lon * codec.CoordScale))
+	}
+	return &Share{Contact: c, Advert: advert}, nil
+}
+
+// parseContactAdd decodes the query of a contact/add share.
+func parseContactAdd(query string, nowTimestamp uint32) (*Share, error) {
+	q, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotShareURI, err)
+	}
+	id, err := core.ParseMeshCoreID(q.Get("public_key"))
+	if err != nil {
+		return nil, fmt.Errorf("public_key: %w", err)
+	}
+	typ, err := parseType(q.Get("type"))
+	if err != nil {
+		return nil, err
+	}
+	name := codec.TruncateUTF8(q.Get("name"), contact.MaxNameLen)
+	return &Share{Contact: &contact.ContactInfo{
+		ID:         id,
+		Name:       name,
+		Type:       typ,
+		OutPathLen: contact.PathUnknown,
+		LastMod:    nowTimestamp,
+	}}, nil
+}
+
+// ImportURI decodes a share URI and adds its contact to store. An advert
+// share goes through contact.ProcessAdvert, exactly as if the advert had been
+// heard, so it updates a known contact unless it is older than the last
+// advert seen. A contact/add share only adds a contact not yet known.
+func ImportURI(store contact.ContactStore, uri string, nowTimestamp uint32) (*contact.ContactInfo, error) {
+	share, err := ParseURI(uri, nowTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	if share.Advert != nil {
+		res := contact.ProcessAdvert(store, share.Advert, nowTimestamp, true)
+		if res.Rejected {
+			return nil, fmt.Errorf("advert rejected: %s", res.RejectReason)
+		}
+		return res.Contact, nil
+	}
+	if existing := store.GetByPubKey(share.Contact.ID); existing != nil {
+		return existing, nil
+	}
+	return store.AddContact(share.Contact)
+}
+
+// cutPrefixFold is strings.CutPrefix, ignoring ASCII case.
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
@@ -0,0 +1,135 @@
+package exchange
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/kabili207/meshcore-go/core/clock"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/advert"
+)
+
+func buildAdvert(t *testing.T, name string, lat, lon float64) ([]byte, ed25519.PublicKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pk [32]byte
+	copy(pk[:], pub)
+	builder := advert.NewSelfAdvertBuilder(&advert.SelfAdvertConfig{
+		PrivateKey: priv,
+		PublicKey:  pk,
+		Clock:      clock.New(),
+		AppData: &codec.AdvertAppData{
+			Name:     name,
+			NodeType: codec.NodeTypeRepeater,
+			Lat:      &lat,
+			Lon:      &lon,
+		},
+	})
+	pkt := builder()
+	if pkt == nil {
+		t.Fatal("builder returned nil packet")
+	}
+	return pkt.WriteTo(), pub
+}
+
+func TestAdvertURI(t *testing.T) {
+	pkt, pub := buildAdvert(t, "Ridge", 45.5, -122.25)
+	uri := AdvertURI(pkt)
+	if !strings.HasPrefix(uri, URIScheme) {
+		t.Fatalf("uri = %q", uri)
+	}
+
+	share, err := ParseURI(strings.ToUpper(uri[:len(URIScheme)])+uri[len(URIScheme):], 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := share.Contact
+	if share.Advert == nil || string(c.ID[:]) != string(pub) {
+		t.Fatalf("share = %+v", share)
+	}
+	if c.Name != "Ridge" || c.Type != codec.NodeTypeRepeater || c.LastMod != 42 ||
+		c.GPSLat != 45500000 || c.GPSLon != -122250000 {
+		t.Errorf("contact = %+v", c)
+	}
+
+	store := newTestManager(t)
+	added, err := ImportURI(store, uri, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.GetByPubKey(added.ID) == nil {
+		t.Fatal("contact not added")
+	}
+}
+
+func TestAdvertURI_BadSignature(t *testing.T) {
+	pkt, _ := buildAdvert(t, "Ridge", 0, 0)
+	pkt[len(pkt)-1] ^= 0xFF // corrupt the app data the signature covers
+	if _, err := ParseURI(AdvertURI(pkt), 0); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("err = %v, want ErrBadSignature", err)
+	}
+}
+
+func TestAdvertURI_NotAdvert(t *testing.T) {
+	pkt := codec.Packet{
+		Header:  (codec.PayloadTypeTxtMsg << codec.PHTypeShift) | codec.RouteTypeFlood,
+		Payload: []byte{1, 2, 3, 4},
+	}
+	if _, err := ParseURI(AdvertURI(pkt.WriteTo()), 0); !errors.Is(err, ErrNotAdvert) {
+		t.Fatalf("err = %v, want ErrNotAdvert", err)
+	}
+}
+
+func TestContactURI(t *testing.T) {
+	want := testContacts()[1]
+	uri := ContactURI(want)
+	if !strings.HasPrefix(uri, URIScheme+"contact/add?") {
+		t.Fatalf("uri = %q", uri)
+	}
+	share, err := ParseURI(uri, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if share.Advert != nil {
+		t.Error("unsigned share has an advert")
+	}
+	if c := share.Contact; c.ID != want.ID || c.Name != want.Name || c.Type != want.Type {
+		t.Errorf("contact = %+v", c)
+	}
+
+	store := newTestManager(t)
+	if _, err := ImportURI(store, uri, 7); err != nil {
+		t.Fatal(err)
+	}
+	if store.GetByPubKey(want.ID) == nil {
+		t.Fatal("contact not added")
+	}
+}
+
+func TestContactURI_LongMultibyteName(t *testing.T) {
+	c := testContacts()[1]
+	// 41 bytes: the 32-byte cap falls in the middle of an é.
+	c.Name = "x" + strings.Repeat("é", 20)
+	share, err := ParseURI(ContactURI(c), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "x" + strings.Repeat("é", 15)
+	if got := share.Contact.Name; got != want || !utf8.ValidString(got) {
+		t.Errorf("name = %q (%d bytes), want %q", got, len(got), want)
+	}
+}
+
+func TestParseURI_Invalid(t *testing.T) {
+	for _, uri := range []string{"", "https://example.com", "meshcore://zz", "meshcore://contact/add?public_key=12"} {
+		if _, err := ParseURI(uri, 0); err == nil {
+			t.Errorf("ParseURI(%q): expected error", uri)
+		}
+	}
+}
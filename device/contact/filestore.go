@@ -18,17 +18,18 @@ const DefaultFlushDebounce = 2 * time.Second
 // (the cached shared secret) is intentionally omitted; it is recomputed from the
 // public key on load.
 type persistedContact struct {
-	ID         string `json:"id"` // hex-encoded 32-byte public key
-	Name       string `json:"name,omitempty"`
-	Type       uint8  `json:"type,omitempty"`
-	Flags      uint8  `json:"flags,omitempty"`
-	OutPathLen uint8  `json:"out_path_len"`
-	OutPath    string `json:"out_path,omitempty"` // hex-encoded
-	LastAdvert uint32 `json:"last_advert,omitempty"`
-	LastMod    uint32 `json:"last_mod,omitempty"`
-	GPSLat     int32  `json:"lat,omitempty"`
-	GPSLon     int32  `json:"lon,omitempty"`
-	SyncSince  uint32 `json:"sync_since,omitempty"`
+	ID         string     `json:"id"` // hex-encoded 32-byte public key
+	Name       string     `json:"name,omitempty"`
+	Type       uint8      `json:"type,omitempty"`
+	Flags      uint8      `json:"flags,omitempty"`
+	OutPathLen uint8      `json:"out_path_len"`
+	OutPath    string     `json:"out_path,omitempty"` // hex-encoded
+	LastAdvert uint32     `json:"last_advert,omitempty"`
+	LastMod    uint32     `json:"last_mod,omitempty"`
+	GPSLat     int32      `json:"lat,omitempty"`
+	GPSLon     int32      `json:"lon,omitempty"`
+	SyncSince  uint32     `json:"sync_since,omitempty"`
+	Stats      *LinkStats `json:"stats,omitempty"`
 }
 
 // FileContactStore is a ContactPersistence backend that stores contacts as a
@@ -151,7 +152,7 @@ func (f *FileContactStore) scheduleLocked() {
 }
 
 func toPersisted(c *ContactInfo) persistedContact {
-	return persistedContact{
+	r := persistedContact{
 		ID:         hex.EncodeToString(c.ID[:]),
 		Name:       c.Name,
 		Type:       c.Type,
@@ -164,11 +165,15 @@ func toPersisted(c *ContactInfo) persistedContact {
 		GPSLon:     c.GPSLon,
 		SyncSince:  c.SyncSince,
 	}
+	if stats := c.Stats(); stats != (LinkStats{}) {
+		r.Stats = &stats
+	}
+	return r
 }
 
 func (r persistedContact) toContactInfo(id core.MeshCoreID) *ContactInfo {
 	outPath, _ := hex.DecodeString(r.OutPath)
-	return &ContactInfo{
+	c := &ContactInfo{
 		ID:                  id,
 		Name:                r.Name,
 		Type:                r.Type,
@@ -181,6 +186,10 @@ func (r persistedContact) toContactInfo(id core.MeshCoreID) *ContactInfo {
 		GPSLon:              r.GPSLon,
 		SyncSince:           r.SyncSince,
 	}
+	if r.Stats != nil {
+		c.SetStats(*r.Stats)
+	}
+	return c
 }
 
 func decodeID(s string) (core.MeshCoreID, error) {
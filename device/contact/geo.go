@@ -0,0 +1,102 @@
+package contact
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances.
+const earthRadiusKm = 6371.0088
+
+// Location is a position in decimal degrees.
+type Location struct {
+	Lat, Lon float64
+}
+
+// DistanceKm returns the great-circle (haversine) distance between l and o in
+// kilometres.
+func (l Location) DistanceKm(o Location) float64 {
+	lat1, lat2 := l.Lat*math.Pi/180, o.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (o.Lon - l.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// AdvertLocation returns the location carried in advert app data, if any.
+// Like the firmware, 0,0 is treated as no location.
+func AdvertLocation(appData *codec.AdvertAppData) (Location, bool) {
+	if appData == nil || !appData.HasLocation() {
+		return Location{}, false
+	}
+	l := Location{Lat: *appData.Lat, Lon: *appData.Lon}
+	return l, l.Lat != 0 || l.Lon != 0
+}
+
+// Location returns the contact's last advertised location. ok is false if it
+// has not advertised one (stored as 0,0, as in the firmware).
+func (c *ContactInfo) Location() (loc Location, ok bool) {
+	if c.GPSLat == 0 && c.GPSLon == 0 {
+		return Location{}, false
+	}
+	return Location{
+		Lat: float64(c.GPSLat) / codec.CoordScale,
+		Lon: float64(c.GPSLon) / codec.CoordScale,
+	}, true
+}
+
+// ContactDistance is a contact and its distance from a query point.
+type ContactDistance struct {
+	Contact    *ContactInfo
+	DistanceKm float64
+}
+
+// SortByDistance returns every contact with a location, nearest to from first.
+// Contacts without a location are left out.
+func SortByDistance(store ContactStore, from Location) []ContactDistance {
+	return nearby(store, from, func(*ContactInfo, float64) bool { return true })
+}
+
+// Within returns the contacts within radiusKm of center, nearest first.
+func Within(store ContactStore, center Location, radiusKm float64) []ContactDistance {
+	return nearby(store, center, func(_ *ContactInfo, d float64) bool { return d <= radiusKm })
+}
+
+// Nearest returns up to n contacts nearest to from, restricted to the given
+// node types (codec.NodeTypeRepeater, ...) if any are given.
+func Nearest(store ContactStore, from Location, n int, types ...uint8) []ContactDistance {
+	if n <= 0 {
+		return nil
+	}
+	out := nearby(store, from, func(c *ContactInfo, _ float64) bool {
+		return len(types) == 0 || slices.Contains(types, c.Type)
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// nearby collects the located contacts that keep accepts, sorted by distance
+// from from, ties in list order.
+func nearby(store ContactStore, from Location, keep func(c *ContactInfo, distKm float64) bool) []ContactDistance {
+	var out []ContactDistance
+	store.ForEach(func(c *ContactInfo) bool {
+		loc, ok := c.Location()
+		if !ok {
+			return true
+		}
+		if d := from.DistanceKm(loc); keep(c, d) {
+			out = append(out, ContactDistance{Contact: c, DistanceKm: d})
+		}
+		return true
+	})
+	slices.SortStableFunc(out, func(a, b ContactDistance) int {
+		return cmp.Compare(a.DistanceKm, b.DistanceKm)
+	})
+	return out
+}
@@ -0,0 +1,84 @@
+package contact
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+func TestLocation_DistanceKm(t *testing.T) {
+	seattle := Location{Lat: 47.6062, Lon: -122.3321}
+	portland := Location{Lat: 45.5152, Lon: -122.6784}
+	if d := seattle.DistanceKm(portland); math.Abs(d-233.8) > 1 {
+		t.Errorf("Seattle-Portland = %.1f km, want ~233.8", d)
+	}
+	if d := seattle.DistanceKm(seattle); d != 0 {
+		t.Errorf("self distance = %v", d)
+	}
+}
+
+func TestGeoQueries(t *testing.T) {
+	m := newTestManager(t, 16, false)
+	add := func(hash byte, typ uint8, lat, lon float64) *ContactInfo {
+		c := makeContactWithID(makeIDWithHash(hash), "", 1)
+		c.Type = typ
+		c.GPSLat = int32(math.Round(lat * codec.CoordScale))
+		c.GPSLon = int32(math.Round(lon * codec.CoordScale))
+		stored, err := m.AddContact(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stored
+	}
+	far := add(1, codec.NodeTypeRepeater, 1.0, 0) // ~111 km
+	near := add(2, codec.NodeTypeChat, 0.01, 0)   // ~1.1 km
+	mid := add(3, codec.NodeTypeRepeater, 0.1, 0) // ~11 km
+	add(4, codec.NodeTypeRepeater, 0, 0)          // no location
+	west := add(5, codec.NodeTypeRoom, 0, -0.05)  // ~5.6 km
+	origin := Location{}
+
+	ids := func(got []ContactDistance) []*ContactInfo {
+		out := make([]*ContactInfo, len(got))
+		for i, g := range got {
+			out[i] = g.Contact
+		}
+		return out
+	}
+	check := func(name string, got []ContactDistance, want ...*ContactInfo) {
+		t.Helper()
+		g := ids(got)
+		if len(g) != len(want) {
+			t.Fatalf("%s: got %d contacts, want %d", name, len(g), len(want))
+		}
+		for i := range want {
+			if g[i] != want[i] {
+				t.Errorf("%s[%d] = %s, want %s", name, i, g[i].ID.String()[:4], want[i].ID.String()[:4])
+			}
+		}
+	}
+
+	check("SortByDistance", SortByDistance(m, origin), near, west, mid, far)
+	check("Within", Within(m, origin, 10), near, west)
+	check("Nearest repeaters", Nearest(m, origin, 1, codec.NodeTypeRepeater), mid)
+	check("Nearest any", Nearest(m, origin, 2), near, west)
+	check("Nearest rooms or chats", Nearest(m, origin, 5, codec.NodeTypeRoom, codec.NodeTypeChat), near, west)
+
+	if got := Within(m, origin, 2); len(got) != 1 || math.Abs(got[0].DistanceKm-1.11) > 0.01 {
+		t.Errorf("Within 2 km = %+v", got)
+	}
+}
+
+func TestAdvertLocation(t *testing.T) {
+	lat, lon := 12.5, -3.25
+	if loc, ok := AdvertLocation(&codec.AdvertAppData{Lat: &lat, Lon: &lon}); !ok || loc != (Location{12.5, -3.25}) {
+		t.Errorf("AdvertLocation = %v, %v", loc, ok)
+	}
+	zero := 0.0
+	if _, ok := AdvertLocation(&codec.AdvertAppData{Lat: &zero, Lon: &zero}); ok {
+		t.Error("0,0 reported as a location")
+	}
+	if _, ok := AdvertLocation(&codec.AdvertAppData{}); ok {
+		t.Error("missing location reported")
+	}
+}
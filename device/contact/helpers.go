@@ -1,7 +1,9 @@
 package contact
 
 import (
+	"bytes"
 	"math"
+	"strings"
 
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
@@ -23,6 +25,11 @@ type AdvertResult struct {
 
 	// RejectReason is a human-readable explanation when Rejected is true.
 	RejectReason string
+
+	// Conflicts lists the stored contacts the advert clashes with (see
+	// DetectConflicts). It is only checked for an advert that adds a contact
+	// or changes a contact's name; rejected adverts report none.
+	Conflicts []Conflict
 }
 
 // AdvertOptions provides optional parameters for ProcessAdvert.
@@ -35,6 +42,10 @@ type AdvertOptions struct {
 	// the advert is rejected for auto-add but still returns the contact
 	// (for "discovered but not added" events).
 	MaxAutoAddHops int
+
+	// Blocklist, if set, rejects adverts from blocked peers: they neither add
+	// nor update a contact. May be nil.
+	Blocklist *Blocklist
 }
 
 // ProcessAdvert handles a received ADVERT packet by verifying the signature,
@@ -77,7 +88,21 @@ func ProcessAdvert(
 
 	existing := store.GetByPubKey(advertID)
 
-	// Step 4: replay prevention
+	var o AdvertOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	// Step 4a: blocked peers are ignored
+	if o.Blocklist.IsBlocked(advertID) {
+		return AdvertResult{
+			Contact:      existing,
+			Rejected:     true,
+			RejectReason: "blocked",
+		}
+	}
+
+	// Step 4b: replay prevention
 	if existing != nil && advert.Timestamp <= existing.LastAdvertTimestamp {
 		return AdvertResult{
 			Contact:      existing,
@@ -87,10 +112,6 @@ func ProcessAdvert(
 	}
 
 	// Step 5a: max auto-add hops filter (new contacts only)
-	var o AdvertOptions
-	if len(opts) > 0 {
-		o = opts[0]
-	}
 	if existing == nil && o.MaxAutoAddHops > 0 && o.HopCount >= o.MaxAutoAddHops {
 		temp := populateContactFromAdvert(advert, nowTimestamp)
 		return AdvertResult{
@@ -110,6 +131,14 @@ func ProcessAdvert(
 		}
 	}
 
+	// Step 5c: impersonation and hash-collision checks, before the store
+	// changes. Only adverts that add a contact or rename one are checked, so
+	// rebroadcasts of an advert that is turned away are not re-reported.
+	var conflicts []Conflict
+	if existing == nil || !strings.EqualFold(existing.Name, advert.AppData.Name) {
+		conflicts = DetectConflicts(store, advertID, advert.AppData.Name, advert.AppData.NodeType)
+	}
+
 	// Step 6: new contact — add to store
 	if existing == nil {
 		newContact := populateContactFromAdvert(advert, nowTimestamp)
@@ -121,8 +150,9 @@ func ProcessAdvert(
 			}
 		}
 		return AdvertResult{
-			Contact: stored,
-			IsNew:   true,
+			Contact:   stored,
+			IsNew:     true,
+			Conflicts: conflicts,
 		}
 	}
 
@@ -147,7 +177,8 @@ func ProcessAdvert(
 	_ = store.UpdateContact(updated)
 
 	return AdvertResult{
-		Contact: store.GetByPubKey(advertID),
+		Contact:   store.GetByPubKey(advertID),
+		Conflicts: conflicts,
 	}
 }
 
@@ -170,23 +201,91 @@ func ProcessPath(
 	pathContent *codec.PathContent,
 	nowTimestamp uint32,
 ) (contact *ContactInfo, extraType uint8, extraData []byte, err error) {
-	found := store.GetByPubKey(senderID)
+	// PathLen is the encoded wire byte (mode bits | hop count).
+	found, err := UpdatePath(store, senderID, pathContent.PathLen, pathContent.Path, nowTimestamp)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return found, pathContent.ExtraType, pathContent.Extra, nil
+}
+
+// UpdatePath sets a contact's direct routing path and LastMod through
+// UpdateContact, so the change is persisted and journaled like any other,
+// and records a path change in its link stats if the path differs. pathLen
+// is the encoded wire byte. It returns the stored contact.
+func UpdatePath(store ContactStore, id core.MeshCoreID, pathLen uint8, path []byte, nowTimestamp uint32) (*ContactInfo, error) {
+	found := store.GetByPubKey(id)
 	if found == nil {
-		return nil, 0, nil, ErrContactNotFound
+		return nil, ErrContactNotFound
 	}
 
-	// Update the direct routing path directly on the stored reference.
-	// PathLen is the encoded wire byte (mode bits | hop count).
-	found.OutPathLen = pathContent.PathLen
-	if len(pathContent.Path) > 0 {
-		found.OutPath = make([]byte, len(pathContent.Path))
-		copy(found.OutPath, pathContent.Path)
-	} else {
-		found.OutPath = nil
+	if found.OutPathLen != pathLen || !bytes.Equal(found.OutPath, path) {
+		_ = UpdateStats(store, id, (*LinkStats).RecordPathChange)
 	}
-	found.LastMod = nowTimestamp
 
-	return found, pathContent.ExtraType, pathContent.Extra, nil
+	updated := &ContactInfo{
+		ID:                  found.ID,
+		Name:                found.Name,
+		Type:                found.Type,
+		Flags:               found.Flags,
+		OutPathLen:          pathLen,
+		OutPath:             path,
+		LastAdvertTimestamp: found.LastAdvertTimestamp,
+		LastMod:             nowTimestamp,
+		GPSLat:              found.GPSLat,
+		GPSLon:              found.GPSLon,
+		SyncSince:           found.SyncSince,
+	}
+	if err := store.UpdateContact(updated); err != nil {
+		return nil, err
+	}
+	return store.GetByPubKey(id), nil
+}
+
+// ResolvePrefix returns the one contact whose public key starts with prefix,
+// or nil if none or several do.
+func ResolvePrefix(store ContactStore, prefix []byte) *ContactInfo {
+	matches := store.SearchByPrefix(prefix)
+	if len(matches) != 1 {
+		return nil
+	}
+	return matches[0]
+}
+
+// PathHop is one hop of a routing path resolved against a contact store.
+type PathHop struct {
+	// Hash is the hop's path hash: the first 1-3 bytes of the relaying
+	// node's public key.
+	Hash []byte
+
+	// Contact is the node the hash names, or nil if no contact or more than
+	// one contact matches.
+	Contact *ContactInfo
+
+	// Matches is how many contacts match Hash, capped at MaxSearchResults.
+	// More than one means the hop is ambiguous.
+	Matches int
+}
+
+// ResolvePath maps the hashes of a routing path (e.g. ContactInfo.OutPath, or
+// a received packet's Path) to contacts. pathLen is the encoded path_len wire
+// byte, which gives the hash size and hop count; a path shorter than it
+// claims is resolved as far as it goes. Wider hashes make hops ambiguous far
+// less often: one byte names 1 of 256 nodes, two bytes 1 of 65536.
+func ResolvePath(store ContactStore, pathLen uint8, path []byte) []PathHop {
+	info := codec.PathInfoFromWireByte(pathLen)
+	size := int(info.HashSize)
+	hops := make([]PathHop, 0, info.HopCount)
+	for i := 0; i < int(info.HopCount) && (i+1)*size <= len(path); i++ {
+		hash := path[i*size : (i+1)*size]
+		matches := store.SearchByPrefix(hash)
+		hop := PathHop{Hash: hash, Matches: len(matches)}
+		if len(matches) == 1 {
+			hop.Contact = matches[0]
+		}
+		hops = append(hops, hop)
+	}
+	return hops
 }
 
 // populateContactFromAdvert creates a ContactInfo from an ADVERT payload.
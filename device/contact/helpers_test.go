@@ -514,3 +514,38 @@ func TestProcessPath_UnknownSender(t *testing.T) {
 		t.Errorf("expected ErrContactNotFound, got %v", err)
 	}
 }
+
+func TestResolvePath(t *testing.T) {
+	kp := generateTestKeyPair(t)
+	m := NewManager(kp.PrivateKey, ManagerConfig{})
+	r1 := core.MeshCoreID{0x12, 0x34, 0x01}
+	r2 := core.MeshCoreID{0x12, 0x35, 0x01} // shares r1's first byte
+	r3 := core.MeshCoreID{0x56, 0x78, 0x01}
+	m.AddContact(makeContactWithID(r1, "Hilltop", 1))
+	m.AddContact(makeContactWithID(r2, "Valley", 2))
+	m.AddContact(makeContactWithID(r3, "Tower", 3))
+
+	// With 1-byte hashes, the first hop is ambiguous.
+	hops := ResolvePath(m, codec.PathInfo{HashSize: 1, HopCount: 2}.ToWireByte(), []byte{0x12, 0x56})
+	if len(hops) != 2 || hops[0].Contact != nil || hops[0].Matches != 2 || hops[1].Contact == nil || hops[1].Contact.Name != "Tower" {
+		t.Errorf("1-byte hops = %+v", hops)
+	}
+
+	// 2-byte hashes tell the repeaters apart; an unknown hop resolves to nil.
+	path := []byte{0x12, 0x35, 0x12, 0x34, 0x99, 0x99}
+	hops = ResolvePath(m, codec.PathInfo{HashSize: 2, HopCount: 3}.ToWireByte(), path)
+	if len(hops) != 3 {
+		t.Fatalf("got %d hops, want 3", len(hops))
+	}
+	if hops[0].Contact == nil || hops[0].Contact.Name != "Valley" || hops[1].Contact == nil || hops[1].Contact.Name != "Hilltop" {
+		t.Errorf("2-byte hops = %+v", hops)
+	}
+	if hops[2].Contact != nil || hops[2].Matches != 0 {
+		t.Errorf("unknown hop = %+v", hops[2])
+	}
+
+	// A path shorter than its length byte claims stops early.
+	if hops := ResolvePath(m, codec.PathInfo{HashSize: 2, HopCount: 3}.ToWireByte(), path[:3]); len(hops) != 1 {
+		t.Errorf("truncated path resolved %d hops, want 1", len(hops))
+	}
+}
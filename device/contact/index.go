@@ -0,0 +1,188 @@
+package contact
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/kabili207/meshcore-go/core"
+)
+
+// contactSlot is one entry of a ContactManager's contact list.
+type contactSlot struct {
+	c *ContactInfo
+
+	// pos is the slot's index in ContactManager.slots.
+	pos int
+
+	// seq orders slots as their positions do, but never changes: an appended
+	// slot takes the next number and a recycled slot inherits the number of the
+	// contact it replaced. Removal compacts positions without reordering, so
+	// seq can break LastMod ties in the eviction heaps and order hash buckets.
+	seq uint64
+
+	// gone is set when the contact is removed or evicted; index entries still
+	// pointing at the slot are stale.
+	gone bool
+
+	// pinned is set while the contact's shared secret is pinned in the
+	// manager's SecretCache as a favourite. It is tracked here, not read back
+	// from Flags, because callers may change Flags through the stored pointer
+	// before calling UpdateContact.
+	pinned bool
+}
+
+// contactIndex finds contacts by public key and hash byte, and the oldest
+// contact of each eviction pool, without scanning the contact list.
+type contactIndex struct {
+	byKey   map[core.MeshCoreID][]*contactSlot // usually one slot; in seq order
+	buckets [256][]*contactSlot                // by ID.Hash(), in seq order
+
+	// lru holds, per pool (regular, transient), a min-heap of slots by
+	// LastMod. It is maintained lazily because callers may change LastMod,
+	// Type, or Flags through the pointers the manager hands out: an entry is
+	// checked against the contact when it reaches the top, and re-filed if it
+	// no longer matches. This is exact as long as LastMod only moves forward,
+	// which holds for a clock-driven modification time.
+	lru [2]lruHeap
+}
+
+func newContactIndex() *contactIndex {
+	return &contactIndex{byKey: make(map[core.MeshCoreID][]*contactSlot)}
+}
+
+// poolOf returns the eviction pool of c: 1 for transient, 0 for regular.
+func poolOf(c *ContactInfo) int {
+	if c.IsTransient() {
+		return 1
+	}
+	return 0
+}
+
+// add indexes a new slot.
+func (x *contactIndex) add(s *contactSlot) {
+	x.byKey[s.c.ID] = insertBySeq(x.byKey[s.c.ID], s)
+	h := s.c.ID.Hash()
+	x.buckets[h] = insertBySeq(x.buckets[h], s)
+	x.touch(s)
+}
+
+// remove drops a slot from the key and hash indexes and marks it gone, which
+// retires its heap entries.
+func (x *contactIndex) remove(s *contactSlot) {
+	s.gone = true
+	if list := removeSlot(x.byKey[s.c.ID], s); len(list) > 0 {
+		x.byKey[s.c.ID] = list
+	} else {
+		delete(x.byKey, s.c.ID)
+	}
+	h := s.c.ID.Hash()
+	x.buckets[h] = removeSlot(x.buckets[h], s)
+}
+
+// get returns the first slot holding id, or nil.
+func (x *contactIndex) get(id core.MeshCoreID) *contactSlot {
+	if list := x.byKey[id]; len(list) > 0 {
+		return list[0]
+	}
+	return nil
+}
+
+// touch files a heap entry for the slot's current LastMod and pool. Call it
+// after the manager changes either.
+func (x *contactIndex) touch(s *contactSlot) {
+	heap.Push(&x.lru[poolOf(s.c)], lruEntry{slot: s, mod: s.c.LastMod})
+}
+
+// oldest returns the evictable slot with the lowest LastMod (ties to the
+// lowest position) in the regular or transient pool, or nil. Favorites are
+// never chosen from the regular pool.
+func (x *contactIndex) oldest(transient bool) *contactSlot {
+	pool := 0
+	if transient {
+		pool = 1
+	}
+	h := &x.lru[pool]
+	var favorites []lruEntry
+	defer func() {
+		for _, e := range favorites {
+			heap.Push(h, e)
+		}
+	}()
+	for h.Len() > 0 {
+		e := heap.Pop(h).(lruEntry)
+		s := e.slot
+		switch {
+		case s.gone:
+			continue
+		case poolOf(s.c) != pool || s.c.LastMod != e.mod:
+			x.touch(s) // re-file under its current pool and time
+			continue
+		case pool == 0 && s.c.IsFavorite():
+			favorites = append(favorites, e)
+			continue
+		}
+		return s
+	}
+	return nil
+}
+
+// compact rebuilds the heaps from the live slots once stale entries
+// outnumber them, bounding heap growth from repeated touches.
+func (x *contactIndex) compact(slots []*contactSlot) {
+	if x.lru[0].Len()+x.lru[1].Len() <= 2*len(slots)+16 {
+		return
+	}
+	x.lru[0], x.lru[1] = x.lru[0][:0], x.lru[1][:0]
+	for _, s := range slots {
+		x.lru[poolOf(s.c)] = append(x.lru[poolOf(s.c)], lruEntry{slot: s, mod: s.c.LastMod})
+	}
+	heap.Init(&x.lru[0])
+	heap.Init(&x.lru[1])
+}
+
+// insertBySeq inserts s into list, which is sorted by seq.
+func insertBySeq(list []*contactSlot, s *contactSlot) []*contactSlot {
+	i := sort.Search(len(list), func(i int) bool { return list[i].seq > s.seq })
+	list = append(list, nil)
+	copy(list[i+1:], list[i:])
+	list[i] = s
+	return list
+}
+
+// removeSlot removes s from list, preserving order.
+func removeSlot(list []*contactSlot, s *contactSlot) []*contactSlot {
+	for i, e := range list {
+		if e == s {
+			copy(list[i:], list[i+1:])
+			list[len(list)-1] = nil
+			return list[:len(list)-1]
+		}
+	}
+	return list
+}
+
+// lruEntry is a slot filed in an eviction heap under the LastMod it had then.
+type lruEntry struct {
+	slot *contactSlot
+	mod  uint32
+}
+
+// lruHeap is a container/heap of lruEntry, oldest first.
+type lruHeap []lruEntry
+
+func (h lruHeap) Len() int { return len(h) }
+func (h lruHeap) Less(i, j int) bool {
+	if h[i].mod != h[j].mod {
+		return h[i].mod < h[j].mod
+	}
+	return h[i].slot.seq < h[j].slot.seq
+}
+func (h lruHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *lruHeap) Push(x any)   { *h = append(*h, x.(lruEntry)) }
+func (h *lruHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = lruEntry{}
+	*h = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,170 @@
+package contact
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+)
+
+// randomID returns a distinct key for n, spread over all hash bytes.
+func randomID(n uint64) core.MeshCoreID {
+	var id core.MeshCoreID
+	binary.LittleEndian.PutUint64(id[:], n*0x9E3779B97F4A7C15)
+	binary.LittleEndian.PutUint64(id[8:], n)
+	return id
+}
+
+// scanOldest is the reference eviction choice: a linear scan in list order
+// for the lowest LastMod in the pool, skipping favorites for regular adds.
+func scanOldest(m *ContactManager, transient bool) (core.MeshCoreID, bool) {
+	var best *ContactInfo
+	m.ForEach(func(c *ContactInfo) bool {
+		if c.IsTransient() != transient || (!transient && c.IsFavorite()) {
+			return true
+		}
+		if best == nil || c.LastMod < best.LastMod {
+			best = c
+		}
+		return true
+	})
+	if best == nil {
+		return core.MeshCoreID{}, false
+	}
+	return best.ID, true
+}
+
+// scanHash is the reference SearchByHash: a linear scan in list order.
+func scanHash(m *ContactManager, hash uint8) []core.MeshCoreID {
+	var ids []core.MeshCoreID
+	m.ForEach(func(c *ContactInfo) bool {
+		if c.ID.Hash() == hash && len(ids) < MaxSearchResults {
+			ids = append(ids, c.ID)
+		}
+		return true
+	})
+	return ids
+}
+
+// The indexes must choose exactly what the firmware's linear scans would,
+// including after removals, updates, favorites, and LastMod changes made
+// through returned pointers.
+func TestManager_IndexMatchesLinearScan(t *testing.T) {
+	kp := generateTestKeyPair(t)
+	m := NewManager(kp.PrivateKey, ManagerConfig{MaxContacts: 64, OverwriteWhenFull: true})
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	var evicted core.MeshCoreID
+	m.SetOnContactOverwrite(func(id core.MeshCoreID) { evicted = id })
+
+	var live []core.MeshCoreID
+	clock := uint32(1000)
+	for n := uint64(0); n < 5000; n++ {
+		clock++
+		switch op := rng.IntN(10); {
+		case op < 5 || len(live) == 0: // add
+			c := makeContactWithID(randomID(n), "c", clock-uint32(rng.IntN(500)))
+			if rng.IntN(6) == 0 {
+				c.Type = codec.NodeTypeNone
+			}
+			want, wantEvict := scanOldest(m, c.IsTransient())
+			full := m.Count() >= 64
+			evicted = core.MeshCoreID{}
+			if _, err := m.AddContact(c); err != nil {
+				if !full || wantEvict {
+					t.Fatalf("op %d: add failed: %v", n, err)
+				}
+				continue
+			}
+			if full && evicted != want {
+				t.Fatalf("op %d: evicted %s, want %s", n, evicted, want)
+			}
+		case op < 6: // remove
+			m.RemoveContact(live[rng.IntN(len(live))])
+		case op < 7: // favorite toggle through the pointer
+			if c := m.GetByPubKey(live[rng.IntN(len(live))]); c != nil {
+				c.SetFavorite(!c.IsFavorite())
+			}
+		case op < 9: // touch through the pointer, as path updates do
+			if c := m.GetByPubKey(live[rng.IntN(len(live))]); c != nil {
+				c.LastMod = clock
+			}
+		default: // update, possibly moving pools
+			if c := m.GetByPubKey(live[rng.IntN(len(live))]); c != nil {
+				upd := makeContactWithID(c.ID, c.Name, clock)
+				upd.Type, upd.Flags = c.Type, c.Flags
+				if rng.IntN(4) == 0 {
+					upd.Type = codec.NodeTypeNone
+				}
+				m.UpdateContact(upd)
+			}
+		}
+
+		live = live[:0]
+		m.ForEach(func(c *ContactInfo) bool {
+			live = append(live, c.ID)
+			return true
+		})
+		h := uint8(rng.IntN(256))
+		got := m.SearchByHash(h)
+		want := scanHash(m, h)
+		if len(got) != len(want) {
+			t.Fatalf("op %d: SearchByHash(%d) = %d results, want %d", n, h, len(got), len(want))
+		}
+		for i := range got {
+			if got[i].ID != want[i] {
+				t.Fatalf("op %d: SearchByHash order differs", n)
+			}
+		}
+	}
+}
+
+func newBenchManager(b *testing.B, n int) (*ContactManager, []core.MeshCoreID) {
+	b.Helper()
+	kp, err := crypto.GenerateKeyPair()
+	if err != nil {
+		b.Fatal(err)
+	}
+	m := NewManager(kp.PrivateKey, ManagerConfig{MaxContacts: n, OverwriteWhenFull: true})
+	ids := make([]core.MeshCoreID, n)
+	for i := range ids {
+		ids[i] = randomID(uint64(i))
+		m.AddContact(makeContactWithID(ids[i], "c", uint32(i)))
+	}
+	return m, ids
+}
+
+func BenchmarkManager_GetByPubKey10k(b *testing.B) {
+	m, ids := newBenchManager(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.GetByPubKey(ids[i%len(ids)])
+	}
+}
+
+func BenchmarkManager_SearchByHash10k(b *testing.B) {
+	m, _ := newBenchManager(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.SearchByHash(uint8(i))
+	}
+}
+
+func BenchmarkManager_AddEvict10k(b *testing.B) {
+	m, _ := newBenchManager(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.AddContact(makeContactWithID(randomID(uint64(10000+i)), "c", uint32(10000+i)))
+	}
+}
+
+func BenchmarkManager_Touch10k(b *testing.B) {
+	m, ids := newBenchManager(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.UpdateContact(makeContactWithID(ids[i%len(ids)], "c", uint32(10000+i)))
+	}
+}
@@ -0,0 +1,345 @@
+package contact
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+)
+
+// DefaultJournalSize is the default number of changes a Journal retains.
+const DefaultJournalSize = 256
+
+// journalSeqReserve is how many sequence numbers a file-backed Journal
+// reserves on disk at a time, ahead of handing them out.
+const journalSeqReserve = 64
+
+// ErrJournalGap is returned when the changes after a sequence number are no
+// longer all retained (or the sequence number is newer than the journal, as
+// after a reset). The caller must resynchronize from a full contact listing
+// and continue from Journal.Seq.
+var ErrJournalGap = errors.New("contact journal: changes no longer retained")
+
+// ChangeOp is the kind of change a journal entry records.
+type ChangeOp uint8
+
+const (
+	// ChangeAdded: the contact was added.
+	ChangeAdded ChangeOp = iota + 1
+
+	// ChangeUpdated: the contact's fields changed.
+	ChangeUpdated
+
+	// ChangeRemoved: the contact was removed or evicted.
+	ChangeRemoved
+)
+
+// String returns "added", "updated", or "removed".
+func (o ChangeOp) String() string {
+	switch o {
+	case ChangeAdded:
+		return "added"
+	case ChangeUpdated:
+		return "updated"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is one journal entry. It names the contact but does not snapshot it;
+// read the current state from the store (a contact added and since removed is
+// simply gone).
+type Change struct {
+	Seq uint64
+	Op  ChangeOp
+	ID  core.MeshCoreID
+}
+
+// persistedJournal is the on-disk JSON form of a Journal.
+type persistedJournal struct {
+	Seq      uint64            `json:"seq"`
+	Reserved uint64            `json:"reserved,omitempty"` // high-water mark; above Seq after an unclean stop
+	Changes  []persistedChange `json:"changes"`
+}
+
+type persistedChange struct {
+	Seq uint64 `json:"seq"`
+	Op  uint8  `json:"op"`
+	ID  string `json:"id"` // hex-encoded 32-byte public key
+}
+
+// Journal is a bounded, sequence-numbered log of contact changes, kept by a
+// ContactManager so that clients can sync incrementally. Unlike filtering on
+// LastMod, it records removals, so a client that was offline learns which
+// contacts are gone. Sequence numbers start at 1 and never repeat within a
+// journal; a client remembers the last one it applied and asks for the changes
+// after it with Since, or follows them with Watch.
+//
+// It is safe for concurrent use. A Journal opened with OpenJournal is persisted
+// to a JSON file, normally next to the contact file; writes are debounced like
+// FileContactStore's, so call Close on shutdown. So that a crash before a
+// debounced write cannot reuse sequence numbers, the file also records a
+// high-water mark, written before any number above it is handed out; after
+// an unclean stop the journal resumes above the mark, and clients
+// resynchronize on ErrJournalGap.
+type Journal struct {
+	path     string
+	debounce time.Duration
+	max      int
+
+	mu       sync.Mutex // also serializes file writes
+	seq      uint64     // last assigned sequence number
+	reserved uint64     // highest sequence number recorded on disk as usable
+	changes  []Change   // oldest first, at most max
+	notify   chan struct{}
+	timer    *time.Timer
+	closed   bool
+}
+
+// NewJournal creates an in-memory journal retaining the last max changes
+// (DefaultJournalSize if max <= 0).
+func NewJournal(max int) *Journal {
+	if max <= 0 {
+		max = DefaultJournalSize
+	}
+	return &Journal{
+		debounce: DefaultFlushDebounce,
+		max:      max,
+		notify:   make(chan struct{}),
+	}
+}
+
+// OpenJournal loads a journal from path, which need not exist yet. Changes
+// are written back to it.
+func OpenJournal(path string, max int) (*Journal, error) {
+	j := NewJournal(max)
+	j.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return j, nil
+		}
+		return nil, err
+	}
+	var rec persistedJournal
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, err
+		}
+	}
+	j.seq = rec.Seq
+	if rec.Reserved > rec.Seq {
+		// Not closed cleanly: numbers up to the mark may have been handed
+		// out with changes that were never written. Resume above them, with
+		// nothing retained, so every client resynchronizes.
+		j.seq = rec.Reserved
+		j.reserved = j.seq
+		return j, nil
+	}
+	j.reserved = j.seq
+	for _, r := range rec.Changes {
+		id, err := core.ParseMeshCoreID(r.ID)
+		if err != nil || r.Seq > rec.Seq {
+			continue // skip malformed entry
+		}
+		j.changes = append(j.changes, Change{Seq: r.Seq, Op: ChangeOp(r.Op), ID: id})
+	}
+	if len(j.changes) > j.max {
+		j.changes = j.changes[len(j.changes)-j.max:]
+	}
+	return j, nil
+}
+
+// Record appends a change, wakes watchers, and returns the entry. A
+// file-backed journal never hands out a number above the high-water mark on
+// disk: if raising the mark fails, the change is not recorded and the write
+// error is returned.
+func (j *Journal) Record(op ChangeOp, id core.MeshCoreID) (Change, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.path != "" && j.seq >= j.reserved {
+		prev := j.reserved
+		j.reserved = j.seq + journalSeqReserve
+		if err := j.writeLocked(); err != nil {
+			j.reserved = prev
+			return Change{}, err
+		}
+	}
+	j.seq++
+	c := Change{Seq: j.seq, Op: op, ID: id}
+	if len(j.changes) == j.max {
+		copy(j.changes, j.changes[1:])
+		j.changes[len(j.changes)-1] = c
+	} else {
+		j.changes = append(j.changes, c)
+	}
+	close(j.notify)
+	j.notify = make(chan struct{})
+	j.scheduleLocked()
+	return c, nil
+}
+
+// Seq returns the sequence number of the latest change, or 0 if none has
+// been recorded. A client that has just listed every contact continues from
+// here.
+func (j *Journal) Seq() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.seq
+}
+
+// Since returns the changes after seq, oldest first: nil if there are none,
+// or ErrJournalGap if some are no longer retained.
+func (j *Journal) Since(seq uint64) ([]Change, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.sinceLocked(seq)
+}
+
+func (j *Journal) sinceLocked(seq uint64) ([]Change, error) {
+	if seq > j.seq {
+		return nil, ErrJournalGap
+	}
+	if seq == j.seq {
+		return nil, nil
+	}
+	first := j.seq + 1 - uint64(len(j.changes)) // seq of changes[0]
+	if seq+1 < first {
+		return nil, ErrJournalGap
+	}
+	out := make([]Change, len(j.changes)-int(seq+1-first))
+	copy(out, j.changes[seq+1-first:])
+	return out, nil
+}
+
+// Watcher follows a Journal from a sequence number. Each watcher keeps its
+// own cursor, so every subscriber sees every change exactly once, however
+// slowly it reads, until it falls more than the journal's size behind.
+type Watcher struct {
+	j      *Journal
+	cursor uint64
+}
+
+// Watch returns a watcher delivering the changes after since. Pass Seq() to
+// follow only new changes.
+func (j *Journal) Watch(since uint64) *Watcher {
+	return &Watcher{j: j, cursor: since}
+}
+
+// Seq returns the sequence number of the last change Next returned (or the
+// starting point, before the first call).
+func (w *Watcher) Seq() uint64 { return w.cursor }
+
+// Next blocks until there are changes after the watcher's cursor, then returns
+// all of them and advances past them. It returns ctx.Err() if ctx is done
+// first, and ErrJournalGap if the watcher fell too far behind; then start a
+// new watcher after resynchronizing.
+func (w *Watcher) Next(ctx context.Context) ([]Change, error) {
+	for {
+		w.j.mu.Lock()
+		changes, err := w.j.sinceLocked(w.cursor)
+		notify := w.j.notify
+		w.j.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			w.cursor = changes[len(changes)-1].Seq
+			return changes, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+// Flush writes the journal to its file immediately, if it has one.
+func (j *Journal) Flush() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.timer != nil {
+		j.timer.Stop()
+		j.timer = nil
+	}
+	if j.path == "" {
+		return nil
+	}
+	return j.writeLocked()
+}
+
+// Close flushes pending changes and stops further debounced writes. The
+// unused reservation is released, so the next OpenJournal continues from Seq.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	j.closed = true
+	j.reserved = j.seq
+	j.mu.Unlock()
+	return j.Flush()
+}
+
+// writeLocked writes the journal to its file atomically, via a temp file in
+// the same directory, synced before the rename. Must be called with j.mu held, which keeps
+// an older snapshot from replacing a newer one.
+func (j *Journal) writeLocked() error {
+	rec := persistedJournal{Seq: j.seq, Reserved: j.reserved, Changes: make([]persistedChange, len(j.changes))}
+	for i, c := range j.changes {
+		rec.Changes[i] = persistedChange{Seq: c.Seq, Op: uint8(c.Op), ID: hex.EncodeToString(c.ID[:])}
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err = f.Chmod(0o644); err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, j.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// scheduleLocked arms the debounce timer for a file-backed journal if one is
+// not already pending. Must be called with j.mu held.
+func (j *Journal) scheduleLocked() {
+	if j.path == "" || j.closed || j.timer != nil {
+		return
+	}
+	j.timer = time.AfterFunc(j.debounce, func() {
+		j.mu.Lock()
+		j.timer = nil
+		j.mu.Unlock()
+		_ = j.Flush()
+	})
+}
+
+// Journaled is implemented by contact stores that keep a change journal, as
+// ContactManager does.
+type Journaled interface {
+	Journal() *Journal
+}
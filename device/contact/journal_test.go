@@ -0,0 +1,255 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+func TestJournal_Since(t *testing.T) {
+	j := NewJournal(3)
+	if got, err := j.Since(0); got != nil || err != nil {
+		t.Fatalf("empty Since(0) = %v, %v", got, err)
+	}
+	for i := byte(1); i <= 5; i++ {
+		j.Record(ChangeAdded, makeIDWithHash(i))
+	}
+	if j.Seq() != 5 {
+		t.Fatalf("Seq = %d, want 5", j.Seq())
+	}
+
+	got, err := j.Since(2)
+	if err != nil || len(got) != 3 || got[0].Seq != 3 || got[2].Seq != 5 || got[2].ID != makeIDWithHash(5) {
+		t.Fatalf("Since(2) = %+v, %v", got, err)
+	}
+	if got, err := j.Since(5); got != nil || err != nil {
+		t.Errorf("Since(5) = %v, %v", got, err)
+	}
+	if _, err := j.Since(1); !errors.Is(err, ErrJournalGap) {
+		t.Errorf("Since(1) err = %v, want gap", err)
+	}
+	if _, err := j.Since(6); !errors.Is(err, ErrJournalGap) {
+		t.Errorf("Since(6) err = %v, want gap", err)
+	}
+}
+
+func TestJournal_Watch(t *testing.T) {
+	j := NewJournal(8)
+	j.Record(ChangeAdded, makeIDWithHash(1))
+	w := j.Watch(j.Seq())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		j.Record(ChangeRemoved, makeIDWithHash(1))
+	}()
+	got, err := w.Next(ctx)
+	if err != nil || len(got) != 1 || got[0].Op != ChangeRemoved || w.Seq() != 2 {
+		t.Fatalf("Next = %+v, %v (cursor %d)", got, err, w.Seq())
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	if _, err := w.Next(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("idle Next err = %v, want deadline", err)
+	}
+}
+
+func TestJournal_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	j, err := OpenJournal(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := byte(1); i <= 3; i++ {
+		j.Record(ChangeUpdated, makeIDWithHash(i))
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenJournal(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Since(1)
+	if err != nil || len(got) != 2 || got[0].Seq != 2 || got[1].ID != makeIDWithHash(3) || got[1].Op != ChangeUpdated {
+		t.Fatalf("Since(1) after reopen = %+v, %v", got, err)
+	}
+	if c, err := reopened.Record(ChangeAdded, makeIDWithHash(4)); err != nil || c.Seq != 4 {
+		t.Errorf("next seq = %d, %v, want 4", c.Seq, err)
+	}
+}
+
+func TestJournal_CrashDoesNotReuseSeq(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "journal.json")
+	j, err := OpenJournal(path, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last Change
+	for i := byte(1); i <= 3; i++ {
+		if last, err = j.Record(ChangeAdded, makeIDWithHash(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reopen without Close or a debounced flush, as after a crash.
+	reopened, err := OpenJournal(path, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Seq() < last.Seq {
+		t.Fatalf("seq after crash = %d, below handed-out %d", reopened.Seq(), last.Seq)
+	}
+	if _, err := reopened.Since(last.Seq); !errors.Is(err, ErrJournalGap) {
+		t.Errorf("Since(%d) err = %v, want gap", last.Seq, err)
+	}
+	if c, err := reopened.Record(ChangeRemoved, makeIDWithHash(1)); err != nil || c.Seq <= last.Seq {
+		t.Errorf("new seq = %d, %v, reuses %d", c.Seq, err, last.Seq)
+	}
+	j.Close()
+	reopened.Close()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("files left behind: %v", entries)
+	}
+}
+
+func TestJournal_ReserveFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	j, err := OpenJournal(filepath.Join(dir, "journal.json"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The high-water mark cannot be written, so no number is handed out.
+	if _, err := j.Record(ChangeAdded, makeIDWithHash(1)); err == nil {
+		t.Fatal("Record succeeded without persisting the reservation")
+	}
+	if j.Seq() != 0 {
+		t.Errorf("seq = %d after failed reservation, want 0", j.Seq())
+	}
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := j.Record(ChangeAdded, makeIDWithHash(1)); err != nil || c.Seq != 1 {
+		t.Errorf("Record after recovery = %+v, %v", c, err)
+	}
+	j.Close()
+}
+
+func TestJournal_ConcurrentFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	j, err := OpenJournal(path, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for k := 0; k < 20; k++ {
+				j.Record(ChangeUpdated, makeIDWithHash(byte(i)))
+				if err := j.Flush(); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenJournal(path, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Seq() != 80 {
+		t.Errorf("seq after reopen = %d, want 80", reopened.Seq())
+	}
+}
+
+func TestManager_Journal(t *testing.T) {
+	m := newTestManager(t, 2, true)
+	a, b, c := makeIDWithHash(0x01), makeIDWithHash(0x02), makeIDWithHash(0x03)
+	m.AddContact(makeContactWithID(a, "A", 100))
+	m.AddContact(makeContactWithID(b, "B", 200))
+	m.UpdateContact(makeContactWithID(b, "B2", 300))
+	m.UpdateStats(b, func(s *LinkStats) { s.Sent++ })
+	m.AddContact(makeContactWithID(c, "C", 400)) // evicts A
+	m.RemoveContact(b)
+
+	got, err := m.Journal().Since(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		op ChangeOp
+		id core.MeshCoreID
+	}{
+		{ChangeAdded, a}, {ChangeAdded, b}, {ChangeUpdated, b},
+		{ChangeRemoved, a}, {ChangeAdded, c}, {ChangeRemoved, b},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Op != w.op || got[i].ID != w.id || got[i].Seq != uint64(i+1) {
+			t.Errorf("change %d = %+v, want %v %x", i, got[i], w.op, w.id[:1])
+		}
+	}
+}
+
+// TestProcessPath_Journaled checks that a path learned from a PATH packet is
+// recorded like any other update, so GET_CHANGES reports it.
+func TestProcessPath_Journaled(t *testing.T) {
+	m := newTestManager(t, 2, true)
+	id := makeIDWithHash(0x01)
+	m.AddContact(makeContactWithID(id, "A", 100))
+	seq := m.Journal().Seq()
+
+	if _, _, _, err := ProcessPath(m, id, &codec.PathContent{PathLen: 1, Path: []byte{0xAA}}, 200); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Journal().Since(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Op != ChangeUpdated || got[0].ID != id {
+		t.Errorf("changes after ProcessPath = %+v, want one update", got)
+	}
+	if c := m.GetByPubKey(id); c.LastMod != 200 || c.Stats().PathChanges != 1 {
+		t.Errorf("LastMod = %d, stats = %+v", c.LastMod, c.Stats())
+	}
+}
+
+func TestManager_JournalSkipsSeeding(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contacts.json")
+	fs := NewFileContactStore(path)
+	fs.Save(makeContactWithID(makeIDWithHash(0x01), "A", 100))
+	if err := fs.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	kp := generateTestKeyPair(t)
+	m := NewManager(kp.PrivateKey, ManagerConfig{Persistence: NewFileContactStore(path)})
+	if m.Count() != 1 || m.Journal().Seq() != 0 {
+		t.Errorf("count = %d, seq = %d; want loaded contact without a journal entry", m.Count(), m.Journal().Seq())
+	}
+}
@@ -31,8 +31,14 @@ var (
 	ErrContactNotFound = errors.New("contact not found")
 )
 
-// Compile-time assertion that ContactManager implements ContactStore.
-var _ ContactStore = (*ContactManager)(nil)
+// Compile-time assertions that ContactManager implements ContactStore,
+// StatsStore, Journaled, and SecretSource.
+var (
+	_ ContactStore = (*ContactManager)(nil)
+	_ StatsStore   = (*ContactManager)(nil)
+	_ Journaled    = (*ContactManager)(nil)
+	_ SecretSource = (*ContactManager)(nil)
+)
 
 // ManagerConfig configures a ContactManager.
 type ManagerConfig struct {
@@ -56,20 +62,40 @@ type ManagerConfig struct {
 	// Persistence.Load at construction, and add/update/remove mutations are
 	// mirrored to Persistence.Save/Delete. Nil keeps the manager in-memory only.
 	Persistence ContactPersistence
+
+	// Journal records every add, update, removal, and eviction with a sequence
+	// number, for incremental sync (see Journal). Stats-only updates are not
+	// recorded. Default: an in-memory journal of DefaultJournalSize changes;
+	// use OpenJournal to keep it across restarts alongside Persistence.
+	Journal *Journal
+
+	// Secrets caches the ECDH shared secrets GetSharedSecret returns.
+	// Favourites' secrets are precomputed when they are added or marked, so
+	// the first packet from one does not pay for the ECDH. Default: an
+	// LRUSecretCache of DefaultSecretCacheSize.
+	Secrets SecretCache
 }
 
 // ContactManager is a thread-safe store for known mesh peers.
 // It provides add, remove, and search operations with firmware-compatible
 // eviction semantics.
 //
+// Contacts are indexed by public key and by hash byte, and each eviction pool
+// keeps a heap ordered by LastMod, so lookups and evictions do not scan the
+// list and the manager scales to thousands of contacts.
+//
 // This is a standalone data structure with no dependency on the router.
 type ContactManager struct {
 	cfg         ManagerConfig
 	log         *slog.Logger
 	mu          sync.RWMutex
-	contacts    []*ContactInfo
+	slots       []*contactSlot // in firmware list order
+	idx         *contactIndex
+	nextSeq     uint64
 	localKey    ed25519.PrivateKey
 	persistence ContactPersistence
+	journal     *Journal // nil while seeding from persistence
+	secrets     SecretCache
 
 	onContactAdded     func(contact *ContactInfo, isNew bool)
 	onContactRemoved   func(id core.MeshCoreID)
@@ -90,16 +116,23 @@ func NewManager(localPrivKey ed25519.PrivateKey, cfg ManagerConfig) *ContactMana
 	if logger == nil {
 		logger = slog.Default()
 	}
+	secrets := cfg.Secrets
+	if secrets == nil {
+		secrets = NewSecretCache(DefaultSecretCacheSize)
+	}
 	m := &ContactManager{
 		cfg:      cfg,
 		log:      logger.WithGroup("contacts"),
-		contacts: make([]*ContactInfo, 0, cfg.MaxContacts+cfg.MaxAnonContacts),
+		slots:    make([]*contactSlot, 0, cfg.MaxContacts+cfg.MaxAnonContacts),
+		idx:      newContactIndex(),
 		localKey: localPrivKey,
+		secrets:  secrets,
 	}
 
 	// Seed from the persistence backend, if any. AddContact runs here with
-	// m.persistence still nil (no callbacks are registered yet either), so the
-	// loaded contacts are not re-persisted. Wire the backend afterward.
+	// m.persistence and m.journal still nil (no callbacks are registered yet
+	// either), so the loaded contacts are not re-persisted or journaled. Wire
+	// them afterward.
 	if cfg.Persistence != nil {
 		loaded, err := cfg.Persistence.Load()
 		if err != nil {
@@ -113,6 +146,10 @@ func NewManager(localPrivKey ed25519.PrivateKey, cfg ManagerConfig) *ContactMana
 		}
 		m.persistence = cfg.Persistence
 	}
+	m.journal = cfg.Journal
+	if m.journal == nil {
+		m.journal = NewJournal(DefaultJournalSize)
+	}
 
 	return m
 }
@@ -152,10 +189,11 @@ func (m *ContactManager) AddContact(c *ContactInfo) (*ContactInfo, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	stored := m.allocateSlot(c.IsTransient())
-	if stored == nil {
+	slot := m.allocateSlot(c.IsTransient())
+	if slot == nil {
 		return nil, ErrContactsFull
 	}
+	stored := slot.c
 
 	// Copy fields into the allocated slot
 	stored.ID = c.ID
@@ -174,14 +212,20 @@ func (m *ContactManager) AddContact(c *ContactInfo) (*ContactInfo, error) {
 	stored.GPSLat = c.GPSLat
 	stored.GPSLon = c.GPSLon
 	stored.SyncSince = c.SyncSince
+	stored.SetStats(c.Stats())
 
 	// Always invalidate shared secret on add (firmware behavior)
-	stored.InvalidateSharedSecret()
+	stored.secrets = m.secrets
+	m.secrets.Forget(stored.ID)
+	slot.pinned = false
+	m.precompute(slot)
+	m.idx.add(slot)
 
 	if m.onContactAdded != nil {
 		m.onContactAdded(stored, true)
 	}
 	m.persist(stored)
+	m.record(ChangeAdded, stored.ID)
 
 	return stored, nil
 }
@@ -193,32 +237,40 @@ func (m *ContactManager) UpdateContact(c *ContactInfo) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	for _, existing := range m.contacts {
-		if existing.ID == c.ID {
-			existing.Name = c.Name
-			existing.Type = c.Type
-			existing.Flags = c.Flags
-			existing.OutPathLen = c.OutPathLen
-			if len(c.OutPath) > 0 {
-				existing.OutPath = make([]byte, len(c.OutPath))
-				copy(existing.OutPath, c.OutPath)
-			} else {
-				existing.OutPath = nil
-			}
-			existing.LastAdvertTimestamp = c.LastAdvertTimestamp
-			existing.LastMod = c.LastMod
-			existing.GPSLat = c.GPSLat
-			existing.GPSLon = c.GPSLon
-			existing.SyncSince = c.SyncSince
-
-			if m.onContactAdded != nil {
-				m.onContactAdded(existing, false)
-			}
-			m.persist(existing)
-			return nil
-		}
+	slot := m.idx.get(c.ID)
+	if slot == nil {
+		return ErrContactNotFound
 	}
-	return ErrContactNotFound
+	existing := slot.c
+	existing.Name = c.Name
+	existing.Type = c.Type
+	existing.Flags = c.Flags
+	existing.OutPathLen = c.OutPathLen
+	if len(c.OutPath) > 0 {
+		existing.OutPath = make([]byte, len(c.OutPath))
+		copy(existing.OutPath, c.OutPath)
+	} else {
+		existing.OutPath = nil
+	}
+	existing.LastAdvertTimestamp = c.LastAdvertTimestamp
+	existing.LastMod = c.LastMod
+	existing.GPSLat = c.GPSLat
+	existing.GPSLon = c.GPSLon
+	existing.SyncSince = c.SyncSince
+	if slot.pinned && !existing.IsFavorite() {
+		m.secrets.Forget(existing.ID) // drop the pin; recomputed on demand
+		slot.pinned = false
+	}
+	m.precompute(slot)
+	m.idx.touch(slot)
+	m.idx.compact(m.slots)
+
+	if m.onContactAdded != nil {
+		m.onContactAdded(existing, false)
+	}
+	m.persist(existing)
+	m.record(ChangeUpdated, existing.ID)
+	return nil
 }
 
 // RemoveContact removes the contact matching the given public key.
@@ -227,25 +279,49 @@ func (m *ContactManager) RemoveContact(id core.MeshCoreID) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	for i, c := range m.contacts {
-		if c.ID == id {
-			// Compact: shift remaining elements left
-			copy(m.contacts[i:], m.contacts[i+1:])
-			m.contacts[len(m.contacts)-1] = nil // avoid memory leak
-			m.contacts = m.contacts[:len(m.contacts)-1]
+	slot := m.idx.get(id)
+	if slot == nil {
+		return ErrContactNotFound
+	}
 
-			if m.onContactRemoved != nil {
-				m.onContactRemoved(id)
-			}
-			if m.persistence != nil {
-				if err := m.persistence.Delete(id); err != nil {
-					m.log.Debug("failed to persist contact removal", "error", err)
-				}
-			}
-			return nil
+	// Compact: shift remaining elements left
+	i := slot.pos
+	copy(m.slots[i:], m.slots[i+1:])
+	m.slots[len(m.slots)-1] = nil // avoid memory leak
+	m.slots = m.slots[:len(m.slots)-1]
+	for _, s := range m.slots[i:] {
+		s.pos--
+	}
+	m.idx.remove(slot)
+	m.secrets.Forget(id)
+
+	if m.onContactRemoved != nil {
+		m.onContactRemoved(id)
+	}
+	if m.persistence != nil {
+		if err := m.persistence.Delete(id); err != nil {
+			m.log.Debug("failed to persist contact removal", "error", err)
 		}
 	}
-	return ErrContactNotFound
+	m.record(ChangeRemoved, id)
+	return nil
+}
+
+// UpdateStats applies fn to the stats of the contact with the given public key
+// and persists the result. Unlike UpdateContact it does not fire the
+// contact-added callback, so it is cheap enough to call for every packet.
+// Returns ErrContactNotFound if the contact does not exist.
+func (m *ContactManager) UpdateStats(id core.MeshCoreID, fn func(*LinkStats)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	slot := m.idx.get(id)
+	if slot == nil {
+		return ErrContactNotFound
+	}
+	slot.c.UpdateStats(fn)
+	m.persist(slot.c)
+	return nil
 }
 
 // persist mirrors a contact to the persistence backend, if configured. Called
@@ -259,15 +335,27 @@ func (m *ContactManager) persist(c *ContactInfo) {
 	}
 }
 
+// record appends a change to the journal, once it is wired. Called with m.mu
+// held.
+func (m *ContactManager) record(op ChangeOp, id core.MeshCoreID) {
+	if m.journal == nil {
+		return
+	}
+	if _, err := m.journal.Record(op, id); err != nil {
+		m.log.Warn("failed to record contact change", "op", op, "error", err)
+	}
+}
+
+// Journal returns the manager's change journal.
+func (m *ContactManager) Journal() *Journal { return m.journal }
+
 // GetByPubKey returns the contact with the exact public key, or nil if not found.
 func (m *ContactManager) GetByPubKey(id core.MeshCoreID) *ContactInfo {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	for _, c := range m.contacts {
-		if c.ID == id {
-			return c
-		}
+	if slot := m.idx.get(id); slot != nil {
+		return slot.c
 	}
 	return nil
 }
@@ -280,9 +368,28 @@ func (m *ContactManager) SearchByHash(hash uint8) []*ContactInfo {
 	defer m.mu.RUnlock()
 
 	var results []*ContactInfo
-	for _, c := range m.contacts {
-		if c.ID.Hash() == hash {
-			results = append(results, c)
+	for _, s := range m.idx.buckets[hash] {
+		results = append(results, s.c)
+		if len(results) >= MaxSearchResults {
+			break
+		}
+	}
+	return results
+}
+
+// SearchByPrefix returns contacts whose public key starts with prefix, up to
+// MaxSearchResults, in list order. An empty prefix matches nothing.
+func (m *ContactManager) SearchByPrefix(prefix []byte) []*ContactInfo {
+	if len(prefix) == 0 || len(prefix) > len(core.MeshCoreID{}) {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var results []*ContactInfo
+	for _, s := range m.idx.buckets[prefix[0]] {
+		if s.c.ID.IsHashMatch(prefix) {
+			results = append(results, s.c)
 			if len(results) >= MaxSearchResults {
 				break
 			}
@@ -294,18 +401,40 @@ func (m *ContactManager) SearchByHash(hash uint8) []*ContactInfo {
 // GetSharedSecret finds the contact by public key and returns the cached
 // ECDH shared secret, computing it lazily if needed.
 func (m *ContactManager) GetSharedSecret(id core.MeshCoreID) ([]byte, error) {
-	c := m.GetByPubKey(id)
-	if c == nil {
+	if m.GetByPubKey(id) == nil {
 		return nil, ErrContactNotFound
 	}
-	return c.GetSharedSecret(m.localKey)
+	return m.secrets.SharedSecret(m.localKey, id)
+}
+
+// SharedSecretWith returns the shared secret between another local identity
+// and a contact, through the same cache. A node uses it for its previous key
+// during a key rotation.
+func (m *ContactManager) SharedSecretWith(local ed25519.PrivateKey, id core.MeshCoreID) ([]byte, error) {
+	if m.GetByPubKey(id) == nil {
+		return nil, ErrContactNotFound
+	}
+	return m.secrets.SharedSecret(local, id)
+}
+
+// precompute pins a favourite's shared secret in the cache. A manager with
+// no local key has nothing to compute. Called with m.mu held.
+func (m *ContactManager) precompute(slot *contactSlot) {
+	if !slot.c.IsFavorite() || len(m.localKey) == 0 {
+		return
+	}
+	if err := m.secrets.Precompute(m.localKey, slot.c.ID); err != nil {
+		m.log.Debug("failed to precompute shared secret", "error", err)
+		return
+	}
+	slot.pinned = true
 }
 
 // Count returns the number of stored contacts.
 func (m *ContactManager) Count() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return len(m.contacts)
+	return len(m.slots)
 }
 
 // ForEach calls fn for each contact. Return false from fn to stop iteration.
@@ -314,29 +443,32 @@ func (m *ContactManager) ForEach(fn func(c *ContactInfo) bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	for _, c := range m.contacts {
-		if !fn(c) {
+	for _, s := range m.slots {
+		if !fn(s.c) {
 			return
 		}
 	}
 }
 
-// allocateSlot returns a pointer to an available contact slot.
+// allocateSlot returns an available contact slot, holding a fresh
+// ContactInfo. The caller fills it in and indexes it.
 //
 // Transient (ADV_TYPE_NONE) and regular contacts share the same backing array
 // (capacity MaxContacts+MaxAnonContacts) but evict from separate pools: a
 // transientOnly add recycles the oldest transient contact, while a regular add
 // evicts the oldest non-favorite, non-transient contact (only when
-// OverwriteWhenFull is enabled). Returns nil if no slot is available.
+// OverwriteWhenFull is enabled). "Oldest" is the lowest LastMod, ties going to
+// the earliest slot. Returns nil if no slot is available.
 //
 // Must be called with m.mu held for writing.
-func (m *ContactManager) allocateSlot(transientOnly bool) *ContactInfo {
+func (m *ContactManager) allocateSlot(transientOnly bool) *contactSlot {
 	// Case 1: space available. Matches firmware, where num_contacts is gated by
 	// MAX_CONTACTS; the +MaxAnonContacts only sizes the backing array headroom.
-	if len(m.contacts) < m.cfg.MaxContacts {
-		c := &ContactInfo{}
-		m.contacts = append(m.contacts, c)
-		return c
+	if len(m.slots) < m.cfg.MaxContacts {
+		s := &contactSlot{c: &ContactInfo{}, pos: len(m.slots), seq: m.nextSeq}
+		m.nextSeq++
+		m.slots = append(m.slots, s)
+		return s
 	}
 
 	// Case 2: evict. Transient adds always recycle within the anon pool;
@@ -345,33 +477,22 @@ func (m *ContactManager) allocateSlot(transientOnly bool) *ContactInfo {
 		return nil
 	}
 
-	oldestIdx := -1
-	var oldestMod uint32 = 0xFFFFFFFF
-
-	for i, c := range m.contacts {
-		if transientOnly {
-			if !c.IsTransient() {
-				continue
-			}
-		} else if c.IsFavorite() || c.IsTransient() {
-			continue
-		}
-		if c.LastMod < oldestMod {
-			oldestMod = c.LastMod
-			oldestIdx = i
-		}
-	}
-
-	if oldestIdx < 0 {
+	victim := m.idx.oldest(transientOnly)
+	if victim == nil {
 		// No evictable contact in the target pool.
 		return nil
 	}
 
 	if m.onContactOverwrite != nil {
-		m.onContactOverwrite(m.contacts[oldestIdx].ID)
+		m.onContactOverwrite(victim.c.ID)
 	}
-
-	// Reset the slot
-	m.contacts[oldestIdx] = &ContactInfo{}
-	return m.contacts[oldestIdx]
+	m.record(ChangeRemoved, victim.c.ID)
+
+	// Reuse the slot's position and order for the new contact.
+	m.idx.remove(victim)
+	m.secrets.Forget(victim.c.ID)
+	s := &contactSlot{c: &ContactInfo{}, pos: victim.pos, seq: victim.seq}
+	m.slots[victim.pos] = s
+	m.idx.compact(m.slots)
+	return s
 }
@@ -164,26 +164,25 @@ func TestManager_AddContact_NeverEvictFavorite(t *testing.T) {
 func TestManager_AddContact_InvalidatesSecret(t *testing.T) {
 	localKP := generateTestKeyPair(t)
 	remoteKP := generateTestKeyPair(t)
-	m := NewManager(localKP.PrivateKey, ManagerConfig{MaxContacts: 10})
+	cache := NewSecretCache(8)
+	m := NewManager(localKP.PrivateKey, ManagerConfig{MaxContacts: 10, Secrets: cache})
 
 	var remoteID core.MeshCoreID
 	copy(remoteID[:], remoteKP.PublicKey)
 
+	// A secret cached before the add is dropped by it.
+	if _, err := cache.SharedSecret(localKP.PrivateKey, remoteID); err != nil {
+		t.Fatal(err)
+	}
 	c := makeContactWithID(remoteID, "Peer", 100)
-	stored, err := m.AddContact(c)
-	if err != nil {
+	if _, err := m.AddContact(c); err != nil {
 		t.Fatalf("AddContact failed: %v", err)
 	}
-
-	// The shared secret should not be valid after add
-	stored.mu.Lock()
-	valid := stored.sharedSecretValid
-	stored.mu.Unlock()
-	if valid {
-		t.Error("shared secret should be invalidated after AddContact")
+	if cache.Len() != 0 {
+		t.Errorf("cache Len = %d after AddContact, want 0", cache.Len())
 	}
 
-	// But GetSharedSecret should work (computes lazily)
+	// GetSharedSecret computes the secret lazily
 	secret, err := m.GetSharedSecret(remoteID)
 	if err != nil {
 		t.Fatalf("GetSharedSecret failed: %v", err)
@@ -393,6 +392,40 @@ func TestManager_SearchByHash(t *testing.T) {
 	}
 }
 
+func TestManager_SearchByPrefix(t *testing.T) {
+	m := newTestManager(t, 10, false)
+
+	a := core.MeshCoreID{0xAA, 0x01, 0x02}
+	b := core.MeshCoreID{0xAA, 0x01, 0x03}
+	c := core.MeshCoreID{0xAA, 0x02, 0x02}
+	m.AddContact(makeContactWithID(a, "A", 1))
+	m.AddContact(makeContactWithID(b, "B", 2))
+	m.AddContact(makeContactWithID(c, "C", 3))
+
+	for _, tt := range []struct {
+		prefix []byte
+		want   []core.MeshCoreID
+	}{
+		{[]byte{0xAA}, []core.MeshCoreID{a, b, c}},
+		{[]byte{0xAA, 0x01}, []core.MeshCoreID{a, b}},
+		{[]byte{0xAA, 0x01, 0x03}, []core.MeshCoreID{b}},
+		{b[:], []core.MeshCoreID{b}},
+		{[]byte{0xAA, 0x03}, nil},
+		{nil, nil},
+	} {
+		got := m.SearchByPrefix(tt.prefix)
+		if len(got) != len(tt.want) {
+			t.Errorf("SearchByPrefix(%x) = %d results, want %d", tt.prefix, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if got[i].ID != tt.want[i] {
+				t.Errorf("SearchByPrefix(%x)[%d] = %s, want %s", tt.prefix, i, got[i].ID, tt.want[i])
+			}
+		}
+	}
+}
+
 func TestManager_SearchByHash_MaxResults(t *testing.T) {
 	m := newTestManager(t, 20, false)
 
@@ -0,0 +1,186 @@
+package contact
+
+import (
+	"container/list"
+	"crypto/ed25519"
+	"sync"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/crypto"
+)
+
+// DefaultSecretCacheSize is the default number of unpinned shared secrets an
+// LRUSecretCache holds.
+const DefaultSecretCacheSize = 256
+
+// SecretCache computes and caches ECDH shared secrets. Entries are keyed by
+// local identity as well as peer, so one cache serves a node holding two keys
+// during a key rotation. ContactManager uses one (ManagerConfig.Secrets);
+// implementations must be safe for concurrent use and must not call back into
+// the manager.
+type SecretCache interface {
+	// SharedSecret returns the shared secret between local and peer,
+	// computing and caching it on a miss. Callers must not modify it.
+	SharedSecret(local ed25519.PrivateKey, peer core.MeshCoreID) ([]byte, error)
+
+	// Precompute computes the secret between local and peer now and keeps it
+	// until Forget, exempt from eviction. Used for favourites.
+	Precompute(local ed25519.PrivateKey, peer core.MeshCoreID) error
+
+	// Forget drops every cached secret for peer, pinned or not.
+	Forget(peer core.MeshCoreID)
+}
+
+// secretKey identifies a cache entry: the local public key and the peer.
+type secretKey struct {
+	local [32]byte
+	peer  core.MeshCoreID
+}
+
+type secretEntry struct {
+	key    secretKey
+	secret []byte
+	pinned bool
+	elem   *list.Element // position in lru; nil when pinned
+}
+
+// LRUSecretCache is the default SecretCache: a map with least-recently-used
+// eviction. Pinned (precomputed) entries do not count against the bound and
+// are never evicted, so its memory is max entries plus the pinned ones.
+type LRUSecretCache struct {
+	max int
+
+	mu      sync.Mutex
+	entries map[secretKey]*secretEntry
+	byPeer  map[core.MeshCoreID][]*secretEntry // one entry per local key
+	lru     *list.List                         // unpinned entries, most recent at the front
+}
+
+var _ SecretCache = (*LRUSecretCache)(nil)
+
+// NewSecretCache creates an LRUSecretCache holding up to max unpinned secrets
+// (DefaultSecretCacheSize if max <= 0).
+func NewSecretCache(max int) *LRUSecretCache {
+	if max <= 0 {
+		max = DefaultSecretCacheSize
+	}
+	return &LRUSecretCache{
+		max:     max,
+		entries: make(map[secretKey]*secretEntry),
+		byPeer:  make(map[core.MeshCoreID][]*secretEntry),
+		lru:     list.New(),
+	}
+}
+
+// SharedSecret implements SecretCache.
+func (c *LRUSecretCache) SharedSecret(local ed25519.PrivateKey, peer core.MeshCoreID) ([]byte, error) {
+	return c.get(local, peer, false)
+}
+
+// Precompute implements SecretCache.
+func (c *LRUSecretCache) Precompute(local ed25519.PrivateKey, peer core.MeshCoreID) error {
+	_, err := c.get(local, peer, true)
+	return err
+}
+
+// get looks up or computes a secret, pinning the entry if pin is set. The
+// ECDH runs outside the lock; a concurrent miss for the same pair computes
+// the same value, and the first to store it wins.
+func (c *LRUSecretCache) get(local ed25519.PrivateKey, peer core.MeshCoreID, pin bool) ([]byte, error) {
+	if len(local) != ed25519.PrivateKeySize {
+		return nil, crypto.ErrInvalidPrivKeySize
+	}
+	k := secretKey{peer: peer}
+	copy(k.local[:], local.Public().(ed25519.PublicKey))
+
+	c.mu.Lock()
+	if e, ok := c.entries[k]; ok {
+		c.touchLocked(e, pin)
+		c.mu.Unlock()
+		return e.secret, nil
+	}
+	c.mu.Unlock()
+
+	secret, err := crypto.ComputeSharedSecret(local, peer[:])
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[k]; ok {
+		c.touchLocked(e, pin)
+		return e.secret, nil
+	}
+	e := &secretEntry{key: k, secret: secret, pinned: pin}
+	if !pin {
+		e.elem = c.lru.PushFront(e)
+	}
+	c.entries[k] = e
+	c.byPeer[peer] = append(c.byPeer[peer], e)
+	for c.lru.Len() > c.max {
+		c.removeLocked(c.lru.Back().Value.(*secretEntry))
+	}
+	return secret, nil
+}
+
+// removeLocked drops e from the cache. Must be called with c.mu held.
+func (c *LRUSecretCache) removeLocked(e *secretEntry) {
+	if e.elem != nil {
+		c.lru.Remove(e.elem)
+	}
+	delete(c.entries, e.key)
+	peers := c.byPeer[e.key.peer]
+	for i, pe := range peers {
+		if pe == e {
+			peers = append(peers[:i], peers[i+1:]...)
+			break
+		}
+	}
+	if len(peers) == 0 {
+		delete(c.byPeer, e.key.peer)
+	} else {
+		c.byPeer[e.key.peer] = peers
+	}
+}
+
+// touchLocked marks e as recently used, pinning it if pin is set. Must be
+// called with c.mu held.
+func (c *LRUSecretCache) touchLocked(e *secretEntry, pin bool) {
+	switch {
+	case e.pinned:
+	case pin:
+		c.lru.Remove(e.elem)
+		e.elem = nil
+		e.pinned = true
+	default:
+		c.lru.MoveToFront(e.elem)
+	}
+}
+
+// Forget implements SecretCache.
+func (c *LRUSecretCache) Forget(peer core.MeshCoreID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range c.byPeer[peer] {
+		if e.elem != nil {
+			c.lru.Remove(e.elem)
+		}
+		delete(c.entries, e.key)
+	}
+	delete(c.byPeer, peer)
+}
+
+// Len returns the number of cached secrets, pinned included.
+func (c *LRUSecretCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// SecretSource is implemented by contact stores that can compute a contact's
+// shared secret with a local identity other than their own, as ContactManager
+// does. Nodes use it during a key rotation.
+type SecretSource interface {
+	SharedSecretWith(local ed25519.PrivateKey, id core.MeshCoreID) ([]byte, error)
+}
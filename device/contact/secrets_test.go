@@ -0,0 +1,213 @@
+package contact
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/crypto"
+)
+
+func peerID(t *testing.T) core.MeshCoreID {
+	t.Helper()
+	var id core.MeshCoreID
+	copy(id[:], generateTestKeyPair(t).PublicKey)
+	return id
+}
+
+func TestLRUSecretCache(t *testing.T) {
+	local := generateTestKeyPair(t)
+	c := NewSecretCache(2)
+	a, b, d := peerID(t), peerID(t), peerID(t)
+
+	got, err := c.SharedSecret(local.PrivateKey, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := crypto.ComputeSharedSecret(local.PrivateKey, a[:])
+	if !bytes.Equal(got, want) {
+		t.Fatal("cached secret differs from ECDH")
+	}
+
+	if err := c.Precompute(local.PrivateKey, d); err != nil {
+		t.Fatal(err)
+	}
+	c.SharedSecret(local.PrivateKey, b)
+	c.SharedSecret(local.PrivateKey, a) // a is now most recent
+	c.SharedSecret(local.PrivateKey, peerID(t))
+
+	// b was evicted; a and the pinned d were not.
+	if c.Len() != 3 {
+		t.Errorf("Len = %d, want 3 (two unpinned + one pinned)", c.Len())
+	}
+	k := func(id core.MeshCoreID) secretKey {
+		key := secretKey{peer: id}
+		copy(key.local[:], local.PublicKey)
+		return key
+	}
+	for id, present := range map[core.MeshCoreID]bool{a: true, b: false, d: true} {
+		if _, ok := c.entries[k(id)]; ok != present {
+			t.Errorf("entry %x present = %v, want %v", id[:2], ok, present)
+		}
+	}
+
+	c.Forget(d)
+	if _, ok := c.entries[k(d)]; ok {
+		t.Error("Forget left the pinned entry")
+	}
+}
+
+func TestLRUSecretCache_SymmetricConcurrent(t *testing.T) {
+	a, b := generateTestKeyPair(t), generateTestKeyPair(t)
+	var aID, bID core.MeshCoreID
+	copy(aID[:], a.PublicKey)
+	copy(bID[:], b.PublicKey)
+	c := NewSecretCache(4)
+
+	done := make(chan []byte)
+	for i := 0; i < 10; i++ {
+		go func() {
+			secret, err := c.SharedSecret(a.PrivateKey, bID)
+			if err != nil {
+				t.Errorf("SharedSecret: %v", err)
+			}
+			done <- secret
+		}()
+	}
+	var first []byte
+	for i := 0; i < 10; i++ {
+		s := <-done
+		if first == nil {
+			first = s
+		} else if !bytes.Equal(s, first) {
+			t.Error("concurrent lookups disagree")
+		}
+	}
+
+	other, err := c.SharedSecret(b.PrivateKey, aID)
+	if err != nil || !bytes.Equal(other, first) {
+		t.Errorf("secret not symmetric: %x vs %x (%v)", other, first, err)
+	}
+}
+
+func TestLRUSecretCache_TwoIdentities(t *testing.T) {
+	oldKey, newKey := generateTestKeyPair(t), generateTestKeyPair(t)
+	c := NewSecretCache(4)
+	peer := peerID(t)
+
+	s1, _ := c.SharedSecret(oldKey.PrivateKey, peer)
+	s2, _ := c.SharedSecret(newKey.PrivateKey, peer)
+	if bytes.Equal(s1, s2) || c.Len() != 2 {
+		t.Fatalf("identities share an entry (len %d)", c.Len())
+	}
+	c.Forget(peer)
+	if c.Len() != 0 {
+		t.Errorf("Forget left %d entries", c.Len())
+	}
+}
+
+// countingCache records Precompute and Forget calls.
+type countingCache struct {
+	*LRUSecretCache
+	pinned map[core.MeshCoreID]int
+}
+
+func (c *countingCache) Precompute(local ed25519.PrivateKey, peer core.MeshCoreID) error {
+	c.pinned[peer]++
+	return c.LRUSecretCache.Precompute(local, peer)
+}
+
+func TestManager_PrecomputesFavorites(t *testing.T) {
+	kp := generateTestKeyPair(t)
+	cache := &countingCache{LRUSecretCache: NewSecretCache(8), pinned: map[core.MeshCoreID]int{}}
+	m := NewManager(kp.PrivateKey, ManagerConfig{Secrets: cache})
+
+	fav := makeContactWithID(peerID(t), "Fav", 100)
+	fav.SetFavorite(true)
+	m.AddContact(fav)
+	plain := makeContactWithID(peerID(t), "Plain", 100)
+	m.AddContact(plain)
+
+	if cache.pinned[fav.ID] != 1 || cache.pinned[plain.ID] != 0 || cache.Len() != 1 {
+		t.Fatalf("pinned = %v, len = %d", cache.pinned, cache.Len())
+	}
+
+	// GetSharedSecret goes through the cache.
+	secret, err := m.GetSharedSecret(plain.ID)
+	if err != nil || len(secret) != 32 || cache.Len() != 2 {
+		t.Fatalf("GetSharedSecret = %x, %v (len %d)", secret, err, cache.Len())
+	}
+
+	// Unmarking a favourite drops its pin; removing a contact drops its secret.
+	unfav := makeContactWithID(fav.ID, "Fav", 200)
+	m.UpdateContact(unfav)
+	m.RemoveContact(plain.ID)
+	if cache.Len() != 0 {
+		t.Errorf("Len = %d after unfavourite and remove, want 0", cache.Len())
+	}
+	if _, err := m.SharedSecretWith(kp.PrivateKey, plain.ID); err != ErrContactNotFound {
+		t.Errorf("SharedSecretWith(removed) err = %v", err)
+	}
+}
+
+func TestLRUSecretCache_BadLocalKey(t *testing.T) {
+	c := NewSecretCache(4)
+	peer := peerID(t)
+	for _, local := range []ed25519.PrivateKey{nil, make(ed25519.PrivateKey, 16)} {
+		if _, err := c.SharedSecret(local, peer); err != crypto.ErrInvalidPrivKeySize {
+			t.Errorf("SharedSecret(%d-byte key) err = %v", len(local), err)
+		}
+		if err := c.Precompute(local, peer); err != crypto.ErrInvalidPrivKeySize {
+			t.Errorf("Precompute(%d-byte key) err = %v", len(local), err)
+		}
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len = %d, want 0", c.Len())
+	}
+}
+
+// TestManager_NoLocalKeyFavorite checks that a manager without a local key,
+// as used for lookups only, stores favourites without computing secrets.
+func TestManager_NoLocalKeyFavorite(t *testing.T) {
+	cache := NewSecretCache(4)
+	m := NewManager(nil, ManagerConfig{Secrets: cache})
+
+	fav := makeContactWithID(peerID(t), "Fav", 100)
+	fav.SetFavorite(true)
+	if _, err := m.AddContact(fav); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len = %d, want 0", cache.Len())
+	}
+	if _, err := m.GetSharedSecret(fav.ID); err == nil {
+		t.Error("GetSharedSecret without a local key succeeded")
+	}
+}
+
+// TestManager_UnfavoriteInPlace checks that a favourite unmarked through the
+// stored pointer before UpdateContact still loses its pin.
+func TestManager_UnfavoriteInPlace(t *testing.T) {
+	kp := generateTestKeyPair(t)
+	cache := NewSecretCache(8)
+	m := NewManager(kp.PrivateKey, ManagerConfig{Secrets: cache})
+
+	fav := makeContactWithID(peerID(t), "Fav", 100)
+	fav.SetFavorite(true)
+	stored, err := m.AddContact(fav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("Len = %d after adding a favourite, want 1", cache.Len())
+	}
+
+	stored.SetFavorite(false)
+	if err := m.UpdateContact(stored); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len = %d after unfavourite, want 0", cache.Len())
+	}
+}
@@ -0,0 +1,157 @@
+package contact
+
+import (
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+)
+
+// statsSmoothing is the weight given to a new sample in the smoothed RTT and
+// SNR averages (an exponentially weighted moving average, as TCP's SRTT).
+const statsSmoothing = 1.0 / 8
+
+// LinkStats holds per-contact delivery and link-quality metrics. They are
+// local observations, not part of the firmware contact record: they are kept
+// with the contact, persisted by FileContactStore, and reset when the contact
+// is evicted. UpdateContact leaves them untouched; use UpdateStats.
+type LinkStats struct {
+	// Delivery of ACK-tracked messages sent to the contact.
+	Sent      uint32 // messages sent expecting an ACK
+	Acked     uint32 // messages acknowledged
+	Failed    uint32 // messages that timed out without an ACK
+	RTTAvgMs  uint32 // smoothed ACK round-trip time
+	RTTLastMs uint32 // most recent ACK round-trip time
+	LastACK   uint32 // our clock time of the most recent ACK
+
+	// Packets received from the contact.
+	Received  uint32  // packets decrypted or adverts heard from the contact
+	LastHeard uint32  // our clock time of the most recent packet
+	SNRAvg    float32 // smoothed SNR (dB) of packets heard directly (zero hops)
+	SNRLast   float32 // SNR (dB) of the most recent zero-hop packet
+	SNRCount  uint32  // number of zero-hop packets in the SNR average
+
+	// RSSI of zero-hop packets, where the transport reports it.
+	RSSIAvg   float32 // smoothed RSSI (dBm)
+	RSSILast  int16   // RSSI (dBm) of the most recent zero-hop packet
+	RSSICount uint32  // number of zero-hop packets in the RSSI average
+
+	// PathChanges counts updates that changed the contact's direct path.
+	PathChanges uint32
+}
+
+// RecordSent counts a message sent to the contact that expects an ACK.
+func (s *LinkStats) RecordSent() {
+	s.Sent++
+}
+
+// RecordACK counts an acknowledged message and folds its round-trip time into
+// the average.
+func (s *LinkStats) RecordACK(rtt time.Duration, now uint32) {
+	ms := uint32(rtt.Milliseconds())
+	if s.Acked == 0 {
+		s.RTTAvgMs = ms
+	} else {
+		s.RTTAvgMs = uint32(float64(s.RTTAvgMs) + (float64(ms)-float64(s.RTTAvgMs))*statsSmoothing)
+	}
+	s.Acked++
+	s.RTTLastMs = ms
+	s.LastACK = now
+}
+
+// RecordTimeout counts a message that was never acknowledged.
+func (s *LinkStats) RecordTimeout() {
+	s.Failed++
+}
+
+// RecordReceived counts a packet heard from the contact. snr (dB) is only
+// meaningful when the packet came straight from the contact, so it is folded
+// into the average only when zeroHop is true.
+func (s *LinkStats) RecordReceived(now uint32, snr float32, zeroHop bool) {
+	s.Received++
+	s.LastHeard = now
+	if !zeroHop {
+		return
+	}
+	if s.SNRCount == 0 {
+		s.SNRAvg = snr
+	} else {
+		s.SNRAvg += (snr - s.SNRAvg) * statsSmoothing
+	}
+	s.SNRCount++
+	s.SNRLast = snr
+}
+
+// RecordRSSI folds the RSSI (dBm) of a packet heard straight from the contact
+// into the average. Call it alongside RecordReceived, for zero-hop packets
+// only, when the transport reports RSSI.
+func (s *LinkStats) RecordRSSI(rssi int16) {
+	if s.RSSICount == 0 {
+		s.RSSIAvg = float32(rssi)
+	} else {
+		s.RSSIAvg += (float32(rssi) - s.RSSIAvg) * statsSmoothing
+	}
+	s.RSSICount++
+	s.RSSILast = rssi
+}
+
+// RecordPathChange counts a change of the contact's direct path.
+func (s *LinkStats) RecordPathChange() {
+	s.PathChanges++
+}
+
+// ACKRate returns the fraction of resolved messages (acknowledged or timed
+// out) that were acknowledged. ok is false if no message has resolved yet.
+func (s LinkStats) ACKRate() (rate float64, ok bool) {
+	resolved := s.Acked + s.Failed
+	if resolved == 0 {
+		return 0, false
+	}
+	return float64(s.Acked) / float64(resolved), true
+}
+
+// StatsStore is implemented by contact stores that update LinkStats atomically
+// and persist them. ContactManager implements it. Use the package-level
+// UpdateStats, which falls back to updating the contact in place for stores
+// that do not.
+type StatsStore interface {
+	// UpdateStats applies fn to the contact's stats. Returns
+	// ErrContactNotFound if the contact does not exist.
+	UpdateStats(id core.MeshCoreID, fn func(*LinkStats)) error
+}
+
+// UpdateStats applies fn to the stats of the contact with the given public key.
+// Returns ErrContactNotFound if the contact is not in the store.
+func UpdateStats(store ContactStore, id core.MeshCoreID, fn func(*LinkStats)) error {
+	if ss, ok := store.(StatsStore); ok {
+		return ss.UpdateStats(id, fn)
+	}
+	c := store.GetByPubKey(id)
+	if c == nil {
+		return ErrContactNotFound
+	}
+	c.UpdateStats(fn)
+	return nil
+}
+
+// Stats returns a snapshot of the contact's link statistics. Thread-safe.
+func (c *ContactInfo) Stats() LinkStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// SetStats replaces the contact's link statistics, e.g. when restoring them
+// from storage. Thread-safe.
+func (c *ContactInfo) SetStats(s LinkStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = s
+}
+
+// UpdateStats applies fn to the contact's link statistics. Thread-safe, but not
+// persisted; prefer the package-level UpdateStats for stored contacts.
+func (c *ContactInfo) UpdateStats(fn func(*LinkStats)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(&c.stats)
+}
@@ -0,0 +1,107 @@
+package contact
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+)
+
+func TestLinkStats_Delivery(t *testing.T) {
+	var s LinkStats
+	if _, ok := s.ACKRate(); ok {
+		t.Error("ACKRate should be unknown before anything resolves")
+	}
+	for range 4 {
+		s.RecordSent()
+	}
+	s.RecordACK(800*time.Millisecond, 100)
+	s.RecordACK(1600*time.Millisecond, 200)
+	s.RecordACK(800*time.Millisecond, 300)
+	s.RecordTimeout()
+
+	if rate, ok := s.ACKRate(); !ok || rate != 0.75 {
+		t.Errorf("ACKRate = %v, %v; want 0.75", rate, ok)
+	}
+	// 800 -> 800 + (1600-800)/8 = 900 -> 900 + (800-900)/8 = 887
+	if s.RTTAvgMs != 887 || s.RTTLastMs != 800 || s.LastACK != 300 {
+		t.Errorf("RTT avg/last/lastACK = %d/%d/%d, want 887/800/300", s.RTTAvgMs, s.RTTLastMs, s.LastACK)
+	}
+}
+
+func TestLinkStats_ReceivedSNR(t *testing.T) {
+	var s LinkStats
+	s.RecordReceived(10, 8, true)
+	s.RecordReceived(20, -20, false) // relayed: SNR belongs to the last hop
+	s.RecordReceived(30, 0, true)
+
+	if s.Received != 3 || s.LastHeard != 30 {
+		t.Errorf("received/lastHeard = %d/%d, want 3/30", s.Received, s.LastHeard)
+	}
+	if s.SNRCount != 2 || s.SNRLast != 0 || s.SNRAvg != 7 {
+		t.Errorf("SNR count/last/avg = %d/%v/%v, want 2/0/7", s.SNRCount, s.SNRLast, s.SNRAvg)
+	}
+}
+
+func TestLinkStats_RSSI(t *testing.T) {
+	var s LinkStats
+	s.RecordRSSI(-90)
+	s.RecordRSSI(-98)
+	if s.RSSICount != 2 || s.RSSILast != -98 || s.RSSIAvg != -91 {
+		t.Errorf("RSSI count/last/avg = %d/%d/%v, want 2/-98/-91", s.RSSICount, s.RSSILast, s.RSSIAvg)
+	}
+}
+
+func TestContactManager_UpdateStatsPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contacts.json")
+	kp, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id core.MeshCoreID
+	id[0] = 0x42
+
+	fs := NewFileContactStore(path)
+	m := NewManager(kp.PrivateKey, ManagerConfig{Persistence: fs})
+	m.AddContact(&ContactInfo{ID: id, Name: "Dave", OutPathLen: PathUnknown})
+
+	added := 0
+	m.SetOnContactAdded(func(*ContactInfo, bool) { added++ })
+	if err := UpdateStats(m, id, func(s *LinkStats) { s.RecordSent(); s.RecordACK(time.Second, 50) }); err != nil {
+		t.Fatal(err)
+	}
+	if added != 0 {
+		t.Error("UpdateStats should not fire the contact-added callback")
+	}
+	if err := UpdateStats(m, core.MeshCoreID{1}, (*LinkStats).RecordSent); err != ErrContactNotFound {
+		t.Errorf("unknown contact: err = %v, want ErrContactNotFound", err)
+	}
+	if err := fs.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	m2 := NewManager(kp.PrivateKey, ManagerConfig{Persistence: NewFileContactStore(path)})
+	got := m2.GetByPubKey(id).Stats()
+	if got.Sent != 1 || got.Acked != 1 || got.RTTAvgMs != 1000 || got.LastACK != 50 {
+		t.Errorf("stats after restart = %+v", got)
+	}
+}
+
+func TestProcessPath_CountsPathChanges(t *testing.T) {
+	m := NewManager(nil, ManagerConfig{})
+	var id core.MeshCoreID
+	id[0] = 0x07
+	m.AddContact(&ContactInfo{ID: id, OutPathLen: PathUnknown})
+
+	path := &codec.PathContent{PathLen: 2, Path: []byte{0x11, 0x22}}
+	ProcessPath(m, id, path, 10)
+	ProcessPath(m, id, path, 20) // same path again
+	ProcessPath(m, id, &codec.PathContent{PathLen: 1, Path: []byte{0x33}}, 30)
+
+	if got := m.GetByPubKey(id).Stats().PathChanges; got != 2 {
+		t.Errorf("PathChanges = %d, want 2", got)
+	}
+}
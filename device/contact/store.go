@@ -7,7 +7,7 @@ import "github.com/kabili207/meshcore-go/core"
 // Custom implementations can provide database-backed or other persistent storage.
 //
 // Implementations must return pointers to internally-held ContactInfo structs
-// from GetByPubKey and SearchByHash (not copies), so that callers can read
+// from GetByPubKey, SearchByHash, and SearchByPrefix (not copies), so that callers can read
 // fields directly. Mutations should go through UpdateContact.
 type ContactStore interface {
 	// AddContact adds a new contact. Returns a pointer to the stored contact.
@@ -32,6 +32,12 @@ type ContactStore interface {
 	// the given hash. Up to MaxSearchResults may be returned due to collisions.
 	SearchByHash(hash uint8) []*ContactInfo
 
+	// SearchByPrefix returns contacts whose public key starts with prefix, of
+	// any length from 1 byte (equivalent to SearchByHash) to the full key, as
+	// used by 2- and 3-byte path hashes. Up to MaxSearchResults are returned,
+	// in list order. An empty prefix matches nothing.
+	SearchByPrefix(prefix []byte) []*ContactInfo
+
 	// GetSharedSecret finds the contact and returns the cached ECDH shared
 	// secret, computing it lazily if needed.
 	GetSharedSecret(id core.MeshCoreID) ([]byte, error)
@@ -24,3 +24,23 @@ type AdvertReceived struct {
 	// (a new contact was created), false if an existing contact was updated.
 	IsNew bool
 }
+
+// AdvertConflict fires after AdvertReceived when an advert from a new key, or
+// one that renames a contact, clashes with other stored contacts: the same name
+// under a different key (possible impersonation), or a 1-byte hash shared with
+// a favourite or, between relays, one that makes paths ambiguous. See
+// contact.DetectConflicts. Adverts that are not added (auto-add off, or too
+// many hops away) are not checked.
+type AdvertConflict struct {
+	Event
+
+	// Advert is the parsed advertisement that raised the conflict.
+	Advert *codec.AdvertPayload
+
+	// Contact is the advertiser, as stored, or a temporary entry if it was not
+	// added.
+	Contact *contact.ContactInfo
+
+	// Conflicts lists the stored contacts it clashes with.
+	Conflicts []contact.Conflict
+}
@@ -51,6 +51,11 @@ type Event struct {
 
 	// Source indicates which transport the packet arrived on.
 	Source transport.PacketSource
+
+	// Rx is the packet's receive metadata: receive time, transport name, and
+	// SNR, RSSI, and frequency where the transport reports them. Nil for
+	// synthetic events.
+	Rx *codec.RxMeta
 }
 
 // ReplyContext carries the cryptographic and routing state needed to send
@@ -79,6 +84,10 @@ type ReplyContext struct {
 	// PathHashSize is the hash size (1, 2, or 3 bytes) from the incoming
 	// flood packet. Used when building PATH return packets.
 	PathHashSize uint8
+
+	// PreviousKey is true if the packet was addressed to the node's previous
+	// key during a key rotation; replies are then sent from that identity.
+	PreviousKey bool
 }
 
 // HasFloodPath returns true if the original packet arrived via flood routing
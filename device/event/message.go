@@ -11,7 +11,8 @@ type TextMessageReceived struct {
 	Message string
 
 	// TxtType is the message type: codec.TxtTypePlain (regular message),
-	// codec.TxtTypeCLI (admin command), or codec.TxtTypeSigned (signed message).
+	// codec.TxtTypeCLI (admin command), codec.TxtTypeSigned (signed message),
+	// or codec.TxtTypeFederated (a post relayed between federated rooms).
 	TxtType uint8
 
 	// Attempt is the sender's retry attempt number (0-3). Attempt > 0
@@ -25,8 +26,14 @@ type TextMessageReceived struct {
 	Timestamp uint32
 
 	// SenderPubKeyPrefix is the first 4 bytes of the sender's public key,
-	// present only for signed messages (TxtType == TxtTypeSigned). Nil otherwise.
+	// present only for signed and federated messages. Nil otherwise.
 	SenderPubKeyPrefix []byte
+
+	// OriginPubKeyPrefix and OriginTimestamp identify the room a federated
+	// message (TxtType == TxtTypeFederated) was first posted on, and when.
+	// Nil and zero otherwise.
+	OriginPubKeyPrefix []byte
+	OriginTimestamp    uint32
 }
 
 // GroupTextReceived fires when an unencrypted group text message is received.
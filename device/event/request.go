@@ -1,5 +1,7 @@
 package event
 
+import "github.com/kabili207/meshcore-go/core/codec"
+
 // AnonRequestReceived fires after an anonymous request (ANON_REQ) packet is
 // successfully decrypted. Anonymous requests use an ephemeral keypair and do
 // not require a pre-existing contact relationship. This is primarily used for
@@ -95,3 +97,31 @@ type StatusResponse struct {
 	// received. Parsing is the application's responsibility.
 	Data []byte
 }
+
+// OwnerInfoResponse fires when a repeater or room server answers a
+// SendOwnerInfoReq. The embedded Event's From field is the responding peer.
+type OwnerInfoResponse struct {
+	Event
+
+	// FirmwareVersion is the peer's firmware version string (e.g. "v1.16.0").
+	FirmwareVersion string
+
+	// Name is the peer's node name.
+	Name string
+
+	// Owner is the peer's free-form owner info text.
+	Owner string
+}
+
+// PostHistoryResponse fires when a room server answers a SendPostHistoryReq.
+// The embedded Event's From field is the room server.
+type PostHistoryResponse struct {
+	Event
+
+	// Posts is the page of posts, newest first.
+	Posts []codec.PostHistoryEntry
+
+	// More reports that older posts remain. Request the next page with the
+	// oldest timestamp in Posts as the before bound.
+	More bool
+}
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/clock"
@@ -75,6 +76,23 @@ type BaseConfig struct {
 	// Default: 0 (off) — appropriate for reliable transports.
 	ExtraAckTransmits int
 
+	// PreviousKey is the private key this node used before a key rotation.
+	// Until PreviousKeyUntil, addressed packets and anonymous requests sent
+	// to the old identity are still decrypted, and replies to them are sent
+	// from it, so peers that have not yet heard the new key's advert keep
+	// working. Adverts, forwarding, and new messages use PrivateKey only.
+	// Nil disables it.
+	PreviousKey ed25519.PrivateKey
+
+	// PreviousKeyUntil ends the rotation grace period. Zero keeps the
+	// previous key until the node is restarted without it.
+	PreviousKeyUntil time.Time
+
+	// Blocklist is the set of peers whose adverts and direct messages the
+	// node ignores. Use contact.OpenBlocklist for a list that survives
+	// restarts. If nil, an in-memory list is created.
+	Blocklist *contact.Blocklist
+
 	// EventHandlers are registered during construction (before Run).
 	EventHandlers []event.Handler
 
@@ -91,11 +109,17 @@ type BaseNode struct {
 	publicKey  [32]byte
 	id         core.MeshCoreID
 
+	// Previous identity during a key rotation (see BaseConfig.PreviousKey)
+	prevKey   ed25519.PrivateKey
+	prevID    core.MeshCoreID
+	prevUntil time.Time
+
 	// Core components
-	Router   *router.Router
-	contacts contact.ContactStore
-	clock    *clock.Clock
-	ack      *ack.Tracker
+	Router    *router.Router
+	contacts  contact.ContactStore
+	blocklist *contact.Blocklist
+	clock     *clock.Clock
+	ack       *ack.Tracker
 
 	// Configuration
 	autoACK            bool
@@ -160,12 +184,18 @@ func NewBase(cfg BaseConfig) (*BaseNode, error) {
 		r = router.New(routerCfg)
 	}
 
+	blocklist := cfg.Blocklist
+	if blocklist == nil {
+		blocklist = contact.NewBlocklist()
+	}
+
 	b := &BaseNode{
 		privateKey:         cfg.PrivateKey,
 		publicKey:          pubKey,
 		id:                 id,
 		Router:             r,
 		contacts:           cfg.Contacts,
+		blocklist:          blocklist,
 		clock:              clk,
 		ack:                cfg.ACKTracker,
 		transports:         cfg.Transports,
@@ -174,6 +204,11 @@ func NewBase(cfg BaseConfig) (*BaseNode, error) {
 		extraAckTransmits:  cfg.ExtraAckTransmits,
 		log:                logger.WithGroup("node"),
 	}
+	if cfg.PreviousKey != nil {
+		b.prevKey = cfg.PreviousKey
+		copy(b.prevID[:], cfg.PreviousKey.Public().(ed25519.PublicKey))
+		b.prevUntil = cfg.PreviousKeyUntil
+	}
 
 	// Register event handlers from config
 	for _, h := range cfg.EventHandlers {
@@ -221,9 +256,46 @@ func (b *BaseNode) PublicKey() [32]byte { return b.publicKey }
 // PrivateKey returns the node's Ed25519 private key.
 func (b *BaseNode) PrivateKey() ed25519.PrivateKey { return b.privateKey }
 
+// PreviousID returns the identity the node still answers for during a key
+// rotation, and whether the grace period is running.
+func (b *BaseNode) PreviousID() (core.MeshCoreID, bool) {
+	if !b.previousKeyActive() {
+		return core.MeshCoreID{}, false
+	}
+	return b.prevID, true
+}
+
+// previousKeyActive reports whether a previous key is configured and its
+// grace period has not ended.
+func (b *BaseNode) previousKeyActive() bool {
+	return b.prevKey != nil && (b.prevUntil.IsZero() || time.Now().Before(b.prevUntil))
+}
+
+// previousSecret returns the shared secret between the previous key and a
+// contact, through the contact store's cache if it has one.
+func (b *BaseNode) previousSecret(id core.MeshCoreID) ([]byte, error) {
+	if src, ok := b.contacts.(contact.SecretSource); ok {
+		return src.SharedSecretWith(b.prevKey, id)
+	}
+	return crypto.ComputeSharedSecret(b.prevKey, id[:])
+}
+
+// replyHash is the source hash for a reply: the previous identity's if the
+// request was addressed to it.
+func (b *BaseNode) replyHash(reply event.ReplyContext) uint8 {
+	if reply.PreviousKey {
+		return b.prevID.Hash()
+	}
+	return b.id.Hash()
+}
+
 // Contacts returns the contact store.
 func (b *BaseNode) Contacts() contact.ContactStore { return b.contacts }
 
+// Blocklist returns the node's blocklist. Peers added to it are ignored from
+// their next packet on.
+func (b *BaseNode) Blocklist() *contact.Blocklist { return b.blocklist }
+
 // Clock returns the node's clock.
 func (b *BaseNode) Clock() *clock.Clock { return b.clock }
 
@@ -265,7 +337,7 @@ func (b *BaseNode) sendEncryptedResponse(reply event.ReplyContext, to core.MeshC
 	}
 
 	mac, ciphertext := codec.SplitMAC(encrypted)
-	payload := codec.BuildAddressedPayload(to.Hash(), b.id.Hash(), mac, ciphertext)
+	payload := codec.BuildAddressedPayload(to.Hash(), b.replyHash(reply), mac, ciphertext)
 	pkt := codec.NewPacket(payloadType, codec.RouteTypeFlood, payload)
 
 	if reply.HasDirectPath() {
@@ -291,7 +363,7 @@ func (b *BaseNode) sendPathReturn(reply event.ReplyContext, to core.MeshCoreID,
 	}
 
 	mac, ciphertext := codec.SplitMAC(encrypted)
-	payload := codec.BuildAddressedPayload(to.Hash(), b.id.Hash(), mac, ciphertext)
+	payload := codec.BuildAddressedPayload(to.Hash(), b.replyHash(reply), mac, ciphertext)
 	pkt := codec.NewPacket(codec.PayloadTypePath, codec.RouteTypeFlood, payload)
 
 	b.Router.SendFloodPathScoped(pkt)
@@ -383,10 +455,11 @@ func (b *BaseNode) SendToContact(to core.MeshCoreID, payloadType uint8, plaintex
 }
 
 // buildReplyContext constructs a ReplyContext from a decrypted addressed packet.
-func (b *BaseNode) buildReplyContext(pkt *codec.Packet, ct *contact.ContactInfo, secret []byte) event.ReplyContext {
+func (b *BaseNode) buildReplyContext(pkt *codec.Packet, ct *contact.ContactInfo, secret []byte, previous bool) event.ReplyContext {
 	reply := event.ReplyContext{
 		SharedSecret:  secret,
 		DirectPathLen: ct.OutPathLen,
+		PreviousKey:   previous,
 	}
 	if ct.HasDirectPath() {
 		reply.DirectPath = make([]byte, len(ct.OutPath))
@@ -405,8 +478,17 @@ func (b *BaseNode) updateContactPathFromFlood(pkt *codec.Packet, ct *contact.Con
 	if !pkt.IsFlood() || pkt.HopCount() == 0 {
 		return
 	}
-	reversed := codec.ReverseFloodPath(pkt)
-	ct.OutPathLen = pkt.PathLen // preserve the encoded wire byte (mode + hop count)
-	ct.OutPath = reversed
-	ct.LastMod = b.clock.GetCurrentTime()
+	// Preserve the encoded wire byte (mode + hop count).
+	_, _ = contact.UpdatePath(b.contacts, ct.ID, pkt.PathLen, codec.ReverseFloodPath(pkt), b.clock.GetCurrentTime())
+}
+
+// contactsByDistance returns the located contacts in store, nearest first,
+// measured from the position appData advertises. ok is false if the node
+// advertises no position.
+func contactsByDistance(store contact.ContactStore, appData *codec.AdvertAppData) ([]contact.ContactDistance, bool) {
+	from, ok := contact.AdvertLocation(appData)
+	if !ok {
+		return nil, false
+	}
+	return contact.SortByDistance(store, from), true
 }
@@ -32,6 +32,16 @@ type CompanionConfig struct {
 	// created with MaxContacts=256 and OverwriteWhenFull=true.
 	Contacts contact.ContactStore
 
+	// Blocklist is the set of peers whose adverts and direct messages the node
+	// ignores. Use contact.OpenBlocklist for a list that survives restarts. If
+	// nil, an in-memory list is created.
+	Blocklist *contact.Blocklist
+
+	// PreviousKey and PreviousKeyUntil keep the node answering for its old
+	// key after a key rotation; see BaseConfig.PreviousKey.
+	PreviousKey      ed25519.PrivateKey
+	PreviousKeyUntil time.Time
+
 	// Advertisement
 	Name     string   // Node name broadcast in adverts.
 	NodeType uint8    // Default: codec.NodeTypeChat.
@@ -50,7 +60,9 @@ type CompanionConfig struct {
 	// (disabled), matching firmware. Set a non-zero value to opt in.
 	AdvertFloodInterval uint8
 
-	// ACKTimeout is how long to wait for an ACK before retrying. Default: 12s.
+	// ACKTimeout is how long to wait for an ACK before retrying, and for the
+	// response to a telemetry, status, owner-info, or post-history request
+	// before forgetting it. Default: 12s.
 	ACKTimeout time.Duration
 
 	// MaxRetries is how many times to resend before giving up. Default: 3.
@@ -83,16 +95,17 @@ type CompanionNode struct {
 	base        *BaseNode
 	ackTracker  *ack.Tracker
 	advertSched *advert.Scheduler
+	appData     *codec.AdvertAppData // the advertised name and position
 	connections *connection.Manager
 	clk         *clock.Clock
 	log         *slog.Logger
 
 	keepAliveEvery time.Duration
+	requestTimeout time.Duration // how long a tagged request stays pending
 
-	pendingMu        sync.Mutex
-	pendingLogins    map[core.MeshCoreID]uint32 // server -> login send time
-	pendingTelemetry map[uint32]core.MeshCoreID // request tag -> peer
-	pendingStatus    map[uint32]core.MeshCoreID // status request tag -> peer
+	pendingMu     sync.Mutex
+	pendingLogins map[core.MeshCoreID]uint32 // server -> login send time
+	pendingReqs   map[uint32]pendingReq      // request tag -> tagged request
 }
 
 // NewCompanion creates a CompanionNode from the given configuration.
@@ -142,6 +155,9 @@ func NewCompanion(cfg CompanionConfig) (*CompanionNode, error) {
 		Transports:        cfg.Transports,
 		ForwardPackets:    cfg.ForwardPackets,
 		ExtraAckTransmits: cfg.ExtraAckTransmits,
+		Blocklist:         cfg.Blocklist,
+		PreviousKey:       cfg.PreviousKey,
+		PreviousKeyUntil:  cfg.PreviousKeyUntil,
 		EventHandlers:     cfg.EventHandlers,
 		Logger:            logger,
 	})
@@ -153,16 +169,17 @@ func NewCompanion(cfg CompanionConfig) (*CompanionNode, error) {
 	base.AddChannel(crypto.DefaultChannelKey)
 
 	// Build advert scheduler
+	appData := &codec.AdvertAppData{
+		Name:     cfg.Name,
+		NodeType: nodeType,
+		Lat:      cfg.Lat,
+		Lon:      cfg.Lon,
+	}
 	advertBuilder := advert.NewSelfAdvertBuilder(&advert.SelfAdvertConfig{
 		PrivateKey: cfg.PrivateKey,
 		PublicKey:  base.PublicKey(),
 		Clock:      clk,
-		AppData: &codec.AdvertAppData{
-			Name:     cfg.Name,
-			NodeType: nodeType,
-			Lat:      cfg.Lat,
-			Lon:      cfg.Lon,
-		},
+		AppData:    appData,
 	})
 
 	// Companions default to no recurring adverts (both intervals 0), matching the
@@ -183,16 +200,17 @@ func NewCompanion(cfg CompanionConfig) (*CompanionNode, error) {
 		base:        base,
 		ackTracker:  tracker,
 		advertSched: scheduler,
+		appData:     appData,
 		connections: connection.NewManager(connection.ManagerConfig{
 			KeepAliveInterval: keepAlive,
 			Logger:            logger,
 		}),
-		clk:              clk,
-		log:              logger.WithGroup("companion"),
-		keepAliveEvery:   keepAlive,
-		pendingLogins:    make(map[core.MeshCoreID]uint32),
-		pendingTelemetry: make(map[uint32]core.MeshCoreID),
-		pendingStatus:    make(map[uint32]core.MeshCoreID),
+		clk:            clk,
+		log:            logger.WithGroup("companion"),
+		keepAliveEvery: keepAlive,
+		requestTimeout: ackTimeout,
+		pendingLogins:  make(map[core.MeshCoreID]uint32),
+		pendingReqs:    make(map[uint32]pendingReq),
 	}
 
 	// Watch responses for login-OK correlation and connection liveness.
@@ -262,6 +280,13 @@ func (n *CompanionNode) AdvertScheduler() *advert.Scheduler {
 	return n.advertSched
 }
 
+// ContactsByDistance returns the contacts with a known location, nearest
+// first, measured from the companion's advertised position
+// (CompanionConfig.Lat/Lon). ok is false if it advertises no position.
+func (n *CompanionNode) ContactsByDistance() ([]contact.ContactDistance, bool) {
+	return contactsByDistance(n.base.Contacts(), n.appData)
+}
+
 // AddChannel registers a group channel by its shared key and returns the channel
 // hash. The built-in "Public" channel is registered automatically.
 func (n *CompanionNode) AddChannel(key []byte) uint8 {
@@ -2,6 +2,7 @@ package node
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
@@ -13,49 +14,51 @@ import (
 // server, or sensor). The reply arrives as a TelemetryResponse event. Returns
 // the request tag, which also correlates the response.
 func (n *CompanionNode) SendTelemetryReq(to core.MeshCoreID) (uint32, error) {
-	secret, err := n.base.Contacts().GetSharedSecret(to)
-	if err != nil {
-		return 0, fmt.Errorf("shared secret: %w", err)
-	}
-
-	tag := n.clk.GetCurrentTimeUnique()
 	// Request data is a 4-byte reserved field; the first byte is the inverted
 	// permission mask. Zero requests all telemetry the peer permits.
 	var reqData [4]byte
-	content := codec.BuildRequestContent(tag, codec.ReqTypeGetTelemetry, reqData[:])
-
-	encrypted, err := crypto.EncryptAddressedWithSecret(content, secret)
-	if err != nil {
-		return 0, fmt.Errorf("encrypt telemetry request: %w", err)
-	}
-	mac, ciphertext := codec.SplitMAC(encrypted)
-	selfID := n.base.ID()
-	payload := codec.BuildAddressedPayload(to.Hash(), selfID.Hash(), mac, ciphertext)
-	pkt := codec.NewPacket(codec.PayloadTypeReq, codec.RouteTypeFlood, payload)
-	n.sendToContact(pkt, n.base.Contacts().GetByPubKey(to))
-
-	n.pendingMu.Lock()
-	n.pendingTelemetry[tag] = to
-	n.pendingMu.Unlock()
-
-	return tag, nil
+	return n.sendTaggedReq(to, codec.ReqTypeGetTelemetry, reqData[:])
 }
 
 // SendStatusReq requests device status (RepeaterStats) from a repeater or room
 // server. The reply arrives as a StatusResponse event. Returns the request tag,
 // which also correlates the response.
 func (n *CompanionNode) SendStatusReq(to core.MeshCoreID) (uint32, error) {
+	return n.sendTaggedReq(to, codec.ReqTypeGetStats, nil)
+}
+
+// SendOwnerInfoReq requests owner info (firmware version, name, owner text) from
+// a repeater or room server we are logged in to. The reply arrives as an
+// OwnerInfoResponse event. Returns the request tag.
+func (n *CompanionNode) SendOwnerInfoReq(to core.MeshCoreID) (uint32, error) {
+	return n.sendTaggedReq(to, codec.ReqTypeGetOwnerInfo, nil)
+}
+
+// SendPostHistoryReq requests a page of up to count posts older than before
+// from a room server we are logged in to, for scrollback. A zero before starts
+// from the newest post; a zero count asks for as many posts as fit in one
+// reply. The reply arrives as a PostHistoryResponse event; while its More flag
+// is set, pass the oldest timestamp received as the next before. Returns the
+// request tag.
+func (n *CompanionNode) SendPostHistoryReq(to core.MeshCoreID, before uint32, count uint8) (uint32, error) {
+	return n.sendTaggedReq(to, codec.ReqTypeGetPostHistory, codec.BuildPostHistoryRequest(before, count))
+}
+
+// sendTaggedReq sends a REQ of the given type to a peer and records it as
+// pending, so handleTaggedResponse can turn the matching response into an
+// event. The request's timestamp doubles as its tag, which the peer echoes.
+func (n *CompanionNode) sendTaggedReq(to core.MeshCoreID, reqType uint8, reqData []byte) (uint32, error) {
 	secret, err := n.base.Contacts().GetSharedSecret(to)
 	if err != nil {
 		return 0, fmt.Errorf("shared secret: %w", err)
 	}
 
 	tag := n.clk.GetCurrentTimeUnique()
-	content := codec.BuildRequestContent(tag, codec.ReqTypeGetStats, nil)
+	content := codec.BuildRequestContent(tag, reqType, reqData)
 
 	encrypted, err := crypto.EncryptAddressedWithSecret(content, secret)
 	if err != nil {
-		return 0, fmt.Errorf("encrypt status request: %w", err)
+		return 0, fmt.Errorf("encrypt %s request: %w", codec.RequestTypeName(reqType), err)
 	}
 	mac, ciphertext := codec.SplitMAC(encrypted)
 	selfID := n.base.ID()
@@ -63,51 +66,85 @@ func (n *CompanionNode) SendStatusReq(to core.MeshCoreID) (uint32, error) {
 	pkt := codec.NewPacket(codec.PayloadTypeReq, codec.RouteTypeFlood, payload)
 	n.sendToContact(pkt, n.base.Contacts().GetByPubKey(to))
 
-	n.pendingMu.Lock()
-	n.pendingStatus[tag] = to
-	n.pendingMu.Unlock()
-
+	n.addPending(tag, pendingReq{reqType: reqType, peer: to})
 	return tag, nil
 }
 
-// handleStatusResponse promotes a response matching a pending status request
-// into a StatusResponse event.
-func (n *CompanionNode) handleStatusResponse(e *event.ResponseReceived) {
+// pendingReq is a tagged request awaiting its response.
+type pendingReq struct {
+	reqType uint8 // codec.ReqType*
+	peer    core.MeshCoreID
+	expires time.Time
+}
+
+// addPending records a request sent under tag, pending for requestTimeout.
+// Requests that have timed out unanswered are dropped here, so the map stays
+// bounded on a lossy mesh.
+func (n *CompanionNode) addPending(tag uint32, req pendingReq) {
+	now := time.Now()
+	req.expires = now.Add(n.requestTimeout)
+
 	n.pendingMu.Lock()
-	peer, pending := n.pendingStatus[e.Tag]
-	if pending && peer == e.From {
-		delete(n.pendingStatus, e.Tag)
-	} else {
-		pending = false
-	}
-	n.pendingMu.Unlock()
-	if !pending {
-		return
+	defer n.pendingMu.Unlock()
+	for t, r := range n.pendingReqs {
+		if now.After(r.expires) {
+			delete(n.pendingReqs, t)
+		}
 	}
-
-	n.base.emitEvent(&event.StatusResponse{
-		Event: n.base.baseEvent(e.RawPacket, e.Source, e.From),
-		Data:  e.Content,
-	})
+	n.pendingReqs[tag] = req
 }
 
-// handleTelemetryResponse promotes a response matching a pending telemetry
-// request into a TelemetryResponse event.
-func (n *CompanionNode) handleTelemetryResponse(e *event.ResponseReceived) {
+// takePending removes and returns the request pending under tag, if it was
+// sent to from and has not timed out.
+func (n *CompanionNode) takePending(tag uint32, from core.MeshCoreID) (pendingReq, bool) {
 	n.pendingMu.Lock()
-	peer, pending := n.pendingTelemetry[e.Tag]
-	if pending && peer == e.From {
-		delete(n.pendingTelemetry, e.Tag)
-	} else {
-		pending = false
+	defer n.pendingMu.Unlock()
+	req, ok := n.pendingReqs[tag]
+	if !ok || req.peer != from {
+		return pendingReq{}, false
 	}
-	n.pendingMu.Unlock()
-	if !pending {
-		return
+	delete(n.pendingReqs, tag)
+	if time.Now().After(req.expires) {
+		return pendingReq{}, false
 	}
+	return req, true
+}
 
-	n.base.emitEvent(&event.TelemetryResponse{
-		Event: n.base.baseEvent(e.RawPacket, e.Source, e.From),
-		Data:  e.Content,
-	})
+// handleTaggedResponse promotes a response matching a pending tagged request
+// into the event for its request type.
+func (n *CompanionNode) handleTaggedResponse(e *event.ResponseReceived) {
+	req, ok := n.takePending(e.Tag, e.From)
+	if !ok {
+		return
+	}
+	base := n.base.baseEvent(e.RawPacket, e.Source, e.From)
+
+	switch req.reqType {
+	case codec.ReqTypeGetTelemetry:
+		n.base.emitEvent(&event.TelemetryResponse{Event: base, Data: e.Content})
+
+	case codec.ReqTypeGetStats:
+		n.base.emitEvent(&event.StatusResponse{Event: base, Data: e.Content})
+
+	case codec.ReqTypeGetOwnerInfo:
+		info := codec.ParseOwnerInfo(e.Content)
+		n.base.emitEvent(&event.OwnerInfoResponse{
+			Event:           base,
+			FirmwareVersion: info.FirmwareVersion,
+			Name:            info.Name,
+			Owner:           info.Owner,
+		})
+
+	case codec.ReqTypeGetPostHistory:
+		page, err := codec.ParsePostHistory(e.Content)
+		if err != nil {
+			n.log.Debug("malformed post history response", "peer", e.From.String(), "error", err)
+			return
+		}
+		n.base.emitEvent(&event.PostHistoryResponse{
+			Event: base,
+			Posts: page.Posts,
+			More:  page.More,
+		})
+	}
 }
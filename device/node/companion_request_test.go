@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"testing"
+	"time"
 
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
@@ -120,3 +121,163 @@ func TestCompanionTelemetry_UnmatchedTagIgnored(t *testing.T) {
 		}
 	}
 }
+
+func TestCompanionOwnerInfo(t *testing.T) {
+	comp, compCap := newTestCompanion(t)
+	collector := &eventCollector{}
+	comp.OnEvent(collector.handler)
+
+	skp, _ := crypto.GenerateKeyPair()
+	var serverID core.MeshCoreID
+	copy(serverID[:], skp.PublicKey)
+	if _, err := comp.base.Contacts().AddContact(&contact.ContactInfo{
+		ID:         serverID,
+		Type:       codec.NodeTypeRepeater,
+		OutPathLen: contact.PathUnknown,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := comp.SendOwnerInfoReq(serverID)
+	if err != nil {
+		t.Fatalf("SendOwnerInfoReq: %v", err)
+	}
+	if len(compCap.sent) == 0 || compCap.sent[len(compCap.sent)-1].PayloadType() != codec.PayloadTypeReq {
+		t.Fatal("expected a REQ packet")
+	}
+
+	body := codec.BuildOwnerInfo(codec.OwnerInfo{FirmwareVersion: "v1.16.0", Name: "Hilltop", Owner: "Jo"})
+	resp := make([]byte, 4+len(body))
+	binary.LittleEndian.PutUint32(resp[0:4], tag)
+	copy(resp[4:], body)
+
+	cpub := comp.base.PublicKey()
+	secret, _ := crypto.ComputeSharedSecret(skp.PrivateKey, cpub[:])
+	encrypted, _ := crypto.EncryptAddressedWithSecret(resp, secret)
+	mac, ciphertext := codec.SplitMAC(encrypted)
+	compID := comp.base.ID()
+	payload := codec.BuildAddressedPayload(compID.Hash(), serverID.Hash(), mac, ciphertext)
+	comp.base.processPacket(codec.NewPacket(codec.PayloadTypeResponse, codec.RouteTypeDirect, payload), transport.PacketSourceMQTT)
+
+	var oi *event.OwnerInfoResponse
+	for _, e := range collector.get() {
+		if x, ok := e.(*event.OwnerInfoResponse); ok {
+			oi = x
+		}
+	}
+	if oi == nil {
+		t.Fatal("expected an OwnerInfoResponse event")
+	}
+	if oi.From != serverID || oi.FirmwareVersion != "v1.16.0" || oi.Name != "Hilltop" || oi.Owner != "Jo" {
+		t.Errorf("OwnerInfoResponse = %+v", oi)
+	}
+}
+
+func TestCompanionPostHistory(t *testing.T) {
+	comp, compCap := newTestCompanion(t)
+	collector := &eventCollector{}
+	comp.OnEvent(collector.handler)
+
+	skp, _ := crypto.GenerateKeyPair()
+	var serverID core.MeshCoreID
+	copy(serverID[:], skp.PublicKey)
+	if _, err := comp.base.Contacts().AddContact(&contact.ContactInfo{
+		ID:         serverID,
+		Type:       codec.NodeTypeRoom,
+		OutPathLen: contact.PathUnknown,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := comp.SendPostHistoryReq(serverID, 5000, 3)
+	if err != nil {
+		t.Fatalf("SendPostHistoryReq: %v", err)
+	}
+	if len(compCap.sent) == 0 || compCap.sent[len(compCap.sent)-1].PayloadType() != codec.PayloadTypeReq {
+		t.Fatal("expected a REQ packet")
+	}
+
+	want := codec.PostHistory{More: true, Posts: []codec.PostHistoryEntry{
+		{Timestamp: 4000, SenderPrefix: [4]byte{0xAA}, Text: "older post"},
+	}}
+	body := codec.BuildPostHistory(want)
+	resp := make([]byte, 4+len(body))
+	binary.LittleEndian.PutUint32(resp[0:4], tag)
+	copy(resp[4:], body)
+
+	cpub := comp.base.PublicKey()
+	secret, _ := crypto.ComputeSharedSecret(skp.PrivateKey, cpub[:])
+	encrypted, _ := crypto.EncryptAddressedWithSecret(resp, secret)
+	mac, ciphertext := codec.SplitMAC(encrypted)
+	compID := comp.base.ID()
+	payload := codec.BuildAddressedPayload(compID.Hash(), serverID.Hash(), mac, ciphertext)
+	comp.base.processPacket(codec.NewPacket(codec.PayloadTypeResponse, codec.RouteTypeDirect, payload), transport.PacketSourceMQTT)
+
+	var ph *event.PostHistoryResponse
+	for _, e := range collector.get() {
+		if x, ok := e.(*event.PostHistoryResponse); ok {
+			ph = x
+		}
+	}
+	if ph == nil {
+		t.Fatal("expected a PostHistoryResponse event")
+	}
+	if ph.From != serverID || !ph.More || len(ph.Posts) != 1 || ph.Posts[0] != want.Posts[0] {
+		t.Errorf("PostHistoryResponse = %+v", ph)
+	}
+}
+
+func TestCompanionTaggedRequest_Expires(t *testing.T) {
+	comp, _ := newTestCompanion(t)
+	collector := &eventCollector{}
+	comp.OnEvent(collector.handler)
+
+	skp, _ := crypto.GenerateKeyPair()
+	var serverID core.MeshCoreID
+	copy(serverID[:], skp.PublicKey)
+	if _, err := comp.base.Contacts().AddContact(&contact.ContactInfo{
+		ID:         serverID,
+		Type:       codec.NodeTypeRepeater,
+		OutPathLen: contact.PathUnknown,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Requests sent with a timeout already past are never answered.
+	comp.requestTimeout = -time.Second
+	tag, err := comp.SendStatusReq(serverID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := comp.SendOwnerInfoReq(serverID); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := make([]byte, 8)
+	binary.LittleEndian.PutUint32(resp[0:4], tag)
+	cpub := comp.base.PublicKey()
+	secret, _ := crypto.ComputeSharedSecret(skp.PrivateKey, cpub[:])
+	encrypted, _ := crypto.EncryptAddressedWithSecret(resp, secret)
+	mac, ciphertext := codec.SplitMAC(encrypted)
+	compID := comp.base.ID()
+	payload := codec.BuildAddressedPayload(compID.Hash(), serverID.Hash(), mac, ciphertext)
+	comp.base.processPacket(codec.NewPacket(codec.PayloadTypeResponse, codec.RouteTypeDirect, payload), transport.PacketSourceMQTT)
+
+	for _, e := range collector.get() {
+		if _, ok := e.(*event.StatusResponse); ok {
+			t.Error("a response after the request timed out should not produce an event")
+		}
+	}
+
+	// Sending another request drops the expired ones still pending.
+	comp.requestTimeout = time.Minute
+	if _, err := comp.SendTelemetryReq(serverID); err != nil {
+		t.Fatal(err)
+	}
+	comp.pendingMu.Lock()
+	pending := len(comp.pendingReqs)
+	comp.pendingMu.Unlock()
+	if pending != 1 {
+		t.Errorf("%d requests pending, want 1", pending)
+	}
+}
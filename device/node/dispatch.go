@@ -44,12 +44,19 @@ func (b *BaseNode) processPacket(pkt *codec.Packet, src transport.PacketSource)
 
 // baseEvent constructs the common Event fields.
 func (b *BaseNode) baseEvent(pkt *codec.Packet, src transport.PacketSource, from core.MeshCoreID) event.Event {
-	return event.Event{
+	evt := event.Event{
 		From:      from,
 		Timestamp: time.Now(),
 		RawPacket: pkt,
 		Source:    src,
 	}
+	if pkt != nil && pkt.Rx != nil {
+		evt.Rx = pkt.Rx
+		if !pkt.Rx.Time.IsZero() {
+			evt.Timestamp = pkt.Rx.Time
+		}
+	}
+	return evt
 }
 
 // handleAdvert processes an ADVERT packet: verify signature, update contacts,
@@ -75,6 +82,13 @@ func (b *BaseNode) handleAdvert(pkt *codec.Packet, src transport.PacketSource) {
 		return
 	}
 
+	// A blocked peer's adverts still relay (the router forwards them) but do
+	// not touch the contact store or reach handlers.
+	if b.blocklist.IsBlocked(advertID) {
+		b.log.Debug("advert from blocked peer ignored", "peer", advertID.String())
+		return
+	}
+
 	if b.autoUpdateContacts {
 		nowTS := b.clock.GetCurrentTime()
 		result := contact.ProcessAdvert(b.contacts, advert, nowTS, true)
@@ -85,6 +99,7 @@ func (b *BaseNode) handleAdvert(pkt *codec.Packet, src transport.PacketSource) {
 
 		// Update path from flood route if available
 		if result.Contact != nil {
+			b.recordReceived(pkt, advertID)
 			b.updateContactPathFromFlood(pkt, result.Contact)
 		}
 
@@ -94,6 +109,7 @@ func (b *BaseNode) handleAdvert(pkt *codec.Packet, src transport.PacketSource) {
 			Contact: result.Contact,
 			IsNew:   result.IsNew,
 		})
+		b.emitConflicts(pkt, src, advertID, advert, result)
 	} else {
 		// Even without auto-update, still emit the event
 		b.emitEvent(&event.AdvertReceived{
@@ -103,13 +119,35 @@ func (b *BaseNode) handleAdvert(pkt *codec.Packet, src transport.PacketSource) {
 	}
 }
 
+// emitConflicts emits AdvertConflict if ProcessAdvert found any.
+func (b *BaseNode) emitConflicts(pkt *codec.Packet, src transport.PacketSource, id core.MeshCoreID, advert *codec.AdvertPayload, result contact.AdvertResult) {
+	if len(result.Conflicts) == 0 {
+		return
+	}
+	for _, c := range result.Conflicts {
+		b.log.Info("advert conflict", "peer", id.String(), "name", advert.AppData.Name,
+			"kind", c.Kind.String(), "other", c.Other.ID.String(), "other_name", c.Other.Name)
+	}
+	b.emitEvent(&event.AdvertConflict{
+		Event:     b.baseEvent(pkt, src, id),
+		Advert:    advert,
+		Contact:   result.Contact,
+		Conflicts: result.Conflicts,
+	})
+}
+
 // handleTxtMsg processes an addressed TXT_MSG packet: find sender, decrypt,
 // parse content, auto-ACK, update contact path, then emit TextMessageReceived.
 func (b *BaseNode) handleTxtMsg(pkt *codec.Packet, src transport.PacketSource) {
-	ct, secret, plaintext := b.decryptAddressed(pkt)
+	ct, secret, plaintext, previous := b.decryptAddressed(pkt)
 	if ct == nil {
 		return
 	}
+	// Dropped without an ACK, so the sender sees the message as undelivered.
+	if b.blocklist.IsBlocked(ct.ID) {
+		b.log.Debug("message from blocked peer dropped", "peer", ct.ID.String())
+		return
+	}
 
 	content, err := codec.ParseTxtMsgContent(plaintext)
 	if err != nil {
@@ -120,7 +158,7 @@ func (b *BaseNode) handleTxtMsg(pkt *codec.Packet, src transport.PacketSource) {
 	// Update contact path from flood route
 	b.updateContactPathFromFlood(pkt, ct)
 
-	reply := b.buildReplyContext(pkt, ct, secret)
+	reply := b.buildReplyContext(pkt, ct, secret, previous)
 
 	// Auto-ACK before emitting event (matches firmware behavior).
 	if b.autoACK {
@@ -144,12 +182,16 @@ func (b *BaseNode) handleTxtMsg(pkt *codec.Packet, src transport.PacketSource) {
 				b.sendAckPayload(ct.ID, ackPayload)
 				b.sendExtraAcks(ct, ackPayload)
 			}
-		case codec.TxtTypeSigned:
+		case codec.TxtTypeSigned, codec.TxtTypeFederated:
 			// Signed messages (e.g. a room server pushing a post) are ACKed with
 			// a 4-byte hash keyed by the receiver's own pubkey, over the signed
-			// content.
+			// content. Federated posts between rooms are ACKed the same way.
 			ackData := codec.TrimTxtMsgContent(plaintext, content)
-			ackHash := crypto.ComputeAckHash(ackData, b.id[:])
+			self := b.id
+			if previous {
+				self = b.prevID
+			}
+			ackHash := crypto.ComputeAckHash(ackData, self[:])
 			b.sendAckPayload(ct.ID, codec.BuildAckPayload(ackHash))
 		}
 	}
@@ -162,6 +204,8 @@ func (b *BaseNode) handleTxtMsg(pkt *codec.Packet, src transport.PacketSource) {
 		Attempt:            content.Attempt,
 		Timestamp:          content.Timestamp,
 		SenderPubKeyPrefix: content.SenderPubKeyPrefix,
+		OriginPubKeyPrefix: content.OriginPubKeyPrefix,
+		OriginTimestamp:    content.OriginTimestamp,
 	})
 }
 
@@ -195,15 +239,20 @@ func (b *BaseNode) handleAnonReq(pkt *codec.Packet, src transport.PacketSource)
 		return
 	}
 
-	// Match firmware: drop anon requests not addressed to us.
+	// Match firmware: drop anon requests not addressed to us. During a key
+	// rotation, requests to the previous key are accepted too.
+	key, previous := b.privateKey, false
 	if anonPayload.DestHash != b.id.Hash() {
-		return
+		if !b.previousKeyActive() || anonPayload.DestHash != b.prevID.Hash() {
+			return
+		}
+		key, previous = b.prevKey, true
 	}
 
 	// Decrypt using our private key and the sender's ephemeral public key
 	plaintext, err := crypto.DecryptAnonymous(
 		codec.PrependMAC(anonPayload.MAC, anonPayload.Ciphertext),
-		b.privateKey,
+		key,
 		anonPayload.PubKey[:],
 	)
 	if err != nil {
@@ -212,7 +261,7 @@ func (b *BaseNode) handleAnonReq(pkt *codec.Packet, src transport.PacketSource)
 	}
 
 	// Compute shared secret for reply encryption
-	secret, err := crypto.ComputeSharedSecret(b.privateKey, anonPayload.PubKey[:])
+	secret, err := crypto.ComputeSharedSecret(key, anonPayload.PubKey[:])
 	if err != nil {
 		b.log.Debug("failed to compute shared secret for anon req", "error", err)
 		return
@@ -224,6 +273,7 @@ func (b *BaseNode) handleAnonReq(pkt *codec.Packet, src transport.PacketSource)
 	reply := event.ReplyContext{
 		SharedSecret:  secret,
 		DirectPathLen: contact.PathUnknown,
+		PreviousKey:   previous,
 	}
 	if pkt.IsFlood() && pkt.PathLen > 0 {
 		reply.FloodPath = codec.ReverseFloodPath(pkt)
@@ -240,7 +290,7 @@ func (b *BaseNode) handleAnonReq(pkt *codec.Packet, src transport.PacketSource)
 // handleReq processes an addressed REQ packet: decrypt, parse request header,
 // then emit RequestReceived.
 func (b *BaseNode) handleReq(pkt *codec.Packet, src transport.PacketSource) {
-	ct, secret, plaintext := b.decryptAddressed(pkt)
+	ct, secret, plaintext, previous := b.decryptAddressed(pkt)
 	if ct == nil {
 		return
 	}
@@ -254,7 +304,7 @@ func (b *BaseNode) handleReq(pkt *codec.Packet, src transport.PacketSource) {
 	// Update contact path from flood route
 	b.updateContactPathFromFlood(pkt, ct)
 
-	reply := b.buildReplyContext(pkt, ct, secret)
+	reply := b.buildReplyContext(pkt, ct, secret, previous)
 
 	b.emitEvent(&event.RequestReceived{
 		Event:       b.baseEvent(pkt, src, ct.ID),
@@ -268,7 +318,7 @@ func (b *BaseNode) handleReq(pkt *codec.Packet, src transport.PacketSource) {
 // handleResponse processes an addressed RESPONSE packet: decrypt, parse
 // response header, then emit ResponseReceived.
 func (b *BaseNode) handleResponse(pkt *codec.Packet, src transport.PacketSource) {
-	ct, secret, plaintext := b.decryptAddressed(pkt)
+	ct, secret, plaintext, previous := b.decryptAddressed(pkt)
 	if ct == nil {
 		return
 	}
@@ -282,7 +332,7 @@ func (b *BaseNode) handleResponse(pkt *codec.Packet, src transport.PacketSource)
 	// Update contact path from flood route
 	b.updateContactPathFromFlood(pkt, ct)
 
-	reply := b.buildReplyContext(pkt, ct, secret)
+	reply := b.buildReplyContext(pkt, ct, secret, previous)
 
 	b.emitEvent(&event.ResponseReceived{
 		Event:   b.baseEvent(pkt, src, ct.ID),
@@ -295,7 +345,7 @@ func (b *BaseNode) handleResponse(pkt *codec.Packet, src transport.PacketSource)
 // handlePath processes an addressed PATH packet: decrypt, update contact
 // routing, then emit inner event (for known types) or PathReceived.
 func (b *BaseNode) handlePath(pkt *codec.Packet, src transport.PacketSource) {
-	ct, secret, plaintext := b.decryptAddressed(pkt)
+	ct, secret, plaintext, previous := b.decryptAddressed(pkt)
 	if ct == nil {
 		return
 	}
@@ -310,7 +360,7 @@ func (b *BaseNode) handlePath(pkt *codec.Packet, src transport.PacketSource) {
 	nowTS := b.clock.GetCurrentTime()
 	contact.ProcessPath(b.contacts, ct.ID, pathContent, nowTS)
 
-	reply := b.buildReplyContext(pkt, ct, secret)
+	reply := b.buildReplyContext(pkt, ct, secret, previous)
 
 	// Unwrap known inner types into their own events
 	switch pathContent.ExtraType {
@@ -448,52 +498,74 @@ func (b *BaseNode) handleTraceComplete(pkt *codec.Packet, src transport.PacketSo
 	})
 }
 
+// recordReceived folds a packet heard from a stored contact into its link
+// stats. The packet's SNR and RSSI describe the last hop, so they are only
+// attributed to the contact for a flood packet that reached us without relays,
+// and only when the transport measured them.
+func (b *BaseNode) recordReceived(pkt *codec.Packet, id core.MeshCoreID) {
+	now := b.clock.GetCurrentTime()
+	zeroHop := pkt.IsFlood() && pkt.HopCount() == 0
+	_ = contact.UpdateStats(b.contacts, id, func(s *contact.LinkStats) {
+		s.RecordReceived(now, pkt.GetSNR(), zeroHop && pkt.SNRKnown())
+		if zeroHop && pkt.Rx != nil && pkt.Rx.HasRSSI {
+			s.RecordRSSI(pkt.Rx.RSSI)
+		}
+	})
+}
+
 // decryptAddressed handles the common addressed packet decryption flow:
 // parse addressed header, search contacts by source hash, try decrypting
 // with each candidate's shared secret. Returns the matching contact,
 // shared secret, and decrypted plaintext, or nil if decryption fails.
-func (b *BaseNode) decryptAddressed(pkt *codec.Packet) (*contact.ContactInfo, []byte, []byte) {
+// previous is true if the packet was addressed to the node's previous key
+// during a key rotation.
+func (b *BaseNode) decryptAddressed(pkt *codec.Packet) (ct *contact.ContactInfo, secret, plaintext []byte, previous bool) {
 	if len(pkt.Payload) < codec.AddressedHeaderSize {
-		return nil, nil, nil
+		return nil, nil, nil, false
 	}
 
 	addrPayload, err := codec.ParseAddressedPayload(pkt.Payload)
 	if err != nil {
 		b.log.Debug("failed to parse addressed payload", "error", err)
-		return nil, nil, nil
+		return nil, nil, nil, false
 	}
 
 	// Match firmware's isHashMatch() check: only attempt decrypt if the
 	// destination hash matches our own. Without this, every addressed packet
 	// transiting a busy MQTT broker triggers a candidate search and decrypt
 	// attempt, producing log spam.
-	if addrPayload.DestHash != b.id.Hash() {
-		return nil, nil, nil
+	toCurrent := addrPayload.DestHash == b.id.Hash()
+	toPrevious := b.previousKeyActive() && addrPayload.DestHash == b.prevID.Hash()
+	if !toCurrent && !toPrevious {
+		return nil, nil, nil, false
 	}
 
 	candidates := b.contacts.SearchByHash(addrPayload.SrcHash)
 	if len(candidates) == 0 {
 		b.log.Debug("unknown sender hash", "hash", addrPayload.SrcHash)
-		return nil, nil, nil
+		return nil, nil, nil, false
 	}
 
+	ciphertext := codec.PrependMAC(addrPayload.MAC, addrPayload.Ciphertext)
 	for _, ct := range candidates {
-		secret, err := b.contacts.GetSharedSecret(ct.ID)
-		if err != nil {
-			continue
+		if toCurrent {
+			if secret, err := b.contacts.GetSharedSecret(ct.ID); err == nil {
+				if plaintext, err := crypto.DecryptAddressedWithSecret(ciphertext, secret); err == nil {
+					b.recordReceived(pkt, ct.ID)
+					return ct, secret, plaintext, false
+				}
+			}
 		}
-
-		plaintext, err := crypto.DecryptAddressedWithSecret(
-			codec.PrependMAC(addrPayload.MAC, addrPayload.Ciphertext),
-			secret,
-		)
-		if err != nil {
-			continue
+		if toPrevious {
+			if secret, err := b.previousSecret(ct.ID); err == nil {
+				if plaintext, err := crypto.DecryptAddressedWithSecret(ciphertext, secret); err == nil {
+					b.recordReceived(pkt, ct.ID)
+					return ct, secret, plaintext, true
+				}
+			}
 		}
-
-		return ct, secret, plaintext
 	}
 
 	b.log.Debug("could not decrypt addressed payload")
-	return nil, nil, nil
+	return nil, nil, nil, false
 }
@@ -334,6 +334,78 @@ func TestHandleTxtMsg_SignedAutoACK(t *testing.T) {
 	}
 }
 
+// TestHandleTxtMsg_BlockedPeer verifies that a message from a blocked contact is
+// dropped without an event or an ACK.
+func TestHandleTxtMsg_BlockedPeer(t *testing.T) {
+	node, collector := testNode(t)
+	ct := &captureTransport{}
+	node.Router.AddTransport(ct, transport.PacketSourceMQTT)
+
+	peer := peerKeyPair(t)
+	var peerID core.MeshCoreID
+	copy(peerID[:], peer.PublicKey)
+	if _, err := node.contacts.AddContact(&contact.ContactInfo{
+		ID:         peerID,
+		Name:       "Pest",
+		OutPathLen: contact.PathUnknown,
+	}); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+	if err := node.Blocklist().Block(peerID, 0, ""); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+
+	secret, err := crypto.ComputeSharedSecret(peer.PrivateKey, node.publicKey[:])
+	if err != nil {
+		t.Fatalf("compute shared secret: %v", err)
+	}
+	plaintext := codec.BuildTxtMsgContent(uint32(time.Now().Unix()), codec.TxtTypePlain, 0, "hello?", nil)
+	encrypted, err := crypto.EncryptAddressedWithSecret(plaintext, secret)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	mac, ciphertext := codec.SplitMAC(encrypted)
+	payload := codec.BuildAddressedPayload(node.id.Hash(), peerID.Hash(), mac, ciphertext)
+
+	node.processPacket(codec.NewPacket(codec.PayloadTypeTxtMsg, codec.RouteTypeDirect, payload), transport.PacketSourceMQTT)
+
+	if events := collector.get(); len(events) != 0 {
+		t.Errorf("expected no events, got %d", len(events))
+	}
+	if len(ct.sent) != 0 {
+		t.Errorf("expected no ACK, sent %d packets", len(ct.sent))
+	}
+}
+
+// TestHandleAdvert_BlockedPeer verifies that a blocked peer's advert neither
+// adds a contact nor emits an event.
+func TestHandleAdvert_BlockedPeer(t *testing.T) {
+	node, collector := testNode(t)
+	peer := peerKeyPair(t)
+
+	var pubKey [32]byte
+	copy(pubKey[:], peer.PublicKey)
+	if err := node.Blocklist().Block(core.MeshCoreID(pubKey), 0, "spam"); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+
+	ts := uint32(time.Now().Unix())
+	appData := &codec.AdvertAppData{NodeType: codec.NodeTypeChat, Name: "Pest"}
+	sig, err := crypto.SignAdvert(peer.PrivateKey, pubKey, ts, codec.BuildAdvertAppData(appData))
+	if err != nil {
+		t.Fatalf("sign advert: %v", err)
+	}
+	payload := codec.BuildAdvertPayload(pubKey, ts, sig, appData)
+	node.processPacket(codec.NewPacket(codec.PayloadTypeAdvert, codec.RouteTypeFlood, payload), transport.PacketSourceMQTT)
+
+	if events := collector.get(); len(events) != 0 {
+		t.Errorf("expected no events, got %d", len(events))
+	}
+	if node.contacts.GetByPubKey(core.MeshCoreID(pubKey)) != nil {
+		t.Error("blocked peer was added as a contact")
+	}
+}
+
 func TestHandleAck(t *testing.T) {
 	node, collector := testNode(t)
 
@@ -434,3 +506,138 @@ func TestMultipleEventHandlers(t *testing.T) {
 		t.Error("collector2 should have received event")
 	}
 }
+
+func TestDispatch_RecordsLinkStats(t *testing.T) {
+	node, _ := testNode(t)
+	peer := peerKeyPair(t)
+	var peerID core.MeshCoreID
+	copy(peerID[:], peer.PublicKey)
+	if _, err := node.contacts.AddContact(&contact.ContactInfo{ID: peerID, OutPathLen: contact.PathUnknown}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Heard directly: SNR is the peer's.
+	direct := buildTxtMsgFrom(t, node, peer, codec.RouteTypeFlood, nil, "one")
+	direct.SNR = 24 // 6 dB
+	node.processPacket(direct, transport.PacketSourceMQTT)
+
+	// Relayed: SNR belongs to the relay, and the flood path becomes our route.
+	relayed := buildTxtMsgFrom(t, node, peer, codec.RouteTypeFlood, []byte{0x99}, "two")
+	relayed.SNR = -40
+	node.processPacket(relayed, transport.PacketSourceMQTT)
+
+	st := node.contacts.GetByPubKey(peerID).Stats()
+	if st.Received != 2 || st.SNRCount != 1 || st.SNRLast != 6 {
+		t.Errorf("received/snrCount/snrLast = %d/%d/%v, want 2/1/6", st.Received, st.SNRCount, st.SNRLast)
+	}
+	if st.PathChanges != 1 {
+		t.Errorf("PathChanges = %d, want 1", st.PathChanges)
+	}
+}
+
+func TestHandleAdvert_NameConflict(t *testing.T) {
+	node, collector := testNode(t)
+
+	var other core.MeshCoreID
+	other[0], other[31] = 0x01, 0x02
+	node.contacts.AddContact(&contact.ContactInfo{ID: other, Name: "Gateway", OutPathLen: contact.PathUnknown})
+
+	peer := peerKeyPair(t)
+	var pubKey [32]byte
+	copy(pubKey[:], peer.PublicKey)
+	ts := uint32(time.Now().Unix())
+	appData := &codec.AdvertAppData{NodeType: codec.NodeTypeChat, Name: "gateway"}
+	sig, err := crypto.SignAdvert(peer.PrivateKey, pubKey, ts, codec.BuildAdvertAppData(appData))
+	if err != nil {
+		t.Fatalf("sign advert: %v", err)
+	}
+	payload := codec.BuildAdvertPayload(pubKey, ts, sig, appData)
+	node.processPacket(codec.NewPacket(codec.PayloadTypeAdvert, codec.RouteTypeFlood, payload), transport.PacketSourceMQTT)
+
+	events := collector.get()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if _, ok := events[0].(*event.AdvertReceived); !ok {
+		t.Fatalf("first event = %T, want AdvertReceived", events[0])
+	}
+	conflict, ok := events[1].(*event.AdvertConflict)
+	if !ok {
+		t.Fatalf("second event = %T, want AdvertConflict", events[1])
+	}
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0].Kind != contact.ConflictNameReuse ||
+		conflict.Conflicts[0].Other.ID != other {
+		t.Errorf("conflicts = %+v", conflict.Conflicts)
+	}
+}
+
+// TestHandleTxtMsg_PreviousKey verifies that during a key rotation a message
+// encrypted to the old key is still decrypted and answered from the old
+// identity, until the grace period ends.
+func TestHandleTxtMsg_PreviousKey(t *testing.T) {
+	oldKP, newKP, peer := peerKeyPair(t), peerKeyPair(t), peerKeyPair(t)
+	var oldID, peerID core.MeshCoreID
+	copy(oldID[:], oldKP.PublicKey)
+	copy(peerID[:], peer.PublicKey)
+
+	// A message from peer, encrypted to the old key.
+	secret, err := crypto.ComputeSharedSecret(peer.PrivateKey, oldKP.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := crypto.EncryptAddressedWithSecret(
+		codec.BuildTxtMsgContent(uint32(time.Now().Unix()), codec.TxtTypePlain, 0, "to old key", nil), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac, ciphertext := codec.SplitMAC(encrypted)
+	payload := codec.BuildAddressedPayload(oldID.Hash(), peerID.Hash(), mac, ciphertext)
+
+	newNode := func(until time.Time) (*BaseNode, *eventCollector) {
+		collector := &eventCollector{}
+		node, err := NewBase(BaseConfig{
+			PrivateKey:       newKP.PrivateKey,
+			PreviousKey:      oldKP.PrivateKey,
+			PreviousKeyUntil: until,
+			Contacts:         contact.NewManager(newKP.PrivateKey, contact.ManagerConfig{}),
+			EventHandlers:    []event.Handler{collector.handler},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		node.contacts.AddContact(&contact.ContactInfo{ID: peerID, Name: "Sender", OutPathLen: contact.PathUnknown})
+		return node, collector
+	}
+
+	node, collector := newNode(time.Now().Add(time.Hour))
+	ct := &captureTransport{}
+	node.Router.AddTransport(ct, transport.PacketSourceMQTT)
+	node.processPacket(codec.NewPacket(codec.PayloadTypeTxtMsg, codec.RouteTypeDirect, payload), transport.PacketSourceMQTT)
+
+	msg, ok := collector.last().(*event.TextMessageReceived)
+	if !ok || msg.Message != "to old key" || !msg.Reply.PreviousKey {
+		t.Fatalf("got %#v, want message via previous key", collector.last())
+	}
+	if prev, ok := node.PreviousID(); !ok || prev != oldID {
+		t.Errorf("PreviousID = %v, %v", prev, ok)
+	}
+
+	ct.sent = nil
+	if err := node.SendReply(msg.Reply, peerID, codec.PayloadTypeResponse, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if len(ct.sent) != 1 {
+		t.Fatalf("sent %d packets, want 1", len(ct.sent))
+	}
+	reply, err := codec.ParseAddressedPayload(ct.sent[0].Payload)
+	if err != nil || reply.SrcHash != oldID.Hash() {
+		t.Errorf("reply source hash = %#x, want old identity %#x", reply.SrcHash, oldID.Hash())
+	}
+
+	// After the grace period the old key is no longer honored.
+	node, collector = newNode(time.Now().Add(-time.Second))
+	node.processPacket(codec.NewPacket(codec.PayloadTypeTxtMsg, codec.RouteTypeDirect, payload), transport.PacketSourceMQTT)
+	if len(collector.get()) != 0 {
+		t.Errorf("expired previous key still decrypted: %v", collector.get())
+	}
+}
@@ -30,6 +30,17 @@ type RepeaterConfig struct {
 	// ContactManager is created.
 	Contacts contact.ContactStore
 
+	// Blocklist is the set of peers whose adverts and direct messages the node
+	// ignores, managed with the "block"/"unblock" CLI commands. Use
+	// contact.OpenBlocklist for a list that survives restarts. If nil, an
+	// in-memory list is created.
+	Blocklist *contact.Blocklist
+
+	// PreviousKey and PreviousKeyUntil keep the node answering for its old
+	// key after a key rotation; see BaseConfig.PreviousKey.
+	PreviousKey      ed25519.PrivateKey
+	PreviousKeyUntil time.Time
+
 	// AdminPassword grants admin access on login. Empty disables admin login.
 	AdminPassword string
 
@@ -50,6 +61,15 @@ type RepeaterConfig struct {
 	// encoder. Without it, telemetry requests are ignored.
 	Telemetry telemetry.Provider
 
+	// History, if set together with Telemetry, records periodic samples of the
+	// provider and answers GET_MIN_MAX_AVG requests from them. Use
+	// telemetry.NewMemoryHistory or telemetry.OpenFileHistory.
+	History telemetry.History
+
+	// SampleInterval is how often Telemetry is sampled into History.
+	// Default: telemetry.DefaultSampleInterval (1 minute).
+	SampleInterval time.Duration
+
 	// Version overrides the entire "ver" CLI reply verbatim. Leave it empty to
 	// use the firmware-format reply built from cli.FirmwareVersion and
 	// FirmwareBuildDate, which is what the phone apps expect.
@@ -110,6 +130,7 @@ type RepeaterNode struct {
 	acl             *acl.MemoryStore
 	auth            acl.Authenticator
 	neighbors       *neighborTable
+	sampler         *telemetry.Sampler
 	discoverLimiter *rateLimiter
 	anonLimiter     *rateLimiter
 	cli             *cli.Dispatcher
@@ -144,13 +165,16 @@ func NewRepeater(cfg RepeaterConfig) (*RepeaterNode, error) {
 	}
 
 	base, err := NewBase(BaseConfig{
-		PrivateKey:     cfg.PrivateKey,
-		Contacts:       contacts,
-		Transports:     cfg.Transports,
-		ForwardPackets: true, // always forward
-		AutoACK:        &autoACK,
-		EventHandlers:  cfg.EventHandlers,
-		Logger:         logger,
+		PrivateKey:       cfg.PrivateKey,
+		Contacts:         contacts,
+		Transports:       cfg.Transports,
+		ForwardPackets:   true, // always forward
+		AutoACK:          &autoACK,
+		Blocklist:        cfg.Blocklist,
+		PreviousKey:      cfg.PreviousKey,
+		PreviousKeyUntil: cfg.PreviousKeyUntil,
+		EventHandlers:    cfg.EventHandlers,
+		Logger:           logger,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create base node: %w", err)
@@ -208,6 +232,15 @@ func NewRepeater(cfg RepeaterConfig) (*RepeaterNode, error) {
 		startTime:   time.Now(),
 		log:         logger.WithGroup("repeater"),
 	}
+	if cfg.Telemetry != nil && cfg.History != nil {
+		n.sampler = telemetry.NewSampler(telemetry.SamplerConfig{
+			Provider: cfg.Telemetry,
+			History:  cfg.History,
+			Clock:    clk,
+			Interval: cfg.SampleInterval,
+			Logger:   logger,
+		})
+	}
 	n.cli = n.buildCLI()
 
 	// Wire admin/ACL event handling (login, requests, CLI).
@@ -217,7 +250,8 @@ func NewRepeater(cfg RepeaterConfig) (*RepeaterNode, error) {
 }
 
 // Run starts all components and blocks until ctx is cancelled.
-// Starts: transports, router (with forwarding), advert scheduler.
+// Starts: transports, router (with forwarding), telemetry sampler (if
+// configured), advert scheduler.
 func (n *RepeaterNode) Run(ctx context.Context) error {
 	if err := n.base.StartTransports(ctx); err != nil {
 		return err
@@ -225,6 +259,10 @@ func (n *RepeaterNode) Run(ctx context.Context) error {
 
 	n.base.Router.Start(ctx)
 
+	if n.sampler != nil {
+		go n.sampler.Start(ctx)
+	}
+
 	n.advertSched.SendNow(true)
 	n.advertSched.Start(ctx)
 
@@ -247,6 +285,14 @@ func (n *RepeaterNode) AdvertScheduler() *advert.Scheduler {
 	return n.advertSched
 }
 
+// ContactsByDistance returns the contacts with a known location, nearest
+// first, measured from the repeater's advertised position (as set with
+// RepeaterConfig.Lat/Lon or "set lat"/"set lon"). ok is false if the repeater
+// advertises no position.
+func (n *RepeaterNode) ContactsByDistance() ([]contact.ContactDistance, bool) {
+	return contactsByDistance(n.base.Contacts(), n.appData)
+}
+
 // ACL returns the repeater's client access-control store.
 func (n *RepeaterNode) ACL() *acl.MemoryStore {
 	return n.acl
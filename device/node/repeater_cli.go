@@ -8,9 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
 	"github.com/kabili207/meshcore-go/device/acl"
 	"github.com/kabili207/meshcore-go/device/cli"
+	"github.com/kabili207/meshcore-go/device/contact"
 	"github.com/kabili207/meshcore-go/device/event"
 	"github.com/kabili207/meshcore-go/device/router"
 )
@@ -185,6 +187,8 @@ func (n *RepeaterNode) buildCLI() *cli.Dispatcher {
 	d.Command("advert.zerohop", func([]string) string { n.advertSched.SendNow(false); return "OK" })
 	d.Command("neighbors", func([]string) string { return n.cliNeighbors() })
 	d.Command("neighbor.remove", func(args []string) string { return n.cliNeighborRemove(args) })
+	d.Command("collisions", func([]string) string { return n.cliCollisions() })
+	d.Command("nearby", func(args []string) string { return n.cliNearby(args) })
 	d.Command("discover.neighbors", func([]string) string { n.SendNodeDiscover(); return "OK" })
 	d.Command("stats-packets", func([]string) string { return r.Counters().Snapshot().String() })
 	d.Command("stats-core", func([]string) string { return n.cliStatsCore() })
@@ -198,6 +202,7 @@ func (n *RepeaterNode) buildCLI() *cli.Dispatcher {
 	})
 	d.Command("setperm", func(args []string) string { return n.cliSetPerm(args) })
 	d.Command("region", func(args []string) string { return n.cliRegion(args) })
+	cli.RegisterBlocklist(d, n.base.Blocklist(), n.base.Contacts(), n.base.Clock().GetCurrentTime)
 
 	if n.cfg.OnSettingChanged != nil {
 		d.AfterSet(n.cfg.OnSettingChanged)
@@ -220,15 +225,99 @@ func (n *RepeaterNode) cliNeighbors() string {
 	}
 	var b strings.Builder
 	for _, e := range nb {
-		fmt.Fprintf(&b, "%s snr=%.2f\n", e.id.String()[:12], float32(e.snr)/4.0)
+		fmt.Fprintf(&b, "%s snr=%.2f", e.id.String()[:12], float32(e.snr)/4.0)
+		if e.hasRSSI {
+			fmt.Fprintf(&b, " rssi=%d", e.rssi)
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// defaultNearbyCount is how many contacts "nearby" lists without an argument.
+const defaultNearbyCount = 8
+
+// cliNearby lists the located contacts nearest the repeater's own position,
+// one per line as "<id> <km>km [name]": "nearby [count]".
+func (n *RepeaterNode) cliNearby(args []string) string {
+	count := defaultNearbyCount
+	if len(args) > 0 {
+		v, err := strconv.Atoi(args[0])
+		if err != nil || v <= 0 {
+			return "Error: usage: nearby [count]"
+		}
+		count = v
+	}
+	near, ok := n.ContactsByDistance()
+	if !ok {
+		return "ERR: no location set"
+	}
+	if len(near) == 0 {
+		return "(no located contacts)"
+	}
+	if len(near) > count {
+		near = near[:count]
+	}
+	var b strings.Builder
+	for _, cd := range near {
+		fmt.Fprintf(&b, "%s %.1fkm", cd.Contact.ID.String()[:12], cd.DistanceKm)
+		if cd.Contact.Name != "" {
+			b.WriteString(" " + cd.Contact.Name)
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// cliCollisions reports the 1-byte hashes shared by more than one known key,
+// across contacts and neighbors, one hash per line as
+// "<hash>: <id> (<name>, fav, neighbor) ...".
+func (n *RepeaterNode) cliCollisions() string {
+	nb := n.neighbors.snapshot(neighborOrderNewest)
+	isNeighbor := make(map[core.MeshCoreID]bool, len(nb))
+	ids := make([]core.MeshCoreID, 0, len(nb))
+	for _, e := range nb {
+		isNeighbor[e.id] = true
+		ids = append(ids, e.id)
+	}
+	store := n.base.Contacts()
+	groups := contact.HashCollisions(store, ids...)
+	if len(groups) == 0 {
+		return "(no collisions)"
+	}
+	var b strings.Builder
+	for _, g := range groups {
+		fmt.Fprintf(&b, "%02x:", g.Hash)
+		for _, id := range g.IDs {
+			var tags []string
+			if c := store.GetByPubKey(id); c != nil {
+				if c.Name != "" {
+					tags = append(tags, c.Name)
+				}
+				if c.IsFavorite() {
+					tags = append(tags, "fav")
+				}
+			}
+			if isNeighbor[id] {
+				tags = append(tags, "neighbor")
+			}
+			fmt.Fprintf(&b, " %s", id.String()[:12])
+			if len(tags) > 0 {
+				fmt.Fprintf(&b, " (%s)", strings.Join(tags, ", "))
+			}
+		}
+		b.WriteByte('\n')
 	}
 	return strings.TrimRight(b.String(), "\n")
 }
 
 // cliACL dumps the ACL client table, one client per line as "<id> perms=<n>".
-func (n *RepeaterNode) cliACL() string {
+func (n *RepeaterNode) cliACL() string { return formatACL(n.acl) }
+
+// formatACL renders an ACL client table for the "get acl" CLI key.
+func formatACL(store *acl.MemoryStore) string {
 	var b strings.Builder
-	n.acl.ForEach(func(c *acl.Client) bool {
+	store.ForEach(func(c *acl.Client) bool {
 		fmt.Fprintf(&b, "%s perms=%d\n", c.ID.String()[:12], c.Permissions)
 		return true
 	})
@@ -262,7 +351,11 @@ func (n *RepeaterNode) cliNeighborRemove(args []string) string {
 }
 
 // cliSetPerm sets a client's permissions by public-key prefix.
-func (n *RepeaterNode) cliSetPerm(args []string) string {
+func (n *RepeaterNode) cliSetPerm(args []string) string { return setPermByPrefix(n.acl, args) }
+
+// setPermByPrefix implements the "setperm <pubkey-hex> <permissions>" command
+// against store.
+func setPermByPrefix(store *acl.MemoryStore, args []string) string {
 	if len(args) < 2 {
 		return "Error: usage: setperm <pubkey-hex> <permissions>"
 	}
@@ -276,8 +369,8 @@ func (n *RepeaterNode) cliSetPerm(args []string) string {
 	}
 
 	var matched *acl.Client
-	n.acl.ForEach(func(c *acl.Client) bool {
-		if len(prefix) <= len(c.ID) && matchesPrefix(c.ID[:], prefix) {
+	store.ForEach(func(c *acl.Client) bool {
+		if c.ID.IsHashMatch(prefix) {
 			matched = c
 			return false
 		}
@@ -288,7 +381,7 @@ func (n *RepeaterNode) cliSetPerm(args []string) string {
 	}
 	matched.Permissions = uint8(perm)
 	// Persist the permission change (UpdateClient mirrors to the backend).
-	_ = n.acl.UpdateClient(matched)
+	_ = store.UpdateClient(matched)
 	return "OK"
 }
 
@@ -350,15 +443,3 @@ func onOff(b bool) string {
 	}
 	return "off"
 }
-
-func matchesPrefix(full, prefix []byte) bool {
-	if len(prefix) > len(full) {
-		return false
-	}
-	for i, b := range prefix {
-		if full[i] != b {
-			return false
-		}
-	}
-	return true
-}
@@ -10,6 +10,7 @@ import (
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/crypto"
 	"github.com/kabili207/meshcore-go/device/cli"
+	"github.com/kabili207/meshcore-go/device/contact"
 )
 
 // Without the opt-in callbacks, clock-setting and reboot report unsupported.
@@ -226,3 +227,75 @@ func TestRepeaterCLI_Stats(t *testing.T) {
 		t.Errorf("stats-radio = %q, want unsupported", got)
 	}
 }
+
+func TestRepeaterCLI_Block(t *testing.T) {
+	n, _ := newTestRepeater(t, "adminpw", "guestpw")
+	var id core.MeshCoreID
+	id[0], id[31] = 0xCD, 0x01
+	if got := n.cli.Execute("block " + id.String() + " spammer"); got != "OK" {
+		t.Fatalf("block = %q, want OK", got)
+	}
+	if !n.Base().Blocklist().IsBlocked(id) {
+		t.Fatal("peer not on the node's blocklist")
+	}
+	if got := n.cli.Execute("blocked"); !strings.Contains(got, id.String()[:12]) || !strings.Contains(got, "spammer") {
+		t.Errorf("blocked = %q", got)
+	}
+	if got := n.cli.Execute("unblock cd"); got != "OK" {
+		t.Errorf("unblock = %q, want OK", got)
+	}
+	if n.Base().Blocklist().IsBlocked(id) {
+		t.Error("peer still blocked")
+	}
+}
+
+func TestRepeaterCLI_Collisions(t *testing.T) {
+	n, _ := newTestRepeater(t, "adminpw", "guestpw")
+	if got := n.cli.Execute("collisions"); got != "(no collisions)" {
+		t.Errorf("empty = %q", got)
+	}
+
+	var a, b core.MeshCoreID
+	a[0], a[31] = 0x7E, 0x01
+	b[0], b[31] = 0x7E, 0x02
+	fav := &contact.ContactInfo{ID: a, Name: "Hill", OutPathLen: contact.PathUnknown}
+	fav.SetFavorite(true)
+	n.Base().Contacts().AddContact(fav)
+	n.neighbors.put(b, 0, 100, 40)
+
+	want := "7e: " + a.String()[:12] + " (Hill, fav) " + b.String()[:12] + " (neighbor)"
+	if got := n.cli.Execute("collisions"); got != want {
+		t.Errorf("collisions = %q, want %q", got, want)
+	}
+}
+
+func TestRepeaterCLI_Nearby(t *testing.T) {
+	n, _ := newTestRepeater(t, "adminpw", "guestpw")
+	if got := n.cli.Execute("nearby"); got != "ERR: no location set" {
+		t.Errorf("no position = %q", got)
+	}
+	if _, ok := n.ContactsByDistance(); ok {
+		t.Error("ContactsByDistance ok without a position")
+	}
+
+	n.cli.Execute("set lat 47.6")
+	n.cli.Execute("set lon -122.3")
+	if got := n.cli.Execute("nearby"); got != "(no located contacts)" {
+		t.Errorf("empty = %q", got)
+	}
+
+	far := &contact.ContactInfo{ID: core.MeshCoreID{0x01}, Name: "Far", OutPathLen: contact.PathUnknown,
+		GPSLat: 48_600_000, GPSLon: -122_300_000}
+	near := &contact.ContactInfo{ID: core.MeshCoreID{0x02}, Name: "Near", OutPathLen: contact.PathUnknown,
+		GPSLat: 47_610_000, GPSLon: -122_300_000}
+	n.Base().Contacts().AddContact(far)
+	n.Base().Contacts().AddContact(near)
+
+	want := near.ID.String()[:12] + " 1.1km Near\n" + far.ID.String()[:12] + " 111.2km Far"
+	if got := n.cli.Execute("nearby"); got != want {
+		t.Errorf("nearby = %q, want %q", got, want)
+	}
+	if got := n.cli.Execute("nearby 1"); !strings.HasSuffix(got, "Near") || strings.Contains(got, "\n") {
+		t.Errorf("nearby 1 = %q", got)
+	}
+}
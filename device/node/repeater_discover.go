@@ -87,7 +87,7 @@ func (n *RepeaterNode) handleDiscoverResp(pkt *codec.Packet, ctrl *codec.Control
 		return
 	}
 	now := n.base.Clock().GetCurrentTime()
-	n.neighbors.put(id, now, now, pkt.SNR)
+	n.neighbors.putHeard(id, now, now, pkt)
 }
 
 // SendNodeDiscover broadcasts a zero-hop request for nearby repeaters. Matching
@@ -26,6 +26,8 @@ type neighborInfo struct {
 	advertTimestamp uint32
 	heardTimestamp  uint32 // our clock, seconds
 	snr             int8   // SNR x4
+	rssi            int16  // dBm, if hasRSSI
+	hasRSSI         bool
 }
 
 // neighborTable tracks directly-heard repeater neighbors, evicting the
@@ -45,19 +47,31 @@ func newNeighborTable(max int) *neighborTable {
 
 // put records or updates a neighbor.
 func (t *neighborTable) put(id core.MeshCoreID, advertTS, heardTS uint32, snr int8) {
+	t.putEntry(neighborInfo{id: id, advertTimestamp: advertTS, heardTimestamp: heardTS, snr: snr})
+}
+
+// putHeard records or updates a neighbor from a packet heard directly from
+// it, taking the signal data from the packet.
+func (t *neighborTable) putHeard(id core.MeshCoreID, advertTS, heardTS uint32, pkt *codec.Packet) {
+	e := neighborInfo{id: id, advertTimestamp: advertTS, heardTimestamp: heardTS, snr: pkt.SNR}
+	if pkt.Rx != nil && pkt.Rx.HasRSSI {
+		e.rssi, e.hasRSSI = pkt.Rx.RSSI, true
+	}
+	t.putEntry(e)
+}
+
+func (t *neighborTable) putEntry(e neighborInfo) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	for _, n := range t.list {
-		if n.id == id {
-			n.advertTimestamp = advertTS
-			n.heardTimestamp = heardTS
-			n.snr = snr
+		if n.id == e.id {
+			*n = e
 			return
 		}
 	}
 
-	entry := &neighborInfo{id: id, advertTimestamp: advertTS, heardTimestamp: heardTS, snr: snr}
+	entry := &e
 	if len(t.list) < t.max {
 		t.list = append(t.list, entry)
 		return
@@ -101,15 +115,16 @@ func (t *neighborTable) count() int {
 	return len(t.list)
 }
 
-// remove drops every neighbor whose ID begins with prefix, returning how many
-// were removed.
+// remove drops every neighbor whose ID begins with prefix (any length, as
+// contact.ContactStore.SearchByPrefix matches), returning how many were
+// removed.
 func (t *neighborTable) remove(prefix []byte) int {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	kept := t.list[:0]
 	removed := 0
 	for _, n := range t.list {
-		if matchesPrefix(n.id[:], prefix) {
+		if n.id.IsHashMatch(prefix) {
 			removed++
 			continue
 		}
@@ -133,7 +148,7 @@ func (n *RepeaterNode) recordNeighbor(evt *event.AdvertReceived) {
 		return // only repeater neighbors
 	}
 	now := n.base.Clock().GetCurrentTime()
-	n.neighbors.put(evt.From, evt.Advert.Timestamp, now, pkt.SNR)
+	n.neighbors.putHeard(evt.From, evt.Advert.Timestamp, now, pkt)
 }
 
 // isShareAdvert reports whether a packet is a "share" (transport codes {0,0},
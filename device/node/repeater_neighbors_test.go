@@ -49,6 +49,21 @@ func TestRepeaterNeighbor_RecordsZeroHopRepeater(t *testing.T) {
 	}
 }
 
+func TestRepeaterNeighbor_RecordsRSSI(t *testing.T) {
+	n, _ := newTestRepeater(t, "adminpw", "guestpw")
+	peer, _ := crypto.GenerateKeyPair()
+
+	pkt := buildRepeaterAdvert(t, peer, 500, codec.NodeTypeRepeater)
+	pkt.SetSNR(7.5)
+	pkt.Rx.RSSI, pkt.Rx.HasRSSI = -97, true
+	n.base.processPacket(pkt, transport.PacketSourceMQTT)
+
+	snap := n.neighbors.snapshot(neighborOrderNewest)
+	if len(snap) != 1 || !snap[0].hasRSSI || snap[0].rssi != -97 || snap[0].snr != 30 {
+		t.Fatalf("neighbors = %+v, want rssi -97 snr 30", snap)
+	}
+}
+
 func TestRepeaterNeighbor_IgnoresNonRepeater(t *testing.T) {
 	n, _ := newTestRepeater(t, "adminpw", "guestpw")
 	peer, _ := crypto.GenerateKeyPair()
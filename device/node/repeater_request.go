@@ -7,6 +7,7 @@ import (
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
 	"github.com/kabili207/meshcore-go/device/acl"
+	"github.com/kabili207/meshcore-go/device/cli"
 	"github.com/kabili207/meshcore-go/device/event"
 	"github.com/kabili207/meshcore-go/device/telemetry"
 )
@@ -55,6 +56,13 @@ func (n *RepeaterNode) handleRequest(evt *event.RequestReceived) {
 	case codec.ReqTypeGetTelemetry:
 		// Any authenticated client may read telemetry; guests get base only.
 		n.sendTelemetry(evt.Reply, evt.From, evt.Tag, evt.RequestData, client)
+	case codec.ReqTypeGetMinMaxAvg:
+		// Read-only or better (firmware); guests get no reply.
+		n.sendMinMaxAvg(evt.Reply, evt.From, evt.Tag, evt.RequestData, client)
+	case codec.ReqTypeGetOwnerInfo:
+		// Any authenticated client may read owner info (firmware allows guests;
+		// the same text is served to anonymous owner queries).
+		n.sendOwnerInfo(evt.Reply, evt.From, evt.Tag)
 	default:
 		n.log.Debug("unhandled repeater request", "type", evt.RequestType, "peer", evt.From.String())
 	}
@@ -103,18 +111,60 @@ func (n *RepeaterNode) sendTelemetry(reply event.ReplyContext, to core.MeshCoreI
 	}
 }
 
+// sendMinMaxAvg replies to REQ_TYPE_GET_AVG_MIN_MAX with tag(4) + the per-channel
+// min/max/avg of the recorded history over the requested window. Requires a
+// configured History.
+func (n *RepeaterNode) sendMinMaxAvg(reply event.ReplyContext, to core.MeshCoreID, tag uint32, reqData []byte, client *acl.Client) {
+	if n.cfg.History == nil {
+		return
+	}
+	body, ok := telemetry.QueryMinMaxAvg(n.cfg.History, n.base.Clock().GetCurrentTime(), reqData, client.Permissions)
+	if !ok {
+		return
+	}
+	resp := make([]byte, 4+len(body))
+	binary.LittleEndian.PutUint32(resp[0:4], tag)
+	copy(resp[4:], body)
+	if err := n.base.SendReply(reply, to, codec.PayloadTypeResponse, resp); err != nil {
+		n.log.Warn("failed to send min/max/avg", "error", err)
+	}
+}
+
+// sendOwnerInfo replies to REQ_TYPE_GET_OWNER_INFO with tag(4) +
+// "version\nname\nowner_info".
+func (n *RepeaterNode) sendOwnerInfo(reply event.ReplyContext, to core.MeshCoreID, tag uint32) {
+	body := codec.BuildOwnerInfo(codec.OwnerInfo{
+		FirmwareVersion: cli.FirmwareVersion,
+		Name:            n.appData.Name,
+		Owner:           n.cfg.OwnerInfo,
+	})
+	resp := make([]byte, 4+len(body))
+	binary.LittleEndian.PutUint32(resp[0:4], tag)
+	copy(resp[4:], body)
+	if err := n.base.SendReply(reply, to, codec.PayloadTypeResponse, resp); err != nil {
+		n.log.Warn("failed to send owner info", "error", err)
+	}
+}
+
 // sendAccessList replies to REQ_TYPE_GET_ACCESS_LIST (admin only) with a
-// tag-prefixed list of [6-byte pubkey prefix][permissions] entries. Deleted or
-// guest (permissions == 0) entries are skipped, matching the firmware.
+// tag-prefixed list of [6-byte pubkey prefix][permissions] entries.
 func (n *RepeaterNode) sendAccessList(reply event.ReplyContext, to core.MeshCoreID, tag uint32, reqData []byte) {
 	if len(reqData) >= 2 && (reqData[0] != 0 || reqData[1] != 0) {
 		return // reserved params must be zero
 	}
+	resp := buildAccessList(n.acl, tag)
+	if err := n.base.SendReply(reply, to, codec.PayloadTypeResponse, resp); err != nil {
+		n.log.Warn("failed to send access list", "error", err)
+	}
+}
 
+// buildAccessList builds a tag-prefixed GET_ACCESS_LIST reply from store. Deleted
+// or guest (permissions == 0) entries are skipped, matching the firmware.
+func buildAccessList(store *acl.MemoryStore, tag uint32) []byte {
 	resp := make([]byte, 4, maxRepeaterReplySize)
 	binary.LittleEndian.PutUint32(resp[0:4], tag)
 
-	n.acl.ForEach(func(c *acl.Client) bool {
+	store.ForEach(func(c *acl.Client) bool {
 		if c.Permissions == 0 {
 			return true
 		}
@@ -127,10 +177,7 @@ func (n *RepeaterNode) sendAccessList(reply event.ReplyContext, to core.MeshCore
 		resp = append(resp, entry...)
 		return true
 	})
-
-	if err := n.base.SendReply(reply, to, codec.PayloadTypeResponse, resp); err != nil {
-		n.log.Warn("failed to send access list", "error", err)
-	}
+	return resp
 }
 
 // sendNeighbors replies to REQ_TYPE_GET_NEIGHBOURS with a tag-prefixed, sorted,
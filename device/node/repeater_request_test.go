@@ -149,3 +149,39 @@ func TestRepeaterRequest_NonClientIgnored(t *testing.T) {
 		t.Error("request from a non-ACL client should be ignored")
 	}
 }
+
+func TestRepeaterRequest_OwnerInfo(t *testing.T) {
+	n, ct := newTestRepeater(t, "adminpw", "guestpw")
+	n.appData.Name = "Hilltop"
+	n.cfg.OwnerInfo = "Jo, callsign XX0XX"
+	client, _ := crypto.GenerateKeyPair()
+	n.base.processPacket(buildRepeaterLogin(t, n, client, 100, "guestpw"), transport.PacketSourceMQTT)
+
+	req := buildRepeaterReq(t, n, client, 200, codec.ReqTypeGetOwnerInfo, nil)
+	n.base.processPacket(req, transport.PacketSourceMQTT)
+
+	resp := lastResponse(ct)
+	if resp == nil {
+		t.Fatal("expected an owner-info response")
+	}
+	pt := decryptRepeaterResponse(t, n, client, resp)
+	if tag := binary.LittleEndian.Uint32(pt[0:4]); tag != 200 {
+		t.Errorf("tag = %d, want 200", tag)
+	}
+	info := codec.ParseOwnerInfo(pt[4:])
+	if info.FirmwareVersion == "" || info.Name != "Hilltop" || info.Owner != "Jo, callsign XX0XX" {
+		t.Errorf("owner info = %+v", info)
+	}
+}
+
+func TestRepeaterRequest_OwnerInfoNonClient(t *testing.T) {
+	n, ct := newTestRepeater(t, "adminpw", "guestpw")
+	client, _ := crypto.GenerateKeyPair()
+	id := clientID(client)
+	n.base.Contacts().AddContact(&contact.ContactInfo{ID: id, OutPathLen: contact.PathUnknown})
+
+	n.base.processPacket(buildRepeaterReq(t, n, client, 200, codec.ReqTypeGetOwnerInfo, nil), transport.PacketSourceMQTT)
+	if countResponses(ct) != 0 {
+		t.Error("non-client should not receive owner info")
+	}
+}
@@ -83,3 +83,48 @@ func TestRepeaterTelemetry_NoProvider(t *testing.T) {
 		t.Errorf("responses = %d, want %d (no provider adds nothing)", got, before)
 	}
 }
+
+func TestRepeaterMinMaxAvg(t *testing.T) {
+	n, ct := newTestRepeater(t, "adminpw", "guestpw")
+	hist := telemetry.NewMemoryHistory(0)
+	n.cfg.History = hist
+	n.base.Clock().SetCurrentTime(2000)
+	_ = hist.Add([]telemetry.Sample{
+		{Timestamp: 1900, Reading: telemetry.Reading{Channel: telemetry.ChannelSelf, Type: telemetry.LPPVoltage, Value: 3.9}},
+		{Timestamp: 1950, Reading: telemetry.Reading{Channel: telemetry.ChannelSelf, Type: telemetry.LPPVoltage, Value: 4.1}},
+	})
+	client, _ := crypto.GenerateKeyPair()
+	loginAdmin(t, n, client)
+
+	req := buildRepeaterReq(t, n, client, 200, codec.ReqTypeGetMinMaxAvg, telemetry.BuildMinMaxAvgRequest(300, 0))
+	n.base.processPacket(req, transport.PacketSourceMQTT)
+
+	resp := lastResponse(ct)
+	if resp == nil {
+		t.Fatal("expected a min/max/avg response")
+	}
+	pt := decryptRepeaterResponse(t, n, client, resp)
+	if tag := binary.LittleEndian.Uint32(pt[0:4]); tag != 200 {
+		t.Errorf("tag = %d, want 200", tag)
+	}
+	_, stats, err := telemetry.ParseMinMaxAvg(pt[4 : 4+4+2+3*2])
+	if err != nil || len(stats) != 1 {
+		t.Fatalf("ParseMinMaxAvg = %+v, %v", stats, err)
+	}
+	if s := stats[0]; s.Min != 3.9 || s.Max != 4.1 || s.Avg != 4 {
+		t.Errorf("stats = %+v, want 3.9/4.1/4", s)
+	}
+}
+
+func TestRepeaterMinMaxAvg_NoHistory(t *testing.T) {
+	n, ct := newTestRepeater(t, "adminpw", "guestpw")
+	client, _ := crypto.GenerateKeyPair()
+	loginAdmin(t, n, client)
+	before := countResponses(ct)
+
+	req := buildRepeaterReq(t, n, client, 200, codec.ReqTypeGetMinMaxAvg, telemetry.BuildMinMaxAvgRequest(300, 0))
+	n.base.processPacket(req, transport.PacketSourceMQTT)
+	if countResponses(ct) != before {
+		t.Error("expected no response without a configured history")
+	}
+}
@@ -23,6 +23,11 @@ type RoomConfig struct {
 	// PrivateKey is the node's Ed25519 private key (64 bytes: seed + pubkey).
 	PrivateKey ed25519.PrivateKey
 
+	// PreviousKey and PreviousKeyUntil keep the node answering for its old
+	// key after a key rotation; see BaseConfig.PreviousKey.
+	PreviousKey      ed25519.PrivateKey
+	PreviousKeyUntil time.Time
+
 	// Transports to connect to the mesh network.
 	Transports []TransportOption
 
@@ -60,6 +65,10 @@ type RoomConfig struct {
 	// ForwardPackets enables packet relaying. Default: true for room servers.
 	ForwardPackets *bool
 
+	// Bridge, if set, links the room to a group channel: new posts are
+	// mirrored to the channel and channel messages become room posts.
+	Bridge *ChannelBridgeConfig
+
 	// EventHandlers registered during construction.
 	EventHandlers []event.Handler
 
@@ -75,6 +84,7 @@ type RoomNode struct {
 	server      *room.Server
 	ackTracker  *ack.Tracker
 	advertSched *advert.Scheduler
+	bridge      *ChannelBridge
 	clk         *clock.Clock
 	log         *slog.Logger
 }
@@ -115,15 +125,18 @@ func NewRoom(cfg RoomConfig) (*RoomNode, error) {
 	}
 
 	base, err := NewBase(BaseConfig{
-		PrivateKey:     cfg.PrivateKey,
-		Contacts:       cfg.Contacts,
-		Clock:          clk,
-		ACKTracker:     tracker,
-		Router:         cfg.Router,
-		Transports:     cfg.Transports,
-		ForwardPackets: forwardPackets,
-		EventHandlers:  cfg.EventHandlers,
-		Logger:         logger,
+		PrivateKey:       cfg.PrivateKey,
+		PreviousKey:      cfg.PreviousKey,
+		PreviousKeyUntil: cfg.PreviousKeyUntil,
+		Contacts:         cfg.Contacts,
+		Clock:            clk,
+		ACKTracker:       tracker,
+		Router:           cfg.Router,
+		Transports:       cfg.Transports,
+		ForwardPackets:   forwardPackets,
+		Blocklist:        cfg.Room.Blocklist,
+		EventHandlers:    cfg.EventHandlers,
+		Logger:           logger,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create base node: %w", err)
@@ -133,17 +146,35 @@ func NewRoom(cfg RoomConfig) (*RoomNode, error) {
 	// Fill in identity fields from our base node.
 	roomCfg := cfg.Room
 	roomCfg.PrivateKey = cfg.PrivateKey
+	roomCfg.PreviousKey = cfg.PreviousKey
+	roomCfg.PreviousKeyUntil = cfg.PreviousKeyUntil
 	roomCfg.PublicKey = base.PublicKey()
 	roomCfg.Clock = clk
 	roomCfg.Contacts = cfg.Contacts
 	roomCfg.ACKTracker = tracker
 	roomCfg.Router = base.Router
+	roomCfg.Blocklist = base.Blocklist()
 	if roomCfg.Logger == nil {
 		roomCfg.Logger = logger
 	}
 
+	var bridge *ChannelBridge
+	if cfg.Bridge != nil {
+		bridge = newChannelBridge(*cfg.Bridge, base, logger)
+		userHook := roomCfg.OnPostAdded
+		roomCfg.OnPostAdded = func(p *room.PostInfo) {
+			if userHook != nil {
+				userHook(p)
+			}
+			bridge.onPost(p)
+		}
+	}
+
 	srv := room.NewServer(roomCfg)
 	srv.SetSender(base) // BaseNode implements room.NodeSender
+	if bridge != nil {
+		bridge.server = srv
+	}
 
 	// Share the AppData pointer between the room server's CLI handlers and
 	// the advert builder so that "set name/lat/lon" commands take effect on
@@ -186,6 +217,7 @@ func NewRoom(cfg RoomConfig) (*RoomNode, error) {
 		server:      srv,
 		ackTracker:  tracker,
 		advertSched: scheduler,
+		bridge:      bridge,
 		clk:         clk,
 		log:         logger.WithGroup("room-node"),
 	}
@@ -225,6 +257,10 @@ func (n *RoomNode) dispatchToServer(evt any) {
 		n.server.HandlePath(e)
 	case *event.AdvertReceived:
 		n.server.HandleAdvertReceived(e)
+	case *event.GroupTextReceived:
+		if n.bridge != nil {
+			n.bridge.onGroupText(e)
+		}
 	}
 }
 
@@ -236,14 +272,19 @@ func (n *RoomNode) Run(ctx context.Context) error {
 	}
 
 	n.base.Router.Start(ctx)
+	n.startServices(ctx)
+	return nil
+}
 
+// startServices starts the per-identity loops: ACK tracker, room server sync
+// loop, and advert scheduler. RoomHost calls it for each tenant after starting
+// the shared transports and router once.
+func (n *RoomNode) startServices(ctx context.Context) {
 	go n.ackTracker.Start(ctx)
 	go n.server.Start(ctx)
 
 	n.advertSched.SendNow(true)
 	n.advertSched.Start(ctx)
-
-	return nil
 }
 
 // OnEvent registers an event handler. Delegates to BaseNode.
@@ -283,6 +324,9 @@ func (n *RoomNode) ClearSendScope() {
 	n.base.Router.ClearSendScope()
 }
 
+// Bridge returns the room's channel bridge, or nil if none is configured.
+func (n *RoomNode) Bridge() *ChannelBridge { return n.bridge }
+
 // AdvertScheduler returns the advert scheduler for manual control.
 func (n *RoomNode) AdvertScheduler() *advert.Scheduler {
 	return n.advertSched
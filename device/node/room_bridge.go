@@ -0,0 +1,196 @@
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/event"
+	"github.com/kabili207/meshcore-go/device/room"
+)
+
+const (
+	// DefaultBridgeRateLimit is how many messages a ChannelBridge forwards in
+	// each direction per BridgeRateWindow when no limit is configured.
+	DefaultBridgeRateLimit = 10
+
+	// DefaultBridgeRateWindow is the rate-limit window of a ChannelBridge.
+	DefaultBridgeRateWindow = time.Minute
+
+	// DefaultBridgeEchoWindow is how long a ChannelBridge remembers the text it
+	// sent to the channel, to recognise the same text coming back.
+	DefaultBridgeEchoWindow = 5 * time.Minute
+)
+
+// BridgeDirection selects which way a ChannelBridge forwards messages.
+type BridgeDirection int
+
+const (
+	// BridgeBoth mirrors room posts to the channel and channel messages to the room.
+	BridgeBoth BridgeDirection = iota
+	// BridgeToChannel only mirrors room posts to the channel.
+	BridgeToChannel
+	// BridgeFromChannel only ingests channel messages as room posts.
+	BridgeFromChannel
+)
+
+// ChannelBridgeConfig configures a RoomNode's bridge between its room and a
+// group channel.
+type ChannelBridgeConfig struct {
+	// Key is the channel's pre-shared key. Required.
+	Key []byte
+
+	// Direction selects which way messages are forwarded. Default: BridgeBoth.
+	Direction BridgeDirection
+
+	// SenderID is the synthetic author of posts ingested from the channel, so
+	// room clients see them as coming from one "channel" member. The zero
+	// value derives it from the key (SHA-256 of the key, so its one-byte
+	// prefix is the channel hash).
+	SenderID core.MeshCoreID
+
+	// RateLimit caps messages forwarded per RateWindow, separately in each
+	// direction; excess messages are dropped. Default: DefaultBridgeRateLimit.
+	RateLimit uint16
+
+	// RateWindow is the window RateLimit applies to.
+	// Default: DefaultBridgeRateWindow (1 minute).
+	RateWindow time.Duration
+
+	// EchoWindow is how long text sent to the channel is remembered; the same
+	// text heard back on the channel within it is not re-ingested.
+	// Default: DefaultBridgeEchoWindow (5 minutes).
+	EchoWindow time.Duration
+}
+
+// ChannelBridge mirrors a room's posts to a group channel, attributed as
+// "name: message", and ingests the channel's messages as room posts from a
+// synthetic sender.
+//
+// Loops are broken three ways: posts by the synthetic sender are never sent
+// back to the channel, text recently sent to the channel is not ingested if it
+// is heard again (e.g. relayed back by a repeater), and each direction is rate
+// limited.
+//
+// Channels are matched by their one-byte hash, as on the wire; if another
+// registered channel shares the hash, its messages are ingested too.
+type ChannelBridge struct {
+	cfg    ChannelBridgeConfig
+	hash   uint8
+	base   *BaseNode
+	server *room.Server
+	log    *slog.Logger
+
+	outLimit *rateLimiter
+	inLimit  *rateLimiter
+
+	mu   sync.Mutex
+	sent map[string]uint32 // text sent to the channel -> clock time sent
+}
+
+// newChannelBridge creates a bridge and registers its channel with base.
+func newChannelBridge(cfg ChannelBridgeConfig, base *BaseNode, logger *slog.Logger) *ChannelBridge {
+	if cfg.SenderID == (core.MeshCoreID{}) {
+		cfg.SenderID = core.MeshCoreID(sha256.Sum256(cfg.Key))
+	}
+	if cfg.RateLimit == 0 {
+		cfg.RateLimit = DefaultBridgeRateLimit
+	}
+	if cfg.RateWindow <= 0 {
+		cfg.RateWindow = DefaultBridgeRateWindow
+	}
+	if cfg.EchoWindow <= 0 {
+		cfg.EchoWindow = DefaultBridgeEchoWindow
+	}
+	windowSecs := uint32(cfg.RateWindow / time.Second)
+	br := &ChannelBridge{
+		cfg:      cfg,
+		hash:     crypto.ComputeChannelHash(cfg.Key),
+		base:     base,
+		log:      logger.WithGroup("bridge"),
+		outLimit: newRateLimiter(cfg.RateLimit, windowSecs),
+		inLimit:  newRateLimiter(cfg.RateLimit, windowSecs),
+		sent:     make(map[string]uint32),
+	}
+	if cfg.Direction != BridgeToChannel {
+		base.AddChannel(cfg.Key)
+	}
+	return br
+}
+
+// SenderID returns the synthetic author of posts ingested from the channel.
+func (br *ChannelBridge) SenderID() core.MeshCoreID { return br.cfg.SenderID }
+
+// onPost mirrors a newly stored room post to the channel. Wired as the room
+// server's OnPostAdded hook.
+func (br *ChannelBridge) onPost(p *room.PostInfo) {
+	if br.cfg.Direction == BridgeFromChannel || p.SenderID == br.cfg.SenderID {
+		return
+	}
+	content, err := codec.ParseTxtMsgContent(p.Content)
+	if err != nil || content.Message == "" {
+		return
+	}
+	now := br.base.Clock().GetCurrentTime()
+	if !br.outLimit.allow(now) {
+		br.log.Info("room post not bridged (rate limited)", "sender", p.SenderID.String())
+		return
+	}
+
+	text := codec.TruncateUTF8(br.senderName(p.SenderID)+": "+content.Message, codec.MaxTextLen)
+	br.rememberSent(text, now)
+	if err := br.base.SendChannelText(br.cfg.Key, text); err != nil {
+		br.log.Warn("failed to bridge room post", "error", err)
+	}
+}
+
+// onGroupText ingests a channel message as a room post.
+func (br *ChannelBridge) onGroupText(e *event.GroupTextReceived) {
+	if br.cfg.Direction == BridgeToChannel || e.ChannelHash != br.hash || e.Message == "" {
+		return
+	}
+	now := br.base.Clock().GetCurrentTime()
+	if br.isEcho(e.Message, now) {
+		br.log.Debug("dropping bridged message heard back on channel")
+		return
+	}
+	if !br.inLimit.allow(now) {
+		br.log.Info("channel message not bridged (rate limited)")
+		return
+	}
+	br.server.AddPost(br.cfg.SenderID, e.Message)
+}
+
+// senderName returns the contact name for id, or a short key prefix.
+func (br *ChannelBridge) senderName(id core.MeshCoreID) string {
+	if ct := br.base.Contacts().GetByPubKey(id); ct != nil && ct.Name != "" {
+		return ct.Name
+	}
+	return hex.EncodeToString(id[:4])
+}
+
+// rememberSent records text sent to the channel, pruning expired entries.
+func (br *ChannelBridge) rememberSent(text string, now uint32) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	window := uint32(br.cfg.EchoWindow / time.Second)
+	for t, at := range br.sent {
+		if now-at > window {
+			delete(br.sent, t)
+		}
+	}
+	br.sent[text] = now
+}
+
+// isEcho reports whether text was sent to the channel within the echo window.
+func (br *ChannelBridge) isEcho(text string, now uint32) bool {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	at, ok := br.sent[text]
+	return ok && now-at <= uint32(br.cfg.EchoWindow/time.Second)
+}
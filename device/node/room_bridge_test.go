@@ -0,0 +1,137 @@
+package node
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/contact"
+	"github.com/kabili207/meshcore-go/device/room"
+	"github.com/kabili207/meshcore-go/transport"
+)
+
+func newBridgedRoom(t *testing.T, bridge ChannelBridgeConfig) (*RoomNode, *captureTransport, *room.MemoryPostStore) {
+	t.Helper()
+	kp, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("generate keypair: %v", err)
+	}
+	priv := ed25519.PrivateKey(kp.PrivateKey)
+	posts := room.NewMemoryPostStore(100)
+	n, err := NewRoom(RoomConfig{
+		PrivateKey: priv,
+		Contacts:   contact.NewManager(priv, contact.ManagerConfig{}),
+		Room: room.ServerConfig{
+			Clients: room.NewMemoryClientStore(20),
+			Posts:   posts,
+		},
+		Bridge: &bridge,
+	})
+	if err != nil {
+		t.Fatalf("new room: %v", err)
+	}
+	ct := &captureTransport{}
+	n.Base().Router.AddTransport(ct, transport.PacketSourceMQTT)
+	return n, ct, posts
+}
+
+// channelTexts decrypts every GRP_TXT the transport sent on key.
+func channelTexts(t *testing.T, ct *captureTransport, key []byte) []string {
+	t.Helper()
+	var out []string
+	for _, p := range ct.sent {
+		if p.PayloadType() != codec.PayloadTypeGrpTxt {
+			continue
+		}
+		grp, err := codec.ParseGroupPayload(p.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plaintext, err := crypto.DecryptGroupMessage(codec.PrependMAC(grp.MAC, grp.Ciphertext), key)
+		if err != nil {
+			continue
+		}
+		_, _, msg, err := crypto.ParseGrpTxtPlaintext(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+func TestChannelBridge_PostToChannel(t *testing.T) {
+	key := crypto.DefaultChannelKey
+	n, ct, _ := newBridgedRoom(t, ChannelBridgeConfig{Key: key})
+
+	alice := core.MeshCoreID{0xA1}
+	n.Base().Contacts().AddContact(&contact.ContactInfo{ID: alice, Name: "Alice", OutPathLen: contact.PathUnknown})
+	n.Server().AddPost(alice, "hello room")
+	n.Server().AddPost(core.MeshCoreID{0xB2, 0x01}, "anon")
+
+	got := channelTexts(t, ct, key)
+	if len(got) != 2 || got[0] != "Alice: hello room" || got[1] != "b2010000: anon" {
+		t.Errorf("channel texts = %q", got)
+	}
+}
+
+func TestChannelBridge_ChannelToRoom(t *testing.T) {
+	key := crypto.DefaultChannelKey
+	n, ct, posts := newBridgedRoom(t, ChannelBridgeConfig{Key: key})
+
+	n.Base().processPacket(buildGrpTxt(t, key, 1000, "Bob: hi all"), transport.PacketSourceMQTT)
+
+	got := posts.GetPostsSince(0)
+	if len(got) != 1 {
+		t.Fatalf("posts = %d, want 1", len(got))
+	}
+	if got[0].SenderID != n.Bridge().SenderID() {
+		t.Errorf("post sender = %s, want the bridge sender", got[0].SenderID)
+	}
+	if n.Bridge().SenderID()[0] != crypto.ComputeChannelHash(key) {
+		t.Error("derived sender prefix should be the channel hash")
+	}
+	c, err := codec.ParseTxtMsgContent(got[0].Content)
+	if err != nil || c.Message != "Bob: hi all" {
+		t.Errorf("post = %q, %v", c.Message, err)
+	}
+	// An ingested post is never mirrored back to the channel.
+	for _, txt := range channelTexts(t, ct, key) {
+		if txt != "Bob: hi all" { // the flood relay of the original is fine
+			t.Errorf("bridge echoed ingested post as %q", txt)
+		}
+	}
+}
+
+func TestChannelBridge_EchoIgnored(t *testing.T) {
+	key := crypto.DefaultChannelKey
+	n, _, posts := newBridgedRoom(t, ChannelBridgeConfig{Key: key})
+
+	n.Server().AddPost(core.MeshCoreID{0xC3}, "ping")
+	// The bridged text relayed back by a repeater.
+	n.Base().processPacket(buildGrpTxt(t, key, 1000, "c3000000: ping"), transport.PacketSourceMQTT)
+
+	if got := posts.Count(); got != 1 {
+		t.Errorf("posts = %d, want 1 (echo must not be ingested)", got)
+	}
+}
+
+func TestChannelBridge_RateLimitAndDirection(t *testing.T) {
+	key := crypto.DefaultChannelKey
+	n, ct, posts := newBridgedRoom(t, ChannelBridgeConfig{Key: key, Direction: BridgeToChannel, RateLimit: 2})
+
+	for _, msg := range []string{"one", "two", "three"} {
+		n.Server().AddPost(core.MeshCoreID{0xD4}, msg)
+	}
+	if got := channelTexts(t, ct, key); len(got) != 2 {
+		t.Errorf("bridged %d posts, want 2 (rate limited)", len(got))
+	}
+
+	// To-channel only: channel messages are not ingested.
+	n.Base().processPacket(buildGrpTxt(t, key, 1000, "Eve: hi"), transport.PacketSourceMQTT)
+	if got := posts.Count(); got != 3 {
+		t.Errorf("posts = %d, want 3", got)
+	}
+}
@@ -0,0 +1,185 @@
+package node
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/event"
+	"github.com/kabili207/meshcore-go/device/router"
+	"github.com/kabili207/meshcore-go/transport"
+)
+
+// RoomHostConfig configures a RoomHost.
+type RoomHostConfig struct {
+	// Transports to connect to the mesh network, shared by every room.
+	Transports []TransportOption
+
+	// Router is an existing router to share. If nil, RoomHost creates one whose
+	// SelfID is the first room's identity (used for relay path hashes when
+	// forwarding).
+	Router *router.Router
+
+	// Rooms configures each hosted room. Each needs its own PrivateKey and
+	// Contacts; their Transports and Router fields are ignored. At least one
+	// room is required.
+	Rooms []RoomConfig
+
+	// ForwardPackets enables packet relaying. Default: true, as for a single
+	// room server.
+	ForwardPackets *bool
+
+	// Logger for host events. Falls back to slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// RoomHost runs several room identities on one router and transport set. Each
+// room is a full RoomNode, with its own key, client ACL, post store, ACK
+// tracker, and advert scheduler; the host owns the shared network and
+// dispatches each incoming packet to the rooms it concerns.
+//
+// Addressed packets (TXT_MSG, REQ, RESPONSE, PATH, ANON_REQ) go only to the
+// rooms whose one-byte hash matches the destination; if two rooms share a hash,
+// both see the packet and MAC verification picks the right one. Everything
+// else (adverts, ACKs, group messages, traces) goes to every room.
+//
+// Router-wide settings are shared: a CLI change to path.hash.mode or
+// flood.max, or a SetSendScope call, made through one room applies to all.
+type RoomHost struct {
+	router     *router.Router
+	transports []TransportOption
+	rooms      []*RoomNode
+	log        *slog.Logger
+}
+
+// NewRoomHost creates the shared router and one RoomNode per configured room.
+func NewRoomHost(cfg RoomHostConfig) (*RoomHost, error) {
+	if len(cfg.Rooms) == 0 {
+		return nil, errors.New("room host: no rooms configured")
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	forwardPackets := true
+	if cfg.ForwardPackets != nil {
+		forwardPackets = *cfg.ForwardPackets
+	}
+
+	r := cfg.Router
+	if r == nil {
+		var selfID core.MeshCoreID
+		copy(selfID[:], cfg.Rooms[0].PrivateKey.Public().(ed25519.PublicKey))
+		r = router.New(router.Config{
+			SelfID:         selfID,
+			ForwardPackets: forwardPackets,
+			Logger:         logger,
+		})
+	}
+
+	h := &RoomHost{
+		router:     r,
+		transports: cfg.Transports,
+		log:        logger.WithGroup("room-host"),
+	}
+	hashes := make(map[uint8]core.MeshCoreID)
+	for i, rc := range cfg.Rooms {
+		rc.Router = r
+		rc.Transports = nil
+		rc.ForwardPackets = &forwardPackets
+		if rc.Logger == nil {
+			rc.Logger = logger
+		}
+		n, err := NewRoom(rc)
+		if err != nil {
+			return nil, fmt.Errorf("room %d: %w", i, err)
+		}
+		if other, ok := hashes[n.ID().Hash()]; ok {
+			h.log.Warn("rooms share a destination hash; both will try each packet",
+				"room", n.ID().String(), "other", other.String())
+		}
+		hashes[n.ID().Hash()] = n.ID()
+		h.rooms = append(h.rooms, n)
+	}
+
+	for _, t := range cfg.Transports {
+		r.AddTransport(t.Transport, t.Source)
+
+		name := t.Name
+		t.Transport.SetStateHandler(func(_ transport.Transport, evt transport.Event) {
+			for _, n := range h.rooms {
+				n.base.emitEvent(&event.TransportStateChanged{
+					TransportName: name,
+					State:         evt,
+				})
+			}
+		})
+	}
+
+	// Each NewBase claimed the router's packet handler; take it back.
+	r.SetPacketHandler(h.dispatch)
+
+	return h, nil
+}
+
+// dispatch routes a packet from the shared router to the rooms it concerns.
+func (h *RoomHost) dispatch(pkt *codec.Packet, src transport.PacketSource) {
+	dest, addressed := destHash(pkt)
+	for _, n := range h.rooms {
+		if addressed && n.ID().Hash() != dest {
+			continue
+		}
+		n.base.processPacket(pkt, src)
+	}
+}
+
+// destHash returns the destination hash of an addressed packet. The second
+// result is false for packets that are not addressed to a single node.
+func destHash(pkt *codec.Packet) (uint8, bool) {
+	switch pkt.PayloadType() {
+	case codec.PayloadTypeTxtMsg, codec.PayloadTypeReq, codec.PayloadTypeResponse,
+		codec.PayloadTypePath, codec.PayloadTypeAnonReq:
+		if len(pkt.Payload) == 0 {
+			return 0, false
+		}
+		return pkt.Payload[0], true
+	}
+	return 0, false
+}
+
+// Run starts the shared transports and router once, then each room's ACK
+// tracker, sync loop, and advert scheduler.
+func (h *RoomHost) Run(ctx context.Context) error {
+	for _, t := range h.transports {
+		if err := t.Transport.Start(ctx); err != nil {
+			return fmt.Errorf("start transport %q: %w", t.Name, err)
+		}
+	}
+	h.router.Start(ctx)
+	for _, n := range h.rooms {
+		n.startServices(ctx)
+	}
+	return nil
+}
+
+// Rooms returns the hosted rooms in configuration order.
+func (h *RoomHost) Rooms() []*RoomNode {
+	return append([]*RoomNode(nil), h.rooms...)
+}
+
+// Room returns the hosted room with the given identity, or nil.
+func (h *RoomHost) Room(id core.MeshCoreID) *RoomNode {
+	for _, n := range h.rooms {
+		if n.ID() == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// Router returns the shared router.
+func (h *RoomHost) Router() *router.Router { return h.router }
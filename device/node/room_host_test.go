@@ -0,0 +1,144 @@
+package node
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/contact"
+	"github.com/kabili207/meshcore-go/device/event"
+	"github.com/kabili207/meshcore-go/device/room"
+	"github.com/kabili207/meshcore-go/transport"
+)
+
+type hostedRoom struct {
+	cfg       RoomConfig
+	clients   *room.MemoryClientStore
+	collector *eventCollector
+}
+
+func newHostedRoom(t *testing.T, avoidHash ...uint8) hostedRoom {
+	t.Helper()
+	var priv ed25519.PrivateKey
+	for priv == nil {
+		kp, err := crypto.GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("generate keypair: %v", err)
+		}
+		hash := kp.PublicKey[0]
+		priv = ed25519.PrivateKey(kp.PrivateKey)
+		for _, h := range avoidHash {
+			if h == hash {
+				priv = nil
+			}
+		}
+	}
+	clients := room.NewMemoryClientStore(20)
+	collector := &eventCollector{}
+	return hostedRoom{
+		cfg: RoomConfig{
+			PrivateKey: priv,
+			Contacts:   contact.NewManager(priv, contact.ManagerConfig{}),
+			Room: room.ServerConfig{
+				AdminPassword: "adminpw",
+				Clients:       clients,
+				Posts:         room.NewMemoryPostStore(100),
+			},
+			EventHandlers: []event.Handler{collector.handler},
+		},
+		clients:   clients,
+		collector: collector,
+	}
+}
+
+func anonLogin(t *testing.T, to *RoomNode, password string) *codec.Packet {
+	t.Helper()
+	login := make([]byte, 8, 8+len(password)+1)
+	binary.LittleEndian.PutUint32(login[0:4], 100)
+	login = append(append(login, password...), 0)
+	pub := to.Base().PublicKey()
+	ephemeral, encrypted, err := crypto.EncryptAnonymous(login, pub[:])
+	if err != nil {
+		t.Fatalf("encrypt anonymous: %v", err)
+	}
+	mac, ciphertext := codec.SplitMAC(encrypted)
+	payload := codec.BuildAnonReqPayload(to.ID().Hash(), ephemeral, mac, ciphertext)
+	return codec.NewPacket(codec.PayloadTypeAnonReq, codec.RouteTypeFlood, payload)
+}
+
+func TestRoomHost_DispatchByDestHash(t *testing.T) {
+	a := newHostedRoom(t)
+	b := newHostedRoom(t, a.cfg.PrivateKey.Public().(ed25519.PublicKey)[0])
+	ct := &captureTransport{}
+
+	h, err := NewRoomHost(RoomHostConfig{
+		Transports: []TransportOption{{Transport: ct, Source: transport.PacketSourceMQTT, Name: "test"}},
+		Rooms:      []RoomConfig{a.cfg, b.cfg},
+	})
+	if err != nil {
+		t.Fatalf("new room host: %v", err)
+	}
+	rooms := h.Rooms()
+	if len(rooms) != 2 {
+		t.Fatalf("rooms = %d, want 2", len(rooms))
+	}
+	roomB := rooms[1]
+	if h.Room(roomB.ID()) != roomB {
+		t.Error("Room(id) did not find the second room")
+	}
+	if roomB.Base().Router != h.Router() || rooms[0].Base().Router != h.Router() {
+		t.Fatal("rooms do not share the host router")
+	}
+
+	h.Router().HandlePacket(anonLogin(t, roomB, "adminpw"), transport.PacketSourceMQTT)
+
+	if a.clients.Count() != 0 || b.clients.Count() != 1 {
+		t.Errorf("client counts a=%d b=%d, want 0 and 1", a.clients.Count(), b.clients.Count())
+	}
+	for _, e := range a.collector.get() {
+		if _, ok := e.(*event.AnonRequestReceived); ok {
+			t.Error("room A saw a login addressed to room B")
+		}
+	}
+	var replied bool
+	for _, p := range ct.sent {
+		if p.PayloadType() == codec.PayloadTypeResponse {
+			replied = true
+		}
+	}
+	if !replied {
+		t.Error("expected room B's login response on the shared transport")
+	}
+}
+
+func TestRoomHost_BroadcastToAllRooms(t *testing.T) {
+	a := newHostedRoom(t)
+	b := newHostedRoom(t, a.cfg.PrivateKey.Public().(ed25519.PublicKey)[0])
+	h, err := NewRoomHost(RoomHostConfig{Rooms: []RoomConfig{a.cfg, b.cfg}})
+	if err != nil {
+		t.Fatalf("new room host: %v", err)
+	}
+
+	ack := codec.NewPacket(codec.PayloadTypeAck, codec.RouteTypeDirect, codec.BuildAckPayload(0xDEADBEEF))
+	h.Router().HandlePacket(ack, transport.PacketSourceMQTT)
+
+	for name, r := range map[string]hostedRoom{"a": a, "b": b} {
+		var got bool
+		for _, e := range r.collector.get() {
+			if _, ok := e.(*event.AckReceived); ok {
+				got = true
+			}
+		}
+		if !got {
+			t.Errorf("room %s did not see the ACK", name)
+		}
+	}
+}
+
+func TestRoomHost_NoRooms(t *testing.T) {
+	if _, err := NewRoomHost(RoomHostConfig{}); err == nil {
+		t.Error("expected an error for a host with no rooms")
+	}
+}
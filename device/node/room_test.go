@@ -3,10 +3,15 @@ package node
 import (
 	"crypto/ed25519"
 	"testing"
+	"time"
 
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
 	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/acl"
 	"github.com/kabili207/meshcore-go/device/contact"
 	"github.com/kabili207/meshcore-go/device/room"
+	"github.com/kabili207/meshcore-go/transport"
 )
 
 // newTestRoom builds a minimal RoomNode through NewRoom, the same path
@@ -62,3 +67,48 @@ func TestNewRoom_RouterCLIKeys(t *testing.T) {
 		}
 	}
 }
+
+// TestRoom_PreviousKey verifies that a RoomNode configured with a previous
+// key still accepts posts encrypted to it during a key rotation.
+func TestRoom_PreviousKey(t *testing.T) {
+	newKP, oldKP, peer := peerKeyPair(t), peerKeyPair(t), peerKeyPair(t)
+	var oldID, peerID core.MeshCoreID
+	copy(oldID[:], oldKP.PublicKey)
+	copy(peerID[:], peer.PublicKey)
+
+	priv := ed25519.PrivateKey(newKP.PrivateKey)
+	contacts := contact.NewManager(priv, contact.ManagerConfig{})
+	clients := room.NewMemoryClientStore(20)
+	posts := room.NewMemoryPostStore(100)
+	n, err := NewRoom(RoomConfig{
+		PrivateKey:       priv,
+		PreviousKey:      oldKP.PrivateKey,
+		PreviousKeyUntil: time.Now().Add(time.Hour),
+		Contacts:         contacts,
+		Room:             room.ServerConfig{Clients: clients, Posts: posts},
+	})
+	if err != nil {
+		t.Fatalf("new room: %v", err)
+	}
+	contacts.AddContact(&contact.ContactInfo{ID: peerID, Name: "Sender", OutPathLen: contact.PathUnknown})
+	if _, err := clients.AddClient(&room.ClientInfo{Client: acl.Client{ID: peerID, Permissions: codec.PermACLReadWrite}}); err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := crypto.ComputeSharedSecret(peer.PrivateKey, oldKP.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := crypto.EncryptAddressedWithSecret(
+		codec.BuildTxtMsgContent(uint32(time.Now().Unix()), codec.TxtTypePlain, 0, "to old key", nil), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac, ciphertext := codec.SplitMAC(encrypted)
+	payload := codec.BuildAddressedPayload(oldID.Hash(), peerID.Hash(), mac, ciphertext)
+	n.Base().processPacket(codec.NewPacket(codec.PayloadTypeTxtMsg, codec.RouteTypeDirect, payload), transport.PacketSourceMQTT)
+
+	if posts.Count() != 1 {
+		t.Errorf("expected 1 post via the previous key, got %d", posts.Count())
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/kabili207/meshcore-go/core/codec"
 	"github.com/kabili207/meshcore-go/core/crypto"
 	"github.com/kabili207/meshcore-go/device/ack"
+	"github.com/kabili207/meshcore-go/device/contact"
 )
 
 // SendOption configures optional behavior for SendText.
@@ -133,17 +134,21 @@ func (n *CompanionNode) sendTextChunk(to core.MeshCoreID, message string, o send
 		n.base.Router.SendFloodScoped(pkt)
 	}
 
-	// Track ACK if callbacks are provided
-	if o.onACK != nil || o.onTimeout != nil {
+	// Track the ACK for everything but CLI commands (which firmware never
+	// ACKs), so delivery stats are kept whether or not callbacks are provided.
+	if o.txtType != codec.TxtTypeCLI || o.onACK != nil || o.onTimeout != nil {
 		ackData := codec.TrimTxtMsgContent(plaintext, &codec.TxtMsgContent{
 			TxtType: o.txtType,
 			Message: message,
 		})
-		ackHash := crypto.ComputeAckHash(ackData, to[:])
-		n.ackTracker.Track(ackHash, ack.PendingACK{
-			OnACK:     o.onACK,
-			OnTimeout: o.onTimeout,
-		})
+		// The receiver keys a plain-text ACK by the sender's (our) pubkey,
+		// and a signed one by its own.
+		ackKey := to
+		if o.txtType == codec.TxtTypePlain {
+			ackKey = n.base.id
+		}
+		ackHash := crypto.ComputeAckHash(ackData, ackKey[:])
+		n.trackDelivery(ackHash, to, o.onACK, o.onTimeout)
 	}
 
 	n.log.Debug("sent text",
@@ -154,6 +159,32 @@ func (n *CompanionNode) sendTextChunk(to core.MeshCoreID, message string, o send
 	return nil
 }
 
+// trackDelivery registers a pending ACK for a message sent to a contact,
+// recording the outcome in the contact's link stats before running the
+// caller's callbacks.
+func (n *CompanionNode) trackDelivery(ackHash uint32, to core.MeshCoreID, onACK, onTimeout func()) {
+	contacts := n.base.contacts
+	_ = contact.UpdateStats(contacts, to, (*contact.LinkStats).RecordSent)
+
+	sentAt := time.Now()
+	n.ackTracker.Track(ackHash, ack.PendingACK{
+		OnACK: func() {
+			rtt := time.Since(sentAt)
+			now := n.clk.GetCurrentTime()
+			_ = contact.UpdateStats(contacts, to, func(s *contact.LinkStats) { s.RecordACK(rtt, now) })
+			if onACK != nil {
+				onACK()
+			}
+		},
+		OnTimeout: func() {
+			_ = contact.UpdateStats(contacts, to, (*contact.LinkStats).RecordTimeout)
+			if onTimeout != nil {
+				onTimeout()
+			}
+		},
+	})
+}
+
 // splitMessage breaks a message into chunks that fit within the DM size limit.
 // Splits on newline boundaries when possible, otherwise at the byte limit.
 func splitMessage(msg string, maxLen int) []string {
@@ -1,9 +1,13 @@
 package node
 
 import (
+	"context"
 	"testing"
 
+	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/contact"
 )
 
 func TestSplitMessage_Short(t *testing.T) {
@@ -72,3 +76,45 @@ func TestSplitMessage_Empty(t *testing.T) {
 		t.Errorf("expected empty string, got %q", chunks[0])
 	}
 }
+
+func TestSendText_RecordsDeliveryStats(t *testing.T) {
+	comp, ct := newTestCompanion(t)
+	peer := peerKeyPair(t)
+	var peerID core.MeshCoreID
+	copy(peerID[:], peer.PublicKey)
+	if _, err := comp.base.Contacts().AddContact(&contact.ContactInfo{ID: peerID, OutPathLen: contact.PathUnknown}); err != nil {
+		t.Fatal(err)
+	}
+
+	acked := false
+	if err := comp.SendText(context.Background(), peerID, "hello", WithOnACK(func() { acked = true })); err != nil {
+		t.Fatal(err)
+	}
+	if err := comp.SendText(context.Background(), peerID, "are you there?"); err != nil {
+		t.Fatal(err)
+	}
+	if got := comp.ackTracker.PendingCount(); got != 2 {
+		t.Fatalf("pending ACKs = %d, want 2 (tracked with or without callbacks)", got)
+	}
+
+	// The peer ACKs the first message, keying the hash by the sender as
+	// BaseNode does for received plain text.
+	addr, err := codec.ParseAddressedPayload(ct.sent[0].Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, _ := crypto.ComputeSharedSecret(peer.PrivateKey, comp.base.publicKey[:])
+	pt, err := crypto.DecryptAddressedWithSecret(codec.PrependMAC(addr.MAC, addr.Ciphertext), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := codec.ParseTxtMsgContent(pt)
+	if !comp.ackTracker.Resolve(crypto.ComputeAckHash(codec.TrimTxtMsgContent(pt, content), comp.base.publicKey[:])) {
+		t.Fatal("ACK hash was not pending")
+	}
+
+	st := comp.base.Contacts().GetByPubKey(peerID).Stats()
+	if !acked || st.Sent != 2 || st.Acked != 1 || st.Failed != 0 {
+		t.Errorf("acked=%v stats=%+v, want callback run and 2 sent / 1 acked", acked, st)
+	}
+}
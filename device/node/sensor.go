@@ -0,0 +1,344 @@
+package node
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/ack"
+	"github.com/kabili207/meshcore-go/device/acl"
+	"github.com/kabili207/meshcore-go/device/advert"
+	"github.com/kabili207/meshcore-go/device/cli"
+	"github.com/kabili207/meshcore-go/device/contact"
+	"github.com/kabili207/meshcore-go/device/event"
+	"github.com/kabili207/meshcore-go/device/telemetry"
+)
+
+// SensorConfig configures a SensorNode.
+type SensorConfig struct {
+	// PrivateKey is the node's Ed25519 private key (64 bytes: seed + pubkey).
+	PrivateKey ed25519.PrivateKey
+
+	// Transports to connect to the mesh network.
+	Transports []TransportOption
+
+	// Contacts is the contact store for client keys. If nil, a default
+	// ContactManager is created.
+	Contacts contact.ContactStore
+
+	// Blocklist is the set of peers whose adverts and direct messages the node
+	// ignores, managed with the "block"/"unblock" CLI commands. Use
+	// contact.OpenBlocklist for a list that survives restarts. If nil, an
+	// in-memory list is created.
+	Blocklist *contact.Blocklist
+
+	// PreviousKey and PreviousKeyUntil keep the node answering for its old
+	// key after a key rotation; see BaseConfig.PreviousKey.
+	PreviousKey      ed25519.PrivateKey
+	PreviousKeyUntil time.Time
+
+	// AdminPassword grants admin access on login. Empty disables admin login.
+	AdminPassword string
+
+	// GuestPassword grants guest access on login. Empty disables guest login.
+	GuestPassword string
+
+	// MaxClients caps the ACL client table. Default: acl.DefaultMaxClients (20).
+	MaxClients int
+
+	// ACLPersistence, if set, makes the admin client list durable across restarts.
+	// See acl.NewFileStore.
+	ACLPersistence acl.Persistence
+
+	// Telemetry supplies the sensor readings. It answers GET_TELEMETRY requests
+	// and is sampled into History. Without it, telemetry requests are ignored and
+	// no history is recorded.
+	Telemetry telemetry.Provider
+
+	// History stores sampled readings for GET_MIN_MAX_AVG requests. If nil, an
+	// in-memory history of telemetry.DefaultHistorySize samples is used.
+	History telemetry.History
+
+	// SampleInterval is how often Telemetry is sampled into History and checked
+	// against Alerts. Default: telemetry.DefaultSampleInterval (1 minute).
+	SampleInterval time.Duration
+
+	// Alerts are threshold rules evaluated on every sample. A triggered alert is
+	// sent as a plain DM to every admin client.
+	Alerts []AlertRule
+
+	// ACKTimeout is how long to wait for an alert ACK before resending.
+	// Default: 12s.
+	ACKTimeout time.Duration
+
+	// MaxRetries is how many times an unacknowledged alert is resent. Default: 3.
+	MaxRetries int
+
+	// Version overrides the entire "ver" CLI reply verbatim. Leave it empty to
+	// use the firmware-format reply built from cli.FirmwareVersion and
+	// FirmwareBuildDate.
+	Version string
+
+	// FirmwareBuildDate is the build date reported in the "ver" CLI reply, in the
+	// firmware's "6 Jun 2026" style. Ignored when Version is set.
+	FirmwareBuildDate string
+
+	// OnSettingChanged, if set, is called after a successful CLI "set".
+	OnSettingChanged func(key, value string)
+
+	// OnReboot, if set, is invoked by the "reboot" CLI command. Without it,
+	// "reboot" reports that it is unsupported.
+	OnReboot func()
+
+	// OnSetClock, if set, is invoked by the "time <epoch>" CLI command with the
+	// requested epoch seconds. Without it, "time" reports unsupported.
+	OnSetClock func(epoch uint32) error
+
+	// Advertisement
+	Name string   // Node name broadcast in adverts.
+	Lat  *float64 // Optional GPS latitude.
+	Lon  *float64 // Optional GPS longitude.
+
+	// OwnerInfo is a free-form owner/contact string exposed via the "owner.info"
+	// CLI key.
+	OwnerInfo string
+
+	// AdvertLocalInterval is the local (zero-hop) advert interval in firmware
+	// units (value * 2 minutes). Default: 1 (2 minutes).
+	AdvertLocalInterval uint8
+
+	// AdvertFloodInterval is the flood advert interval in hours. Default: 12.
+	AdvertFloodInterval uint8
+
+	// EventHandlers registered during construction.
+	EventHandlers []event.Handler
+
+	// Logger for node events. Falls back to slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// SensorNode is a telemetry node (advert type sensor). It authenticates
+// admin/guest clients like a repeater, answers GET_TELEMETRY from its provider
+// and GET_MIN_MAX_AVG from recorded history, and pushes threshold alerts to its
+// admins. Unlike a repeater it does not forward packets.
+//
+// This corresponds to the firmware's SensorMesh.
+type SensorNode struct {
+	base        *BaseNode
+	advertSched *advert.Scheduler
+	ackTracker  *ack.Tracker
+	acl         *acl.MemoryStore
+	auth        acl.Authenticator
+	history     telemetry.History
+	sampler     *telemetry.Sampler
+	alerts      *alertSet
+	cli         *cli.Dispatcher
+	appData     *codec.AdvertAppData
+	cfg         SensorConfig
+	log         *slog.Logger
+}
+
+// NewSensor creates a SensorNode from the given configuration.
+func NewSensor(cfg SensorConfig) (*SensorNode, error) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	// Sensors only accept CLI text from admins, which is never ACKed.
+	autoACK := false
+
+	contacts := cfg.Contacts
+	if contacts == nil {
+		contacts = contact.NewManager(cfg.PrivateKey, contact.ManagerConfig{
+			MaxContacts:       64,
+			OverwriteWhenFull: true,
+		})
+	}
+
+	ackTimeout := cfg.ACKTimeout
+	if ackTimeout == 0 {
+		ackTimeout = ack.DefaultACKTimeout
+	}
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = ack.DefaultMaxRetries
+	}
+
+	// Alert retries are driven from OnTimeout so each resend can carry a new
+	// attempt number (and so a new ACK hash); the tracker itself never resends.
+	tracker := ack.NewTracker(ack.TrackerConfig{
+		ACKTimeout: ackTimeout,
+		MaxRetries: 0,
+		Logger:     logger,
+	})
+
+	base, err := NewBase(BaseConfig{
+		PrivateKey:       cfg.PrivateKey,
+		Contacts:         contacts,
+		ACKTracker:       tracker,
+		Transports:       cfg.Transports,
+		ForwardPackets:   false,
+		AutoACK:          &autoACK,
+		Blocklist:        cfg.Blocklist,
+		PreviousKey:      cfg.PreviousKey,
+		PreviousKeyUntil: cfg.PreviousKeyUntil,
+		EventHandlers:    cfg.EventHandlers,
+		Logger:           logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create base node: %w", err)
+	}
+
+	clk := base.Clock()
+	appData := &codec.AdvertAppData{
+		Name:     cfg.Name,
+		NodeType: codec.NodeTypeSensor,
+		Lat:      cfg.Lat,
+		Lon:      cfg.Lon,
+	}
+	advertBuilder := advert.NewSelfAdvertBuilder(&advert.SelfAdvertConfig{
+		PrivateKey: cfg.PrivateKey,
+		PublicKey:  base.PublicKey(),
+		Clock:      clk,
+		AppData:    appData,
+	})
+
+	localInterval := cfg.AdvertLocalInterval
+	if localInterval == 0 {
+		localInterval = advert.DefaultLocalAdvertInterval
+	}
+	floodInterval := cfg.AdvertFloodInterval
+	if floodInterval == 0 {
+		floodInterval = advert.DefaultFloodAdvertInterval
+	}
+
+	scheduler := advert.NewScheduler(base.Router, advertBuilder, advert.SchedulerConfig{
+		LocalAdvertInterval: localInterval,
+		FloodAdvertInterval: floodInterval,
+		Logger:              logger,
+	})
+
+	history := cfg.History
+	if history == nil {
+		history = telemetry.NewMemoryHistory(0)
+	}
+
+	n := &SensorNode{
+		base:        base,
+		advertSched: scheduler,
+		ackTracker:  tracker,
+		acl:         acl.NewMemoryStore(cfg.MaxClients, acl.WithPersistence(cfg.ACLPersistence)),
+		auth: acl.Authenticator{
+			AdminPassword: cfg.AdminPassword,
+			GuestPassword: cfg.GuestPassword,
+			GuestPerms:    codec.PermACLGuest,
+		},
+		history: history,
+		alerts:  newAlertSet(cfg.Alerts, maxRetries),
+		appData: appData,
+		cfg:     cfg,
+		log:     logger.WithGroup("sensor"),
+	}
+	if cfg.Telemetry != nil {
+		n.sampler = telemetry.NewSampler(telemetry.SamplerConfig{
+			Provider: cfg.Telemetry,
+			History:  history,
+			Clock:    clk,
+			Interval: cfg.SampleInterval,
+			OnSample: n.checkAlerts,
+			Logger:   logger,
+		})
+	}
+	n.cli = n.buildCLI()
+
+	base.OnEvent(n.dispatchEvents)
+
+	return n, nil
+}
+
+// Run starts all components and blocks until ctx is cancelled.
+// Starts: transports, router, ACK tracker, telemetry sampler, advert scheduler.
+func (n *SensorNode) Run(ctx context.Context) error {
+	if err := n.base.StartTransports(ctx); err != nil {
+		return err
+	}
+
+	n.base.Router.Start(ctx)
+
+	go n.ackTracker.Start(ctx)
+	if n.sampler != nil {
+		go n.sampler.Start(ctx)
+	}
+
+	n.advertSched.SendNow(true)
+	n.advertSched.Start(ctx)
+
+	return nil
+}
+
+// OnEvent registers an event handler. Delegates to BaseNode.
+func (n *SensorNode) OnEvent(fn event.Handler) {
+	n.base.OnEvent(fn)
+}
+
+// Base returns the underlying BaseNode for advanced use.
+func (n *SensorNode) Base() *BaseNode { return n.base }
+
+// ID returns the node's MeshCoreID.
+func (n *SensorNode) ID() core.MeshCoreID { return n.base.ID() }
+
+// AdvertScheduler returns the advert scheduler for manual control.
+func (n *SensorNode) AdvertScheduler() *advert.Scheduler {
+	return n.advertSched
+}
+
+// ACL returns the sensor's client access-control store.
+func (n *SensorNode) ACL() *acl.MemoryStore {
+	return n.acl
+}
+
+// History returns the recorded telemetry history.
+func (n *SensorNode) History() telemetry.History {
+	return n.history
+}
+
+// ACKTracker returns the ACK tracker used for alert delivery.
+func (n *SensorNode) ACKTracker() *ack.Tracker {
+	return n.ackTracker
+}
+
+// SampleNow records a telemetry sample immediately and evaluates alerts, without
+// waiting for the next SampleInterval tick. It is a no-op without a provider.
+func (n *SensorNode) SampleNow() {
+	if n.sampler != nil {
+		n.sampler.SampleNow()
+	}
+}
+
+// SetConfig applies a CLI config key programmatically, firing OnSettingChanged.
+func (n *SensorNode) SetConfig(key, value string) error {
+	return n.cli.Set(key, value)
+}
+
+// LoadConfig applies a persisted config key WITHOUT firing OnSettingChanged.
+// Apply these before Run for guaranteed thread-safety.
+func (n *SensorNode) LoadConfig(key, value string) error {
+	return n.cli.Load(key, value)
+}
+
+// GetConfig returns the current value of a CLI config key.
+func (n *SensorNode) GetConfig(key string) (string, bool) {
+	return n.cli.Get(key)
+}
+
+// ExecuteCLI runs a raw CLI command line against the sensor's dispatcher and
+// returns the reply text, like a remote admin command but without the ACL check.
+// It is not synchronized against concurrent over-the-air CLI.
+func (n *SensorNode) ExecuteCLI(cmd string) string {
+	return n.cli.Execute(cmd)
+}
@@ -0,0 +1,141 @@
+package node
+
+import (
+	"encoding/binary"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/acl"
+	"github.com/kabili207/meshcore-go/device/contact"
+	"github.com/kabili207/meshcore-go/device/event"
+	"github.com/kabili207/meshcore-go/device/telemetry"
+)
+
+// dispatchEvents routes BaseNode events to the sensor's admin handlers.
+func (n *SensorNode) dispatchEvents(evt any) {
+	switch e := evt.(type) {
+	case *event.AnonRequestReceived:
+		n.handleLogin(e)
+	case *event.RequestReceived:
+		n.handleRequest(e)
+	case *event.TextMessageReceived:
+		n.handleCLIMessage(e)
+	}
+}
+
+// handleLogin authenticates an ANON_REQ login and, on success, records the client
+// in the ACL and sends a login response. The sensor answers no typed anon
+// requests, so anything that is not a login is ignored.
+func (n *SensorNode) handleLogin(evt *event.AnonRequestReceived) {
+	// Decrypted login data is timestamp(4) + password(null-terminated).
+	if len(evt.Plaintext) < 5 {
+		return
+	}
+	if typeByte := evt.Plaintext[4]; typeByte != 0 && typeByte < ' ' {
+		n.log.Debug("unsupported anon request", "type", typeByte)
+		return
+	}
+
+	senderTimestamp := binary.LittleEndian.Uint32(evt.Plaintext[0:4])
+	password := nullTerminated(evt.Plaintext[4:])
+	senderID := evt.From
+
+	existing := n.acl.GetClient(senderID)
+	existingPerms := acl.Reject
+	if existing != nil {
+		existingPerms = int(existing.Permissions)
+	}
+
+	perm := n.auth.Resolve(existingPerms, password)
+	if perm == acl.Reject {
+		n.log.Debug("sensor login rejected", "peer", senderID.String())
+		return
+	}
+	if existing != nil && senderTimestamp <= existing.LastTimestamp {
+		n.log.Debug("sensor login replay", "peer", senderID.String())
+		return
+	}
+
+	nowTS := n.base.Clock().GetCurrentTime()
+	if _, err := n.acl.AddClient(&acl.Client{
+		ID:            senderID,
+		Permissions:   uint8(perm),
+		OutPathLen:    acl.PathUnknown,
+		LastTimestamp: senderTimestamp,
+		LastActivity:  nowTS,
+	}); err != nil {
+		n.log.Warn("sensor ACL full, login dropped", "peer", senderID.String(), "error", err)
+		return
+	}
+
+	// Ensure the client is a contact so later REQ/CLI packets decrypt and alerts
+	// can be addressed to it.
+	if n.base.Contacts().GetByPubKey(senderID) == nil {
+		n.base.Contacts().AddContact(&contact.ContactInfo{
+			ID:         senderID,
+			OutPathLen: contact.PathUnknown,
+			LastMod:    nowTS,
+		})
+	}
+	if evt.Reply.HasFloodPath() {
+		if ct := n.base.Contacts().GetByPubKey(senderID); ct != nil {
+			ct.OutPathLen = contact.PathUnknown
+		}
+	}
+
+	n.log.Info("sensor client logged in", "peer", senderID.String(), "perms", perm)
+
+	resp := buildRepeaterLoginResponse(nowTS, uint8(perm))
+	if err := n.base.SendReply(evt.Reply, senderID, codec.PayloadTypeResponse, resp); err != nil {
+		n.log.Warn("failed to send login response", "error", err)
+	}
+}
+
+// handleRequest processes an addressed REQ from an authenticated client.
+// Requests from peers not in the ACL are ignored.
+func (n *SensorNode) handleRequest(evt *event.RequestReceived) {
+	client := n.acl.GetClient(evt.From)
+	if client == nil {
+		n.log.Debug("request from non-client", "peer", evt.From.String())
+		return
+	}
+	client.LastActivity = n.base.Clock().GetCurrentTime()
+
+	switch evt.RequestType {
+	case codec.ReqTypeGetTelemetry:
+		// Any authenticated client may read telemetry; guests get base only.
+		if n.cfg.Telemetry == nil {
+			return
+		}
+		n.sendTagged(evt.Reply, evt.From, evt.Tag, telemetry.Encode(n.cfg.Telemetry, evt.RequestData, client.Permissions))
+	case codec.ReqTypeGetMinMaxAvg:
+		// Read-only or better (firmware); guests get no reply.
+		body, ok := telemetry.QueryMinMaxAvg(n.history, n.base.Clock().GetCurrentTime(), evt.RequestData, client.Permissions)
+		if !ok {
+			return
+		}
+		n.sendTagged(evt.Reply, evt.From, evt.Tag, body)
+	case codec.ReqTypeGetAccessList:
+		if !client.IsAdmin() {
+			return
+		}
+		if len(evt.RequestData) >= 2 && (evt.RequestData[0] != 0 || evt.RequestData[1] != 0) {
+			return // reserved params must be zero
+		}
+		if err := n.base.SendReply(evt.Reply, evt.From, codec.PayloadTypeResponse, buildAccessList(n.acl, evt.Tag)); err != nil {
+			n.log.Warn("failed to send access list", "error", err)
+		}
+	default:
+		n.log.Debug("unhandled sensor request", "type", evt.RequestType, "peer", evt.From.String())
+	}
+}
+
+// sendTagged replies with tag(4) + body.
+func (n *SensorNode) sendTagged(reply event.ReplyContext, to core.MeshCoreID, tag uint32, body []byte) {
+	resp := make([]byte, 4+len(body))
+	binary.LittleEndian.PutUint32(resp[0:4], tag)
+	copy(resp[4:], body)
+	if err := n.base.SendReply(reply, to, codec.PayloadTypeResponse, resp); err != nil {
+		n.log.Warn("failed to send response", "error", err)
+	}
+}
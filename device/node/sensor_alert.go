@@ -0,0 +1,151 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/ack"
+	"github.com/kabili207/meshcore-go/device/acl"
+	"github.com/kabili207/meshcore-go/device/telemetry"
+)
+
+// AlertRule is a threshold on one sensor reading. When a sample crosses a
+// threshold the alert fires once and stays latched until the reading returns
+// inside the range, matching the firmware's alertIf trigger semantics.
+type AlertRule struct {
+	// Name identifies the rule in alert text and the "alerts" CLI listing.
+	Name string
+
+	// Channel and Type select the CayenneLPP reading the rule watches.
+	Channel uint8
+	Type    uint8
+
+	// High fires the alert when the reading rises above it. Nil disables.
+	High *float64
+
+	// Low fires the alert when the reading falls below it. Nil disables.
+	Low *float64
+}
+
+// alertState is the latch for one rule.
+type alertState struct {
+	rule      AlertRule
+	triggered bool
+	last      float64
+}
+
+// alertSet evaluates AlertRules against sample batches.
+type alertSet struct {
+	mu         sync.Mutex
+	rules      []alertState
+	maxRetries int
+}
+
+func newAlertSet(rules []AlertRule, maxRetries int) *alertSet {
+	s := &alertSet{maxRetries: maxRetries}
+	for _, r := range rules {
+		s.rules = append(s.rules, alertState{rule: r})
+	}
+	return s
+}
+
+// evaluate updates each rule's latch from samples and returns the alert text
+// for rules that fired on this batch.
+func (s *alertSet) evaluate(samples []telemetry.Sample) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fired []string
+	for i := range s.rules {
+		st := &s.rules[i]
+		for _, smp := range samples {
+			if smp.Channel != st.rule.Channel || smp.Type != st.rule.Type {
+				continue
+			}
+			st.last = smp.Value
+			text := st.rule.check(smp.Value)
+			if text == "" {
+				st.triggered = false
+				continue
+			}
+			if !st.triggered {
+				st.triggered = true
+				fired = append(fired, text)
+			}
+		}
+	}
+	return fired
+}
+
+// check returns the alert text if v is outside the rule's range.
+func (r AlertRule) check(v float64) string {
+	switch {
+	case r.High != nil && v > *r.High:
+		return fmt.Sprintf("%s high: %.2f", r.Name, v)
+	case r.Low != nil && v < *r.Low:
+		return fmt.Sprintf("%s low: %.2f", r.Name, v)
+	}
+	return ""
+}
+
+// summary lists each rule with its latch state, one per line.
+func (s *alertSet) summary() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rules) == 0 {
+		return "(no alerts)"
+	}
+	var b strings.Builder
+	for _, st := range s.rules {
+		state := "ok"
+		if st.triggered {
+			state = "ALERT"
+		}
+		fmt.Fprintf(&b, "%s ch=%d last=%.2f %s\n", st.rule.Name, st.rule.Channel, st.last, state)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// checkAlerts is the sampler's OnSample hook: it evaluates the alert rules and
+// sends each newly fired alert to every admin client.
+func (n *SensorNode) checkAlerts(samples []telemetry.Sample) {
+	for _, text := range n.alerts.evaluate(samples) {
+		n.log.Info("sensor alert", "text", text)
+		n.acl.ForEach(func(c *acl.Client) bool {
+			if c.IsAdmin() {
+				n.sendAlert(c.ID, n.base.Clock().GetCurrentTime(), text, 0)
+			}
+			return true
+		})
+	}
+}
+
+// sendAlert sends an alert as a plain DM and tracks its ACK. On timeout it is
+// resent with the next attempt number, so each retry has a distinct packet and
+// ACK hash, until MaxRetries is exhausted.
+func (n *SensorNode) sendAlert(to core.MeshCoreID, timestamp uint32, text string, attempt int) {
+	content := codec.BuildTxtMsgContent(timestamp, codec.TxtTypePlain, uint8(attempt), text, nil)
+
+	// Plain-text ACKs are keyed by the sender's (our) public key.
+	pub := n.base.PublicKey()
+	ackHash := crypto.ComputeAckHash(content, pub[:])
+	n.ackTracker.Track(ackHash, ack.PendingACK{
+		OnTimeout: func() {
+			if attempt < n.alerts.maxRetries {
+				n.sendAlert(to, timestamp, text, attempt+1)
+			} else {
+				n.log.Warn("alert not acknowledged", "peer", to.String())
+			}
+		},
+	})
+
+	if err := n.base.SendToContact(to, codec.PayloadTypeTxtMsg, content); err != nil {
+		n.ackTracker.Cancel(ackHash)
+		n.log.Warn("failed to send alert", "peer", to.String(), "error", err)
+	}
+}
@@ -0,0 +1,129 @@
+package node
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/cli"
+	"github.com/kabili207/meshcore-go/device/event"
+)
+
+// handleCLIMessage runs an admin CLI command received as a TXT_TYPE_CLI message
+// and replies with the result. Non-admin senders and non-CLI text are ignored.
+func (n *SensorNode) handleCLIMessage(evt *event.TextMessageReceived) {
+	if evt.TxtType != codec.TxtTypeCLI {
+		return
+	}
+	client := n.acl.GetClient(evt.From)
+	if client == nil || !client.IsAdmin() {
+		return
+	}
+	client.LastActivity = n.base.Clock().GetCurrentTime()
+
+	cmd := evt.Message
+	prefix := ""
+	if len(cmd) > 4 && cmd[2] == '|' {
+		prefix = cmd[:3]
+		cmd = cmd[3:]
+	}
+
+	reply := n.cli.Execute(cmd)
+	if reply == "" {
+		return
+	}
+	content := codec.BuildTxtMsgContent(n.base.Clock().GetCurrentTime(), codec.TxtTypeCLI, 0, prefix+reply, nil)
+	if err := n.base.SendReply(evt.Reply, evt.From, codec.PayloadTypeTxtMsg, content); err != nil {
+		n.log.Warn("failed to send CLI reply", "error", err)
+	}
+}
+
+// buildCLI constructs the sensor's CLI dispatcher. Called once from NewSensor.
+func (n *SensorNode) buildCLI() *cli.Dispatcher {
+	d := cli.New()
+
+	// --- Config keys ---
+	d.Key("name", cli.ConfigKey{
+		Get: func() string { return n.appData.Name },
+		Set: func(v string) error { n.appData.Name = v; return nil },
+	})
+	d.Key("lat", cli.ConfigKey{
+		Get: func() string { return formatCoord(n.appData.Lat) },
+		Set: func(v string) error { return setCoord(&n.appData.Lat, v, "bad latitude") },
+	})
+	d.Key("lon", cli.ConfigKey{
+		Get: func() string { return formatCoord(n.appData.Lon) },
+		Set: func(v string) error { return setCoord(&n.appData.Lon, v, "bad longitude") },
+	})
+	d.Key("advert.interval", cli.ConfigKey{
+		Get: func() string { return strconv.Itoa(int(n.advertSched.LocalInterval())) },
+		Set: func(v string) error {
+			iv, err := parseInterval(v)
+			if err != nil {
+				return err
+			}
+			n.advertSched.UpdateIntervals(iv, n.advertSched.FloodInterval())
+			return nil
+		},
+	})
+	d.Key("flood.advert.interval", cli.ConfigKey{
+		Get: func() string { return strconv.Itoa(int(n.advertSched.FloodInterval())) },
+		Set: func(v string) error {
+			iv, err := parseInterval(v)
+			if err != nil {
+				return err
+			}
+			n.advertSched.UpdateIntervals(n.advertSched.LocalInterval(), iv)
+			return nil
+		},
+	})
+	d.Key("owner.info", cli.ConfigKey{
+		Get: func() string { return n.cfg.OwnerInfo },
+		Set: func(v string) error { n.cfg.OwnerInfo = v; return nil },
+	})
+
+	// --- Read-only keys ---
+	d.Key("public.key", cli.ConfigKey{Get: func() string {
+		pk := n.base.PublicKey()
+		return hex.EncodeToString(pk[:])
+	}})
+	d.Key("role", cli.ConfigKey{Get: func() string { return "sensor" }})
+	d.Key("acl", cli.ConfigKey{Get: func() string { return formatACL(n.acl) }})
+
+	// --- Commands ---
+	d.Command("ver", func([]string) string { return n.cliVer() })
+	d.Command("version", func([]string) string { return n.cliVer() })
+	d.Command("clock", func([]string) string {
+		t := time.Unix(int64(n.base.Clock().GetCurrentTime()), 0).UTC()
+		return fmt.Sprintf("%02d:%02d - %02d/%02d/%04d UTC",
+			t.Hour(), t.Minute(), t.Day(), t.Month(), t.Year())
+	})
+	d.Command("time", func(args []string) string { return cli.SetClock(n.cfg.OnSetClock, args) })
+	d.Command("reboot", func([]string) string { return cli.Reboot(n.cfg.OnReboot) })
+	d.Command("advert", func([]string) string { n.advertSched.SendNow(true); return "OK" })
+	d.Command("advert.zerohop", func([]string) string { n.advertSched.SendNow(false); return "OK" })
+	d.Command("password", func(args []string) string {
+		if len(args) < 1 {
+			return "Error: usage: password <new>"
+		}
+		n.auth.AdminPassword = args[0]
+		return "OK"
+	})
+	d.Command("setperm", func(args []string) string { return setPermByPrefix(n.acl, args) })
+	d.Command("alerts", func([]string) string { return n.alerts.summary() })
+	cli.RegisterBlocklist(d, n.base.Blocklist(), n.base.Contacts(), n.base.Clock().GetCurrentTime)
+
+	if n.cfg.OnSettingChanged != nil {
+		d.AfterSet(n.cfg.OnSettingChanged)
+	}
+	return d
+}
+
+func (n *SensorNode) cliVer() string {
+	if n.cfg.Version != "" {
+		return n.cfg.Version
+	}
+	return cli.FormatVersion("", n.cfg.FirmwareBuildDate)
+}
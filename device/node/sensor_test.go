@@ -0,0 +1,186 @@
+package node
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	cayennelpp "github.com/TheThingsNetwork/go-cayenne-lib"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/telemetry"
+	"github.com/kabili207/meshcore-go/transport"
+)
+
+// sensorReading is a telemetry provider with one adjustable temperature reading.
+type sensorReading struct{ temp float64 }
+
+func (s *sensorReading) QuerySensors(_ uint8, enc cayennelpp.Encoder) {
+	enc.AddTemperature(telemetry.ChannelSelf, s.temp)
+}
+
+func newTestSensor(t *testing.T, p telemetry.Provider, alerts ...AlertRule) (*SensorNode, *captureTransport) {
+	t.Helper()
+	kp, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("generate keypair: %v", err)
+	}
+	n, err := NewSensor(SensorConfig{
+		PrivateKey:    ed25519.PrivateKey(kp.PrivateKey),
+		AdminPassword: "adminpw",
+		GuestPassword: "guestpw",
+		Telemetry:     p,
+		Alerts:        alerts,
+	})
+	if err != nil {
+		t.Fatalf("new sensor: %v", err)
+	}
+	ct := &captureTransport{}
+	n.base.Router.AddTransport(ct, transport.PacketSourceMQTT)
+	return n, ct
+}
+
+// sensorAsRepeater lets the repeater packet-building helpers, which only use
+// the base node, address the sensor.
+func sensorAsRepeater(n *SensorNode) *RepeaterNode { return &RepeaterNode{base: n.base} }
+
+func countTxtMsgs(ct *captureTransport) int {
+	count := 0
+	for _, p := range ct.sent {
+		if p.PayloadType() == codec.PayloadTypeTxtMsg {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSensor_AdvertType(t *testing.T) {
+	n, _ := newTestSensor(t, nil)
+	if n.appData.NodeType != codec.NodeTypeSensor {
+		t.Errorf("node type = %d, want sensor", n.appData.NodeType)
+	}
+	if got := n.ExecuteCLI("get role"); !strings.Contains(got, "sensor") {
+		t.Errorf("get role = %q, want sensor", got)
+	}
+}
+
+func TestSensor_Telemetry(t *testing.T) {
+	n, ct := newTestSensor(t, &sensorReading{temp: 21.5})
+	r := sensorAsRepeater(n)
+	client, _ := crypto.GenerateKeyPair()
+	n.base.processPacket(buildRepeaterLogin(t, r, client, 100, "guestpw"), transport.PacketSourceMQTT)
+
+	n.base.processPacket(buildRepeaterReq(t, r, client, 200, codec.ReqTypeGetTelemetry, []byte{0}), transport.PacketSourceMQTT)
+	resp := lastResponse(ct)
+	if resp == nil {
+		t.Fatal("expected a telemetry response")
+	}
+	pt := decryptRepeaterResponse(t, r, client, resp)
+	if tag := binary.LittleEndian.Uint32(pt[0:4]); tag != 200 {
+		t.Errorf("tag = %d, want 200", tag)
+	}
+	readings, _ := telemetry.ParseReadings(pt[4:])
+	if len(readings) == 0 || readings[0].Value != 21.5 {
+		t.Errorf("readings = %+v, want 21.5°C", readings)
+	}
+}
+
+func TestSensor_MinMaxAvg(t *testing.T) {
+	reading := &sensorReading{}
+	n, ct := newTestSensor(t, reading)
+	r := sensorAsRepeater(n)
+	n.base.Clock().SetCurrentTime(10000)
+	for _, v := range []float64{10, 20, 30} {
+		reading.temp = v
+		n.SampleNow()
+	}
+
+	admin, _ := crypto.GenerateKeyPair()
+	loginAdmin(t, r, admin)
+	n.base.processPacket(buildRepeaterReq(t, r, admin, 300, codec.ReqTypeGetMinMaxAvg,
+		telemetry.BuildMinMaxAvgRequest(3600, 0)), transport.PacketSourceMQTT)
+
+	resp := lastResponse(ct)
+	if resp == nil {
+		t.Fatal("expected a min/max/avg response")
+	}
+	pt := decryptRepeaterResponse(t, r, admin, resp)
+	if tag := binary.LittleEndian.Uint32(pt[0:4]); tag != 300 {
+		t.Errorf("tag = %d, want 300", tag)
+	}
+	_, stats, err := telemetry.ParseMinMaxAvg(pt[4:])
+	if err != nil || len(stats) != 1 {
+		t.Fatalf("ParseMinMaxAvg = %+v, %v", stats, err)
+	}
+	if s := stats[0]; s.Min != 10 || s.Max != 30 || s.Avg != 20 {
+		t.Errorf("stats = %+v, want 10/30/20", s)
+	}
+}
+
+func TestSensor_MinMaxAvgGuestDenied(t *testing.T) {
+	n, ct := newTestSensor(t, &sensorReading{temp: 5})
+	r := sensorAsRepeater(n)
+	n.SampleNow()
+	guest, _ := crypto.GenerateKeyPair()
+	n.base.processPacket(buildRepeaterLogin(t, r, guest, 100, "guestpw"), transport.PacketSourceMQTT)
+	before := countResponses(ct)
+
+	n.base.processPacket(buildRepeaterReq(t, r, guest, 200, codec.ReqTypeGetMinMaxAvg,
+		telemetry.BuildMinMaxAvgRequest(3600, 0)), transport.PacketSourceMQTT)
+	if countResponses(ct) != before {
+		t.Error("guest should not receive a min/max/avg response")
+	}
+}
+
+func TestSensor_AlertLatchesAndRearms(t *testing.T) {
+	high := 25.0
+	reading := &sensorReading{temp: 20}
+	n, ct := newTestSensor(t, reading, AlertRule{Name: "Temp", Channel: telemetry.ChannelSelf, Type: 103, High: &high})
+	r := sensorAsRepeater(n)
+	admin, _ := crypto.GenerateKeyPair()
+	loginAdmin(t, r, admin)
+
+	n.SampleNow()
+	if countTxtMsgs(ct) != 0 {
+		t.Fatal("no alert expected while in range")
+	}
+
+	reading.temp = 30
+	n.SampleNow()
+	n.SampleNow() // still high: latched, no second alert
+	if got := countTxtMsgs(ct); got != 1 {
+		t.Fatalf("alerts sent = %d, want 1", got)
+	}
+	pt := decryptRepeaterResponse(t, r, admin, ct.sent[len(ct.sent)-1])
+	content, err := codec.ParseTxtMsgContent(pt)
+	if err != nil {
+		t.Fatalf("parse alert: %v", err)
+	}
+	if content.TxtType != codec.TxtTypePlain || !strings.HasPrefix(content.Message, "Temp high") {
+		t.Errorf("alert = %+v, want plain \"Temp high ...\"", content)
+	}
+
+	// The alert is pending until the admin ACKs it (hash keyed by the sensor's key).
+	pub := n.base.PublicKey()
+	hash := crypto.ComputeAckHash(codec.TrimTxtMsgContent(pt, content), pub[:])
+	if !n.ackTracker.Resolve(hash) {
+		t.Error("alert ACK hash was not pending")
+	}
+
+	reading.temp = 20
+	n.SampleNow()
+	reading.temp = 31
+	n.SampleNow()
+	if got := countTxtMsgs(ct); got != 2 {
+		t.Errorf("alerts sent after re-arm = %d, want 2", got)
+	}
+}
+
+func TestSensor_CLIAlerts(t *testing.T) {
+	low := 3.3
+	n, _ := newTestSensor(t, nil, AlertRule{Name: "Battery", Channel: 1, Type: telemetry.LPPVoltage, Low: &low})
+	if got := n.ExecuteCLI("alerts"); !strings.HasPrefix(got, "Battery ch=1") {
+		t.Errorf("alerts = %q", got)
+	}
+}
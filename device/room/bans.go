@@ -0,0 +1,141 @@
+package room
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/kabili207/meshcore-go/core"
+)
+
+// Ban is one entry in a BanList.
+type Ban struct {
+	ID     core.MeshCoreID
+	Since  uint32 // room clock time the ban was added
+	Reason string
+}
+
+// persistedBan is the on-disk JSON form of a Ban.
+type persistedBan struct {
+	ID     string `json:"id"` // hex-encoded 32-byte public key
+	Since  uint32 `json:"since,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// BanList is the set of clients barred from a room: banned clients cannot log
+// in or post. It is safe for concurrent use. A BanList opened with
+// OpenBanList is persisted to a JSON file on every change (atomically, via
+// temp file + rename); bans change rarely, so writes are not debounced.
+type BanList struct {
+	path string
+
+	mu   sync.RWMutex
+	bans map[core.MeshCoreID]Ban
+}
+
+// NewBanList creates an empty, in-memory ban list.
+func NewBanList() *BanList {
+	return &BanList{bans: make(map[core.MeshCoreID]Ban)}
+}
+
+// OpenBanList loads a ban list from path, which need not exist yet. Changes are
+// written back to it.
+func OpenBanList(path string) (*BanList, error) {
+	l := NewBanList()
+	l.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, err
+	}
+	var records []persistedBan
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range records {
+		b, err := hex.DecodeString(r.ID)
+		if err != nil || len(b) != len(core.MeshCoreID{}) {
+			continue // skip malformed entry
+		}
+		var id core.MeshCoreID
+		copy(id[:], b)
+		l.bans[id] = Ban{ID: id, Since: r.Since, Reason: r.Reason}
+	}
+	return l, nil
+}
+
+// Ban adds or updates a ban.
+func (l *BanList) Ban(id core.MeshCoreID, since uint32, reason string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bans[id] = Ban{ID: id, Since: since, Reason: reason}
+	return l.saveLocked()
+}
+
+// Unban removes a ban. Returns false if id was not banned.
+func (l *BanList) Unban(id core.MeshCoreID) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.bans[id]; !ok {
+		return false, nil
+	}
+	delete(l.bans, id)
+	return true, l.saveLocked()
+}
+
+// IsBanned reports whether id is banned. A nil BanList bans nobody.
+func (l *BanList) IsBanned(id core.MeshCoreID) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.bans[id]
+	return ok
+}
+
+// List returns all bans, oldest first.
+func (l *BanList) List() []Ban {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]Ban, 0, len(l.bans))
+	for _, b := range l.bans {
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Since != out[j].Since {
+			return out[i].Since < out[j].Since
+		}
+		return out[i].ID.String() < out[j].ID.String()
+	})
+	return out
+}
+
+// saveLocked writes the list to its file, if it has one. Must be called with
+// l.mu held.
+func (l *BanList) saveLocked() error {
+	if l.path == "" {
+		return nil
+	}
+	records := make([]persistedBan, 0, len(l.bans))
+	for _, b := range l.bans {
+		records = append(records, persistedBan{ID: hex.EncodeToString(b.ID[:]), Since: b.Since, Reason: b.Reason})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
@@ -33,7 +33,7 @@ func (s *Server) handleCLICommand(pkt *codec.Packet, senderID core.MeshCoreID, s
 		"peer", senderID.String(),
 		"cmd", cmd)
 
-	reply := s.executeCLI(cmd)
+	reply := s.runCLI(senderID, cmd)
 	if reply == "" {
 		return
 	}
@@ -57,7 +57,7 @@ func (s *Server) executeCLI(cmd string) string {
 // over-the-air path), so a local console bridge may drive it concurrently with
 // remote admin CLI. Returns "" for commands that produce no reply.
 func (s *Server) ExecuteCLI(cmd string) string {
-	return s.executeCLI(cmd)
+	return s.runCLI(core.MeshCoreID{}, cmd)
 }
 
 // SetConfig applies a CLI config key programmatically, firing OnSettingChanged
@@ -286,9 +286,21 @@ func (s *Server) buildCLI() *cli.Dispatcher {
 	d.Command("password", func(args []string) string { return s.cliPassword(args) })
 	d.Command("setperm", func(args []string) string { return s.cliSetPerm(args) })
 	d.Command("region", func(args []string) string { return s.cliRegion(args) })
+	d.Command("posts", func(args []string) string { return s.cliPosts(args) })
+	d.Command("delpost", func(args []string) string { return s.cliDelPost(args) })
+	d.Command("kick", func(args []string) string { return s.cliKick(args) })
+	// Bans keep a client out of this room; blocks silence a peer across the
+	// whole node. A blocked peer's messages never reach the room, so a ban is
+	// only needed to refuse logins and drop a client that is connected.
+	d.Command("ban", func(args []string) string { return s.cliBan(args) })
+	d.Command("unban", func(args []string) string { return s.cliUnban(args) })
+	d.Command("bans", func([]string) string { return s.cliBans() })
+	cli.RegisterBlocklist(d, s.blocklist, s.cfg.Contacts, s.cfg.Clock.GetCurrentTime)
+	d.Command("federation", func([]string) string { return s.cliFederation() })
 	d.Command("stats-packets", func([]string) string { return s.cfg.Router.Counters().Snapshot().String() })
 	d.Command("stats-core", func([]string) string { return s.cliStatsCore() })
 	d.Command("stats-radio", func([]string) string { return "unsupported" })
+	d.Command("stats-sync", func([]string) string { return s.cliStatsSync() })
 	d.Command("clear", func(args []string) string {
 		if len(args) >= 1 && args[0] == "stats" {
 			return s.cliClearStats()
@@ -423,7 +435,7 @@ func (s *Server) cliSetPerm(args []string) string {
 	// Search for matching client by public key prefix
 	var matched *ClientInfo
 	s.cfg.Clients.ForEach(func(c *ClientInfo) bool {
-		if matchesPubKeyPrefix(c.ID[:], pubKeyBytes) {
+		if c.ID.IsHashMatch(pubKeyBytes) {
 			matched = c
 			return false
 		}
@@ -441,6 +453,207 @@ func (s *Server) cliSetPerm(args []string) string {
 	return "OK"
 }
 
+// cliStatsSync reports the post sync scheduler's queues and lag.
+func (s *Server) cliStatsSync() string {
+	st := s.SyncStats()
+	return fmt.Sprintf("clients=%d posts=%d inflight=%d max_lag=%ds avg_lag=%ds",
+		st.PendingClients, st.PendingPosts, st.InFlight, st.MaxLagSecs, st.AvgLagSecs)
+}
+
+// cliPostsDefault is how many posts "posts" lists without an argument.
+const cliPostsDefault = 5
+
+// cliPostPreviewLen caps the text shown per post by "posts".
+const cliPostPreviewLen = 32
+
+// cliPosts lists the newest posts, oldest first, one per line as
+// "<timestamp> <sender> <text>". The timestamp is the key for "delpost".
+func (s *Server) cliPosts(args []string) string {
+	n := cliPostsDefault
+	if len(args) >= 1 {
+		v, err := strconv.Atoi(args[0])
+		if err != nil || v <= 0 {
+			return "Error: usage: posts [count]"
+		}
+		n = v
+	}
+	posts := s.cfg.Posts.GetPostsSince(0)
+	if len(posts) > n {
+		posts = posts[len(posts)-n:]
+	}
+	if len(posts) == 0 {
+		return "(no posts)"
+	}
+	var b strings.Builder
+	for _, p := range posts {
+		text := ""
+		if c, err := codec.ParseTxtMsgContent(p.Content); err == nil {
+			text = c.Message
+		}
+		if r := []rune(text); len(r) > cliPostPreviewLen {
+			text = string(r[:cliPostPreviewLen]) + "..."
+		}
+		fmt.Fprintf(&b, "%d %s %s\n", p.Timestamp, p.SenderID.String()[:12], text)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// cliDelPost deletes a post by its timestamp, as listed by "posts".
+func (s *Server) cliDelPost(args []string) string {
+	if len(args) < 1 {
+		return "Error: usage: delpost <timestamp>"
+	}
+	ts, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return "Error: bad timestamp"
+	}
+	found, err := DeletePost(s.cfg.Posts, uint32(ts))
+	switch {
+	case errors.Is(err, ErrPostDeleteUnsupported):
+		return "ERR: unsupported"
+	case err != nil:
+		s.log.Warn("failed to delete post", "timestamp", ts, "error", err)
+		return "ERR: " + err.Error()
+	case !found:
+		return "ERR: post not found"
+	}
+	s.sync.invalidate()
+	s.log.Info("post deleted", "timestamp", ts)
+	return "OK"
+}
+
+// cliKick logs a client out by removing it from the client table. It may log
+// in again; use "ban" to keep it out.
+func (s *Server) cliKick(args []string) string {
+	if len(args) < 1 {
+		return "Error: usage: kick <pubkey-hex>"
+	}
+	c, errMsg := s.findClientByPrefix(args[0])
+	if c == nil {
+		return errMsg
+	}
+	s.removeClient(c.ID, LogoutKicked)
+	s.log.Info("client kicked", "peer", c.ID.String())
+	return "OK"
+}
+
+// cliBan bans a client, identified by a prefix of a connected client's key or
+// by a full 64-character public key, and removes it from the client table.
+// Any further arguments are recorded as the reason.
+func (s *Server) cliBan(args []string) string {
+	if len(args) < 1 {
+		return "Error: usage: ban <pubkey-hex> [reason]"
+	}
+	var id core.MeshCoreID
+	if c, errMsg := s.findClientByPrefix(args[0]); c != nil {
+		id = c.ID
+	} else if b, err := hex.DecodeString(args[0]); err == nil && len(b) == len(id) {
+		copy(id[:], b)
+	} else {
+		return errMsg
+	}
+
+	if err := s.bans.Ban(id, s.cfg.Clock.GetCurrentTime(), strings.Join(args[1:], " ")); err != nil {
+		s.log.Warn("failed to save ban list", "error", err)
+		return "ERR: " + err.Error()
+	}
+	if s.cfg.Clients.GetClient(id) != nil {
+		s.removeClient(id, LogoutBanned)
+	}
+	s.log.Info("client banned", "peer", id.String())
+	return "OK"
+}
+
+// cliUnban lifts a ban, identified by a prefix of the banned key.
+func (s *Server) cliUnban(args []string) string {
+	if len(args) < 1 {
+		return "Error: usage: unban <pubkey-hex>"
+	}
+	prefix, err := hex.DecodeString(args[0])
+	if err != nil || len(prefix) == 0 {
+		return "ERR: bad pubkey"
+	}
+	for _, b := range s.bans.List() {
+		if !b.ID.IsHashMatch(prefix) {
+			continue
+		}
+		if _, err := s.bans.Unban(b.ID); err != nil {
+			s.log.Warn("failed to save ban list", "error", err)
+			return "ERR: " + err.Error()
+		}
+		s.log.Info("client unbanned", "peer", b.ID.String())
+		return "OK"
+	}
+	return "ERR: not banned"
+}
+
+// cliBans lists the ban list, one entry per line as "<id> since=<ts> [reason]".
+func (s *Server) cliBans() string {
+	bans := s.bans.List()
+	if len(bans) == 0 {
+		return "(no bans)"
+	}
+	var b strings.Builder
+	for _, ban := range bans {
+		fmt.Fprintf(&b, "%s since=%d", ban.ID.String()[:12], ban.Since)
+		if ban.Reason != "" {
+			b.WriteString(" " + ban.Reason)
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// cliFederation lists the federation peers, whether each is logged in here,
+// and the sync point reached with it.
+func (s *Server) cliFederation() string {
+	peers := s.FederationPeers()
+	if len(peers) == 0 {
+		return "(no peers)"
+	}
+	var b strings.Builder
+	for _, p := range peers {
+		state := "out"
+		if s.cfg.Clients.GetClient(p.ID) != nil {
+			state = "in"
+		}
+		fmt.Fprintf(&b, "%s %s sync=%d\n", p.ID.String()[:12], state, p.SyncSince)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// findClientByPrefix returns the first client whose key starts with the given
+// hex prefix, or nil and the CLI error to report.
+func (s *Server) findClientByPrefix(prefixHex string) (*ClientInfo, string) {
+	prefix, err := hex.DecodeString(prefixHex)
+	if err != nil || len(prefix) == 0 {
+		return nil, "ERR: bad pubkey"
+	}
+	var matched *ClientInfo
+	s.cfg.Clients.ForEach(func(c *ClientInfo) bool {
+		if c.ID.IsHashMatch(prefix) {
+			matched = c
+			return false
+		}
+		return true
+	})
+	if matched == nil {
+		return nil, "ERR: client not found"
+	}
+	return matched, ""
+}
+
+// removeClient drops a client from the client table, forgets its rate-limit
+// and sync state, and reports the logout with the given reason (Logout*).
+func (s *Server) removeClient(id core.MeshCoreID, reason string) {
+	if err := s.cfg.Clients.RemoveClient(id); err != nil {
+		s.log.Warn("failed to remove client", "peer", id.String(), "error", err)
+	}
+	s.postLimiter.forget(id)
+	s.sync.forget(id)
+	s.emit(Event{Kind: EventClientLogout, Client: id, Reason: reason})
+}
+
 // StatsResetter is an optional interface that StatsProviders can implement
 // to support the "clear stats" CLI command.
 type StatsResetter interface {
@@ -462,16 +675,3 @@ func (s *Server) cliClearStats() string {
 func normalizeNumber(s string) string {
 	return strings.ReplaceAll(s, "\u2212", "-")
 }
-
-// matchesPubKeyPrefix returns true if fullKey starts with the given prefix.
-func matchesPubKeyPrefix(fullKey, prefix []byte) bool {
-	if len(prefix) > len(fullKey) {
-		return false
-	}
-	for i, b := range prefix {
-		if fullKey[i] != b {
-			return false
-		}
-	}
-	return true
-}
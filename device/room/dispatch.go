@@ -1,6 +1,8 @@
 package room
 
 import (
+	"time"
+
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
 	"github.com/kabili207/meshcore-go/core/crypto"
@@ -35,7 +37,7 @@ func (s *Server) handleAdvert(pkt *codec.Packet) {
 	}
 
 	nowTS := s.cfg.Clock.GetCurrentTime()
-	result := contact.ProcessAdvert(s.cfg.Contacts, advert, nowTS, true)
+	result := contact.ProcessAdvert(s.cfg.Contacts, advert, nowTS, true, contact.AdvertOptions{Blocklist: s.blocklist})
 	if result.Rejected {
 		s.log.Debug("advert rejected", "reason", result.RejectReason)
 	}
@@ -112,25 +114,32 @@ func (s *Server) handleAddressed(pkt *codec.Packet) {
 		return
 	}
 
-	// Try decrypting with each candidate's shared secret
+	// Try decrypting with each candidate's shared secret, and with the
+	// previous key's if the packet is addressed to the old identity.
+	toPrevious := s.previousKeyActive() && addrPayload.DestHash == s.prevID.Hash()
+	encrypted := codec.PrependMAC(addrPayload.MAC, addrPayload.Ciphertext)
 	for _, ct := range contactCandidates {
-		secret, err := s.cfg.Contacts.GetSharedSecret(ct.ID)
-		if err != nil {
+		secret, plaintext, ok := s.decryptFrom(ct.ID, encrypted, toPrevious)
+		if !ok {
 			continue
 		}
 
-		plaintext, err := crypto.DecryptAddressedWithSecret(codec.PrependMAC(addrPayload.MAC, addrPayload.Ciphertext), secret)
-		if err != nil {
-			continue
+		// Decryption succeeded — this is the sender. A blocked sender's
+		// messages are dropped unACKed, as BaseNode does on the event path.
+		if pkt.PayloadType() == codec.PayloadTypeTxtMsg && s.blocklist.IsBlocked(ct.ID) {
+			s.log.Debug("message from blocked peer dropped", "peer", ct.ID.String())
+			return
 		}
-
-		// Decryption succeeded — this is the sender
 		// PATH packets don't require the sender to be a client
 		if pkt.PayloadType() == codec.PayloadTypePath {
 			s.handleDecryptedPath(pkt, ct.ID, secret, plaintext)
 			return
 		}
 
+		if pkt.PayloadType() == codec.PayloadTypeTxtMsg && s.isPeer(ct.ID) && s.handleFederatedText(pkt, ct.ID, secret, plaintext) {
+			return
+		}
+
 		client := s.cfg.Clients.GetClient(ct.ID)
 		if client == nil {
 			s.log.Debug("addressed from non-client", "peer", ct.ID.String())
@@ -152,6 +161,44 @@ func (s *Server) handleAddressed(pkt *codec.Packet) {
 	s.log.Debug("could not decrypt addressed payload")
 }
 
+// decryptFrom decrypts an addressed payload from a contact with the current
+// key's shared secret, then with the previous key's if tryPrevious is set.
+// It returns the secret that worked, for encrypting the reply.
+func (s *Server) decryptFrom(id core.MeshCoreID, encrypted []byte, tryPrevious bool) (secret, plaintext []byte, ok bool) {
+	if secret, err := s.cfg.Contacts.GetSharedSecret(id); err == nil {
+		if plaintext, err := crypto.DecryptAddressedWithSecret(encrypted, secret); err == nil {
+			return secret, plaintext, true
+		}
+	}
+	if !tryPrevious {
+		return nil, nil, false
+	}
+	secret, err := s.previousSecret(id)
+	if err != nil {
+		return nil, nil, false
+	}
+	plaintext, err = crypto.DecryptAddressedWithSecret(encrypted, secret)
+	if err != nil {
+		return nil, nil, false
+	}
+	return secret, plaintext, true
+}
+
+// previousKeyActive reports whether a previous key is configured and its
+// grace period has not ended.
+func (s *Server) previousKeyActive() bool {
+	return s.cfg.PreviousKey != nil && (s.cfg.PreviousKeyUntil.IsZero() || time.Now().Before(s.cfg.PreviousKeyUntil))
+}
+
+// previousSecret returns the shared secret between the previous key and a
+// contact, through the contact store's cache if it has one.
+func (s *Server) previousSecret(id core.MeshCoreID) ([]byte, error) {
+	if src, ok := s.cfg.Contacts.(contact.SecretSource); ok {
+		return src.SharedSecretWith(s.cfg.PreviousKey, id)
+	}
+	return crypto.ComputeSharedSecret(s.cfg.PreviousKey, id[:])
+}
+
 // handleTextMessage processes a decrypted text message from a client.
 func (s *Server) handleTextMessage(pkt *codec.Packet, client *ClientInfo, senderID core.MeshCoreID, secret, plaintext []byte) {
 	if len(plaintext) < 5 {
@@ -188,21 +235,22 @@ func (s *Server) handleTextMessage(pkt *codec.Packet, client *ClientInfo, sender
 			}
 		}
 
-		// Store the post (use unpadded content so sync ACK hashes match)
-		nowTS := s.cfg.Clock.GetCurrentTimeUnique()
-		_ = s.cfg.Posts.AddPost(&PostInfo{
-			Timestamp: nowTS,
-			SenderID:  senderID,
-			Content:   ackData,
-		})
-		if s.cfg.PostCounter != nil {
-			s.cfg.PostCounter.IncrementPosted()
+		// A moderated-out post is still ACKed, as on the event path where
+		// BaseNode ACKs before the room sees it: retries would only be
+		// dropped again.
+		if message, ok := s.moderatePost(client, content.Message); ok {
+			// Store the post (use unpadded content so sync ACK hashes match)
+			postData := ackData
+			if message != content.Message {
+				postData = buildPostContent(content.Timestamp, message)
+			}
+			s.storePost(&PostInfo{
+				Timestamp: s.cfg.Clock.GetCurrentTimeUnique(),
+				SenderID:  senderID,
+				Content:   postData,
+			})
 		}
 
-		s.log.Debug("post stored",
-			"sender", senderID.String(),
-			"timestamp", nowTS)
-
 		// Send ACK back (plain text only — firmware doesn't ACK CLI commands).
 		// Since v1.16 plain text-message ACKs are the 6-byte extended form: the
 		// 4-byte hash, the sender's tail attempt byte, and a random byte.
@@ -217,6 +265,24 @@ func (s *Server) handleTextMessage(pkt *codec.Packet, client *ClientInfo, sender
 	}
 }
 
+// handleFederatedText ACKs and imports a federated post pushed by a peer room.
+// It reports false if the message is not a federated post, so a peer that is
+// also a client can still be handled as one.
+func (s *Server) handleFederatedText(pkt *codec.Packet, peerID core.MeshCoreID, secret, plaintext []byte) bool {
+	content, err := codec.ParseTxtMsgContent(plaintext)
+	if err != nil || content.TxtType != codec.TxtTypeFederated {
+		return false
+	}
+
+	// Like any signed push, the ACK is keyed by the recipient's (our) pubkey.
+	ackData := codec.TrimTxtMsgContent(plaintext, content)
+	ackHash := crypto.ComputeAckHash(ackData, s.cfg.PublicKey[:])
+	s.sendACK(pkt, peerID, secret, codec.BuildAckPayload(ackHash))
+
+	s.handleFederatedPush(peerID, content.Timestamp, content)
+	return true
+}
+
 // handleRequest processes a decrypted REQ from a client.
 func (s *Server) handleRequest(pkt *codec.Packet, client *ClientInfo, senderID core.MeshCoreID, secret, plaintext []byte) {
 	if len(plaintext) < 5 {
@@ -249,10 +315,25 @@ func (s *Server) handleRequest(pkt *codec.Packet, client *ClientInfo, senderID c
 		s.log.Debug("get_telemetry", "peer", senderID.String())
 		s.handleGetTelemetry(pkt, tag, client, senderID, secret, content.RequestData)
 
+	case codec.ReqTypeGetMinMaxAvg:
+		s.log.Debug("get_min_max_avg", "peer", senderID.String())
+		s.handleGetMinMaxAvg(pkt, tag, client, senderID, secret, content.RequestData)
+
+	case codec.ReqTypeGetOwnerInfo:
+		s.log.Debug("get_owner_info", "peer", senderID.String())
+		s.sendEncryptedResponse(pkt, senderID, secret, codec.PayloadTypeResponse, s.buildOwnerInfoResponse(tag))
+
 	case codec.ReqTypeGetAccessList:
 		s.log.Debug("get_access_list", "peer", senderID.String())
 		s.handleGetAccessList(pkt, tag, client, senderID, secret, content.RequestData)
 
+	case codec.ReqTypeGetPostHistory:
+		s.log.Debug("get_post_history", "peer", senderID.String())
+		limit := historyReplyLimit(pkt.IsFlood(), len(codec.ReverseFloodPath(pkt)))
+		if resp := s.buildPostHistoryResponse(tag, client, content.RequestData, limit); resp != nil {
+			s.sendEncryptedResponse(pkt, senderID, secret, codec.PayloadTypeResponse, resp)
+		}
+
 	default:
 		s.log.Debug("unhandled request type",
 			"type", codec.RequestTypeName(content.RequestType),
@@ -0,0 +1,192 @@
+package room
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+// EventKind identifies a room activity event.
+type EventKind string
+
+const (
+	// EventPostStored fires after a post is stored, from a client or AddPost.
+	EventPostStored EventKind = "post_stored"
+
+	// EventPostPushed fires when a client acknowledges a pushed post (or, with
+	// no ACK tracker, when the push is sent).
+	EventPostPushed EventKind = "post_pushed"
+
+	// EventPushFailed fires when a pushed post is not acknowledged in time.
+	EventPushFailed EventKind = "push_failed"
+
+	// EventClientLogin fires after a client logs in.
+	EventClientLogin EventKind = "client_login"
+
+	// EventClientLogout fires when a client leaves the client table: kicked,
+	// banned, or evicted to make room for another client.
+	EventClientLogout EventKind = "client_logout"
+
+	// EventCLICommand fires after a CLI command runs, remotely or through
+	// ExecuteCLI.
+	EventCLICommand EventKind = "cli_command"
+)
+
+// Logout reasons carried in EventClientLogout.
+const (
+	LogoutKicked = "kicked" // "kick" CLI command
+	LogoutBanned = "banned" // "ban" CLI command
+
+	// LogoutEvicted is reported for a client that left the client store by
+	// other means: at once when it is evicted to make room for a new login,
+	// or at the next sync round when the application removes it.
+	LogoutEvicted = "evicted"
+)
+
+// Event is one room activity event. Fields not relevant to Kind are zero.
+type Event struct {
+	Kind EventKind
+
+	// Time is the room clock time the event happened.
+	Time uint32
+
+	// Client is the client involved: the one logging in or out, receiving a
+	// push, or running a CLI command. Zero for posts stored and for commands
+	// run through ExecuteCLI.
+	Client core.MeshCoreID
+
+	// PostTimestamp and PostSender identify the post of post events.
+	PostTimestamp uint32
+	PostSender    core.MeshCoreID
+
+	// Text is the message of EventPostStored.
+	Text string
+
+	// Permissions is the granted ACL permissions of EventClientLogin.
+	Permissions uint8
+
+	// Failures is the client's consecutive push failures, for EventPushFailed.
+	Failures int
+
+	// Reason is why the client left, for EventClientLogout (Logout*).
+	Reason string
+
+	// Command and Reply are the command line and reply of EventCLICommand.
+	// The argument of a "password" command is redacted.
+	Command string
+	Reply   string
+}
+
+// eventJSON is the JSON form of an Event, as sent by WebhookSink and written
+// by JSONLinesSink. Public keys are hex-encoded.
+type eventJSON struct {
+	Kind          EventKind `json:"kind"`
+	Time          uint32    `json:"time"`
+	Client        string    `json:"client,omitempty"`
+	PostTimestamp uint32    `json:"post_ts,omitempty"`
+	PostSender    string    `json:"post_sender,omitempty"`
+	Text          string    `json:"text,omitempty"`
+	Permissions   uint8     `json:"perms,omitempty"`
+	Failures      int       `json:"failures,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	Command       string    `json:"command,omitempty"`
+	Reply         string    `json:"reply,omitempty"`
+}
+
+// MarshalJSON encodes the event with snake_case keys, hex public keys, and
+// zero fields omitted.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(eventJSON{
+		Kind:          e.Kind,
+		Time:          e.Time,
+		Client:        hexID(e.Client),
+		PostTimestamp: e.PostTimestamp,
+		PostSender:    hexID(e.PostSender),
+		Text:          e.Text,
+		Permissions:   e.Permissions,
+		Failures:      e.Failures,
+		Reason:        e.Reason,
+		Command:       e.Command,
+		Reply:         e.Reply,
+	})
+}
+
+// hexID returns id in hex, or "" for the zero ID.
+func hexID(id core.MeshCoreID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// EventSink receives room activity events. HandleEvent is called on the room's
+// receive and sync paths, so it must not block; sinks that do I/O should
+// queue, as WebhookSink does.
+type EventSink interface {
+	HandleEvent(e Event)
+}
+
+// EventSinkFunc adapts a function to an EventSink.
+type EventSinkFunc func(e Event)
+
+// HandleEvent calls f(e).
+func (f EventSinkFunc) HandleEvent(e Event) { f(e) }
+
+// AddEventSink registers a sink for room activity events, in addition to
+// ServerConfig.EventSinks.
+func (s *Server) AddEventSink(sink EventSink) {
+	s.sinksMu.Lock()
+	defer s.sinksMu.Unlock()
+	s.sinks = append(s.sinks[:len(s.sinks):len(s.sinks)], sink)
+}
+
+// emit stamps e with the current time and delivers it to every sink.
+func (s *Server) emit(e Event) {
+	s.sinksMu.RLock()
+	sinks := s.sinks
+	s.sinksMu.RUnlock()
+	if len(sinks) == 0 {
+		return
+	}
+	e.Time = s.cfg.Clock.GetCurrentTime()
+	for _, sink := range sinks {
+		sink.HandleEvent(e)
+	}
+}
+
+// emitPostStored reports a stored post.
+func (s *Server) emitPostStored(p *PostInfo) {
+	e := Event{Kind: EventPostStored, PostTimestamp: p.Timestamp, PostSender: p.SenderID}
+	if content, err := codec.ParseTxtMsgContent(p.Content); err == nil {
+		e.Text = content.Message
+	}
+	s.emit(e)
+}
+
+// runCLI executes a CLI command on behalf of from (zero for a local console)
+// and reports it.
+func (s *Server) runCLI(from core.MeshCoreID, cmd string) string {
+	reply := s.executeCLI(cmd)
+	redactedCmd, redactedReply := redactCLI(cmd, reply)
+	s.emit(Event{Kind: EventCLICommand, Client: from, Command: redactedCmd, Reply: redactedReply})
+	return reply
+}
+
+// redactCLI hides the passwords in a CLI command and its reply: the argument
+// of "password" and "set guest.password", and the reply to
+// "get guest.password".
+func redactCLI(cmd, reply string) (string, string) {
+	fields := strings.Fields(cmd)
+	switch {
+	case len(fields) > 1 && fields[0] == "password":
+		return "password ***", reply
+	case len(fields) > 2 && fields[0] == "set" && fields[1] == "guest.password":
+		return "set guest.password ***", reply
+	case len(fields) == 2 && fields[0] == "get" && fields[1] == "guest.password":
+		return cmd, "***"
+	}
+	return cmd, reply
+}
@@ -0,0 +1,296 @@
+package room
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultWebhookQueueSize is how many events a WebhookSink buffers before
+	// dropping new ones.
+	DefaultWebhookQueueSize = 256
+
+	// DefaultWebhookMaxRetries is how many times a WebhookSink retries a
+	// failed delivery.
+	DefaultWebhookMaxRetries = 3
+
+	// DefaultWebhookRetryDelay is the delay before a WebhookSink's first retry.
+	// It doubles with each further attempt.
+	DefaultWebhookRetryDelay = time.Second
+
+	// DefaultWebhookTimeout is the request timeout of a WebhookSink's default
+	// HTTP client.
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+// Compile-time assertions that the sinks implement EventSink.
+var (
+	_ EventSink = (*WebhookSink)(nil)
+	_ EventSink = (*JSONLinesSink)(nil)
+)
+
+// WebhookConfig configures a WebhookSink.
+type WebhookConfig struct {
+	// URL receives each event as a JSON POST. Required.
+	URL string
+
+	// Header is added to every request, e.g. for an Authorization token.
+	Header http.Header
+
+	// Client sends the requests. Default: an http.Client with
+	// DefaultWebhookTimeout.
+	Client *http.Client
+
+	// QueueSize bounds the events waiting for delivery; events arriving while
+	// it is full are dropped. Default: DefaultWebhookQueueSize.
+	QueueSize int
+
+	// MaxRetries is how many times a failed delivery is retried. Network
+	// errors, 429, and 5xx responses are retried; other responses are not.
+	// Default: DefaultWebhookMaxRetries. Negative disables retries.
+	MaxRetries int
+
+	// RetryDelay is the delay before the first retry, doubling after each.
+	// Default: DefaultWebhookRetryDelay.
+	RetryDelay time.Duration
+
+	// Logger for delivery failures. Falls back to slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// WebhookSink posts room events to an HTTP endpoint, one JSON object per
+// request, in order. Events are queued and delivered by a background
+// goroutine, so HandleEvent never blocks; call Close to deliver what is queued
+// and stop it.
+type WebhookSink struct {
+	cfg    WebhookConfig
+	log    *slog.Logger
+	queue  chan Event
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	closeOnce sync.Once
+	mu        sync.RWMutex // guards closed against sends on queue
+	closed    bool
+	dropped   atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// NewWebhookSink creates a WebhookSink and starts its delivery goroutine.
+func NewWebhookSink(cfg WebhookConfig) *WebhookSink {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: DefaultWebhookTimeout}
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultWebhookQueueSize
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultWebhookMaxRetries
+	} else if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultWebhookRetryDelay
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &WebhookSink{
+		cfg:    cfg,
+		log:    logger.WithGroup("webhook"),
+		queue:  make(chan Event, cfg.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// HandleEvent queues e for delivery, dropping it if the queue is full or the
+// sink is closed.
+func (w *WebhookSink) HandleEvent(e Event) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- e:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many events were dropped because the queue was full.
+func (w *WebhookSink) Dropped() uint64 { return w.dropped.Load() }
+
+// Failed returns how many events could not be delivered after all retries.
+func (w *WebhookSink) Failed() uint64 { return w.failed.Load() }
+
+// Close stops accepting events and waits for queued events to be delivered,
+// or until ctx is done, after which pending deliveries are abandoned.
+func (w *WebhookSink) Close(ctx context.Context) error {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		close(w.queue)
+		w.mu.Unlock()
+	})
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		<-w.done
+		return ctx.Err()
+	}
+}
+
+// run delivers queued events until the queue is closed and drained.
+func (w *WebhookSink) run() {
+	defer close(w.done)
+	defer w.cancel()
+	for e := range w.queue {
+		if w.ctx.Err() != nil {
+			w.failed.Add(1)
+			continue
+		}
+		if err := w.deliver(e); err != nil {
+			w.failed.Add(1)
+			w.log.Warn("webhook delivery failed", "kind", e.Kind, "error", err)
+		}
+	}
+}
+
+// deliver posts one event, retrying transient failures with backoff.
+func (w *WebhookSink) deliver(e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	delay := w.cfg.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.cfg.MaxRetries {
+			return err
+		}
+		select {
+		case <-time.After(delay):
+		case <-w.ctx.Done():
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// post sends one request. It reports whether a failure is worth retrying.
+func (w *WebhookSink) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range w.cfg.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.cfg.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook: %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook: %s", resp.Status)
+	}
+}
+
+// JSONLinesSink writes room events to a writer as JSON lines, one object per
+// event. Writes are synchronous, which is fine for local files; use a
+// WebhookSink or a queueing EventSinkFunc for slow writers.
+type JSONLinesSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	err    error
+}
+
+// NewJSONLinesSink creates a sink writing to w.
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: w}
+}
+
+// OpenJSONLinesSink creates a sink appending to the file at path, creating it
+// if needed. Close closes the file.
+func OpenJSONLinesSink(path string) (*JSONLinesSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLinesSink{w: f, closer: f}, nil
+}
+
+// HandleEvent writes e as one line. After a write error, events are dropped;
+// see Err.
+func (j *JSONLinesSink) HandleEvent(e Event) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err != nil {
+		return
+	}
+	if _, err := j.w.Write(line); err != nil {
+		j.err = err
+	}
+}
+
+// Err returns the first write error, or os.ErrClosed after Close.
+func (j *JSONLinesSink) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Close closes the underlying file, if the sink opened it. Later events are
+// dropped.
+func (j *JSONLinesSink) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err == nil {
+		j.err = os.ErrClosed
+	}
+	if j.closer == nil {
+		return nil
+	}
+	c := j.closer
+	j.closer = nil
+	return c.Close()
+}
@@ -0,0 +1,246 @@
+package room
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/acl"
+	"github.com/kabili207/meshcore-go/transport"
+)
+
+// eventRecorder is an EventSink that keeps every event.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *eventRecorder) HandleEvent(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// last returns the most recent event of the given kind.
+func (r *eventRecorder) last(kind EventKind) (Event, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if r.events[i].Kind == kind {
+			return r.events[i], true
+		}
+	}
+	return Event{}, false
+}
+
+func TestEvents_RoomActivity(t *testing.T) {
+	h := newTestHarness(t)
+	h.server.cfg.ACKTracker = nil // pushes count as delivered
+	rec := &eventRecorder{}
+	h.server.AddEventSink(rec)
+
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	h.server.HandlePacket(h.buildAnonReqPacketWithKey(t, clientKey, 100, 0, "admin123"), transport.PacketSourceMQTT)
+	if e, ok := rec.last(EventClientLogin); !ok || e.Client != clientID || e.Permissions != codec.PermACLAdmin {
+		t.Errorf("login event = %+v, %v", e, ok)
+	}
+
+	author := core.MeshCoreID{0x42}
+	p := h.server.AddPost(author, "hello room")
+	if e, ok := rec.last(EventPostStored); !ok || e.PostTimestamp != p.Timestamp || e.PostSender != author || e.Text != "hello room" {
+		t.Errorf("post stored event = %+v, %v", e, ok)
+	}
+
+	h.clients.GetClient(clientID).LastActivity = 1
+	p.Timestamp = 10 // old enough to push now
+	h.posts.Clear()
+	h.posts.AddPost(p)
+	h.server.syncOnce()
+	if e, ok := rec.last(EventPostPushed); !ok || e.Client != clientID || e.PostTimestamp != 10 || e.PostSender != author {
+		t.Errorf("post pushed event = %+v, %v", e, ok)
+	}
+
+	h.server.ExecuteCLI("password hunter2")
+	if e, ok := rec.last(EventCLICommand); !ok || e.Command != "password ***" || e.Reply != "OK" || !e.Client.IsZero() {
+		t.Errorf("cli event = %+v, %v", e, ok)
+	}
+
+	h.server.ExecuteCLI("set guest.password letmein")
+	if e, ok := rec.last(EventCLICommand); !ok || e.Command != "set guest.password ***" || e.Reply != "OK" {
+		t.Errorf("set guest.password event = %+v, %v", e, ok)
+	}
+	if reply := h.server.ExecuteCLI("get guest.password"); reply != "letmein" {
+		t.Errorf("get guest.password = %q", reply)
+	}
+	if e, ok := rec.last(EventCLICommand); !ok || e.Command != "get guest.password" || e.Reply != "***" {
+		t.Errorf("get guest.password event = %+v, %v", e, ok)
+	}
+
+	h.server.ExecuteCLI("kick " + clientID.String()[:8])
+	if e, ok := rec.last(EventClientLogout); !ok || e.Client != clientID || e.Reason != LogoutKicked {
+		t.Errorf("kick event = %+v, %v", e, ok)
+	}
+}
+
+func TestEvents_EvictionReported(t *testing.T) {
+	h := newTestHarness(t)
+	rec := &eventRecorder{}
+	h.server.AddEventSink(rec)
+
+	id := core.MeshCoreID{0x07}
+	if _, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: id}}); err != nil {
+		t.Fatal(err)
+	}
+	h.server.syncOnce()
+	if _, ok := rec.last(EventClientLogout); ok {
+		t.Fatal("unexpected logout event")
+	}
+
+	h.clients.RemoveClient(id)
+	h.server.syncOnce()
+	if e, ok := rec.last(EventClientLogout); !ok || e.Client != id || e.Reason != LogoutEvicted {
+		t.Errorf("eviction event = %+v, %v", e, ok)
+	}
+}
+
+func TestEvents_EvictionReportedAtLogin(t *testing.T) {
+	h := newTestHarness(t)
+	h.clients.maxClients = 1
+	rec := &eventRecorder{}
+	h.server.AddEventSink(rec)
+
+	old := core.MeshCoreID{0x07}
+	if _, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: old, LastActivity: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	h.server.syncOnce()
+
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	h.server.HandlePacket(h.buildAnonReqPacketWithKey(t, clientKey, 100, 0, "guest123"), transport.PacketSourceMQTT)
+	if h.clients.GetClient(clientID) == nil || h.clients.GetClient(old) != nil {
+		t.Fatal("login did not evict the old client")
+	}
+	// Reported by the login itself, without waiting for a sync round.
+	if e, ok := rec.last(EventClientLogout); !ok || e.Client != old || e.Reason != LogoutEvicted {
+		t.Errorf("eviction event = %+v, %v", e, ok)
+	}
+}
+
+func TestEvent_JSON(t *testing.T) {
+	data, err := json.Marshal(Event{Kind: EventClientLogin, Time: 5, Client: core.MeshCoreID{0xAB}, Permissions: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["kind"] != "client_login" || got["time"] != 5.0 || got["perms"] != 3.0 {
+		t.Errorf("json = %s", data)
+	}
+	if c, _ := got["client"].(string); len(c) != 64 || c[:2] != "ab" {
+		t.Errorf("client = %q", c)
+	}
+	if _, ok := got["post_sender"]; ok {
+		t.Errorf("zero post_sender not omitted: %s", data)
+	}
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink, err := OpenJSONLinesSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.HandleEvent(Event{Kind: EventPostStored, Text: "one"})
+	sink.HandleEvent(Event{Kind: EventPostStored, Text: "two"})
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sink.HandleEvent(Event{Kind: EventPostStored, Text: "after close"})
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var texts []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e struct{ Text string }
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		texts = append(texts, e.Text)
+	}
+	if len(texts) != 2 || texts[0] != "one" || texts[1] != "two" {
+		t.Errorf("lines = %q", texts)
+	}
+}
+
+func TestWebhookSink_RetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	var mu sync.Mutex
+	var kinds []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var e struct{ Kind string }
+		json.NewDecoder(r.Body).Decode(&e)
+		mu.Lock()
+		kinds = append(kinds, e.Kind)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(WebhookConfig{
+		URL:        srv.URL,
+		Header:     http.Header{"Authorization": {"Bearer tok"}},
+		RetryDelay: time.Millisecond,
+	})
+	sink.HandleEvent(Event{Kind: EventClientLogin})
+	sink.HandleEvent(Event{Kind: EventClientLogout})
+	if err := sink.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if sink.Failed() != 0 || sink.Dropped() != 0 {
+		t.Errorf("failed=%d dropped=%d, want 0", sink.Failed(), sink.Dropped())
+	}
+	if len(kinds) != 2 || kinds[0] != "client_login" || kinds[1] != "client_logout" {
+		t.Errorf("delivered %q, want login then logout", kinds)
+	}
+}
+
+func TestWebhookSink_ClientErrorNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(WebhookConfig{URL: srv.URL, RetryDelay: time.Millisecond})
+	sink.HandleEvent(Event{Kind: EventPostStored})
+	sink.Close(context.Background())
+
+	if calls.Load() != 1 || sink.Failed() != 1 {
+		t.Errorf("calls=%d failed=%d, want 1 and 1", calls.Load(), sink.Failed())
+	}
+}
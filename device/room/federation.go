@@ -0,0 +1,451 @@
+package room
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/core/crypto"
+	"github.com/kabili207/meshcore-go/device/contact"
+)
+
+const (
+	// DefaultFederationLoginInterval is how often a room logs in to each of its
+	// federation peers, refreshing its place in the peer's client table and
+	// its sync point there.
+	DefaultFederationLoginInterval = 10 * time.Minute
+
+	// federationSeenSize bounds the set of imported posts remembered for
+	// duplicate detection.
+	federationSeenSize = 1024
+
+	// maxFederatedText is the longest post text that fits a federated push.
+	maxFederatedText = maxAddressedPlaintext - codec.FederatedTxtHeaderSize
+)
+
+// FederationPeer is another room server this room merges posts with.
+//
+// Both rooms list each other. Each logs in to the other as a client, so each
+// receives the other's posts through the normal sync loop, framed as
+// codec.TxtTypeFederated messages that carry the room and time the post was
+// first made. Imported posts are pushed on to this room's clients (and other
+// peers), but never back to the room they came from; a post that has come
+// around to the room it started on, or that arrives twice by different
+// routes, is dropped.
+type FederationPeer struct {
+	// ID is the peer room's public key.
+	ID core.MeshCoreID
+
+	// Password is sent when logging in to the peer. It must grant at least
+	// read access there.
+	Password string
+
+	// SyncSince is the peer's timestamp of the last post imported from it,
+	// sent as the sync point when logging in. Restore it from FederationPeers
+	// after a restart; zero asks the peer for everything it holds, and posts
+	// already imported are dropped as duplicates.
+	SyncSince uint32
+}
+
+// FederatedPost is a post in the form federated rooms exchange, for moving
+// posts over a side channel (e.g. IP) instead of the mesh; see ExportPosts and
+// ImportFederatedPosts. Its JSON form has hex public keys.
+type FederatedPost struct {
+	// Origin is the room the post was first made on.
+	Origin core.MeshCoreID
+
+	// OriginTimestamp is the post's time on the Origin room.
+	OriginTimestamp uint32
+
+	// Author is the client who made the post. Posts relayed over the mesh
+	// carry only a 4-byte prefix of the author's key; unless the author is a
+	// known contact, the rest of the key is zero.
+	Author core.MeshCoreID
+
+	// Text is the post's message.
+	Text string
+}
+
+// federatedPostJSON is the JSON form of a FederatedPost.
+type federatedPostJSON struct {
+	Origin          string `json:"origin"`
+	OriginTimestamp uint32 `json:"origin_ts"`
+	Author          string `json:"author"`
+	Text            string `json:"text"`
+}
+
+// MarshalJSON encodes the post with snake_case keys and hex public keys.
+func (p FederatedPost) MarshalJSON() ([]byte, error) {
+	return json.Marshal(federatedPostJSON{
+		Origin:          p.Origin.String(),
+		OriginTimestamp: p.OriginTimestamp,
+		Author:          p.Author.String(),
+		Text:            p.Text,
+	})
+}
+
+// UnmarshalJSON decodes the form written by MarshalJSON.
+func (p *FederatedPost) UnmarshalJSON(data []byte) error {
+	var j federatedPostJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	origin, err := core.ParseMeshCoreID(j.Origin)
+	if err != nil {
+		return fmt.Errorf("origin: %w", err)
+	}
+	author, err := core.ParseMeshCoreID(j.Author)
+	if err != nil {
+		return fmt.Errorf("author: %w", err)
+	}
+	*p = FederatedPost{Origin: origin, OriginTimestamp: j.OriginTimestamp, Author: author, Text: j.Text}
+	return nil
+}
+
+// federation holds the peers and the recently imported posts.
+type federation struct {
+	mu    sync.Mutex
+	peers map[core.MeshCoreID]*FederationPeer
+	seen  map[federationKey]struct{}
+	ring  []federationKey // seen keys, oldest overwritten first
+	next  int
+}
+
+// federationKey identifies a post across rooms by the prefixes the mesh
+// format carries: origin room, origin time, and author.
+type federationKey struct {
+	origin [4]byte
+	ts     uint32
+	author [4]byte
+}
+
+func newFederation(peers []FederationPeer) *federation {
+	f := &federation{
+		peers: make(map[core.MeshCoreID]*FederationPeer, len(peers)),
+		seen:  make(map[federationKey]struct{}),
+	}
+	for _, p := range peers {
+		p := p
+		f.peers[p.ID] = &p
+	}
+	return f
+}
+
+func makeFederationKey(origin []byte, ts uint32, author []byte) federationKey {
+	k := federationKey{ts: ts}
+	copy(k.origin[:], origin)
+	copy(k.author[:], author)
+	return k
+}
+
+// markSeen records k, reporting false if it was already recorded.
+func (f *federation) markSeen(k federationKey) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.seen[k]; ok {
+		return false
+	}
+	if len(f.ring) < federationSeenSize {
+		f.ring = append(f.ring, k)
+	} else {
+		delete(f.seen, f.ring[f.next])
+		f.ring[f.next] = k
+		f.next = (f.next + 1) % federationSeenSize
+	}
+	f.seen[k] = struct{}{}
+	return true
+}
+
+// advance moves a peer's sync point to ts, reporting false if ts is not newer
+// (a retransmission) or the peer is unknown.
+func (f *federation) advance(peer core.MeshCoreID, ts uint32) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.peers[peer]
+	if !ok || ts <= p.SyncSince {
+		return false
+	}
+	p.SyncSince = ts
+	return true
+}
+
+// isPeer reports whether id is a configured federation peer.
+func (s *Server) isPeer(id core.MeshCoreID) bool {
+	s.fed.mu.Lock()
+	defer s.fed.mu.Unlock()
+	_, ok := s.fed.peers[id]
+	return ok
+}
+
+// FederationPeers returns the configured peers with their current sync
+// points, for the application to persist and pass back as
+// ServerConfig.Federation on restart.
+func (s *Server) FederationPeers() []FederationPeer {
+	s.fed.mu.Lock()
+	defer s.fed.mu.Unlock()
+	peers := make([]FederationPeer, 0, len(s.fed.peers))
+	for _, p := range s.fed.peers {
+		peers = append(peers, *p)
+	}
+	sort.Slice(peers, func(i, j int) bool { return bytes.Compare(peers[i].ID[:], peers[j].ID[:]) < 0 })
+	return peers
+}
+
+// initFederation adds the peers to the contact store, so their pushes can be
+// decrypted, and remembers the federated posts already stored so a peer
+// resending them after a restart does not duplicate them.
+func (s *Server) initFederation() {
+	s.fed = newFederation(s.cfg.Federation)
+	if len(s.cfg.Federation) == 0 {
+		return
+	}
+	if s.cfg.Contacts != nil {
+		for _, p := range s.cfg.Federation {
+			if s.cfg.Contacts.GetByPubKey(p.ID) == nil {
+				s.cfg.Contacts.AddContact(&contact.ContactInfo{
+					ID:         p.ID,
+					Type:       codec.NodeTypeRoom,
+					OutPathLen: contact.PathUnknown,
+				})
+			}
+		}
+	}
+	if s.cfg.Posts != nil {
+		for _, p := range s.cfg.Posts.GetPostsSince(0) {
+			if p.IsFederated() {
+				s.fed.markSeen(makeFederationKey(p.Origin[:], p.OriginTimestamp, p.SenderID[:]))
+			}
+		}
+	}
+}
+
+// runFederationLoop logs in to every peer at once and then every
+// FederationLoginInterval, until ctx is cancelled.
+func (s *Server) runFederationLoop(ctx context.Context) {
+	interval := s.cfg.FederationLoginInterval
+	if interval <= 0 {
+		interval = DefaultFederationLoginInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, p := range s.FederationPeers() {
+			if err := s.loginToPeer(p); err != nil {
+				s.log.Debug("federation login failed", "peer", p.ID.String(), "error", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loginToPeer sends an ANON_REQ login to a peer room, carrying our sync point
+// with it as a companion's login does.
+func (s *Server) loginToPeer(p FederationPeer) error {
+	if s.cfg.Router == nil {
+		return fmt.Errorf("no router")
+	}
+	secret, err := s.cfg.Contacts.GetSharedSecret(p.ID)
+	if err != nil {
+		return fmt.Errorf("shared secret: %w", err)
+	}
+
+	plaintext := make([]byte, 8+len(p.Password)+1)
+	binary.LittleEndian.PutUint32(plaintext[0:4], s.cfg.Clock.GetCurrentTimeUnique())
+	binary.LittleEndian.PutUint32(plaintext[4:8], p.SyncSince)
+	copy(plaintext[8:], p.Password)
+
+	encrypted, err := crypto.EncryptAddressedWithSecret(plaintext, secret)
+	if err != nil {
+		return fmt.Errorf("encrypt login: %w", err)
+	}
+	mac, ciphertext := codec.SplitMAC(encrypted)
+	pkt := &codec.Packet{
+		Header:  codec.PayloadTypeAnonReq << codec.PHTypeShift,
+		Payload: codec.BuildAnonReqPayload(p.ID.Hash(), s.cfg.PublicKey, mac, ciphertext),
+	}
+
+	ct := s.cfg.Contacts.GetByPubKey(p.ID)
+	if ct != nil && ct.HasDirectPath() {
+		s.cfg.Router.SendDirect(pkt, ct.OutPath)
+	} else {
+		s.cfg.Router.SendFloodScoped(pkt)
+	}
+	s.log.Debug("federation login sent", "peer", p.ID.String(), "sync_since", p.SyncSince)
+	return nil
+}
+
+// handleFederatedPush imports a post pushed by a peer. ts is the peer's own
+// timestamp for the post, which becomes our sync point with it. The caller
+// has already ACKed the push, so a retransmission is simply ignored.
+func (s *Server) handleFederatedPush(peer core.MeshCoreID, ts uint32, content *codec.TxtMsgContent) {
+	if !s.fed.advance(peer, ts) {
+		s.log.Debug("federated push not newer than sync point", "peer", peer.String(), "ts", ts)
+		return
+	}
+	origin := s.resolvePrefix(content.OriginPubKeyPrefix)
+	author := s.resolvePrefix(content.SenderPubKeyPrefix)
+	s.importPost(origin, content.OriginTimestamp, author, content.Message)
+}
+
+// importPost stores a post made on another room, unless it was made here or
+// has been imported already. It reports whether the post was stored.
+//
+// The post is stored at the local time it arrives, not its origin time: the
+// local timestamp is the sync point clients and peers advance through, so a
+// post back-dated to its origin time would never reach those already synced
+// past it. Imported posts therefore sync in arrival order; OriginTimestamp
+// keeps the time the post was made.
+func (s *Server) importPost(origin core.MeshCoreID, originTS uint32, author core.MeshCoreID, text string) bool {
+	if origin.IsZero() || bytes.Equal(origin[:4], s.cfg.PublicKey[:4]) {
+		return false
+	}
+	if !s.fed.markSeen(makeFederationKey(origin[:], originTS, author[:])) {
+		s.log.Debug("duplicate federated post", "origin", origin.String(), "origin_ts", originTS)
+		return false
+	}
+	if s.authorBlocked(author) {
+		s.log.Debug("federated post from blocked author dropped", "author", author.String())
+		return false
+	}
+	ts := s.cfg.Clock.GetCurrentTimeUnique()
+	s.storePost(&PostInfo{
+		Timestamp:       ts,
+		SenderID:        author,
+		Content:         buildPostContent(ts, text),
+		Origin:          origin,
+		OriginTimestamp: originTS,
+	})
+	return true
+}
+
+// authorBlocked reports whether a federated post's author is blocked. A post
+// pushed over the mesh names its author by a 4-byte prefix, which
+// resolvePrefix leaves unexpanded when no contact or several contacts match.
+// Such an author is blocked if any contact or blocked key sharing the prefix
+// is.
+func (s *Server) authorBlocked(author core.MeshCoreID) bool {
+	if s.blocklist.IsBlocked(author) {
+		return true
+	}
+	var tail core.MeshCoreID
+	if !bytes.Equal(author[4:], tail[4:]) {
+		return false // a full key, checked above
+	}
+	if s.cfg.Contacts != nil {
+		for _, ct := range s.cfg.Contacts.SearchByPrefix(author[:4]) {
+			if s.blocklist.IsBlocked(ct.ID) {
+				return true
+			}
+		}
+	}
+	for _, b := range s.blocklist.List() {
+		if b.ID.IsHashMatch(author[:4]) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePrefix expands a 4-byte key prefix to a full key using the peers and
+// the contact store. An unknown prefix is returned with the rest zero.
+func (s *Server) resolvePrefix(prefix []byte) core.MeshCoreID {
+	var id core.MeshCoreID
+	if len(prefix) < 4 {
+		return id
+	}
+	if bytes.Equal(prefix[:4], s.cfg.PublicKey[:4]) {
+		return core.MeshCoreID(s.cfg.PublicKey)
+	}
+	s.fed.mu.Lock()
+	for pid := range s.fed.peers {
+		if bytes.Equal(pid[:4], prefix[:4]) {
+			s.fed.mu.Unlock()
+			return pid
+		}
+	}
+	s.fed.mu.Unlock()
+	if s.cfg.Contacts != nil {
+		if ct := contact.ResolvePrefix(s.cfg.Contacts, prefix[:4]); ct != nil {
+			return ct.ID
+		}
+	}
+	copy(id[:], prefix[:4])
+	return id
+}
+
+// postOrigin returns the room a stored post was first made on and its time
+// there: the post's Origin, or this room for local posts.
+func (s *Server) postOrigin(p *PostInfo) (core.MeshCoreID, uint32) {
+	if p.IsFederated() {
+		return p.Origin, p.OriginTimestamp
+	}
+	return core.MeshCoreID(s.cfg.PublicKey), p.Timestamp
+}
+
+// buildFederatedPush frames a stored post for a peer room: the post's local
+// timestamp (the peer's sync point), its author, and its origin.
+func (s *Server) buildFederatedPush(post *PostInfo, text string) []byte {
+	var rnd [1]byte
+	_, _ = rand.Read(rnd[:])
+	origin, originTS := s.postOrigin(post)
+	return codec.BuildFederatedTxtMsgContent(post.Timestamp, rnd[0]&0x03, post.SenderID[:4], origin[:4], originTS,
+		codec.TruncateUTF8(text, maxFederatedText))
+}
+
+// ExportPosts returns the posts stored after the local timestamp since,
+// oldest first, for sending to a peer over a side channel, along with the
+// timestamp to pass as since next time. Posts that cannot be parsed are
+// skipped.
+func (s *Server) ExportPosts(since uint32) ([]FederatedPost, uint32) {
+	var out []FederatedPost
+	next := since
+	for _, p := range s.cfg.Posts.GetPostsSince(since) {
+		next = p.Timestamp
+		content, err := codec.ParseTxtMsgContent(p.Content)
+		if err != nil {
+			continue
+		}
+		origin, originTS := s.postOrigin(p)
+		out = append(out, FederatedPost{Origin: origin, OriginTimestamp: originTS, Author: p.SenderID, Text: content.Message})
+	}
+	return out, next
+}
+
+// ImportFederatedPosts stores posts received from a peer over a side channel,
+// in origin-time order within the batch. Like posts pushed over the mesh, they
+// are stored after every post already held, so a batch arriving late is still
+// synced to clients that have seen earlier ones. Posts made on this room and
+// posts already imported, by either channel, are dropped. Returns how many
+// posts were stored.
+func (s *Server) ImportFederatedPosts(posts []FederatedPost) int {
+	sorted := append([]FederatedPost(nil), posts...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].OriginTimestamp < sorted[j].OriginTimestamp })
+	n := 0
+	for _, p := range sorted {
+		if s.importPost(p.Origin, p.OriginTimestamp, p.Author, p.Text) {
+			n++
+		}
+	}
+	return n
+}
+
+// wantsPost reports whether a post should be synced to client id: not to its
+// author, and not back to the room it was imported from.
+func wantsPost(id core.MeshCoreID, p *PostInfo) bool {
+	if p.SenderID == id {
+		return false
+	}
+	return !p.IsFederated() || !bytes.Equal(p.Origin[:4], id[:4])
+}
@@ -0,0 +1,230 @@
+package room
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/contact"
+	"github.com/kabili207/meshcore-go/device/event"
+)
+
+// federate configures h to federate with the rooms in peers, logging in to
+// each with its admin password.
+func (h *testHarness) federate(peers ...*testHarness) {
+	for _, p := range peers {
+		h.server.cfg.Federation = append(h.server.cfg.Federation, FederationPeer{
+			ID:       core.MeshCoreID(p.server.cfg.PublicKey),
+			Password: "admin123",
+		})
+	}
+	h.server.initFederation()
+}
+
+// deliver hands the last packet sent by from to the room of to.
+func deliver(t *testing.T, from, to *testHarness) {
+	t.Helper()
+	pkt := from.transport.lastPacket()
+	if pkt == nil {
+		t.Fatal("nothing sent")
+	}
+	from.transport.reset()
+	to.server.HandlePacket(pkt, 0)
+}
+
+func TestFederation_PostsFlowBetweenRooms(t *testing.T) {
+	a, b := newTestHarness(t), newTestHarness(t)
+	a.federate(b)
+	b.federate(a)
+	aID := core.MeshCoreID(a.server.cfg.PublicKey)
+	bID := core.MeshCoreID(b.server.cfg.PublicKey)
+
+	// A logs in to B, becoming one of B's clients.
+	if err := a.server.loginToPeer(a.server.FederationPeers()[0]); err != nil {
+		t.Fatal(err)
+	}
+	deliver(t, a, b)
+	if b.clients.GetClient(aID) == nil {
+		t.Fatal("peer login not accepted")
+	}
+	b.transport.reset()
+
+	author := core.MeshCoreID{0x42, 0x43, 0x44, 0x45}
+	b.posts.AddPost(&PostInfo{Timestamp: 10, SenderID: author, Content: buildPostContent(10, "hello from B")})
+	b.server.syncOnce()
+
+	push := b.transport.lastPacket()
+	deliver(t, b, a)
+	posts := a.posts.GetPostsSince(0)
+	if len(posts) != 1 {
+		t.Fatalf("A has %d posts, want 1", len(posts))
+	}
+	p := posts[0]
+	if p.Origin != bID || p.OriginTimestamp != 10 || p.SenderID[0] != 0x42 {
+		t.Errorf("imported post = origin %s ts %d sender %s", p.Origin, p.OriginTimestamp, p.SenderID)
+	}
+	if c, _ := codec.ParseTxtMsgContent(p.Content); c == nil || c.Message != "hello from B" {
+		t.Errorf("imported content = %+v", c)
+	}
+
+	// A's ACK completes B's push and advances A's cursor with B.
+	deliver(t, a, b)
+	if got := b.clients.GetClient(aID).SyncSince; got != 10 {
+		t.Errorf("B's sync point for A = %d, want 10", got)
+	}
+	if got := a.server.FederationPeers()[0].SyncSince; got != 10 {
+		t.Errorf("A's cursor with B = %d, want 10", got)
+	}
+
+	// A retransmitted push is ignored.
+	a.server.HandlePacket(push, 0)
+	if a.posts.Count() != 1 {
+		t.Errorf("retransmission imported again: %d posts", a.posts.Count())
+	}
+
+	// Once B logs in to A, the imported post is not pushed back to it.
+	if err := b.server.loginToPeer(b.server.FederationPeers()[0]); err != nil {
+		t.Fatal(err)
+	}
+	deliver(t, b, a)
+	if bc := a.clients.GetClient(bID); bc == nil || a.server.unsyncedCount(bc) != 0 {
+		t.Errorf("imported post queued for its origin room")
+	}
+}
+
+func TestFederation_LoopAndDuplicateSuppression(t *testing.T) {
+	h := newTestHarness(t)
+	self := core.MeshCoreID(h.server.cfg.PublicKey)
+	other := core.MeshCoreID{0x77, 0x01}
+	author := core.MeshCoreID{0x42}
+
+	n := h.server.ImportFederatedPosts([]FederatedPost{
+		{Origin: other, OriginTimestamp: 30, Author: author, Text: "second"},
+		{Origin: other, OriginTimestamp: 20, Author: author, Text: "first"},
+		{Origin: other, OriginTimestamp: 20, Author: author, Text: "first"},
+		{Origin: self, OriginTimestamp: 5, Author: author, Text: "ours"},
+	})
+	if n != 2 {
+		t.Fatalf("imported %d, want 2", n)
+	}
+	posts := h.posts.GetPostsSince(0)
+	first, _ := codec.ParseTxtMsgContent(posts[0].Content)
+	if first.Message != "first" {
+		t.Errorf("oldest stored = %q, want posts in origin order", first.Message)
+	}
+
+	// The same post arriving over the mesh is a duplicate too.
+	h.server.cfg.Federation = []FederationPeer{{ID: other}}
+	h.server.initFederation()
+	h.server.HandleTextMessage(&event.TextMessageReceived{
+		Event:              event.Event{From: other},
+		Message:            "second",
+		TxtType:            codec.TxtTypeFederated,
+		Timestamp:          99,
+		SenderPubKeyPrefix: author[:4],
+		OriginPubKeyPrefix: other[:4],
+		OriginTimestamp:    30,
+	})
+	if h.posts.Count() != 2 {
+		t.Errorf("mesh duplicate stored: %d posts", h.posts.Count())
+	}
+
+	// Exported posts name their origin.
+	exported, next := h.server.ExportPosts(0)
+	if len(exported) != 2 || exported[0].Origin != other || next != posts[1].Timestamp {
+		t.Errorf("export = %+v, next %d", exported, next)
+	}
+}
+
+func TestFederation_LateBatchStillSynced(t *testing.T) {
+	h := newTestHarness(t)
+	other := core.MeshCoreID{0x77, 0x01}
+	author := core.MeshCoreID{0x42}
+
+	h.server.ImportFederatedPosts([]FederatedPost{{Origin: other, OriginTimestamp: 50, Author: author, Text: "newer"}})
+	synced := h.posts.GetPostsSince(0)[0].Timestamp
+
+	// A batch made earlier on its origin arrives second.
+	h.server.ImportFederatedPosts([]FederatedPost{{Origin: other, OriginTimestamp: 10, Author: author, Text: "older"}})
+
+	// A client synced past the first batch still gets the second.
+	late := h.posts.GetPostsSince(synced)
+	if len(late) != 1 || late[0].OriginTimestamp != 10 {
+		t.Fatalf("posts since first batch = %+v", late)
+	}
+	exported, _ := h.server.ExportPosts(synced)
+	if len(exported) != 1 || exported[0].OriginTimestamp != 10 || exported[0].Text != "older" {
+		t.Errorf("export = %+v", exported)
+	}
+}
+
+func TestFederatedPost_JSON(t *testing.T) {
+	in := FederatedPost{Origin: core.MeshCoreID{0xAB}, OriginTimestamp: 7, Author: core.MeshCoreID{0xCD}, Text: "hi"}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]any
+	json.Unmarshal(data, &raw)
+	if o, _ := raw["origin"].(string); len(o) != 64 || o[:2] != "ab" || raw["origin_ts"] != 7.0 {
+		t.Errorf("json = %s", data)
+	}
+	var out FederatedPost
+	if err := json.Unmarshal(data, &out); err != nil || out != in {
+		t.Errorf("round trip = %+v, %v", out, err)
+	}
+}
+
+func TestFederation_BlockedAuthorByPrefix(t *testing.T) {
+	h := newTestHarness(t)
+	other := core.MeshCoreID{0x77, 0x01}
+	h.server.cfg.Federation = []FederationPeer{{ID: other}}
+	h.server.initFederation()
+
+	// Two contacts share the blocked author's prefix, so it cannot be
+	// resolved to a full key.
+	blocked := core.MeshCoreID{0x42, 0x42, 0x42, 0x42, 0x01}
+	twin := core.MeshCoreID{0x42, 0x42, 0x42, 0x42, 0x02}
+	for _, id := range []core.MeshCoreID{blocked, twin} {
+		if _, err := h.contacts.AddContact(&contact.ContactInfo{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.server.blocklist.Block(blocked, 1, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	push := func(author core.MeshCoreID, originTS uint32) {
+		h.server.HandleTextMessage(&event.TextMessageReceived{
+			Event:              event.Event{From: other},
+			Message:            "hi",
+			TxtType:            codec.TxtTypeFederated,
+			Timestamp:          originTS,
+			SenderPubKeyPrefix: author[:4],
+			OriginPubKeyPrefix: other[:4],
+			OriginTimestamp:    originTS,
+		})
+	}
+	push(blocked, 10)
+	if n := h.posts.Count(); n != 0 {
+		t.Fatalf("post from blocked prefix stored: %d posts", n)
+	}
+
+	// A blocked key that is no longer a contact is matched by prefix too.
+	if err := h.contacts.RemoveContact(blocked); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.contacts.RemoveContact(twin); err != nil {
+		t.Fatal(err)
+	}
+	push(blocked, 20)
+	if n := h.posts.Count(); n != 0 {
+		t.Fatalf("post from blocked non-contact stored: %d posts", n)
+	}
+
+	push(core.MeshCoreID{0x43}, 30)
+	if n := h.posts.Count(); n != 1 {
+		t.Errorf("post from unblocked author: %d posts, want 1", n)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
 	"github.com/kabili207/meshcore-go/core/crypto"
-	"github.com/kabili207/meshcore-go/device/acl"
 	"github.com/kabili207/meshcore-go/device/contact"
 	"github.com/kabili207/meshcore-go/device/event"
 	"github.com/kabili207/meshcore-go/device/telemetry"
@@ -26,6 +25,10 @@ func (s *Server) HandleLogin(evt *event.AnonRequestReceived) {
 	password := extractNullTerminated(evt.Plaintext[8:])
 
 	senderID := evt.From
+	if s.bans.IsBanned(senderID) {
+		s.log.Debug("login from banned client ignored", "peer", senderID.String())
+		return
+	}
 
 	existingClient := s.cfg.Clients.GetClient(senderID)
 
@@ -44,9 +47,7 @@ func (s *Server) HandleLogin(evt *event.AnonRequestReceived) {
 		}
 		client = existingClient
 	} else {
-		client, err = s.cfg.Clients.AddClient(&ClientInfo{
-			Client: acl.Client{ID: senderID},
-		})
+		client, err = s.addClient(senderID)
 		if err != nil {
 			s.log.Warn("failed to add client", "error", err)
 			return
@@ -59,6 +60,7 @@ func (s *Server) HandleLogin(evt *event.AnonRequestReceived) {
 	client.PushFailures = 0
 	client.LastActivity = nowTS
 	client.Permissions = uint8(perm)
+	s.sync.resync(client)
 
 	// Ensure the client exists in the contact store
 	if s.cfg.Contacts.GetByPubKey(senderID) == nil {
@@ -83,6 +85,8 @@ func (s *Server) HandleLogin(evt *event.AnonRequestReceived) {
 		"perms", perm,
 		"sync_since", senderSyncSince)
 
+	s.emit(Event{Kind: EventClientLogin, Client: senderID, Permissions: uint8(perm)})
+
 	// Build and send login response
 	s.sendLoginResponseEvent(evt.Reply, senderID, uint8(perm), nowTS)
 }
@@ -121,6 +125,28 @@ func (s *Server) sendLoginResponseEvent(reply event.ReplyContext, recipientID co
 func (s *Server) HandleTextMessage(evt *event.TextMessageReceived) {
 	senderID := evt.From
 
+	// Blocked peers are dropped here as on the legacy path, before any
+	// handler sees them.
+	if s.blocklist.IsBlocked(senderID) {
+		s.log.Debug("message from blocked peer dropped", "peer", senderID.String())
+		return
+	}
+
+	// A peer room pushing a post. BaseNode has already ACKed it.
+	if evt.TxtType == codec.TxtTypeFederated {
+		if s.isPeer(senderID) {
+			s.handleFederatedPush(senderID, evt.Timestamp, &codec.TxtMsgContent{
+				Timestamp:          evt.Timestamp,
+				TxtType:            evt.TxtType,
+				Message:            evt.Message,
+				SenderPubKeyPrefix: evt.SenderPubKeyPrefix,
+				OriginPubKeyPrefix: evt.OriginPubKeyPrefix,
+				OriginTimestamp:    evt.OriginTimestamp,
+			})
+		}
+		return
+	}
+
 	client := s.cfg.Clients.GetClient(senderID)
 	if client == nil {
 		s.log.Debug("addressed from non-client", "peer", senderID.String())
@@ -149,29 +175,19 @@ func (s *Server) HandleTextMessage(evt *event.TextMessageReceived) {
 			}
 		}
 
-		// Store the post
-		postNowTS := s.cfg.Clock.GetCurrentTimeUnique()
-		// Build content for ACK hash computation and storage.
-		// The event carries the message string; we reconstruct the content
-		// for storage using the same format the firmware expects.
-		content := codec.BuildTxtMsgContent(postNowTS, codec.TxtTypePlain, 0, evt.Message, nil)
-		ackData := codec.TrimTxtMsgContent(content, &codec.TxtMsgContent{
-			TxtType: codec.TxtTypePlain,
-			Message: evt.Message,
-		})
+		message, ok := s.moderatePost(client, evt.Message)
+		if !ok {
+			return
+		}
 
-		_ = s.cfg.Posts.AddPost(&PostInfo{
+		// Store the post. The event carries the message string; the content is
+		// rebuilt in the format the firmware stores and pushes.
+		postNowTS := s.cfg.Clock.GetCurrentTimeUnique()
+		s.storePost(&PostInfo{
 			Timestamp: postNowTS,
 			SenderID:  senderID,
-			Content:   ackData,
+			Content:   buildPostContent(postNowTS, message),
 		})
-		if s.cfg.PostCounter != nil {
-			s.cfg.PostCounter.IncrementPosted()
-		}
-
-		s.log.Debug("post stored",
-			"sender", senderID.String(),
-			"timestamp", postNowTS)
 
 		// Note: ACK is already sent by BaseNode's auto-ACK for TxtTypePlain.
 		// The room server does NOT need to send ACK separately.
@@ -197,7 +213,7 @@ func (s *Server) handleCLICommandEvent(reply event.ReplyContext, senderID core.M
 		"peer", senderID.String(),
 		"cmd", cmd)
 
-	replyText := s.executeCLI(cmd)
+	replyText := s.runCLI(senderID, cmd)
 	if replyText == "" {
 		return
 	}
@@ -248,6 +264,7 @@ func (s *Server) HandleRequest(evt *event.RequestReceived) {
 		}
 		if fs := binary.LittleEndian.Uint32(forceSinceBytes[:]); fs > 0 {
 			client.SyncSince = fs
+			s.sync.resync(client)
 		}
 		client.PushFailures = 0
 
@@ -270,10 +287,27 @@ func (s *Server) HandleRequest(evt *event.RequestReceived) {
 		s.log.Debug("get_telemetry", "peer", senderID.String())
 		s.handleGetTelemetryEvent(evt.Reply, tag, client, senderID, evt.RequestData)
 
+	case codec.ReqTypeGetMinMaxAvg:
+		s.log.Debug("get_min_max_avg", "peer", senderID.String())
+		s.handleGetMinMaxAvgEvent(evt.Reply, tag, client, senderID, evt.RequestData)
+
+	case codec.ReqTypeGetOwnerInfo:
+		s.log.Debug("get_owner_info", "peer", senderID.String())
+		if s.sender != nil {
+			s.sender.SendReply(evt.Reply, senderID, codec.PayloadTypeResponse, s.buildOwnerInfoResponse(tag))
+		}
+
 	case codec.ReqTypeGetAccessList:
 		s.log.Debug("get_access_list", "peer", senderID.String())
 		s.handleGetAccessListEvent(evt.Reply, tag, client, senderID, evt.RequestData)
 
+	case codec.ReqTypeGetPostHistory:
+		s.log.Debug("get_post_history", "peer", senderID.String())
+		limit := historyReplyLimit(evt.Reply.HasFloodPath(), len(evt.Reply.FloodPath))
+		if resp := s.buildPostHistoryResponse(tag, client, evt.RequestData, limit); resp != nil && s.sender != nil {
+			s.sender.SendReply(evt.Reply, senderID, codec.PayloadTypeResponse, resp)
+		}
+
 	default:
 		s.log.Debug("unhandled request type",
 			"type", codec.RequestTypeName(evt.RequestType),
@@ -315,6 +349,17 @@ func (s *Server) handleGetTelemetryEvent(reply event.ReplyContext, tag uint32, c
 	}
 }
 
+// handleGetMinMaxAvgEvent handles GET_MIN_MAX_AVG using the event-based sender.
+func (s *Server) handleGetMinMaxAvgEvent(reply event.ReplyContext, tag uint32, client *ClientInfo, senderID core.MeshCoreID, requestData []byte) {
+	resp := s.buildMinMaxAvgResponse(tag, client, requestData)
+	if resp == nil {
+		return
+	}
+	if s.sender != nil {
+		s.sender.SendReply(reply, senderID, codec.PayloadTypeResponse, resp)
+	}
+}
+
 // handleGetAccessListEvent handles GET_ACCESS_LIST using the event-based sender.
 func (s *Server) handleGetAccessListEvent(reply event.ReplyContext, tag uint32, client *ClientInfo, senderID core.MeshCoreID, requestData []byte) {
 	if !client.IsAdmin() {
@@ -64,6 +64,10 @@ func (s *Server) handleAnonReq(pkt *codec.Packet) {
 	// Determine sender identity from the ephemeral public key
 	var senderID core.MeshCoreID
 	copy(senderID[:], anonPayload.PubKey[:])
+	if s.bans.IsBanned(senderID) {
+		s.log.Debug("login from banned client ignored", "peer", senderID.String())
+		return
+	}
 
 	// Check if sender is already a known client
 	existingClient := s.cfg.Clients.GetClient(senderID)
@@ -86,9 +90,7 @@ func (s *Server) handleAnonReq(pkt *codec.Packet) {
 		}
 		client = existingClient
 	} else {
-		client, err = s.cfg.Clients.AddClient(&ClientInfo{
-			Client: acl.Client{ID: senderID},
-		})
+		client, err = s.addClient(senderID)
 		if err != nil {
 			s.log.Warn("failed to add client", "error", err)
 			return
@@ -102,6 +104,7 @@ func (s *Server) handleAnonReq(pkt *codec.Packet) {
 	client.PushFailures = 0
 	client.LastActivity = nowTS
 	client.Permissions = uint8(perm)
+	s.sync.resync(client)
 
 	// Ensure the client exists in the contact store so that addressed
 	// packets (TXT_MSG, REQ) can be decrypted via SearchByHash/GetSharedSecret.
@@ -118,6 +121,8 @@ func (s *Server) handleAnonReq(pkt *codec.Packet) {
 		"perms", perm,
 		"sync_since", senderSyncSince)
 
+	s.emit(Event{Kind: EventClientLogin, Client: senderID, Permissions: uint8(perm)})
+
 	// Build and send login response
 	s.sendLoginResponse(pkt, senderID, secret, uint8(perm), nowTS)
 }
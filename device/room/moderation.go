@@ -0,0 +1,144 @@
+package room
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+// DefaultPostRateWindow is the window PostRateLimit counts posts over when
+// PostRateWindow is unset.
+const DefaultPostRateWindow = time.Minute
+
+// PostAction is a PostFilter's verdict on a post.
+type PostAction int
+
+const (
+	// PostAccept stores the post unchanged.
+	PostAccept PostAction = iota
+	// PostReject drops the post.
+	PostReject
+	// PostRewrite stores PostDecision.Message in place of the original text.
+	PostRewrite
+)
+
+// PostDecision is the result of filtering a post.
+type PostDecision struct {
+	Action PostAction
+
+	// Message is the replacement text for PostRewrite.
+	Message string
+
+	// Reason is logged when a post is rejected or rewritten. Optional.
+	Reason string
+}
+
+// PostFilter inspects a plain-text post from a writable client before it is
+// stored, and may accept, reject, or rewrite it. It runs on the server's
+// receive path, so it must not block. The client is the live session; do not
+// modify it.
+type PostFilter interface {
+	FilterPost(client *ClientInfo, message string) PostDecision
+}
+
+// PostFilterFunc adapts a function to the PostFilter interface.
+type PostFilterFunc func(client *ClientInfo, message string) PostDecision
+
+// FilterPost calls f.
+func (f PostFilterFunc) FilterPost(client *ClientInfo, message string) PostDecision {
+	return f(client, message)
+}
+
+// postLimiter is a per-client fixed-window post counter.
+type postLimiter struct {
+	mu      sync.Mutex
+	windows map[core.MeshCoreID]*postWindow
+}
+
+type postWindow struct {
+	start uint32
+	count int
+}
+
+// allow reports whether id may post at now, counting the post if so. limit
+// posts are allowed per window of the given length in seconds.
+func (l *postLimiter) allow(id core.MeshCoreID, now uint32, limit int, window uint32) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.windows == nil {
+		l.windows = make(map[core.MeshCoreID]*postWindow)
+	}
+	w := l.windows[id]
+	if w == nil || now < w.start || now-w.start >= window {
+		// Drop expired windows so the map only holds recent posters.
+		for k, other := range l.windows {
+			if now < other.start || now-other.start >= window {
+				delete(l.windows, k)
+			}
+		}
+		w = &postWindow{start: now}
+		l.windows[id] = w
+	}
+	if w.count >= limit {
+		return false
+	}
+	w.count++
+	return true
+}
+
+// forget drops a client's window, e.g. when it is kicked.
+func (l *postLimiter) forget(id core.MeshCoreID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.windows, id)
+}
+
+// moderatePost runs a plain-text post through the ban list, the per-client rate
+// limit (admins are exempt), and the PostFilter. It returns the text to store,
+// or false if the post is dropped. Posts from blocked peers never get here:
+// both message paths drop them on arrival.
+func (s *Server) moderatePost(client *ClientInfo, message string) (string, bool) {
+	if s.bans.IsBanned(client.ID) {
+		s.log.Debug("post from banned client dropped", "peer", client.ID.String())
+		return "", false
+	}
+
+	if s.cfg.PostRateLimit > 0 && !client.IsAdmin() {
+		window := s.cfg.PostRateWindow
+		if window <= 0 {
+			window = DefaultPostRateWindow
+		}
+		now := s.cfg.Clock.GetCurrentTime()
+		if !s.postLimiter.allow(client.ID, now, s.cfg.PostRateLimit, uint32(window/time.Second)) {
+			s.log.Info("post rate limited", "peer", client.ID.String())
+			return "", false
+		}
+	}
+
+	if s.cfg.PostFilter == nil {
+		return message, true
+	}
+	d := s.cfg.PostFilter.FilterPost(client, message)
+	switch d.Action {
+	case PostReject:
+		s.log.Info("post rejected", "peer", client.ID.String(), "reason", d.Reason)
+		return "", false
+	case PostRewrite:
+		s.log.Info("post rewritten", "peer", client.ID.String(), "reason", d.Reason)
+		return d.Message, true
+	default:
+		return message, true
+	}
+}
+
+// buildPostContent builds the stored form of a post: the unpadded TXT_MSG
+// content (timestamp, flags, text) used for sync pushes and their ACK hashes.
+func buildPostContent(timestamp uint32, message string) []byte {
+	content := codec.BuildTxtMsgContent(timestamp, codec.TxtTypePlain, 0, message, nil)
+	return codec.TrimTxtMsgContent(content, &codec.TxtMsgContent{
+		TxtType: codec.TxtTypePlain,
+		Message: message,
+	})
+}
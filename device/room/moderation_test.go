@@ -0,0 +1,337 @@
+package room
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/acl"
+	"github.com/kabili207/meshcore-go/device/event"
+	"github.com/kabili207/meshcore-go/transport"
+)
+
+func addWriter(t *testing.T, h *testHarness, perms uint8) core.MeshCoreID {
+	t.Helper()
+	_, id := h.makeClientKeyAndContact(t)
+	if _, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: id, Permissions: perms}}); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func postEvent(from core.MeshCoreID, ts uint32, msg string) *event.TextMessageReceived {
+	return &event.TextMessageReceived{
+		Event:     event.Event{From: from},
+		Message:   msg,
+		TxtType:   codec.TxtTypePlain,
+		Timestamp: ts,
+	}
+}
+
+func postMessages(t *testing.T, store PostStore) []string {
+	t.Helper()
+	var out []string
+	for _, p := range store.GetPostsSince(0) {
+		c, err := codec.ParseTxtMsgContent(p.Content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, c.Message)
+	}
+	return out
+}
+
+func TestPostFilter_RejectAndRewrite(t *testing.T) {
+	h := newTestHarness(t)
+	h.server.cfg.PostFilter = PostFilterFunc(func(_ *ClientInfo, msg string) PostDecision {
+		switch {
+		case strings.Contains(msg, "spam"):
+			return PostDecision{Action: PostReject, Reason: "spam"}
+		case strings.Contains(msg, "darn"):
+			return PostDecision{Action: PostRewrite, Message: strings.ReplaceAll(msg, "darn", "****")}
+		}
+		return PostDecision{}
+	})
+	id := addWriter(t, h, codec.PermACLReadWrite)
+
+	h.server.HandleTextMessage(postEvent(id, 100, "buy spam"))
+	h.server.HandleTextMessage(postEvent(id, 101, "oh darn"))
+	h.server.HandleTextMessage(postEvent(id, 102, "hello"))
+
+	got := postMessages(t, h.posts)
+	if len(got) != 2 || got[0] != "oh ****" || got[1] != "hello" {
+		t.Errorf("posts = %q, want [oh **** hello]", got)
+	}
+}
+
+func TestPostFilter_RewriteLegacyPath(t *testing.T) {
+	h := newTestHarness(t)
+	h.server.cfg.PostFilter = PostFilterFunc(func(_ *ClientInfo, msg string) PostDecision {
+		return PostDecision{Action: PostRewrite, Message: strings.ToUpper(msg)}
+	})
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	if _, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: clientID, Permissions: codec.PermACLReadWrite}}); err != nil {
+		t.Fatal(err)
+	}
+
+	content := codec.BuildTxtMsgContent(200, codec.TxtTypePlain<<2, 0, "quiet", nil)
+	h.server.HandlePacket(h.buildAddressedPacket(t, clientKey, clientID, codec.PayloadTypeTxtMsg, content), transport.PacketSourceMQTT)
+
+	if got := postMessages(t, h.posts); len(got) != 1 || got[0] != "QUIET" {
+		t.Errorf("posts = %q, want [QUIET]", got)
+	}
+	if h.transport.sentCount() == 0 {
+		t.Error("expected an ACK for the rewritten post")
+	}
+}
+
+func TestPostRateLimit(t *testing.T) {
+	h := newTestHarness(t)
+	h.server.cfg.PostRateLimit = 2
+	writer := addWriter(t, h, codec.PermACLReadWrite)
+	admin := addWriter(t, h, codec.PermACLAdmin)
+
+	for i := uint32(1); i <= 4; i++ {
+		h.server.HandleTextMessage(postEvent(writer, 100+i, "w"))
+		h.server.HandleTextMessage(postEvent(admin, 100+i, "a"))
+	}
+
+	var writerPosts, adminPosts int
+	for _, p := range h.posts.GetPostsSince(0) {
+		if p.SenderID == writer {
+			writerPosts++
+		} else {
+			adminPosts++
+		}
+	}
+	if writerPosts != 2 {
+		t.Errorf("writer posts = %d, want 2 (rate limited)", writerPosts)
+	}
+	if adminPosts != 4 {
+		t.Errorf("admin posts = %d, want 4 (exempt)", adminPosts)
+	}
+}
+
+func TestPostLimiter_WindowResets(t *testing.T) {
+	var l postLimiter
+	var id core.MeshCoreID
+	if !l.allow(id, 1000, 1, 60) {
+		t.Fatal("first post refused")
+	}
+	if l.allow(id, 1059, 1, 60) {
+		t.Error("second post inside the window allowed")
+	}
+	if !l.allow(id, 1060, 1, 60) {
+		t.Error("post in the next window refused")
+	}
+}
+
+func TestBan_BlocksLoginAndPosts(t *testing.T) {
+	h := newTestHarness(t)
+	id := addWriter(t, h, codec.PermACLReadWrite)
+
+	if got := h.server.executeCLI("ban " + id.String()[:8] + " too loud"); got != "OK" {
+		t.Fatalf("ban = %q, want OK", got)
+	}
+	if h.clients.GetClient(id) != nil {
+		t.Error("banned client still in client table")
+	}
+
+	// Re-adding the session directly must still not let it post.
+	if _, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: id, Permissions: codec.PermACLReadWrite}}); err != nil {
+		t.Fatal(err)
+	}
+	h.server.HandleTextMessage(postEvent(id, 100, "hi"))
+	if h.posts.Count() != 0 {
+		t.Errorf("banned client posted: %d posts", h.posts.Count())
+	}
+
+	if got := h.server.executeCLI("bans"); !strings.Contains(got, id.String()[:12]) || !strings.Contains(got, "too loud") {
+		t.Errorf("bans = %q", got)
+	}
+	if got := h.server.executeCLI("unban " + id.String()[:8]); got != "OK" {
+		t.Errorf("unban = %q, want OK", got)
+	}
+	if got := h.server.executeCLI("bans"); got != "(no bans)" {
+		t.Errorf("bans after unban = %q", got)
+	}
+}
+
+func TestBan_LoginIgnored(t *testing.T) {
+	h := newTestHarness(t)
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+
+	// A full key bans a client that is not connected.
+	if got := h.server.executeCLI("ban " + clientID.String()); got != "OK" {
+		t.Fatalf("ban = %q, want OK", got)
+	}
+	h.server.HandlePacket(h.buildAnonReqPacketWithKey(t, clientKey, 100, 0, "admin123"), transport.PacketSourceMQTT)
+
+	if h.clients.GetClient(clientID) != nil {
+		t.Error("banned client logged in")
+	}
+	if h.transport.sentCount() != 0 {
+		t.Error("expected no login response for a banned client")
+	}
+}
+
+func TestBanList_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bans.json")
+	l, err := OpenBanList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a, b core.MeshCoreID
+	a[0], b[0] = 1, 2
+	if err := l.Ban(a, 10, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Ban(b, 20, ""); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := l.Unban(b); !ok || err != nil {
+		t.Fatalf("Unban = %v, %v", ok, err)
+	}
+
+	reloaded, err := OpenBanList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reloaded.List()
+	if len(got) != 1 || got[0].ID != a || got[0].Since != 10 || got[0].Reason != "spam" {
+		t.Errorf("reloaded bans = %+v", got)
+	}
+}
+
+func TestCLI_PostsAndDelPost(t *testing.T) {
+	h := newTestHarness(t)
+	id := addWriter(t, h, codec.PermACLReadWrite)
+	for i, msg := range []string{"one", "two", "three"} {
+		_ = h.posts.AddPost(&PostInfo{Timestamp: uint32(1000 + i), SenderID: id, Content: buildPostContent(uint32(1000+i), msg)})
+	}
+
+	got := h.server.executeCLI("posts 2")
+	want := "1001 " + id.String()[:12] + " two\n1002 " + id.String()[:12] + " three"
+	if got != want {
+		t.Errorf("posts = %q, want %q", got, want)
+	}
+
+	if got := h.server.executeCLI("delpost 1001"); got != "OK" {
+		t.Errorf("delpost = %q, want OK", got)
+	}
+	if got := h.server.executeCLI("delpost 1001"); got != "ERR: post not found" {
+		t.Errorf("delpost again = %q", got)
+	}
+	if got := postMessages(t, h.posts); len(got) != 2 || got[0] != "one" || got[1] != "three" {
+		t.Errorf("posts after delete = %q", got)
+	}
+}
+
+func TestCLI_Kick(t *testing.T) {
+	h := newTestHarness(t)
+	id := addWriter(t, h, codec.PermACLReadWrite)
+
+	if got := h.server.executeCLI("kick " + id.String()[:6]); got != "OK" {
+		t.Errorf("kick = %q, want OK", got)
+	}
+	if h.clients.GetClient(id) != nil {
+		t.Error("kicked client still in client table")
+	}
+	if got := h.server.executeCLI("kick " + id.String()[:6]); got != "ERR: client not found" {
+		t.Errorf("kick again = %q", got)
+	}
+}
+
+func TestMemoryPostStore_DeletePostAfterWrap(t *testing.T) {
+	s := NewMemoryPostStore(3)
+	for ts := uint32(1); ts <= 5; ts++ {
+		_ = s.AddPost(makePost(ts, 1, "x"))
+	}
+	if ok, _ := s.DeletePost(4); !ok {
+		t.Fatal("DeletePost(4) = false")
+	}
+	if ok, _ := s.DeletePost(1); ok {
+		t.Error("DeletePost of an evicted post = true")
+	}
+	_ = s.AddPost(makePost(6, 1, "x"))
+
+	var got []uint32
+	for _, p := range s.GetPostsSince(0) {
+		got = append(got, p.Timestamp)
+	}
+	if len(got) != 3 || got[0] != 3 || got[1] != 5 || got[2] != 6 {
+		t.Errorf("timestamps = %v, want [3 5 6]", got)
+	}
+}
+
+func TestFilePostStore_DeletePostPersists(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenFilePostStore(FilePostStoreConfig{Dir: dir, SegmentSize: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ts := uint32(1); ts <= 4; ts++ {
+		if err := s.AddPost(makePost(ts, 1, "hello world")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// One post from an older segment and one from the active segment.
+	for _, ts := range []uint32{1, 4} {
+		if ok, err := s.DeletePost(ts); !ok || err != nil {
+			t.Fatalf("DeletePost(%d) = %v, %v", ts, ok, err)
+		}
+	}
+	if err := s.AddPost(makePost(5, 1, "after")); err != nil {
+		t.Fatalf("AddPost after deleting from the active segment: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenFilePostStore(FilePostStoreConfig{Dir: dir, SegmentSize: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	var got []uint32
+	for _, p := range reopened.GetPostsSince(0) {
+		got = append(got, p.Timestamp)
+	}
+	if len(got) != 3 || got[0] != 2 || got[1] != 3 || got[2] != 5 {
+		t.Errorf("timestamps after reopen = %v, want [2 3 5]", got)
+	}
+}
+
+func TestBlock_RefusesPosts(t *testing.T) {
+	h := newTestHarness(t)
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	if _, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: clientID, Permissions: codec.PermACLReadWrite}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.server.executeCLI("block " + clientID.String()[:8]); got != "OK" {
+		t.Fatalf("block = %q, want OK", got)
+	}
+
+	// Legacy path: dropped before the ACK.
+	content := codec.BuildTxtMsgContent(200, codec.TxtTypePlain<<2, 0, "let me in", nil)
+	h.server.HandlePacket(h.buildAddressedPacket(t, clientKey, clientID, codec.PayloadTypeTxtMsg, content), transport.PacketSourceMQTT)
+	if h.transport.sentCount() != 0 {
+		t.Error("blocked post was ACKed")
+	}
+
+	// Event path.
+	h.server.HandleTextMessage(postEvent(clientID, 201, "hello?"))
+	if h.posts.Count() != 0 {
+		t.Errorf("blocked peer posted: %d posts", h.posts.Count())
+	}
+
+	if got := h.server.executeCLI("unblock " + clientID.String()[:8]); got != "OK" {
+		t.Fatalf("unblock = %q, want OK", got)
+	}
+	h.server.HandleTextMessage(postEvent(clientID, 202, "thanks"))
+	if h.posts.Count() != 1 {
+		t.Errorf("posts after unblock = %d, want 1", h.posts.Count())
+	}
+}
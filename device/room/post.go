@@ -21,4 +21,66 @@ type PostInfo struct {
 
 	// Content is the raw encrypted message content (addressed payload).
 	Content []byte
+
+	// Origin is the public key of the room the post was first stored on, for
+	// posts imported from a federated peer. Zero for posts made here.
+	Origin core.MeshCoreID
+
+	// OriginTimestamp is the post's Timestamp on the Origin room. Zero for
+	// posts made here.
+	OriginTimestamp uint32
+}
+
+// IsFederated reports whether the post was imported from another room.
+func (p *PostInfo) IsFederated() bool {
+	return !p.Origin.IsZero()
+}
+
+// copyPost returns a copy of p that shares no memory with it.
+func copyPost(p *PostInfo) *PostInfo {
+	stored := *p
+	if len(p.Content) > 0 {
+		stored.Content = make([]byte, len(p.Content))
+		copy(stored.Content, p.Content)
+	} else {
+		stored.Content = nil
+	}
+	return &stored
+}
+
+// AddPost stores a plain-text post from sender as though a client had posted
+// it, for posts that originate outside the mesh (bridges, local consoles). The
+// sender need not be a logged-in client, and the post is not moderated. It is
+// pushed to clients by the sync loop like any other post.
+func (s *Server) AddPost(sender core.MeshCoreID, message string) *PostInfo {
+	ts := s.cfg.Clock.GetCurrentTimeUnique()
+	p := &PostInfo{
+		Timestamp: ts,
+		SenderID:  sender,
+		Content:   buildPostContent(ts, message),
+	}
+	s.storePost(p)
+	return p
+}
+
+// storePost adds a post to the store, queues it for sync, counts it, and
+// notifies OnPostAdded.
+func (s *Server) storePost(p *PostInfo) {
+	if err := s.cfg.Posts.AddPost(p); err != nil {
+		s.log.Warn("failed to store post", "sender", p.SenderID.String(), "error", err)
+		return
+	}
+	s.sync.added(p)
+	if s.cfg.PostCounter != nil {
+		s.cfg.PostCounter.IncrementPosted()
+	}
+
+	s.log.Debug("post stored",
+		"sender", p.SenderID.String(),
+		"timestamp", p.Timestamp)
+
+	if s.cfg.OnPostAdded != nil {
+		s.cfg.OnPostAdded(p)
+	}
+	s.emitPostStored(p)
 }
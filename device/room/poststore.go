@@ -1,7 +1,10 @@
 package room
 
+import "errors"
+
 // PostStore is the interface for post storage backends.
-// The default in-memory implementation uses a circular buffer (MemoryPostStore).
+// The default in-memory implementation uses a circular buffer (MemoryPostStore);
+// FilePostStore keeps posts across restarts.
 type PostStore interface {
 	// AddPost adds a post to the store. In bounded implementations, the oldest
 	// post may be evicted when at capacity.
@@ -17,3 +20,51 @@ type PostStore interface {
 	// Clear removes all posts.
 	Clear()
 }
+
+// PostDeleter is an optional PostStore extension for removing a single post,
+// used by the "delpost" admin command. MemoryPostStore and FilePostStore
+// implement it.
+type PostDeleter interface {
+	// DeletePost removes every post with the given timestamp. Returns false
+	// if no such post is stored.
+	DeletePost(timestamp uint32) (bool, error)
+}
+
+// DeletePost removes the posts with the given timestamp from store. It fails
+// with ErrPostDeleteUnsupported if the store does not implement PostDeleter.
+func DeletePost(store PostStore, timestamp uint32) (bool, error) {
+	d, ok := store.(PostDeleter)
+	if !ok {
+		return false, ErrPostDeleteUnsupported
+	}
+	return d.DeletePost(timestamp)
+}
+
+// ErrPostDeleteUnsupported is returned by DeletePost for stores that cannot
+// delete individual posts.
+var ErrPostDeleteUnsupported = errors.New("room: post store does not support deletion")
+
+// PostPager is an optional PostStore extension for reading posts older than a
+// given timestamp, used to answer post history requests. MemoryPostStore and
+// FilePostStore implement it.
+type PostPager interface {
+	// GetPostsBefore returns up to limit posts with Timestamp strictly less
+	// than the given timestamp (zero means no bound), ordered newest to oldest.
+	GetPostsBefore(timestamp uint32, limit int) []*PostInfo
+}
+
+// PostsBefore returns up to limit posts older than timestamp from store,
+// newest first. Stores that do not implement PostPager are scanned in full.
+func PostsBefore(store PostStore, timestamp uint32, limit int) []*PostInfo {
+	if pg, ok := store.(PostPager); ok {
+		return pg.GetPostsBefore(timestamp, limit)
+	}
+	all := store.GetPostsSince(0)
+	var result []*PostInfo
+	for i := len(all) - 1; i >= 0 && len(result) < limit; i-- {
+		if timestamp == 0 || all[i].Timestamp < timestamp {
+			result = append(result, all[i])
+		}
+	}
+	return result
+}
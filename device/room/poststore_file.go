@@ -0,0 +1,597 @@
+package room
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPostSegmentSize is the size at which FilePostStore starts a new
+	// log segment.
+	DefaultPostSegmentSize = 256 * 1024
+
+	// postRecordHeaderSize is the per-record framing: body length(4) + CRC-32(4).
+	postRecordHeaderSize = 8
+
+	// postRecordFixedSize is the fixed part of a record body: timestamp(4) +
+	// sender public key(32).
+	postRecordFixedSize = 4 + 32
+
+	// postRecordOriginSize is the origin part of a federated post's record
+	// body: origin public key(32) + origin timestamp(4).
+	postRecordOriginSize = 32 + 4
+
+	// postRecordOriginFlag marks, in the length field, a record whose body
+	// carries the origin part after the sender. Records written before
+	// federation never set it, so they still decode.
+	postRecordOriginFlag = 1 << 31
+
+	// maxPostContentSize bounds a record body when loading, so a corrupt length
+	// field cannot trigger a huge allocation.
+	maxPostContentSize = 64 * 1024
+
+	postSegmentPrefix = "posts-"
+	postSegmentSuffix = ".log"
+)
+
+// Compile-time assertions that FilePostStore implements PostStore,
+// PostDeleter, and PostPager.
+var (
+	_ PostStore   = (*FilePostStore)(nil)
+	_ PostDeleter = (*FilePostStore)(nil)
+	_ PostPager   = (*FilePostStore)(nil)
+)
+
+// FilePostStoreConfig configures a FilePostStore.
+type FilePostStoreConfig struct {
+	// Dir is the directory holding the log segments. It is created if missing
+	// and should not be shared with anything else.
+	Dir string
+
+	// MaxPosts caps the number of retained posts. Default: DefaultMaxPosts (100).
+	MaxPosts int
+
+	// MaxAge drops posts older than this, measured back from the newest post's
+	// timestamp (so a room whose clock has stalled never empties itself).
+	// 0 keeps posts regardless of age.
+	MaxAge time.Duration
+
+	// MaxBytes caps the total encoded size of retained posts. 0 means no limit.
+	MaxBytes int64
+
+	// SegmentSize is the size at which a new segment is started.
+	// Default: DefaultPostSegmentSize (256 KiB).
+	SegmentSize int64
+
+	// SyncWrites fsyncs the log after every post. Without it a post survives a
+	// process crash but may be lost on power failure.
+	SyncWrites bool
+
+	// Logger for storage events. Falls back to slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// FilePostStore is a durable PostStore backed by an append-only log split into
+// segments. Each post is one checksummed record; a record torn by a crash is
+// detected and truncated on the next open.
+//
+// Retained posts are also held in memory, sorted by timestamp, so
+// GetPostsSince is a binary search rather than a scan of the log. Posts that
+// fall out of retention are dropped from memory at once; their segments are
+// deleted when empty, or rewritten (temp file + rename) once more than half
+// their bytes are dead.
+type FilePostStore struct {
+	cfg FilePostStoreConfig
+	log *slog.Logger
+
+	mu       sync.RWMutex
+	index    []postEntry // retained posts, ordered by timestamp
+	bytes    int64       // encoded size of retained posts
+	segments []*postSegment
+	active   *os.File // append handle for the last segment
+	nextID   int
+}
+
+// postEntry is a retained post and the segment holding its record.
+type postEntry struct {
+	post *PostInfo
+	seg  *postSegment
+	size int64 // encoded record size
+}
+
+// postSegment tracks one log file.
+type postSegment struct {
+	id        int
+	path      string
+	size      int64 // bytes in the file
+	live      int   // retained records
+	liveBytes int64 // bytes of retained records
+}
+
+// OpenFilePostStore opens (or creates) a segmented post log in cfg.Dir and
+// loads the retained posts.
+func OpenFilePostStore(cfg FilePostStoreConfig) (*FilePostStore, error) {
+	if cfg.MaxPosts <= 0 {
+		cfg.MaxPosts = DefaultMaxPosts
+	}
+	if cfg.SegmentSize <= 0 {
+		cfg.SegmentSize = DefaultPostSegmentSize
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &FilePostStore{cfg: cfg, log: logger.WithGroup("posts")}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	s.enforceRetentionLocked()
+	if err := s.compactLocked(); err != nil {
+		return nil, err
+	}
+	if err := s.openActiveLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// AddPost appends a post to the log and applies retention.
+func (s *FilePostStore) AddPost(p *PostInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		return os.ErrClosed
+	}
+
+	stored := copyPost(p)
+	rec := encodePostRecord(stored)
+	seg := s.segments[len(s.segments)-1]
+	_, err := s.active.Write(rec)
+	if err == nil && s.cfg.SyncWrites {
+		err = s.active.Sync()
+	}
+	if err != nil {
+		// Cut off whatever part of the record reached the file, so the next
+		// append does not land behind torn bytes that load would truncate.
+		if terr := s.active.Truncate(seg.size); terr != nil {
+			// The log can no longer be appended to safely.
+			_ = s.active.Close()
+			s.active = nil
+			return errors.Join(err, terr)
+		}
+		return err
+	}
+	seg.size += int64(len(rec))
+	s.insertLocked(postEntry{post: stored, seg: seg, size: int64(len(rec))})
+
+	s.enforceRetentionLocked()
+	if seg.size >= s.cfg.SegmentSize {
+		if err := s.rollLocked(); err != nil {
+			return err
+		}
+	}
+	return s.compactLocked()
+}
+
+// GetPostsSince returns posts with Timestamp > timestamp, ordered oldest first.
+func (s *FilePostStore) GetPostsSince(timestamp uint32) []*PostInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := sort.Search(len(s.index), func(i int) bool { return s.index[i].post.Timestamp > timestamp })
+	if i == len(s.index) {
+		return nil
+	}
+	result := make([]*PostInfo, 0, len(s.index)-i)
+	for _, e := range s.index[i:] {
+		result = append(result, e.post)
+	}
+	return result
+}
+
+// GetPostsBefore returns up to limit posts with Timestamp < timestamp (any
+// timestamp if zero), ordered newest first.
+func (s *FilePostStore) GetPostsBefore(timestamp uint32, limit int) []*PostInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	end := len(s.index)
+	if timestamp != 0 {
+		end = sort.Search(len(s.index), func(i int) bool { return s.index[i].post.Timestamp >= timestamp })
+	}
+	var result []*PostInfo
+	for i := end - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, s.index[i].post)
+	}
+	return result
+}
+
+// Count returns the number of retained posts.
+func (s *FilePostStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.index)
+}
+
+// Clear removes all posts and deletes the log segments.
+func (s *FilePostStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active != nil {
+		_ = s.active.Close()
+		s.active = nil
+	}
+	for _, seg := range s.segments {
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			s.log.Warn("failed to remove post segment", "path", seg.path, "error", err)
+		}
+	}
+	if err := syncDir(s.cfg.Dir); err != nil {
+		s.log.Warn("failed to sync post directory", "error", err)
+	}
+	s.index = nil
+	s.bytes = 0
+	s.segments = nil
+	if err := s.openActiveLocked(); err != nil {
+		s.log.Warn("failed to reopen post log", "error", err)
+	}
+}
+
+// DeletePost removes every post with the given timestamp. The segments
+// holding them are rewritten at once so the posts do not reappear on the
+// next open.
+func (s *FilePostStore) DeletePost(timestamp uint32) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		return false, os.ErrClosed
+	}
+
+	i := sort.Search(len(s.index), func(i int) bool { return s.index[i].post.Timestamp >= timestamp })
+	j := i
+	for j < len(s.index) && s.index[j].post.Timestamp == timestamp {
+		j++
+	}
+	if i == j {
+		return false, nil
+	}
+	var segs []*postSegment
+	for _, e := range s.index[i:j] {
+		e.seg.live--
+		e.seg.liveBytes -= e.size
+		s.bytes -= e.size
+		if !slices.Contains(segs, e.seg) {
+			segs = append(segs, e.seg)
+		}
+	}
+	s.index = append(s.index[:i], s.index[j:]...)
+
+	active := s.segments[len(s.segments)-1]
+	for _, seg := range segs {
+		if seg == active {
+			continue
+		}
+		if err := s.rewriteLocked(seg); err != nil {
+			return true, err
+		}
+	}
+	if slices.Contains(segs, active) {
+		// The active segment: release the append handle across the rewrite.
+		if err := s.active.Close(); err != nil {
+			s.active = nil
+			return true, err
+		}
+		s.active = nil
+		if err := s.rewriteLocked(active); err != nil {
+			return true, errors.Join(err, s.openActiveLocked())
+		}
+		if err := s.openActiveLocked(); err != nil {
+			return true, err
+		}
+	}
+	return true, s.compactLocked()
+}
+
+// Compact deletes segments holding no retained posts and rewrites those that
+// are mostly dead. It runs automatically after every AddPost; call it directly
+// after changing retention externally.
+func (s *FilePostStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compactLocked()
+}
+
+// Close syncs and closes the log. Further AddPost calls fail with
+// os.ErrClosed; reads keep working from memory.
+func (s *FilePostStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		return nil
+	}
+	err := errors.Join(s.active.Sync(), s.active.Close())
+	s.active = nil
+	return err
+}
+
+// load reads every segment in the directory, truncating any torn or corrupt
+// tail. Called once from OpenFilePostStore.
+func (s *FilePostStore) load() error {
+	dirEntries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for _, de := range dirEntries {
+		name := de.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			// Leftover from a rewrite interrupted before its rename.
+			_ = os.Remove(filepath.Join(s.cfg.Dir, name))
+			continue
+		}
+		var id int
+		if _, err := fmt.Sscanf(name, postSegmentPrefix+"%08d"+postSegmentSuffix, &id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		seg := &postSegment{id: id, path: s.segmentPath(id)}
+		data, err := os.ReadFile(seg.path)
+		if err != nil {
+			return err
+		}
+		off := 0
+		for off < len(data) {
+			p, n, ok := decodePostRecord(data[off:])
+			if !ok {
+				break
+			}
+			s.insertLocked(postEntry{post: p, seg: seg, size: int64(n)})
+			off += n
+		}
+		if off < len(data) {
+			s.log.Warn("truncating damaged post segment", "path", seg.path,
+				"offset", off, "dropped", len(data)-off)
+			if err := os.Truncate(seg.path, int64(off)); err != nil {
+				return err
+			}
+		}
+		seg.size = int64(off)
+		s.segments = append(s.segments, seg)
+		s.nextID = id + 1
+	}
+	return nil
+}
+
+// insertLocked adds an entry to the timestamp-ordered index. Posts normally
+// arrive in order, so this is an append; an out-of-order timestamp (e.g. after
+// a clock correction) is inserted after any posts with the same timestamp.
+func (s *FilePostStore) insertLocked(e postEntry) {
+	i := len(s.index)
+	if i > 0 && s.index[i-1].post.Timestamp > e.post.Timestamp {
+		i = sort.Search(len(s.index), func(j int) bool { return s.index[j].post.Timestamp > e.post.Timestamp })
+	}
+	s.index = append(s.index, postEntry{})
+	copy(s.index[i+1:], s.index[i:])
+	s.index[i] = e
+	e.seg.live++
+	e.seg.liveBytes += e.size
+	s.bytes += e.size
+}
+
+// enforceRetentionLocked drops the oldest posts until the count, byte, and age
+// limits are met.
+func (s *FilePostStore) enforceRetentionLocked() {
+	var minTS uint32
+	if s.cfg.MaxAge > 0 && len(s.index) > 0 {
+		newest := s.index[len(s.index)-1].post.Timestamp
+		if age := uint32(s.cfg.MaxAge / time.Second); newest > age {
+			minTS = newest - age
+		}
+	}
+	drop := 0
+	for drop < len(s.index) {
+		remaining := len(s.index) - drop
+		e := s.index[drop]
+		if remaining <= s.cfg.MaxPosts &&
+			(s.cfg.MaxBytes <= 0 || s.bytes <= s.cfg.MaxBytes) &&
+			e.post.Timestamp >= minTS {
+			break
+		}
+		e.seg.live--
+		e.seg.liveBytes -= e.size
+		s.bytes -= e.size
+		drop++
+	}
+	if drop > 0 {
+		s.index = append(s.index[:0], s.index[drop:]...)
+	}
+}
+
+// compactLocked deletes empty segments and rewrites mostly-dead ones. The
+// active (last) segment is left alone.
+func (s *FilePostStore) compactLocked() error {
+	if len(s.segments) == 0 {
+		return nil
+	}
+	kept := s.segments[:0]
+	last := s.segments[len(s.segments)-1]
+	for _, seg := range s.segments {
+		switch {
+		case seg == last:
+		case seg.live == 0:
+			if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		case seg.liveBytes*2 < seg.size:
+			if err := s.rewriteLocked(seg); err != nil {
+				return err
+			}
+		}
+		kept = append(kept, seg)
+	}
+	for i := len(kept); i < len(s.segments); i++ {
+		s.segments[i] = nil
+	}
+	s.segments = kept
+	return nil
+}
+
+// rewriteLocked replaces a segment's file with just its retained records.
+func (s *FilePostStore) rewriteLocked(seg *postSegment) error {
+	var buf []byte
+	for _, e := range s.index {
+		if e.seg == seg {
+			buf = append(buf, encodePostRecord(e.post)...)
+		}
+	}
+	if err := writeFileSync(seg.path+".tmp", buf); err != nil {
+		return err
+	}
+	if err := os.Rename(seg.path+".tmp", seg.path); err != nil {
+		return err
+	}
+	seg.size = int64(len(buf))
+	return syncDir(s.cfg.Dir)
+}
+
+// rollLocked closes the active segment and starts a new one.
+func (s *FilePostStore) rollLocked() error {
+	if err := errors.Join(s.active.Sync(), s.active.Close()); err != nil {
+		s.active = nil
+		return err
+	}
+	s.active = nil
+	s.segments = append(s.segments, &postSegment{id: s.nextID, path: s.segmentPath(s.nextID)})
+	s.nextID++
+	return s.openActiveLocked()
+}
+
+// openActiveLocked opens the last segment for appending, creating one if the
+// store has none.
+func (s *FilePostStore) openActiveLocked() error {
+	if len(s.segments) == 0 {
+		s.segments = append(s.segments, &postSegment{id: s.nextID, path: s.segmentPath(s.nextID)})
+		s.nextID++
+	}
+	seg := s.segments[len(s.segments)-1]
+	f, err := os.OpenFile(seg.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	// Sync the directory so a newly created segment survives a power loss.
+	if err := syncDir(s.cfg.Dir); err != nil {
+		f.Close()
+		return err
+	}
+	s.active = f
+	return nil
+}
+
+func (s *FilePostStore) segmentPath(id int) string {
+	return filepath.Join(s.cfg.Dir, fmt.Sprintf("%s%08d%s", postSegmentPrefix, id, postSegmentSuffix))
+}
+
+// encodePostRecord frames a post as [body len u32][crc32 u32][body], where the
+// body is timestamp(4) + sender(32) + content. For a federated post the length
+// carries postRecordOriginFlag and origin(32) + origin timestamp(4) follow the
+// sender.
+func encodePostRecord(p *PostInfo) []byte {
+	fixed := postRecordFixedSize
+	if p.IsFederated() {
+		fixed += postRecordOriginSize
+	}
+	bodyLen := fixed + len(p.Content)
+	rec := make([]byte, postRecordHeaderSize+bodyLen)
+	body := rec[postRecordHeaderSize:]
+	binary.LittleEndian.PutUint32(body[0:4], p.Timestamp)
+	copy(body[4:36], p.SenderID[:])
+	lenField := uint32(bodyLen)
+	if p.IsFederated() {
+		copy(body[36:68], p.Origin[:])
+		binary.LittleEndian.PutUint32(body[68:72], p.OriginTimestamp)
+		lenField |= postRecordOriginFlag
+	}
+	copy(body[fixed:], p.Content)
+	binary.LittleEndian.PutUint32(rec[0:4], lenField)
+	binary.LittleEndian.PutUint32(rec[4:8], crc32.ChecksumIEEE(body))
+	return rec
+}
+
+// decodePostRecord parses one record, returning the post and the record's
+// size. ok is false for a torn, oversized, or corrupt record.
+func decodePostRecord(data []byte) (p *PostInfo, n int, ok bool) {
+	if len(data) < postRecordHeaderSize {
+		return nil, 0, false
+	}
+	lenField := binary.LittleEndian.Uint32(data[0:4])
+	fixed := postRecordFixedSize
+	if lenField&postRecordOriginFlag != 0 {
+		fixed += postRecordOriginSize
+	}
+	bodyLen := int(lenField &^ postRecordOriginFlag)
+	if bodyLen < fixed || bodyLen > fixed+maxPostContentSize ||
+		len(data) < postRecordHeaderSize+bodyLen {
+		return nil, 0, false
+	}
+	body := data[postRecordHeaderSize : postRecordHeaderSize+bodyLen]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[4:8]) {
+		return nil, 0, false
+	}
+	p = &PostInfo{Timestamp: binary.LittleEndian.Uint32(body[0:4])}
+	copy(p.SenderID[:], body[4:36])
+	if fixed > postRecordFixedSize {
+		copy(p.Origin[:], body[36:68])
+		p.OriginTimestamp = binary.LittleEndian.Uint32(body[68:72])
+	}
+	if len(body) > fixed {
+		p.Content = append([]byte(nil), body[fixed:]...)
+	}
+	return p, postRecordHeaderSize + bodyLen, true
+}
+
+// writeFileSync writes data to path and fsyncs it before closing, so a
+// following rename cannot expose a partially written file.
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return errors.Join(f.Sync(), f.Close())
+}
+
+// syncDir fsyncs a directory, making file creations and renames in it
+// durable. Windows cannot sync a directory handle and does not need to.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return errors.Join(d.Sync(), d.Close())
+}
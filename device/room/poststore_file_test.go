@@ -0,0 +1,217 @@
+package room
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+)
+
+func openTestFileStore(t *testing.T, cfg FilePostStoreConfig) *FilePostStore {
+	t.Helper()
+	if cfg.Dir == "" {
+		cfg.Dir = t.TempDir()
+	}
+	s, err := OpenFilePostStore(cfg)
+	if err != nil {
+		t.Fatalf("OpenFilePostStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, postSegmentPrefix+"*"+postSegmentSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestFilePostStore_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	s.AddPost(makePost(100, 0x01, "a"))
+	s.AddPost(makePost(200, 0x02, "b"))
+	s.AddPost(makePost(300, 0x03, "c"))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s2 := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	if s2.Count() != 3 {
+		t.Fatalf("Count() after reopen = %d, want 3", s2.Count())
+	}
+	posts := s2.GetPostsSince(100)
+	if len(posts) != 2 || string(posts[0].Content) != "b" || posts[1].SenderID[0] != 0x03 {
+		t.Errorf("GetPostsSince(100) = %+v, want b, c", posts)
+	}
+	if posts := s2.GetPostsSince(300); len(posts) != 0 {
+		t.Errorf("GetPostsSince(300) = %d posts, want 0", len(posts))
+	}
+}
+
+func TestFilePostStore_OutOfOrderTimestamps(t *testing.T) {
+	s := openTestFileStore(t, FilePostStoreConfig{})
+	s.AddPost(makePost(100, 0x01, "a"))
+	s.AddPost(makePost(300, 0x01, "c"))
+	s.AddPost(makePost(200, 0x01, "b")) // clock stepped back
+
+	var got string
+	for _, p := range s.GetPostsSince(0) {
+		got += string(p.Content)
+	}
+	if got != "abc" {
+		t.Errorf("order = %q, want abc", got)
+	}
+}
+
+func TestFilePostStore_RetentionByCount(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestFileStore(t, FilePostStoreConfig{Dir: dir, MaxPosts: 3})
+	for i := uint32(1); i <= 5; i++ {
+		s.AddPost(makePost(i*100, 0x01, "x"))
+	}
+	posts := s.GetPostsSince(0)
+	if len(posts) != 3 || posts[0].Timestamp != 300 {
+		t.Fatalf("retained %d posts starting at %d, want 3 starting at 300", len(posts), posts[0].Timestamp)
+	}
+
+	// Retention also applies on reopen, including a tighter limit.
+	s.Close()
+	s2 := openTestFileStore(t, FilePostStoreConfig{Dir: dir, MaxPosts: 2})
+	if posts := s2.GetPostsSince(0); len(posts) != 2 || posts[0].Timestamp != 400 {
+		t.Errorf("after reopen: %d posts, want 2 starting at 400", len(posts))
+	}
+}
+
+func TestFilePostStore_RetentionByAge(t *testing.T) {
+	s := openTestFileStore(t, FilePostStoreConfig{MaxAge: time.Hour})
+	s.AddPost(makePost(1000, 0x01, "old"))
+	s.AddPost(makePost(4000, 0x01, "recent"))
+	s.AddPost(makePost(5000, 0x01, "new")) // 1000 is now more than an hour older
+
+	posts := s.GetPostsSince(0)
+	if len(posts) != 2 || string(posts[0].Content) != "recent" {
+		t.Errorf("retained %+v, want recent, new", posts)
+	}
+}
+
+func TestFilePostStore_RetentionByBytes(t *testing.T) {
+	rec := int64(postRecordHeaderSize + postRecordFixedSize + 10)
+	s := openTestFileStore(t, FilePostStoreConfig{MaxBytes: 2 * rec})
+	for i := uint32(1); i <= 4; i++ {
+		s.AddPost(makePost(i, 0x01, "0123456789"))
+	}
+	if s.Count() != 2 {
+		t.Errorf("Count() = %d, want 2", s.Count())
+	}
+}
+
+func TestFilePostStore_TornTailTruncated(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	s.AddPost(makePost(100, 0x01, "kept"))
+	s.AddPost(makePost(200, 0x01, "torn"))
+	s.Close()
+
+	files := segmentFiles(t, dir)
+	if len(files) != 1 {
+		t.Fatalf("segments = %v, want 1", files)
+	}
+	info, _ := os.Stat(files[0])
+	if err := os.Truncate(files[0], info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+
+	s2 := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	if posts := s2.GetPostsSince(0); len(posts) != 1 || string(posts[0].Content) != "kept" {
+		t.Fatalf("after torn write: %+v, want only kept", posts)
+	}
+	// The log is usable again: a new post lands after the truncated record.
+	s2.AddPost(makePost(300, 0x01, "after"))
+	s2.Close()
+	s3 := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	if s3.Count() != 2 {
+		t.Errorf("Count() = %d, want 2", s3.Count())
+	}
+}
+
+func TestFilePostStore_CorruptRecordDropped(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	s.AddPost(makePost(100, 0x01, "good"))
+	s.AddPost(makePost(200, 0x01, "flipped"))
+	s.Close()
+
+	path := segmentFiles(t, dir)[0]
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xFF
+	os.WriteFile(path, data, 0o644)
+
+	s2 := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	if s2.Count() != 1 {
+		t.Errorf("Count() = %d, want 1 (checksum mismatch dropped)", s2.Count())
+	}
+}
+
+func TestFilePostStore_SegmentsCompacted(t *testing.T) {
+	dir := t.TempDir()
+	rec := int64(postRecordHeaderSize + postRecordFixedSize + 1)
+	s := openTestFileStore(t, FilePostStoreConfig{Dir: dir, MaxPosts: 4, SegmentSize: 2 * rec})
+	for i := uint32(1); i <= 20; i++ {
+		s.AddPost(makePost(i, 0x01, "x"))
+	}
+	// 4 retained posts over 2-record segments, plus the fresh active segment.
+	if files := segmentFiles(t, dir); len(files) > 3 {
+		t.Errorf("segments = %d, want dead segments deleted", len(files))
+	}
+	s.Close()
+	s2 := openTestFileStore(t, FilePostStoreConfig{Dir: dir, MaxPosts: 4, SegmentSize: 2 * rec})
+	if posts := s2.GetPostsSince(0); len(posts) != 4 || posts[0].Timestamp != 17 {
+		t.Errorf("after reopen: %d posts, want 4 starting at 17", len(posts))
+	}
+}
+
+func TestFilePostStore_Clear(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	s.AddPost(makePost(100, 0x01, "a"))
+	s.Clear()
+	if s.Count() != 0 {
+		t.Errorf("Count() after Clear = %d", s.Count())
+	}
+	s.AddPost(makePost(200, 0x01, "b"))
+	s.Close()
+
+	s2 := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	if posts := s2.GetPostsSince(0); len(posts) != 1 || string(posts[0].Content) != "b" {
+		t.Errorf("after Clear and reopen: %+v, want only b", posts)
+	}
+}
+
+func TestFilePostStore_FederatedOrigin(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	s.AddPost(makePost(100, 0x01, "local"))
+	fed := makePost(200, 0x02, "imported")
+	fed.Origin = core.MeshCoreID{0xEE, 0x01}
+	fed.OriginTimestamp = 150
+	s.AddPost(fed)
+	s.Close()
+
+	s2 := openTestFileStore(t, FilePostStoreConfig{Dir: dir})
+	posts := s2.GetPostsSince(0)
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want 2", len(posts))
+	}
+	if posts[0].IsFederated() || string(posts[0].Content) != "local" {
+		t.Errorf("local post = %+v", posts[0])
+	}
+	if posts[1].Origin != fed.Origin || posts[1].OriginTimestamp != 150 || string(posts[1].Content) != "imported" {
+		t.Errorf("federated post = %+v", posts[1])
+	}
+}
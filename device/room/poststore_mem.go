@@ -3,7 +3,11 @@ package room
 import "sync"
 
 // Compile-time assertion that MemoryPostStore implements PostStore.
-var _ PostStore = (*MemoryPostStore)(nil)
+var (
+	_ PostStore   = (*MemoryPostStore)(nil)
+	_ PostDeleter = (*MemoryPostStore)(nil)
+	_ PostPager   = (*MemoryPostStore)(nil)
+)
 
 // MemoryPostStore is an in-memory PostStore backed by a circular buffer.
 // When the buffer is full, the oldest post is overwritten.
@@ -34,16 +38,7 @@ func (s *MemoryPostStore) AddPost(p *PostInfo) error {
 	defer s.mu.Unlock()
 
 	// Copy the post to avoid aliasing
-	stored := &PostInfo{
-		Timestamp: p.Timestamp,
-		SenderID:  p.SenderID,
-	}
-	if len(p.Content) > 0 {
-		stored.Content = make([]byte, len(p.Content))
-		copy(stored.Content, p.Content)
-	}
-
-	s.posts[s.head] = stored
+	s.posts[s.head] = copyPost(p)
 	s.head = (s.head + 1) % s.capacity
 	if s.count < s.capacity {
 		s.count++
@@ -70,6 +65,25 @@ func (s *MemoryPostStore) GetPostsSince(timestamp uint32) []*PostInfo {
 	return result
 }
 
+// GetPostsBefore returns up to limit posts with Timestamp < timestamp (any
+// timestamp if zero), ordered newest first.
+func (s *MemoryPostStore) GetPostsBefore(timestamp uint32, limit int) []*PostInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*PostInfo
+
+	// Iterate from newest to oldest
+	start := s.oldestIndex()
+	for i := s.count - 1; i >= 0 && len(result) < limit; i-- {
+		p := s.posts[(start+i)%s.capacity]
+		if p != nil && (timestamp == 0 || p.Timestamp < timestamp) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
 // Count returns the number of stored posts.
 func (s *MemoryPostStore) Count() int {
 	s.mu.RLock()
@@ -89,6 +103,33 @@ func (s *MemoryPostStore) Clear() {
 	s.count = 0
 }
 
+// DeletePost removes every post with the given timestamp, compacting the
+// remaining posts so they stay contiguous in the buffer.
+func (s *MemoryPostStore) DeletePost(timestamp uint32) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := make([]*PostInfo, 0, s.count)
+	start := s.oldestIndex()
+	for i := 0; i < s.count; i++ {
+		p := s.posts[(start+i)%s.capacity]
+		if p != nil && p.Timestamp != timestamp {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == s.count {
+		return false, nil
+	}
+
+	for i := range s.posts {
+		s.posts[i] = nil
+	}
+	copy(s.posts, kept)
+	s.count = len(kept)
+	s.head = s.count % s.capacity
+	return true, nil
+}
+
 // oldestIndex returns the index of the oldest post in the circular buffer.
 // Must be called with s.mu held.
 func (s *MemoryPostStore) oldestIndex() int {
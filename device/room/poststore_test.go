@@ -1,6 +1,7 @@
 package room
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/kabili207/meshcore-go/core"
@@ -257,3 +258,77 @@ func TestMemoryPostStore_SingleCapacity(t *testing.T) {
 		t.Errorf("timestamp = %d, want 200", posts[0].Timestamp)
 	}
 }
+
+// plainPostStore hides a store's optional extensions.
+type plainPostStore struct{ PostStore }
+
+func TestPostsBefore(t *testing.T) {
+	mem := NewMemoryPostStore(4)
+	file := openTestFileStore(t, FilePostStoreConfig{MaxPosts: 4})
+	for _, ts := range []uint32{100, 200, 300, 400, 500} { // evicts the oldest
+		mem.AddPost(makePost(ts, 0x01, "x"))
+		file.AddPost(makePost(ts, 0x01, "x"))
+	}
+
+	stores := map[string]PostStore{
+		"memory":   mem,
+		"file":     file,
+		"fallback": plainPostStore{mem},
+	}
+	for name, store := range stores {
+		got := func(before uint32, limit int) []uint32 {
+			var ts []uint32
+			for _, p := range PostsBefore(store, before, limit) {
+				ts = append(ts, p.Timestamp)
+			}
+			return ts
+		}
+		if ts := got(0, 2); !slices.Equal(ts, []uint32{500, 400}) {
+			t.Errorf("%s: PostsBefore(0, 2) = %v, want [500 400]", name, ts)
+		}
+		if ts := got(400, 10); !slices.Equal(ts, []uint32{300, 200}) {
+			t.Errorf("%s: PostsBefore(400, 10) = %v, want [300 200]", name, ts)
+		}
+		if ts := got(200, 10); len(ts) != 0 {
+			t.Errorf("%s: PostsBefore(200, 10) = %v, want none", name, ts)
+		}
+	}
+}
+
+// TestDeletePost_SameTimestamp checks that both stores delete every post
+// sharing the timestamp, not just the first.
+func TestDeletePost_SameTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	stores := map[string]PostStore{
+		"memory": NewMemoryPostStore(8),
+		"file":   openTestFileStore(t, FilePostStoreConfig{Dir: dir, MaxPosts: 8}),
+	}
+	for name, store := range stores {
+		for _, p := range []*PostInfo{
+			makePost(100, 0x01, "a"), makePost(200, 0x01, "b"),
+			makePost(200, 0x02, "c"), makePost(300, 0x01, "d"),
+		} {
+			store.AddPost(p)
+		}
+		if ok, err := DeletePost(store, 200); !ok || err != nil {
+			t.Fatalf("%s: DeletePost(200) = %v, %v", name, ok, err)
+		}
+		var ts []uint32
+		for _, p := range store.GetPostsSince(0) {
+			ts = append(ts, p.Timestamp)
+		}
+		if !slices.Equal(ts, []uint32{100, 300}) {
+			t.Errorf("%s: posts after delete = %v, want [100 300]", name, ts)
+		}
+		if ok, _ := DeletePost(store, 200); ok {
+			t.Errorf("%s: second DeletePost(200) = true", name)
+		}
+	}
+
+	// The deletion survives a reopen.
+	stores["file"].(*FilePostStore).Close()
+	reopened := openTestFileStore(t, FilePostStoreConfig{Dir: dir, MaxPosts: 8})
+	if n := reopened.Count(); n != 2 {
+		t.Errorf("reopened store has %d posts, want 2", n)
+	}
+}
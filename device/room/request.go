@@ -5,6 +5,7 @@ import (
 
 	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/cli"
 	"github.com/kabili207/meshcore-go/device/telemetry"
 )
 
@@ -19,6 +20,14 @@ const (
 	// maxReplySize is the maximum plaintext response size (firmware: sizeof(reply_data)).
 	// The firmware uses a 60-byte buffer: tag(4) + up to 56 bytes of data.
 	maxReplySize = 60
+
+	// maxAddressedPlaintext is the largest plaintext that fits one addressed
+	// packet: the payload less dest(1) + src(1) + MAC(2), rounded down to the
+	// cipher block size.
+	maxAddressedPlaintext = (codec.MaxPacketPayload - 4) / 16 * 16
+
+	// maxHistoryPosts caps the posts returned in one post history response.
+	maxHistoryPosts = 16
 )
 
 // handleGetStatus handles a GET_STATUS request. Returns the 52-byte ServerStats
@@ -56,6 +65,47 @@ func (s *Server) handleGetTelemetry(origPkt *codec.Packet, tag uint32, client *C
 	s.sendEncryptedResponse(origPkt, senderID, secret, codec.PayloadTypeResponse, resp)
 }
 
+// handleGetMinMaxAvg handles a GET_MIN_MAX_AVG request. Returns the per-channel
+// min/max/avg of the recorded telemetry history over the requested window.
+func (s *Server) handleGetMinMaxAvg(origPkt *codec.Packet, tag uint32, client *ClientInfo, senderID core.MeshCoreID, secret []byte, requestData []byte) {
+	resp := s.buildMinMaxAvgResponse(tag, client, requestData)
+	if resp == nil {
+		return
+	}
+	s.sendEncryptedResponse(origPkt, senderID, secret, codec.PayloadTypeResponse, resp)
+}
+
+// buildMinMaxAvgResponse builds tag(4) + the min/max/avg body, or nil when there
+// is no history or the client may not read it (firmware requires read-only or
+// better).
+func (s *Server) buildMinMaxAvgResponse(tag uint32, client *ClientInfo, requestData []byte) []byte {
+	if s.cfg.History == nil {
+		return nil
+	}
+	body, ok := telemetry.QueryMinMaxAvg(s.cfg.History, s.cfg.Clock.GetCurrentTime(), requestData, client.Permissions)
+	if !ok {
+		return nil
+	}
+	resp := make([]byte, 4+len(body))
+	binary.LittleEndian.PutUint32(resp[0:4], tag)
+	copy(resp[4:], body)
+	return resp
+}
+
+// buildOwnerInfoResponse builds tag(4) + "version\nname\nowner_info" for a
+// GET_OWNER_INFO request. Any logged-in client may read it, as in firmware.
+func (s *Server) buildOwnerInfoResponse(tag uint32) []byte {
+	body := codec.BuildOwnerInfo(codec.OwnerInfo{
+		FirmwareVersion: cli.FirmwareVersion,
+		Name:            s.cfg.Name,
+		Owner:           s.cfg.OwnerInfo,
+	})
+	resp := make([]byte, 4+len(body))
+	binary.LittleEndian.PutUint32(resp[0:4], tag)
+	copy(resp[4:], body)
+	return resp
+}
+
 // handleGetAccessList handles a GET_ACCESS_LIST request. Admin-only: returns
 // the list of admin clients as 7-byte entries (6-byte pubkey prefix + permissions).
 func (s *Server) handleGetAccessList(origPkt *codec.Packet, tag uint32, client *ClientInfo, senderID core.MeshCoreID, secret []byte, requestData []byte) {
@@ -95,3 +145,62 @@ func (s *Server) handleGetAccessList(origPkt *codec.Packet, tag uint32, client *
 
 	s.sendEncryptedResponse(origPkt, senderID, secret, codec.PayloadTypeResponse, resp)
 }
+
+// historyReplyLimit returns the largest post history response that fits one
+// packet. A reply sent as a path return also carries path_len(1), the
+// pathLen-byte return path, and the extra type(1).
+func historyReplyLimit(pathReturn bool, pathLen int) int {
+	if !pathReturn {
+		return maxAddressedPlaintext
+	}
+	return maxAddressedPlaintext - 2 - pathLen
+}
+
+// buildPostHistoryResponse builds the response to a GET_POST_HISTORY request:
+// tag(4) followed by a codec.PostHistory page of posts older than the
+// requested timestamp, newest first, sized to fit limit bytes. Clients page
+// back by repeating the request with the oldest timestamp received while the
+// More flag is set. A post too long to fit even alone has its text cut.
+// Returns nil if the client may not read the room.
+func (s *Server) buildPostHistoryResponse(tag uint32, client *ClientInfo, requestData []byte, limit int) []byte {
+	if !client.CanRead() {
+		return nil
+	}
+
+	before, count := codec.ParsePostHistoryRequest(requestData)
+	want := int(count)
+	if want == 0 || want > maxHistoryPosts {
+		want = maxHistoryPosts
+	}
+	// Fetch one extra post to learn whether older posts remain.
+	posts := PostsBefore(s.cfg.Posts, before, want+1)
+
+	var page codec.PostHistory
+	space := limit - 4 - codec.PostHistoryHeaderSize
+	i := 0
+	for ; i < len(posts) && len(page.Posts) < want; i++ {
+		p := posts[i]
+		content, err := codec.ParseTxtMsgContent(p.Content)
+		if err != nil {
+			continue
+		}
+		text := content.Message
+		if avail := space - codec.PostHistoryEntryHeaderSize; len(text) > avail {
+			if len(page.Posts) > 0 {
+				break
+			}
+			text = codec.TruncateUTF8(text, max(avail, 0))
+		}
+		entry := codec.PostHistoryEntry{Timestamp: p.Timestamp, Text: text}
+		copy(entry.SenderPrefix[:], p.SenderID[:])
+		page.Posts = append(page.Posts, entry)
+		space -= codec.PostHistoryEntryHeaderSize + len(text)
+	}
+	page.More = i < len(posts)
+
+	body := codec.BuildPostHistory(page)
+	resp := make([]byte, 4+len(body))
+	binary.LittleEndian.PutUint32(resp[0:4], tag)
+	copy(resp[4:], body)
+	return resp
+}
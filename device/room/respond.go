@@ -30,7 +30,7 @@ func (s *Server) sendEncryptedResponse(origPkt *codec.Packet, recipientID core.M
 	mac, ciphertext := codec.SplitMAC(encrypted)
 
 	destHash := recipientID.Hash()
-	srcHash := core.MeshCoreID(s.cfg.PublicKey).Hash()
+	srcHash := s.replySrcHash(origPkt)
 	payload := codec.BuildAddressedPayload(destHash, srcHash, mac, ciphertext)
 
 	pkt := &codec.Packet{
@@ -75,7 +75,7 @@ func (s *Server) sendPathReturn(origPkt *codec.Packet, recipientID core.MeshCore
 	mac, ciphertext := codec.SplitMAC(encrypted)
 
 	destHash := recipientID.Hash()
-	srcHash := core.MeshCoreID(s.cfg.PublicKey).Hash()
+	srcHash := s.replySrcHash(origPkt)
 	payload := codec.BuildAddressedPayload(destHash, srcHash, mac, ciphertext)
 
 	// Step 4: Send as PATH via flood with delay
@@ -89,3 +89,13 @@ func (s *Server) sendPathReturn(origPkt *codec.Packet, recipientID core.MeshCore
 		"peer", recipientID.String(),
 		"path_len", len(returnPath))
 }
+
+// replySrcHash is the source hash for a response to origPkt: the previous
+// identity's if origPkt was addressed to it, otherwise the current one's.
+func (s *Server) replySrcHash(origPkt *codec.Packet) uint8 {
+	if origPkt != nil && len(origPkt.Payload) > 0 && s.previousKeyActive() &&
+		origPkt.PayloadType() != codec.PayloadTypeAnonReq && origPkt.Payload[0] == s.prevID.Hash() {
+		return s.prevID.Hash()
+	}
+	return core.MeshCoreID(s.cfg.PublicKey).Hash()
+}
@@ -13,7 +13,9 @@ import (
 	"crypto/ed25519"
 	"log/slog"
 	"sync"
+	"time"
 
+	"github.com/kabili207/meshcore-go/core"
 	"github.com/kabili207/meshcore-go/core/clock"
 	"github.com/kabili207/meshcore-go/core/codec"
 	"github.com/kabili207/meshcore-go/device/ack"
@@ -29,6 +31,17 @@ type ServerConfig struct {
 	PrivateKey ed25519.PrivateKey
 	PublicKey  [32]byte
 
+	// PreviousKey is the private key this room used before a key rotation.
+	// Until PreviousKeyUntil, addressed packets sent to the old identity are
+	// still decrypted and answered from it, so clients that have not yet
+	// heard the new key's advert keep working. Post pushes and adverts use
+	// PrivateKey only. Nil disables it.
+	PreviousKey ed25519.PrivateKey
+
+	// PreviousKeyUntil ends the rotation grace period. Zero keeps the
+	// previous key until the server is restarted without it.
+	PreviousKeyUntil time.Time
+
 	// Clock for timestamps
 	Clock *clock.Clock
 
@@ -59,6 +72,71 @@ type ServerConfig struct {
 	Stats     StatsProvider
 	Telemetry telemetry.Provider
 
+	// History, if set together with Telemetry, records periodic samples of the
+	// provider (while Start runs) and answers GET_MIN_MAX_AVG requests from them.
+	// If nil, those requests return no response.
+	History telemetry.History
+
+	// SampleInterval is how often Telemetry is sampled into History.
+	// Default: telemetry.DefaultSampleInterval (1 minute).
+	SampleInterval time.Duration
+
+	// SyncInterval is the delay between post sync rounds that pushed
+	// something. Default: SyncPushInterval (1.2s, as firmware).
+	SyncInterval time.Duration
+
+	// SyncMaxInFlight bounds how many post pushes may await an ACK at once,
+	// across all clients. Each round pushes to as many ready clients as the
+	// bound allows, so together with SyncInterval it sets the sync airtime
+	// budget. Default: DefaultSyncMaxInFlight.
+	SyncMaxInFlight int
+
+	// PostFilter, if set, inspects every plain-text post before it is stored
+	// and may accept, reject, or rewrite it. May be nil.
+	PostFilter PostFilter
+
+	// PostRateLimit caps how many posts a non-admin client may make per
+	// PostRateWindow; posts over the limit are dropped. 0 disables the limit.
+	PostRateLimit int
+
+	// PostRateWindow is the window PostRateLimit applies to.
+	// Default: DefaultPostRateWindow (1 minute).
+	PostRateWindow time.Duration
+
+	// Bans is the list of clients barred from this room: they cannot log in
+	// or post, and banning a connected client drops it. Managed with the
+	// "ban"/"unban" CLI commands. Use OpenBanList for a list that survives
+	// restarts. If nil, an in-memory list is created.
+	Bans *BanList
+
+	// Blocklist is the node-wide set of peers whose direct messages and
+	// adverts are ignored, and whose federated posts are dropped, managed
+	// with the "block"/"unblock" CLI commands. Unlike Bans it is not about
+	// room membership: it is shared with the rest of the node (RoomNode
+	// passes its BaseNode's list here), and a blocked peer may still log in.
+	// If nil, an in-memory list is created.
+	Blocklist *contact.Blocklist
+
+	// OnPostAdded is called after a post is stored, whether it came from a
+	// client or from AddPost. It runs on the receive path and must not block.
+	// May be nil.
+	OnPostAdded func(p *PostInfo)
+
+	// EventSinks receive room activity events: posts stored and pushed, push
+	// failures, client logins and logouts, and CLI commands. More can be added
+	// with AddEventSink. May be empty.
+	EventSinks []EventSink
+
+	// Federation lists peer room servers to merge posts with: this room logs
+	// in to each and imports the posts it is pushed, and pushes its own posts
+	// to each peer that logs in here. Peers must list each other. See
+	// FederationPeer. May be empty.
+	Federation []FederationPeer
+
+	// FederationLoginInterval is how often Start logs in to each federation
+	// peer. Default: DefaultFederationLoginInterval (10 minutes).
+	FederationLoginInterval time.Duration
+
 	// PostCounter is an optional counter for room-level post statistics.
 	// DefaultStatsProvider implements this interface.
 	PostCounter PostCounter
@@ -160,13 +238,35 @@ type Server struct {
 	cancel context.CancelFunc
 	cli    *cli.Dispatcher
 
+	// sampler records Telemetry into History; nil unless both are configured.
+	sampler *telemetry.Sampler
+
+	// prevID is the public identity of cfg.PreviousKey, if one is set.
+	prevID core.MeshCoreID
+
 	// sender is the event-based response sender. When set, the event-based
 	// handler methods (HandleLogin, HandleTextMessage, etc.) use this for
 	// sending responses. When nil, only the legacy HandlePacket path works.
 	sender NodeSender
 
-	// Sync loop state
-	nextClientIdx int
+	// bans is cfg.Bans, or an in-memory list if none was configured.
+	bans *BanList
+
+	// blocklist is cfg.Blocklist, or an in-memory list if none was configured.
+	blocklist *contact.Blocklist
+
+	// postLimiter enforces PostRateLimit.
+	postLimiter postLimiter
+
+	// sync schedules post pushes to clients.
+	sync *syncScheduler
+
+	// fed tracks federation peers and imported posts.
+	fed *federation
+
+	// sinks receive activity events; replaced, never mutated, under sinksMu.
+	sinksMu sync.RWMutex
+	sinks   []EventSink
 }
 
 // SetSender sets the NodeSender used by event-based handler methods
@@ -229,11 +329,35 @@ func NewServer(cfg ServerConfig) *Server {
 		cfg: cfg,
 		log: logger.WithGroup("room"),
 	}
+	if cfg.PreviousKey != nil {
+		copy(s.prevID[:], cfg.PreviousKey.Public().(ed25519.PublicKey))
+	}
+	s.sync = newSyncScheduler(cfg.SyncMaxInFlight)
+	s.sinks = append([]EventSink(nil), cfg.EventSinks...)
+	s.bans = cfg.Bans
+	if s.bans == nil {
+		s.bans = NewBanList()
+	}
+	s.blocklist = cfg.Blocklist
+	if s.blocklist == nil {
+		s.blocklist = contact.NewBlocklist()
+	}
+	if cfg.Telemetry != nil && cfg.History != nil {
+		s.sampler = telemetry.NewSampler(telemetry.SamplerConfig{
+			Provider: cfg.Telemetry,
+			History:  cfg.History,
+			Clock:    cfg.Clock,
+			Interval: cfg.SampleInterval,
+			Logger:   logger,
+		})
+	}
+	s.initFederation()
 	s.cli = s.buildCLI()
 	return s
 }
 
-// Start begins the server's background loops (post sync). Blocks until
+// Start begins the server's background loops (post sync and, if configured,
+// telemetry sampling and federation logins). Blocks until
 // the context is cancelled. Typically called in a goroutine:
 //
 //	go server.Start(ctx)
@@ -243,6 +367,12 @@ func (s *Server) Start(ctx context.Context) {
 	s.cancel = cancel
 	s.mu.Unlock()
 
+	if s.sampler != nil {
+		go s.sampler.Start(ctx)
+	}
+	if len(s.cfg.Federation) > 0 {
+		go s.runFederationLoop(ctx)
+	}
 	s.runSyncLoop(ctx)
 }
 
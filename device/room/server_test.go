@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -1131,6 +1132,91 @@ func TestRequest_GetTelemetry_NoProvider(t *testing.T) {
 	}
 }
 
+func TestRequest_GetMinMaxAvg(t *testing.T) {
+	h := newTestHarness(t)
+	h.server.cfg.Clock.SetCurrentTime(5000)
+
+	hist := telemetry.NewMemoryHistory(0)
+	_ = hist.Add([]telemetry.Sample{
+		{Timestamp: 4900, Reading: telemetry.Reading{Channel: telemetry.ChannelSelf, Type: 103, Value: 18}},
+		{Timestamp: 4950, Reading: telemetry.Reading{Channel: telemetry.ChannelSelf, Type: 103, Value: 22}},
+		{Timestamp: 4950, Reading: telemetry.Reading{Channel: 2, Type: 104, Value: 60}, Perm: telemetry.PermEnvironment},
+	})
+	h.server.cfg.History = hist
+
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	_, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: clientID, Permissions: codec.PermACLReadOnly}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqContent := codec.BuildRequestContent(400, codec.ReqTypeGetMinMaxAvg, telemetry.BuildMinMaxAvgRequest(600, 0))
+	pkt := h.buildAddressedPacket(t, clientKey, clientID, codec.PayloadTypeReq, reqContent)
+	h.server.HandlePacket(pkt, transport.PacketSourceMQTT)
+
+	if h.transport.sentCount() == 0 {
+		t.Fatal("expected a response packet")
+	}
+	plaintext := h.decryptResponse(t, clientKey, h.transport.lastPacket())
+	if tag := binary.LittleEndian.Uint32(plaintext[0:4]); tag != 400 {
+		t.Errorf("expected tag=400, got %d", tag)
+	}
+	now, stats, err := telemetry.ParseMinMaxAvg(plaintext[4 : 4+4+(2+3*2)+(2+3*1)]) // strip AES padding
+	if err != nil || now != 5000 || len(stats) != 2 {
+		t.Fatalf("ParseMinMaxAvg = %d, %+v, %v", now, stats, err)
+	}
+	if stats[0].Min != 18 || stats[0].Max != 22 || stats[0].Avg != 20 {
+		t.Errorf("temperature stats = %+v", stats[0])
+	}
+}
+
+func TestRequest_GetMinMaxAvg_GuestDenied(t *testing.T) {
+	h := newTestHarness(t)
+	h.server.cfg.History = telemetry.NewMemoryHistory(0)
+
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	_, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: clientID, Permissions: codec.PermACLGuest}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqContent := codec.BuildRequestContent(400, codec.ReqTypeGetMinMaxAvg, telemetry.BuildMinMaxAvgRequest(600, 0))
+	pkt := h.buildAddressedPacket(t, clientKey, clientID, codec.PayloadTypeReq, reqContent)
+	h.server.HandlePacket(pkt, transport.PacketSourceMQTT)
+
+	if h.transport.sentCount() != 0 {
+		t.Errorf("expected no response for a guest, got %d packets", h.transport.sentCount())
+	}
+}
+
+func TestRequest_GetOwnerInfo(t *testing.T) {
+	h := newTestHarness(t)
+	h.server.cfg.Name = "Club Room"
+	h.server.cfg.OwnerInfo = "Ask for Jo"
+
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	_, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: clientID, Permissions: codec.PermACLReadWrite}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqContent := codec.BuildRequestContent(400, codec.ReqTypeGetOwnerInfo, nil)
+	pkt := h.buildAddressedPacket(t, clientKey, clientID, codec.PayloadTypeReq, reqContent)
+	h.server.HandlePacket(pkt, transport.PacketSourceMQTT)
+
+	if h.transport.sentCount() == 0 {
+		t.Fatal("expected a response packet")
+	}
+	plaintext := h.decryptResponse(t, clientKey, h.transport.lastPacket())
+	if tag := binary.LittleEndian.Uint32(plaintext[0:4]); tag != 400 {
+		t.Errorf("expected tag=400, got %d", tag)
+	}
+	info := codec.ParseOwnerInfo(plaintext[4:])
+	if info.FirmwareVersion == "" || info.Name != "Club Room" || info.Owner != "Ask for Jo" {
+		t.Errorf("owner info = %+v", info)
+	}
+}
+
 func TestRequest_GetAccessList_Admin(t *testing.T) {
 	h := newTestHarness(t)
 
@@ -1272,6 +1358,115 @@ func TestRequest_GetAccessList_EmptyACL(t *testing.T) {
 	}
 }
 
+// requestPostHistory sends a GET_POST_HISTORY request and returns the decoded
+// page, or nil if the server did not answer.
+func (h *testHarness) requestPostHistory(t *testing.T, clientKey *crypto.KeyPair, clientID core.MeshCoreID, tag, before uint32, count uint8) *codec.PostHistory {
+	t.Helper()
+	sent := h.transport.sentCount()
+	reqContent := codec.BuildRequestContent(tag, codec.ReqTypeGetPostHistory, codec.BuildPostHistoryRequest(before, count))
+	pkt := h.buildAddressedPacket(t, clientKey, clientID, codec.PayloadTypeReq, reqContent)
+	h.server.HandlePacket(pkt, transport.PacketSourceMQTT)
+	if h.transport.sentCount() == sent {
+		return nil
+	}
+
+	plaintext := h.decryptResponse(t, clientKey, h.transport.lastPacket())
+	if got := binary.LittleEndian.Uint32(plaintext[0:4]); got != tag {
+		t.Errorf("expected tag=%d, got %d", tag, got)
+	}
+	page, err := codec.ParsePostHistory(plaintext[4:])
+	if err != nil {
+		t.Fatalf("ParsePostHistory: %v", err)
+	}
+	return page
+}
+
+func TestRequest_GetPostHistory_Pages(t *testing.T) {
+	h := newTestHarness(t)
+
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	_, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: clientID, Permissions: codec.PermACLReadOnly}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, msg := range []string{"first", "second", "third"} {
+		ts := uint32(1000 + 100*i)
+		h.posts.AddPost(&PostInfo{Timestamp: ts, SenderID: core.MeshCoreID{byte(i + 1)}, Content: buildPostContent(ts, msg)})
+	}
+
+	page := h.requestPostHistory(t, clientKey, clientID, 600, 0, 2)
+	if page == nil {
+		t.Fatal("expected a response packet")
+	}
+	if !page.More || len(page.Posts) != 2 {
+		t.Fatalf("page 1 = %+v, want 2 posts and more", page)
+	}
+	if p := page.Posts[0]; p.Timestamp != 1200 || p.Text != "third" || p.SenderPrefix[0] != 3 {
+		t.Errorf("page 1 newest = %+v", p)
+	}
+	if p := page.Posts[1]; p.Timestamp != 1100 || p.Text != "second" {
+		t.Errorf("page 1 oldest = %+v", p)
+	}
+
+	page = h.requestPostHistory(t, clientKey, clientID, 601, page.Posts[1].Timestamp, 2)
+	if page == nil {
+		t.Fatal("expected a response packet")
+	}
+	if page.More || len(page.Posts) != 1 || page.Posts[0].Text != "first" {
+		t.Errorf("page 2 = %+v, want only first and no more", page)
+	}
+}
+
+func TestRequest_GetPostHistory_FitsOnePacket(t *testing.T) {
+	h := newTestHarness(t)
+
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	_, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: clientID, Permissions: codec.PermACLReadWrite}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := strings.Repeat("x", codec.MaxTextLen)
+	for ts := uint32(1000); ts < 1005; ts++ {
+		h.posts.AddPost(&PostInfo{Timestamp: ts, SenderID: core.MeshCoreID{0x01}, Content: buildPostContent(ts, long)})
+	}
+
+	page := h.requestPostHistory(t, clientKey, clientID, 700, 0, 0)
+	if page == nil {
+		t.Fatal("expected a response packet")
+	}
+	if !page.More || len(page.Posts) != 1 || page.Posts[0].Text != long {
+		t.Errorf("page = %d posts, more=%v; want one full post and more", len(page.Posts), page.More)
+	}
+	if size := len(h.transport.lastPacket().Payload); size > codec.MaxPacketPayload {
+		t.Errorf("response payload = %d bytes, exceeds %d", size, codec.MaxPacketPayload)
+	}
+
+	// A long return path leaves less room: the text is cut to fit.
+	resp := h.server.buildPostHistoryResponse(701, h.clients.GetClient(clientID), nil, historyReplyLimit(true, codec.MaxPathSize))
+	if len(resp) > historyReplyLimit(true, codec.MaxPathSize) {
+		t.Errorf("response = %d bytes, exceeds limit", len(resp))
+	}
+	cut, err := codec.ParsePostHistory(resp[4:])
+	if err != nil || len(cut.Posts) != 1 || len(cut.Posts[0].Text) >= len(long) {
+		t.Errorf("long-path page = %+v, %v; want one cut post", cut, err)
+	}
+}
+
+func TestRequest_GetPostHistory_GuestDenied(t *testing.T) {
+	h := newTestHarness(t)
+
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	_, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: clientID, Permissions: codec.PermACLGuest}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.posts.AddPost(&PostInfo{Timestamp: 1000, SenderID: core.MeshCoreID{0x01}, Content: buildPostContent(1000, "hi")})
+
+	if page := h.requestPostHistory(t, clientKey, clientID, 800, 0, 0); page != nil {
+		t.Errorf("expected no response for guest, got %+v", page)
+	}
+}
+
 // --- Sync loop tests ---
 
 func TestSyncOnce_PushesPost(t *testing.T) {
@@ -1481,3 +1676,67 @@ func TestExtractNullTerminated(t *testing.T) {
 		})
 	}
 }
+
+// TestTextMessage_PreviousKey verifies that during a key rotation messages
+// encrypted to the room's old key are still handled, and replies come from
+// the old identity, until the grace period ends.
+func TestTextMessage_PreviousKey(t *testing.T) {
+	h := newTestHarness(t)
+	oldKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var oldID core.MeshCoreID
+	copy(oldID[:], oldKey.PublicKey)
+
+	clientKey, clientID := h.makeClientKeyAndContact(t)
+	if _, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: clientID, Permissions: codec.PermACLAdmin}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages from the client, encrypted to the old key.
+	secret, err := crypto.ComputeSharedSecret(clientKey.PrivateKey, oldKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toOldKey := func(ts uint32, txtType uint8, text string, route uint8) *codec.Packet {
+		encrypted, err := crypto.EncryptAddressedWithSecret(
+			codec.BuildTxtMsgContent(ts, txtType, 0, text, nil), secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mac, ciphertext := codec.SplitMAC(encrypted)
+		return &codec.Packet{
+			Header:  (codec.PayloadTypeTxtMsg << codec.PHTypeShift) | route,
+			Payload: codec.BuildAddressedPayload(oldID.Hash(), clientID.Hash(), mac, ciphertext),
+		}
+	}
+
+	cfg := h.server.cfg
+	cfg.PreviousKey = oldKey.PrivateKey
+	cfg.PreviousKeyUntil = time.Now().Add(time.Hour)
+	h.server = NewServer(cfg)
+
+	h.server.HandlePacket(toOldKey(200, codec.TxtTypePlain, "to old key", codec.RouteTypeDirect), transport.PacketSourceMQTT)
+	if h.posts.Count() != 1 {
+		t.Fatalf("expected 1 post via the previous key, got %d", h.posts.Count())
+	}
+
+	h.transport.reset()
+	h.server.HandlePacket(toOldKey(201, codec.TxtTypeCLI, "ver", codec.RouteTypeDirect), transport.PacketSourceMQTT)
+	reply := h.transport.lastPacket()
+	if reply == nil || reply.PayloadType() != codec.PayloadTypeTxtMsg {
+		t.Fatalf("expected a CLI reply, got %v", reply)
+	}
+	if reply.Payload[1] != oldID.Hash() {
+		t.Errorf("reply source hash = %#x, want old identity %#x", reply.Payload[1], oldID.Hash())
+	}
+
+	// After the grace period the old key is no longer honored.
+	cfg.PreviousKeyUntil = time.Now().Add(-time.Second)
+	h.server = NewServer(cfg)
+	h.server.HandlePacket(toOldKey(300, codec.TxtTypePlain, "too late", codec.RouteTypeDirect), transport.PacketSourceMQTT)
+	if h.posts.Count() != 1 {
+		t.Errorf("expired previous key still decrypted: %d posts", h.posts.Count())
+	}
+}
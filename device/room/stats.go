@@ -35,6 +35,14 @@ type ServerStats struct {
 	NFloodDups       uint16 // Offset 46: flood route duplicate count
 	NPosted          uint16 // Offset 48: posts added to server
 	NPostPush        uint16 // Offset 50: posts pushed to clients
+
+	// Sync scheduler state, filled in by Server.Stats. These are not part of
+	// the firmware struct and are not serialized by MarshalBinary.
+	SyncPendingClients uint16 // clients with posts waiting
+	SyncPendingPosts   uint32 // posts waiting, summed over clients
+	SyncInFlight       uint16 // pushes awaiting an ACK
+	SyncMaxLagSecs     uint32 // age of the oldest post still waiting
+	SyncAvgLagSecs     uint32 // moving average of post-to-ACK time
 }
 
 // MarshalBinary serializes the stats to a 52-byte little-endian blob
@@ -62,6 +70,29 @@ func (s *ServerStats) MarshalBinary() []byte {
 	return data
 }
 
+// Stats returns the StatsProvider's statistics (zero if none is configured)
+// with the sync scheduler fields filled in.
+func (s *Server) Stats() ServerStats {
+	var st ServerStats
+	if s.cfg.Stats != nil {
+		st = s.cfg.Stats.GetStats()
+	}
+	sched := s.SyncStats()
+	st.SyncPendingClients = clampUint16(sched.PendingClients)
+	st.SyncPendingPosts = uint32(sched.PendingPosts)
+	st.SyncInFlight = clampUint16(sched.InFlight)
+	st.SyncMaxLagSecs = sched.MaxLagSecs
+	st.SyncAvgLagSecs = sched.AvgLagSecs
+	return st
+}
+
+func clampUint16(n int) uint16 {
+	if n > 0xFFFF {
+		return 0xFFFF
+	}
+	return uint16(n)
+}
+
 // StatsProvider supplies server statistics for GET_STATUS responses.
 // Implementations populate the ServerStats struct from whatever data sources
 // are available (hardware, counters, etc.).
@@ -9,6 +9,7 @@ import (
 	"github.com/kabili207/meshcore-go/core/codec"
 	"github.com/kabili207/meshcore-go/core/crypto"
 	"github.com/kabili207/meshcore-go/device/ack"
+	"github.com/kabili207/meshcore-go/device/acl"
 )
 
 const (
@@ -31,7 +32,11 @@ const (
 
 // runSyncLoop runs the post sync loop until the context is cancelled.
 func (s *Server) runSyncLoop(ctx context.Context) {
-	timer := time.NewTimer(SyncPushInterval)
+	interval := s.cfg.SyncInterval
+	if interval <= 0 {
+		interval = SyncPushInterval
+	}
+	timer := time.NewTimer(interval)
 	defer timer.Stop()
 
 	for {
@@ -41,7 +46,7 @@ func (s *Server) runSyncLoop(ctx context.Context) {
 		case <-timer.C:
 			pushed := s.syncOnce()
 			if pushed {
-				timer.Reset(SyncPushInterval)
+				timer.Reset(interval)
 			} else {
 				timer.Reset(SyncIdleInterval)
 			}
@@ -49,71 +54,57 @@ func (s *Server) runSyncLoop(ctx context.Context) {
 	}
 }
 
-// syncOnce performs one round of the sync loop: picks the next client and
-// pushes the oldest unseen post. Returns true if a post was pushed.
+// syncOnce performs one round of the sync loop: the scheduler picks the ready
+// clients, up to the in-flight limit, and each is pushed its oldest unseen
+// post. Returns true if a post was pushed.
 func (s *Server) syncOnce() bool {
-	clientCount := s.cfg.Clients.Count()
-	if clientCount == 0 {
-		return false
-	}
-
-	s.mu.Lock()
-	idx := s.nextClientIdx
-	s.nextClientIdx = (s.nextClientIdx + 1) % clientCount
-	s.mu.Unlock()
-
-	// Get client at this index
-	var client *ClientInfo
-	i := 0
-	s.cfg.Clients.ForEach(func(c *ClientInfo) bool {
-		if i == idx {
-			client = c
-			return false
-		}
-		i++
-		return true
-	})
-
-	if client == nil {
-		return false
+	nowTS := s.cfg.Clock.GetCurrentTime()
+	picks := s.sync.next(s.cfg.Clients, s.cfg.Posts, nowTS)
+	s.emitDropped()
+	for _, p := range picks {
+		s.pushPostToClient(p.client, p.post)
 	}
+	return len(picks) > 0
+}
 
-	// Skip if client is not ready for push
-	if client.LastActivity == 0 {
-		return false
-	}
-	if client.PushFailures >= MaxPushFailures {
-		return false
+// emitDropped reports a logout for each client the scheduler found missing
+// from the client store.
+func (s *Server) emitDropped() {
+	for _, id := range s.sync.takeDropped() {
+		s.postLimiter.forget(id)
+		s.emit(Event{Kind: EventClientLogout, Client: id, Reason: LogoutEvicted})
 	}
+}
 
-	// Find the oldest unsynced post for this client
-	nowTS := s.cfg.Clock.GetCurrentTime()
-	posts := s.cfg.Posts.GetPostsSince(client.SyncSince)
-
-	for _, post := range posts {
-		// Post must be old enough
-		if nowTS < post.Timestamp+PostSyncDelay {
-			continue
-		}
-		// Don't push a client's own posts back to them
-		if post.SenderID == client.ID {
-			continue
-		}
-
-		s.pushPostToClient(client, post)
-		return true
+// addClient adds a newly logged-in client to the client store. If the store
+// evicted another client to make room, the eviction is reported at once
+// rather than at the next reconcile.
+func (s *Server) addClient(id core.MeshCoreID) (*ClientInfo, error) {
+	before := s.cfg.Clients.Count()
+	client, err := s.cfg.Clients.AddClient(&ClientInfo{Client: acl.Client{ID: id}})
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.Clients.Count() <= before {
+		s.sync.reconcile(s.cfg.Clients, s.cfg.Posts)
+		s.emitDropped()
 	}
+	return client, nil
+}
 
-	return false
+// SyncStats reports the state of the post sync scheduler.
+func (s *Server) SyncStats() SyncStats {
+	return s.sync.stats(s.cfg.Clock.GetCurrentTime())
 }
 
 // unsyncedCount returns how many stored posts are newer than the client's sync
-// point and not authored by the client (firmware getUnsyncedCount). The result
+// point and not authored by the client (firmware getUnsyncedCount), nor
+// imported from it. The result
 // is clamped to a single byte for the keep-alive ACK.
 func (s *Server) unsyncedCount(client *ClientInfo) uint8 {
 	count := 0
 	for _, p := range s.cfg.Posts.GetPostsSince(client.SyncSince) {
-		if p.SenderID != client.ID {
+		if wantsPost(client.ID, p) {
 			count++
 		}
 	}
@@ -126,6 +117,7 @@ func (s *Server) unsyncedCount(client *ClientInfo) uint8 {
 // pushPostToClient sends a post to a client and tracks the expected ACK.
 func (s *Server) pushPostToClient(client *ClientInfo, post *PostInfo) {
 	if len(post.Content) == 0 {
+		s.sync.skip(client.ID, post.Timestamp)
 		return
 	}
 
@@ -134,6 +126,7 @@ func (s *Server) pushPostToClient(client *ClientInfo, post *PostInfo) {
 	stored, err := codec.ParseTxtMsgContent(post.Content)
 	if err != nil {
 		s.log.Debug("failed to parse stored post", "peer", client.ID.String(), "error", err)
+		s.sync.skip(client.ID, post.Timestamp)
 		return
 	}
 
@@ -141,33 +134,51 @@ func (s *Server) pushPostToClient(client *ClientInfo, post *PostInfo) {
 	// prefix and the post's original timestamp, so the recipient can attribute it
 	// (firmware pushPostToClient). The random attempt byte keeps the packet hash
 	// unique across retransmissions.
-	var rnd [1]byte
-	_, _ = rand.Read(rnd[:])
-	payload := codec.BuildTxtMsgContent(post.Timestamp, codec.TxtTypeSigned, rnd[0]&0x03, stored.Message, post.SenderID[:4])
+	// A federation peer gets the federated form, which also names the post's
+	// origin room.
+	var payload []byte
+	if s.isPeer(client.ID) {
+		payload = s.buildFederatedPush(post, stored.Message)
+	} else {
+		var rnd [1]byte
+		_, _ = rand.Read(rnd[:])
+		payload = codec.BuildTxtMsgContent(post.Timestamp, codec.TxtTypeSigned, rnd[0]&0x03, stored.Message, post.SenderID[:4])
+	}
 
 	// Expected ACK: signed messages are keyed by the recipient's pubkey, hashed
 	// over the full signed payload.
 	ackHash := crypto.ComputeAckHash(payload, client.ID[:])
 
-	// Track this push for ACK
+	// Track this push for ACK. Without a tracker the push is assumed
+	// delivered, so the client's queue still advances.
 	clientID := client.ID
 	postTimestamp := post.Timestamp
+	postSender := post.SenderID
+	onACK := func() {
+		c := s.cfg.Clients.GetClient(clientID)
+		if c != nil {
+			c.SyncSince = postTimestamp
+			c.PushFailures = 0
+		}
+		s.sync.acked(clientID, postTimestamp, s.cfg.Clock.GetCurrentTime())
+		s.emit(Event{Kind: EventPostPushed, Client: clientID, PostTimestamp: postTimestamp, PostSender: postSender})
+	}
 	if s.cfg.ACKTracker != nil {
 		s.cfg.ACKTracker.Track(ackHash, ack.PendingACK{
-			OnACK: func() {
-				c := s.cfg.Clients.GetClient(clientID)
-				if c != nil {
-					c.SyncSince = postTimestamp
-					c.PushFailures = 0
-				}
-			},
+			OnACK: onACK,
 			OnTimeout: func() {
+				failures := 0
 				c := s.cfg.Clients.GetClient(clientID)
 				if c != nil {
 					c.PushFailures++
+					failures = int(c.PushFailures)
 				}
+				s.sync.failed(clientID, failures, s.cfg.Clock.GetCurrentTime())
+				s.emit(Event{Kind: EventPushFailed, Client: clientID, PostTimestamp: postTimestamp, PostSender: postSender, Failures: failures})
 			},
 		})
+	} else {
+		defer onACK()
 	}
 
 	client.PushPostTimestamp = postTimestamp
@@ -0,0 +1,391 @@
+package room
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/kabili207/meshcore-go/core"
+)
+
+const (
+	// DefaultSyncMaxInFlight is the default number of unacknowledged post
+	// pushes allowed at once, across all clients.
+	DefaultSyncMaxInFlight = 4
+
+	// syncBackoffBase is the delay, in seconds, before retrying a client after
+	// its first failed push. It doubles with each consecutive failure until
+	// MaxPushFailures parks the client until it is next heard from.
+	syncBackoffBase uint32 = 10
+
+	// syncLagWeight is the EWMA weight of each new delivery-lag sample.
+	syncLagWeight = 1.0 / 8
+
+	// syncReconcileRounds is how many rounds may pass before the client store
+	// is walked even though its count is unchanged, to catch a client replaced
+	// by eviction.
+	syncReconcileRounds = 64
+)
+
+// SyncStats describes the state of the post sync scheduler.
+type SyncStats struct {
+	PendingClients int    // clients with at least one post waiting
+	PendingPosts   int    // posts waiting, summed over clients
+	InFlight       int    // pushes awaiting an ACK
+	MaxLagSecs     uint32 // age of the oldest post still waiting for a client
+	AvgLagSecs     uint32 // moving average of post-stored-to-ACKed time
+}
+
+// syncScheduler keeps a ready queue of unsynced posts for each client so a
+// sync round only looks at clients that have something to receive. New posts
+// are found by asking the store for anything newer than the last post seen,
+// plus the posts the server reported storing since the last round, which
+// catches a post stored after a newer one; both are fanned out to every
+// client's queue. A queue is rebuilt from the store when its client logs in,
+// changes its sync point, or posts are deleted.
+//
+// Each round pushes the head post of ready clients, most recently active
+// first, while fewer than maxInFlight pushes are unacknowledged. A client is
+// ready when its head post is older than PostSyncDelay, it has no push in
+// flight, and it is not backing off after a failed push.
+type syncScheduler struct {
+	maxInFlight int
+
+	mu          sync.Mutex
+	queues      map[core.MeshCoreID]*syncQueue // every known client
+	pending     map[core.MeshCoreID]*syncQueue // queues with posts waiting
+	newest      uint32                         // newest post timestamp fanned out
+	stored      []*PostInfo                    // posts stored since the last fan-out, in store order
+	clientCount int                            // client count at last reconcile
+	rounds      int                            // rounds since last reconcile
+	dropped     []core.MeshCoreID              // clients gone from the store, not via forget
+	inFlight    int
+	lagAvg      float64
+	lagSeen     bool
+}
+
+// syncQueue is one client's pending posts, oldest first.
+type syncQueue struct {
+	client   *ClientInfo
+	posts    []*PostInfo
+	stale    bool   // rebuild from the store before use
+	inFlight bool   // a push is awaiting an ACK
+	retryAt  uint32 // backoff: no push before this clock time
+}
+
+// syncPick is a post chosen for a client in one round.
+type syncPick struct {
+	client *ClientInfo
+	post   *PostInfo
+}
+
+func newSyncScheduler(maxInFlight int) *syncScheduler {
+	if maxInFlight <= 0 {
+		maxInFlight = DefaultSyncMaxInFlight
+	}
+	return &syncScheduler{
+		maxInFlight: maxInFlight,
+		queues:      make(map[core.MeshCoreID]*syncQueue),
+		pending:     make(map[core.MeshCoreID]*syncQueue),
+		clientCount: -1,
+	}
+}
+
+// next brings the queues up to date with the stores and returns the pushes to
+// make this round, marking them in flight.
+func (q *syncScheduler) next(clients ClientStore, posts PostStore, now uint32) []syncPick {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.reconcileLocked(clients, posts)
+	q.fanOutLocked(posts)
+
+	budget := q.maxInFlight - q.inFlight
+	if budget <= 0 || len(q.pending) == 0 {
+		return nil
+	}
+
+	var ready []*syncQueue
+	for id, sq := range q.pending {
+		if sq.stale {
+			q.refillLocked(sq, posts)
+		}
+		if len(sq.posts) == 0 {
+			delete(q.pending, id)
+			continue
+		}
+		c := sq.client
+		switch {
+		case sq.inFlight,
+			c.LastActivity == 0,
+			c.PushFailures >= MaxPushFailures,
+			c.PushFailures > 0 && now < sq.retryAt,
+			now < sq.posts[0].Timestamp+PostSyncDelay:
+			continue
+		}
+		ready = append(ready, sq)
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		return ready[i].client.LastActivity > ready[j].client.LastActivity
+	})
+	if len(ready) > budget {
+		ready = ready[:budget]
+	}
+
+	picks := make([]syncPick, 0, len(ready))
+	for _, sq := range ready {
+		sq.inFlight = true
+		q.inFlight++
+		picks = append(picks, syncPick{client: sq.client, post: sq.posts[0]})
+	}
+	return picks
+}
+
+// reconcileLocked adds queues for new clients and drops those of removed
+// clients. It only walks the client store when the client count changes, or
+// every syncReconcileRounds rounds.
+func (q *syncScheduler) reconcileLocked(clients ClientStore, posts PostStore) {
+	n := clients.Count()
+	q.rounds++
+	if n == q.clientCount && q.rounds < syncReconcileRounds {
+		return
+	}
+	q.clientCount = n
+	q.rounds = 0
+
+	seen := make(map[core.MeshCoreID]bool, n)
+	clients.ForEach(func(c *ClientInfo) bool {
+		seen[c.ID] = true
+		if sq := q.queues[c.ID]; sq == nil || sq.client != c {
+			q.addLocked(c)
+		}
+		return true
+	})
+	for id := range q.queues {
+		if !seen[id] {
+			q.forgetLocked(id)
+			q.dropped = append(q.dropped, id)
+		}
+	}
+	if q.newest == 0 {
+		// First reconcile: the refills above cover every existing post.
+		if all := posts.GetPostsSince(0); len(all) > 0 {
+			q.newest = all[len(all)-1].Timestamp
+		}
+	}
+}
+
+// reconcile walks the client store now, so clients it evicted are found
+// without waiting for the next round.
+func (q *syncScheduler) reconcile(clients ClientStore, posts PostStore) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.clientCount = -1
+	q.reconcileLocked(clients, posts)
+}
+
+// added records a post the server stored, so it is fanned out next round
+// even if a newer post was fanned out first.
+func (q *syncScheduler) added(p *PostInfo) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stored = append(q.stored, p)
+}
+
+// fanOutLocked adds posts stored since the last round to every queue.
+func (q *syncScheduler) fanOutLocked(posts PostStore) {
+	fresh := posts.GetPostsSince(q.newest)
+	for _, p := range q.stored {
+		// Newer posts are already in fresh; older ones the scan missed.
+		if p.Timestamp <= q.newest {
+			fresh = append(fresh, p)
+		}
+	}
+	q.stored = nil
+	if len(fresh) == 0 {
+		return
+	}
+	sort.SliceStable(fresh, func(i, j int) bool { return fresh[i].Timestamp < fresh[j].Timestamp })
+	if last := fresh[len(fresh)-1].Timestamp; last > q.newest {
+		q.newest = last
+	}
+	for id, sq := range q.queues {
+		if sq.stale {
+			continue // the refill will pick these up
+		}
+		for _, p := range fresh {
+			if wantsPost(id, p) && p.Timestamp > sq.client.SyncSince {
+				sq.insert(p)
+			}
+		}
+		if len(sq.posts) > 0 {
+			q.pending[id] = sq
+		}
+	}
+}
+
+// insert adds p to the queue in timestamp order. A post older than an
+// in-flight head is dropped: the ACK for that push moves the client's sync
+// point past it, just as a refill from the store would.
+func (sq *syncQueue) insert(p *PostInfo) {
+	if sq.inFlight && len(sq.posts) > 0 && p.Timestamp < sq.posts[0].Timestamp {
+		return
+	}
+	i := len(sq.posts)
+	for i > 0 && sq.posts[i-1].Timestamp > p.Timestamp {
+		i--
+	}
+	sq.posts = slices.Insert(sq.posts, i, p)
+}
+
+// refillLocked rebuilds a queue from the store.
+func (q *syncScheduler) refillLocked(sq *syncQueue, posts PostStore) {
+	sq.posts = sq.posts[:0]
+	for _, p := range posts.GetPostsSince(sq.client.SyncSince) {
+		if wantsPost(sq.client.ID, p) {
+			sq.posts = append(sq.posts, p)
+		}
+	}
+	sq.stale = false
+}
+
+// acked records a delivered push and advances the client's queue.
+func (q *syncScheduler) acked(id core.MeshCoreID, postTS, now uint32) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if lag := float64(now) - float64(postTS); lag >= 0 {
+		if !q.lagSeen {
+			q.lagAvg, q.lagSeen = lag, true
+		} else {
+			q.lagAvg += (lag - q.lagAvg) * syncLagWeight
+		}
+	}
+
+	q.advanceLocked(id, postTS)
+}
+
+// skip drops an in-flight post that could not be pushed (e.g. unparseable
+// content) so the client's queue moves past it.
+func (q *syncScheduler) skip(id core.MeshCoreID, postTS uint32) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.advanceLocked(id, postTS)
+}
+
+// advanceLocked settles a client's in-flight push and pops posts up to postTS.
+func (q *syncScheduler) advanceLocked(id core.MeshCoreID, postTS uint32) {
+	sq := q.queues[id]
+	if sq == nil || !sq.inFlight {
+		return
+	}
+	q.settleLocked(sq)
+	for len(sq.posts) > 0 && sq.posts[0].Timestamp <= postTS {
+		sq.posts = sq.posts[1:]
+	}
+	if len(sq.posts) == 0 && !sq.stale {
+		delete(q.pending, id)
+	}
+}
+
+// failed records an unacknowledged push and backs the client off.
+func (q *syncScheduler) failed(id core.MeshCoreID, failures int, now uint32) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	sq := q.queues[id]
+	if sq == nil || !sq.inFlight {
+		return
+	}
+	q.settleLocked(sq)
+	if failures > 0 {
+		sq.retryAt = now + syncBackoffBase<<(failures-1)
+	}
+}
+
+// settleLocked clears a queue's in-flight push.
+func (q *syncScheduler) settleLocked(sq *syncQueue) {
+	sq.inFlight = false
+	q.inFlight--
+}
+
+// resync rebuilds a client's queue, after it logged in or its sync point
+// moved.
+func (q *syncScheduler) resync(c *ClientInfo) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.addLocked(c)
+}
+
+// addLocked (re)creates a client's queue, to be filled from the store. An
+// in-flight push to the client stays counted until it settles.
+func (q *syncScheduler) addLocked(c *ClientInfo) {
+	sq := q.queues[c.ID]
+	if sq == nil {
+		sq = &syncQueue{}
+		q.queues[c.ID] = sq
+	}
+	sq.client = c
+	sq.stale = true
+	sq.retryAt = 0
+	q.pending[c.ID] = sq
+}
+
+// invalidate marks every queue for rebuilding, after posts were deleted.
+func (q *syncScheduler) invalidate() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, sq := range q.queues {
+		sq.stale = true
+		q.pending[id] = sq
+	}
+	q.newest = 0
+	q.stored = nil
+	q.clientCount = -1
+}
+
+// takeDropped returns and clears the clients found missing from the client
+// store since the last call: evicted by the store rather than removed through
+// forget.
+func (q *syncScheduler) takeDropped() []core.MeshCoreID {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	d := q.dropped
+	q.dropped = nil
+	return d
+}
+
+// forget drops a removed client's queue.
+func (q *syncScheduler) forget(id core.MeshCoreID) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.forgetLocked(id)
+	q.clientCount = -1
+}
+
+func (q *syncScheduler) forgetLocked(id core.MeshCoreID) {
+	if sq := q.queues[id]; sq != nil && sq.inFlight {
+		q.settleLocked(sq)
+	}
+	delete(q.queues, id)
+	delete(q.pending, id)
+}
+
+// stats summarises the queues at clock time now.
+func (q *syncScheduler) stats(now uint32) SyncStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	st := SyncStats{InFlight: q.inFlight, AvgLagSecs: uint32(q.lagAvg + 0.5)}
+	for _, sq := range q.pending {
+		if len(sq.posts) == 0 {
+			continue
+		}
+		st.PendingClients++
+		st.PendingPosts += len(sq.posts)
+		if ts := sq.posts[0].Timestamp; now > ts && now-ts > st.MaxLagSecs {
+			st.MaxLagSecs = now - ts
+		}
+	}
+	return st
+}
@@ -0,0 +1,185 @@
+package room
+
+import (
+	"testing"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/device/acl"
+)
+
+func schedClient(t *testing.T, clients ClientStore, id byte, lastActivity uint32) *ClientInfo {
+	t.Helper()
+	c, err := clients.AddClient(&ClientInfo{Client: acl.Client{ID: core.MeshCoreID{id}, LastActivity: lastActivity}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSyncScheduler_PrioritisesActiveClientsWithinBudget(t *testing.T) {
+	clients := NewMemoryClientStore(10)
+	posts := NewMemoryPostStore(10)
+	q := newSyncScheduler(2)
+
+	schedClient(t, clients, 1, 100)
+	schedClient(t, clients, 2, 300)
+	schedClient(t, clients, 3, 200)
+	_ = posts.AddPost(makePost(10, 9, "hi"))
+
+	picks := q.next(clients, posts, 1000)
+	if len(picks) != 2 {
+		t.Fatalf("picks = %d, want 2 (in-flight budget)", len(picks))
+	}
+	if picks[0].client.ID[0] != 2 || picks[1].client.ID[0] != 3 {
+		t.Errorf("picked clients %d, %d; want 2, 3 (most recently active)", picks[0].client.ID[0], picks[1].client.ID[0])
+	}
+
+	// The budget is spent until a push settles.
+	if got := q.next(clients, posts, 1001); len(got) != 0 {
+		t.Errorf("picks with full budget = %d, want 0", len(got))
+	}
+	q.acked(picks[0].client.ID, 10, 1002)
+	got := q.next(clients, posts, 1003)
+	if len(got) != 1 || got[0].client.ID[0] != 1 {
+		t.Errorf("after ACK picked %v, want client 1", got)
+	}
+}
+
+func TestSyncScheduler_AdvancesQueueOnACK(t *testing.T) {
+	clients := NewMemoryClientStore(10)
+	posts := NewMemoryPostStore(10)
+	q := newSyncScheduler(1)
+	c := schedClient(t, clients, 1, 100)
+
+	_ = posts.AddPost(makePost(10, 9, "a"))
+	_ = posts.AddPost(makePost(11, 1, "own"))
+	_ = posts.AddPost(makePost(12, 9, "b"))
+
+	var pushed []uint32
+	for now := uint32(1000); now < 1010; now++ {
+		for _, p := range q.next(clients, posts, now) {
+			pushed = append(pushed, p.post.Timestamp)
+			c.SyncSince = p.post.Timestamp
+			q.acked(c.ID, p.post.Timestamp, now)
+		}
+	}
+	if len(pushed) != 2 || pushed[0] != 10 || pushed[1] != 12 {
+		t.Errorf("pushed %v, want [10 12] (own post skipped)", pushed)
+	}
+
+	// A post stored later is fanned out to the queue.
+	_ = posts.AddPost(makePost(1018, 9, "late"))
+	if got := q.next(clients, posts, 1020); len(got) != 0 {
+		t.Error("post pushed before PostSyncDelay elapsed")
+	}
+	if got := q.next(clients, posts, 1024); len(got) != 1 || got[0].post.Timestamp != 1018 {
+		t.Errorf("picks = %v, want the late post", got)
+	}
+}
+
+func TestSyncScheduler_BacksOffAfterFailure(t *testing.T) {
+	clients := NewMemoryClientStore(10)
+	posts := NewMemoryPostStore(10)
+	q := newSyncScheduler(1)
+	c := schedClient(t, clients, 1, 100)
+	_ = posts.AddPost(makePost(10, 9, "hi"))
+
+	if len(q.next(clients, posts, 1000)) != 1 {
+		t.Fatal("expected a push")
+	}
+	c.PushFailures = 2
+	q.failed(c.ID, 2, 1000)
+
+	if got := q.next(clients, posts, 1000+2*syncBackoffBase-1); len(got) != 0 {
+		t.Error("client retried during backoff")
+	}
+	if got := q.next(clients, posts, 1000+2*syncBackoffBase); len(got) != 1 {
+		t.Error("client not retried after backoff")
+	}
+
+	c.PushFailures = MaxPushFailures
+	q.failed(c.ID, MaxPushFailures, 2000)
+	if got := q.next(clients, posts, 9000); len(got) != 0 {
+		t.Error("client pushed after MaxPushFailures")
+	}
+}
+
+func TestSyncScheduler_StatsAndInvalidate(t *testing.T) {
+	clients := NewMemoryClientStore(10)
+	posts := NewMemoryPostStore(10)
+	q := newSyncScheduler(1)
+	schedClient(t, clients, 1, 100)
+	schedClient(t, clients, 2, 0) // never active: queued but not pushed
+	_ = posts.AddPost(makePost(10, 9, "a"))
+	_ = posts.AddPost(makePost(20, 9, "b"))
+
+	picks := q.next(clients, posts, 100)
+	if len(picks) != 1 {
+		t.Fatalf("picks = %d, want 1", len(picks))
+	}
+	picks[0].client.SyncSince = 10
+	q.acked(picks[0].client.ID, 10, 40)
+
+	st := q.stats(100)
+	if st.PendingClients != 2 || st.PendingPosts != 3 || st.InFlight != 0 {
+		t.Errorf("stats = %+v, want 2 clients, 3 posts, 0 in flight", st)
+	}
+	if st.MaxLagSecs != 90 || st.AvgLagSecs != 30 {
+		t.Errorf("lag max=%d avg=%d, want 90 and 30", st.MaxLagSecs, st.AvgLagSecs)
+	}
+
+	// Deleting a post rebuilds the queues from the store.
+	_, _ = posts.DeletePost(20)
+	q.invalidate()
+	q.next(clients, posts, 100)
+	if st := q.stats(100); st.PendingPosts != 1 {
+		t.Errorf("pending after delete = %d, want 1", st.PendingPosts)
+	}
+}
+
+func TestServerStats_IncludesSync(t *testing.T) {
+	h := newTestHarness(t)
+	h.server.cfg.Stats = &mockStatsProvider{stats: ServerStats{NPosted: 7}}
+	_, err := h.clients.AddClient(&ClientInfo{Client: acl.Client{ID: core.MeshCoreID{1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = h.posts.AddPost(makePost(10, 9, "hi"))
+	h.server.syncOnce()
+
+	st := h.server.Stats()
+	if st.NPosted != 7 || st.SyncPendingClients != 1 || st.SyncPendingPosts != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+	if len(st.MarshalBinary()) != ServerStatsSize {
+		t.Error("sync fields must not change the wire size")
+	}
+}
+
+func TestSyncScheduler_FansOutPostStoredAfterNewer(t *testing.T) {
+	clients := NewMemoryClientStore(10)
+	posts := NewMemoryPostStore(10)
+	q := newSyncScheduler(1)
+	schedClient(t, clients, 1, 100)
+
+	_ = posts.AddPost(makePost(20, 9, "newer"))
+	if got := q.next(clients, posts, 20); len(got) != 0 {
+		t.Fatal("post pushed before PostSyncDelay elapsed")
+	}
+
+	// A post stamped before the one already fanned out, stored late.
+	late := makePost(15, 9, "older")
+	_ = posts.AddPost(late)
+	q.added(late)
+
+	var pushed []uint32
+	for now := uint32(1000); now < 1010; now++ {
+		for _, p := range q.next(clients, posts, now) {
+			pushed = append(pushed, p.post.Timestamp)
+			q.acked(p.client.ID, p.post.Timestamp, now)
+		}
+	}
+	if len(pushed) != 2 || pushed[0] != 15 || pushed[1] != 20 {
+		t.Errorf("pushed %v, want [15 20]", pushed)
+	}
+}
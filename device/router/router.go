@@ -67,6 +67,9 @@ type PacketHandler func(pkt *codec.Packet, src transport.PacketSource)
 // physical reception, so the path-blind dedup gate must not hide duplicates
 // from them (it still gates forwarding and application processing).
 //
+// Receptions carry their receive metadata in pkt.Rx. Transmissions are
+// reported with source transport.PacketSourceLocal; ignore Rx for those.
+//
 // The monitor must not modify the packet. It runs synchronously on the
 // serialized receive path, so it should return quickly or dispatch work to a
 // goroutine.
@@ -410,8 +413,17 @@ func (r *Router) Counters() *RouterCounters { return &r.counters }
 // HandlePacket is the main routing entry point. It processes an incoming packet,
 // dispatches it to the application callback, and makes forwarding decisions.
 //
+// The packet's receive metadata (pkt.Rx) travels with it to the monitor and
+// the application. A packet from a transport that sets none is given one
+// stamped with the current time, taking its SNR field as measured, so every
+// reception has a receive time.
+//
 // This corresponds to the firmware's Mesh::onRecvPacket() + routeRecvPacket().
 func (r *Router) HandlePacket(pkt *codec.Packet, src transport.PacketSource) {
+	if pkt.Rx == nil {
+		pkt.Rx = &codec.RxMeta{Time: time.Now(), SNR: pkt.GetSNR(), HasSNR: true}
+	}
+
 	// Gate 1: version check
 	if pkt.PayloadVersion() > codec.PayloadVer1 {
 		r.log.Debug("dropping packet with unsupported version",
@@ -521,6 +533,7 @@ func (r *Router) handleMultipart(pkt *codec.Packet, src transport.PacketSource)
 		Path:           append([]byte(nil), pkt.Path...),
 		Payload:        frag.Data,
 		SNR:            pkt.SNR,
+		Rx:             pkt.Rx,
 	}
 	r.HandlePacket(inner, src)
 }
@@ -651,6 +664,7 @@ func (r *Router) routeFloodForward(pkt *codec.Packet, src transport.PacketSource
 	// Clone the packet before modifying path for forwarding.
 	// The original was already dispatched to the app.
 	fwd := pkt.Clone()
+	fwd.Rx = nil
 
 	// Append our N-byte hash to the path
 	selfHash := r.cfg.SelfID.HashN(int(info.HashSize))
@@ -228,6 +228,38 @@ func TestHandlePacket_FloodForward(t *testing.T) {
 	}
 }
 
+func TestHandlePacket_StampsRx(t *testing.T) {
+	mt := newMockTransport()
+	r := New(Config{SelfID: selfID(0xAA), ForwardPackets: true})
+	r.AddTransport(mt, transport.PacketSourceMQTT)
+
+	var seen *codec.RxMeta
+	r.SetPacketHandler(func(pkt *codec.Packet, src transport.PacketSource) {
+		seen = pkt.Rx
+	})
+
+	// A transport that sets no metadata still gets a receive time, and the
+	// SNR byte is trusted as measured.
+	pkt := makeFloodPacket(codec.PayloadTypeTxtMsg, []byte{0x01})
+	pkt.SNR = 20
+	r.HandlePacket(pkt, transport.PacketSourceSerial)
+	if seen == nil || seen.Time.IsZero() || !seen.HasSNR || seen.SNR != 5 {
+		t.Fatalf("Rx = %+v, want stamped time and SNR 5", seen)
+	}
+	if sent := mt.lastSent(); sent == nil || sent.Rx != nil {
+		t.Error("forwarded packet should not carry the reception's metadata")
+	}
+
+	// Metadata set by the transport is kept as is.
+	pkt = makeFloodPacket(codec.PayloadTypeTxtMsg, []byte{0x02})
+	rx := &codec.RxMeta{Transport: "mqtt:test", RSSI: -97, HasRSSI: true}
+	pkt.Rx = rx
+	r.HandlePacket(pkt, transport.PacketSourceSerial)
+	if seen != rx {
+		t.Errorf("Rx = %+v, want the transport's", seen)
+	}
+}
+
 func TestHandlePacket_FloodNoForward(t *testing.T) {
 	mt := newMockTransport()
 	r := New(Config{
@@ -48,6 +48,7 @@ func (r *Router) handleTrace(pkt *codec.Packet, src transport.PacketSource) {
 
 	// Clone, append our SNR to the path, and forward
 	fwd := pkt.Clone()
+	fwd.Rx = nil
 
 	if int(fwd.PathLen) >= len(fwd.Path) {
 		fwd.Path = append(fwd.Path, byte(pkt.SNR))
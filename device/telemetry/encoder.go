@@ -0,0 +1,159 @@
+package telemetry
+
+import (
+	"io"
+
+	cayennelpp "github.com/TheThingsNetwork/go-cayenne-lib"
+)
+
+// ValueAdder is implemented by encoders that can write the extended scalar
+// CayenneLPP types (voltage, current, percentage, ...) that go-cayenne-lib does
+// not expose. The encoder passed to a Provider by Encode and Sampler implements
+// it; use AddValue rather than asserting directly.
+type ValueAdder interface {
+	AddValue(channel, lppType uint8, value float64)
+}
+
+// Compile-time assertions for the extended encoder.
+var (
+	_ cayennelpp.Encoder = (*Encoder)(nil)
+	_ ValueAdder         = (*Encoder)(nil)
+)
+
+// Encoder is a cayennelpp.Encoder that also writes the extended LPP types used
+// by MeshCore firmware. Standard types are encoded by go-cayenne-lib, so their
+// bytes are identical to cayennelpp.NewEncoder.
+type Encoder struct {
+	buf     []byte
+	scratch cayennelpp.Encoder
+}
+
+// NewEncoder creates an empty extended encoder.
+func NewEncoder() *Encoder {
+	return &Encoder{scratch: cayennelpp.NewEncoder()}
+}
+
+// AddValue appends a scalar data point of any known type. Unknown and
+// multi-value types are ignored.
+func (e *Encoder) AddValue(channel, lppType uint8, value float64) {
+	if !IsScalarType(lppType) {
+		return
+	}
+	e.buf = append(e.buf, channel, lppType)
+	e.buf = AppendValue(e.buf, lppType, value)
+}
+
+// AddValue writes a scalar reading of an extended LPP type to enc. It returns
+// false, writing nothing, if enc cannot encode extended types.
+func AddValue(enc cayennelpp.Encoder, channel, lppType uint8, value float64) bool {
+	va, ok := enc.(ValueAdder)
+	if !ok {
+		return false
+	}
+	va.AddValue(channel, lppType, value)
+	return true
+}
+
+// AddVoltage writes a voltage reading (LPP type 116, as firmware reports battery
+// voltage). Encoders without extended types get an analog input instead.
+func AddVoltage(enc cayennelpp.Encoder, channel uint8, volts float64) {
+	if !AddValue(enc, channel, LPPVoltage, volts) {
+		enc.AddAnalogInput(channel, volts)
+	}
+}
+
+// add runs fn against the scratch go-cayenne-lib encoder and appends its output.
+func (e *Encoder) add(fn func(cayennelpp.Encoder)) {
+	e.scratch.Reset()
+	fn(e.scratch)
+	e.buf = append(e.buf, e.scratch.Bytes()...)
+}
+
+// Grow ensures room for another n bytes without reallocating.
+func (e *Encoder) Grow(n int) {
+	if cap(e.buf)-len(e.buf) < n {
+		grown := make([]byte, len(e.buf), len(e.buf)+n)
+		copy(grown, e.buf)
+		e.buf = grown
+	}
+}
+
+// Bytes returns the encoded data points. The slice aliases the encoder's
+// buffer and is valid until the next Add or Reset.
+func (e *Encoder) Bytes() []byte { return e.buf }
+
+// Reset discards the encoded data points, keeping the buffer for reuse.
+func (e *Encoder) Reset() { e.buf = e.buf[:0] }
+
+// WriteTo writes the encoded data points to w. Unlike bytes.Buffer, it leaves
+// the encoder unchanged; call Reset to start a new payload.
+func (e *Encoder) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(e.buf)
+	return int64(n), err
+}
+
+// AddPort writes a bare port value: the channel and the value in hundredths,
+// with no type byte, as go-cayenne-lib does.
+func (e *Encoder) AddPort(channel uint8, value float64) {
+	e.add(func(s cayennelpp.Encoder) { s.AddPort(channel, value) })
+}
+
+// AddDigitalInput writes a digital input (LPP type 0).
+func (e *Encoder) AddDigitalInput(channel, value uint8) {
+	e.add(func(s cayennelpp.Encoder) { s.AddDigitalInput(channel, value) })
+}
+
+// AddDigitalOutput writes a digital output (LPP type 1).
+func (e *Encoder) AddDigitalOutput(channel, value uint8) {
+	e.add(func(s cayennelpp.Encoder) { s.AddDigitalOutput(channel, value) })
+}
+
+// AddAnalogInput writes an analog input (LPP type 2).
+func (e *Encoder) AddAnalogInput(channel uint8, value float64) {
+	e.add(func(s cayennelpp.Encoder) { s.AddAnalogInput(channel, value) })
+}
+
+// AddAnalogOutput writes an analog output (LPP type 3).
+func (e *Encoder) AddAnalogOutput(channel uint8, value float64) {
+	e.add(func(s cayennelpp.Encoder) { s.AddAnalogOutput(channel, value) })
+}
+
+// AddLuminosity writes an illuminance reading in lux (LPP type 101).
+func (e *Encoder) AddLuminosity(channel uint8, value uint16) {
+	e.add(func(s cayennelpp.Encoder) { s.AddLuminosity(channel, value) })
+}
+
+// AddPresence writes a presence reading (LPP type 102).
+func (e *Encoder) AddPresence(channel, value uint8) {
+	e.add(func(s cayennelpp.Encoder) { s.AddPresence(channel, value) })
+}
+
+// AddTemperature writes a temperature in degrees Celsius (LPP type 103).
+func (e *Encoder) AddTemperature(channel uint8, celcius float64) {
+	e.add(func(s cayennelpp.Encoder) { s.AddTemperature(channel, celcius) })
+}
+
+// AddRelativeHumidity writes a relative humidity in percent (LPP type 104).
+func (e *Encoder) AddRelativeHumidity(channel uint8, rh float64) {
+	e.add(func(s cayennelpp.Encoder) { s.AddRelativeHumidity(channel, rh) })
+}
+
+// AddAccelerometer writes an acceleration in G per axis (LPP type 113).
+func (e *Encoder) AddAccelerometer(channel uint8, x, y, z float64) {
+	e.add(func(s cayennelpp.Encoder) { s.AddAccelerometer(channel, x, y, z) })
+}
+
+// AddBarometricPressure writes a barometric pressure in hPa (LPP type 115).
+func (e *Encoder) AddBarometricPressure(channel uint8, hpa float64) {
+	e.add(func(s cayennelpp.Encoder) { s.AddBarometricPressure(channel, hpa) })
+}
+
+// AddGyrometer writes a rotation rate in degrees per second per axis (LPP type 134).
+func (e *Encoder) AddGyrometer(channel uint8, x, y, z float64) {
+	e.add(func(s cayennelpp.Encoder) { s.AddGyrometer(channel, x, y, z) })
+}
+
+// AddGPS writes a location in decimal degrees and altitude in meters (LPP type 136).
+func (e *Encoder) AddGPS(channel uint8, latitude, longitude, meters float64) {
+	e.add(func(s cayennelpp.Encoder) { s.AddGPS(channel, latitude, longitude, meters) })
+}
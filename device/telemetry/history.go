@@ -0,0 +1,103 @@
+package telemetry
+
+import "sync"
+
+// DefaultHistorySize is the default capacity of a MemoryHistory, in samples.
+const DefaultHistorySize = 4096
+
+// Sample is one scalar reading recorded at a point in time.
+type Sample struct {
+	// Timestamp is the node's clock (epoch seconds) when the reading was taken.
+	Timestamp uint32
+
+	Reading
+
+	// Perm is the permission category the reading belongs to: 0 for base
+	// telemetry, or one of PermLocation/PermEnvironment. Queries filter on it
+	// the same way a Provider is gated by the GET_TELEMETRY mask.
+	Perm uint8
+}
+
+// visible reports whether a sample may be returned to a requester with mask.
+// Base telemetry (Perm 0) is always visible.
+func (s *Sample) visible(mask uint8) bool {
+	return s.Perm == 0 || s.Perm&mask != 0
+}
+
+// History stores recorded telemetry samples for GET_MIN_MAX_AVG queries.
+// Implementations are bounded: the oldest samples are dropped when full.
+type History interface {
+	// Add records samples. They are expected in non-decreasing time order.
+	Add(samples []Sample) error
+
+	// Query returns the samples with from <= Timestamp <= to, oldest first.
+	Query(from, to uint32) []Sample
+
+	// Count returns the number of stored samples.
+	Count() int
+}
+
+// Compile-time assertion that MemoryHistory implements History.
+var _ History = (*MemoryHistory)(nil)
+
+// MemoryHistory is an in-memory History backed by a circular buffer. When the
+// buffer is full, the oldest sample is overwritten.
+type MemoryHistory struct {
+	mu       sync.RWMutex
+	samples  []Sample
+	capacity int
+	head     int // next write position
+	count    int // number of stored samples (up to capacity)
+}
+
+// NewMemoryHistory creates an in-memory history with the given capacity. If
+// capacity is 0, DefaultHistorySize is used.
+func NewMemoryHistory(capacity int) *MemoryHistory {
+	if capacity <= 0 {
+		capacity = DefaultHistorySize
+	}
+	return &MemoryHistory{
+		samples:  make([]Sample, capacity),
+		capacity: capacity,
+	}
+}
+
+// Add appends samples to the ring, overwriting the oldest when full.
+func (h *MemoryHistory) Add(samples []Sample) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, s := range samples {
+		h.samples[h.head] = s
+		h.head = (h.head + 1) % h.capacity
+		if h.count < h.capacity {
+			h.count++
+		}
+	}
+	return nil
+}
+
+// Query returns the samples in [from, to], oldest first.
+func (h *MemoryHistory) Query(from, to uint32) []Sample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var out []Sample
+	start := 0
+	if h.count == h.capacity {
+		start = h.head // head points to the oldest entry after wrapping
+	}
+	for i := 0; i < h.count; i++ {
+		s := h.samples[(start+i)%h.capacity]
+		if s.Timestamp >= from && s.Timestamp <= to {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Count returns the number of stored samples.
+func (h *MemoryHistory) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.count
+}
@@ -0,0 +1,174 @@
+package telemetry
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"sync"
+)
+
+// sampleRecordSize is the on-disk size of one Sample: timestamp(4) + channel(1)
+// + type(1) + perm(1) + value(8, IEEE 754).
+const sampleRecordSize = 15
+
+// Compile-time assertion that FileHistory implements History.
+var _ History = (*FileHistory)(nil)
+
+// FileHistory is a History that survives restarts. Samples are held in a
+// MemoryHistory ring and appended to a file as fixed-size binary records. When
+// the file grows to twice the ring capacity it is rewritten (temp file + rename)
+// with just the retained samples, so disk use stays bounded. A torn record left
+// by a crash is ignored on the next open.
+type FileHistory struct {
+	path string
+	mem  *MemoryHistory
+
+	mu      sync.Mutex
+	file    *os.File
+	records int // records currently in the file
+}
+
+// OpenFileHistory opens (or creates) a file-backed history at path holding up to
+// capacity samples. If capacity is 0, DefaultHistorySize is used. Existing
+// samples are loaded into memory.
+func OpenFileHistory(path string, capacity int) (*FileHistory, error) {
+	h := &FileHistory{path: path, mem: NewMemoryHistory(capacity)}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	samples := make([]Sample, 0, len(data)/sampleRecordSize)
+	for len(data) >= sampleRecordSize {
+		samples = append(samples, decodeSample(data[:sampleRecordSize]))
+		data = data[sampleRecordSize:]
+	}
+	_ = h.mem.Add(samples)
+
+	// Rewrite on open: drops a torn tail and anything beyond capacity.
+	if err := h.compactLocked(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Add appends samples to the file and, once they are written, records them in
+// memory. A failed write leaves both unchanged.
+func (h *FileHistory) Add(samples []Sample) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file == nil {
+		return os.ErrClosed
+	}
+
+	buf := make([]byte, 0, len(samples)*sampleRecordSize)
+	for i := range samples {
+		buf = appendSample(buf, &samples[i])
+	}
+	if _, err := h.file.Write(buf); err != nil {
+		// Drop any partial record so later appends stay aligned.
+		if terr := h.file.Truncate(int64(h.records) * sampleRecordSize); terr != nil {
+			_ = h.file.Close()
+			h.file = nil
+			return errors.Join(err, terr)
+		}
+		return err
+	}
+	h.records += len(samples)
+	_ = h.mem.Add(samples)
+
+	if h.records >= 2*h.mem.capacity {
+		return h.compactLocked()
+	}
+	return nil
+}
+
+// Query returns the samples in [from, to], oldest first.
+func (h *FileHistory) Query(from, to uint32) []Sample {
+	return h.mem.Query(from, to)
+}
+
+// Count returns the number of stored samples.
+func (h *FileHistory) Count() int {
+	return h.mem.Count()
+}
+
+// Close syncs and closes the backing file.
+func (h *FileHistory) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file == nil {
+		return nil
+	}
+	err := errors.Join(h.file.Sync(), h.file.Close())
+	h.file = nil
+	return err
+}
+
+// compactLocked rewrites the file with the samples currently in memory and
+// reopens it for appending. Must be called with h.mu held (or before h is
+// shared).
+func (h *FileHistory) compactLocked() error {
+	samples := h.mem.Query(0, math.MaxUint32)
+	buf := make([]byte, 0, len(samples)*sampleRecordSize)
+	for i := range samples {
+		buf = appendSample(buf, &samples[i])
+	}
+
+	tmp := h.path + ".tmp"
+	if err := writeFileSync(tmp, buf); err != nil {
+		return err
+	}
+	if h.file != nil {
+		_ = h.file.Close()
+		h.file = nil
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	h.file = f
+	h.records = len(samples)
+	return nil
+}
+
+// writeFileSync writes data to path and syncs it, so a rename over the live
+// file never exposes a half-written copy after a crash.
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return errors.Join(f.Sync(), f.Close())
+}
+
+func appendSample(dst []byte, s *Sample) []byte {
+	var rec [sampleRecordSize]byte
+	binary.LittleEndian.PutUint32(rec[0:4], s.Timestamp)
+	rec[4] = s.Channel
+	rec[5] = s.Type
+	rec[6] = s.Perm
+	binary.LittleEndian.PutUint64(rec[7:15], math.Float64bits(s.Value))
+	return append(dst, rec[:]...)
+}
+
+func decodeSample(rec []byte) Sample {
+	return Sample{
+		Timestamp: binary.LittleEndian.Uint32(rec[0:4]),
+		Reading: Reading{
+			Channel: rec[4],
+			Type:    rec[5],
+			Value:   math.Float64frombits(binary.LittleEndian.Uint64(rec[7:15])),
+		},
+		Perm: rec[6],
+	}
+}
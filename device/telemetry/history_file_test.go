@@ -0,0 +1,93 @@
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileHistory_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.bin")
+	h, err := OpenFileHistory(path, 10)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := h.Add([]Sample{sample(100, 1, 103, 21.5, 0), sample(100, 2, 104, 40, PermEnvironment)}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	h, err = OpenFileHistory(path, 10)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer h.Close()
+	got := h.Query(0, 200)
+	if len(got) != 2 || got[0].Value != 21.5 || got[1].Perm != PermEnvironment {
+		t.Errorf("reloaded = %+v", got)
+	}
+}
+
+func TestFileHistory_CompactsAndIgnoresTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.bin")
+	h, err := OpenFileHistory(path, 4)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for i := uint32(1); i <= 9; i++ {
+		_ = h.Add([]Sample{sample(i, 1, 103, float64(i), 0)})
+	}
+	h.Close()
+
+	info, _ := os.Stat(path)
+	if info.Size() >= 8*sampleRecordSize {
+		t.Errorf("file size = %d, want compacted below %d", info.Size(), 8*sampleRecordSize)
+	}
+
+	// Simulate a crash mid-append.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	f.Write([]byte{1, 2, 3})
+	f.Close()
+
+	h, err = OpenFileHistory(path, 4)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer h.Close()
+	got := h.Query(0, 100)
+	if len(got) != 4 || got[0].Timestamp != 6 || got[3].Timestamp != 9 {
+		t.Errorf("reloaded = %+v, want timestamps 6..9", got)
+	}
+}
+
+func TestFileHistory_FailedWriteKeepsMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.bin")
+	h, err := OpenFileHistory(path, 10)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := h.Add([]Sample{sample(100, 1, 103, 21.5, 0)}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	// Break the backing file so the next append fails.
+	h.file.Close()
+	if err := h.Add([]Sample{sample(200, 1, 103, 22, 0)}); err == nil {
+		t.Fatal("add on a closed file succeeded")
+	}
+	if n := h.Count(); n != 1 {
+		t.Errorf("Count = %d after failed write, want 1", n)
+	}
+	h.Close()
+
+	h, err = OpenFileHistory(path, 10)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer h.Close()
+	if got := h.Query(0, 300); len(got) != 1 || got[0].Timestamp != 100 {
+		t.Errorf("reloaded = %+v", got)
+	}
+}
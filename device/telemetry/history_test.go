@@ -0,0 +1,198 @@
+package telemetry
+
+import (
+	"math"
+	"testing"
+
+	cayennelpp "github.com/TheThingsNetwork/go-cayenne-lib"
+	"github.com/kabili207/meshcore-go/core/clock"
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+func sample(ts uint32, ch, typ uint8, v float64, perm uint8) Sample {
+	return Sample{Timestamp: ts, Reading: Reading{Channel: ch, Type: typ, Value: v}, Perm: perm}
+}
+
+func TestParseReadings_RoundTrip(t *testing.T) {
+	enc := cayennelpp.NewEncoder()
+	enc.AddTemperature(ChannelSelf, -12.5)
+	enc.AddRelativeHumidity(2, 55)
+	enc.AddGPS(3, 52.1, 4.3, 10) // multi-value, skipped
+	enc.AddAnalogInput(4, 3.71)
+
+	got, err := ParseReadings(enc.Bytes())
+	if err != nil {
+		t.Fatalf("ParseReadings: %v", err)
+	}
+	want := []Reading{
+		{ChannelSelf, 103, -12.5},
+		{2, 104, 55},
+		{4, 2, 3.71},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d readings, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].Channel != want[i].Channel || got[i].Type != want[i].Type ||
+			math.Abs(got[i].Value-want[i].Value) > 1e-9 {
+			t.Errorf("reading %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseReadings_Truncated(t *testing.T) {
+	got, err := ParseReadings([]byte{1, 103, 0x00, 0xC8, 2, 103, 0x01})
+	if err != ErrLPPTruncated {
+		t.Fatalf("err = %v, want ErrLPPTruncated", err)
+	}
+	if len(got) != 1 || got[0].Value != 20 {
+		t.Errorf("partial readings = %+v, want one 20°C reading", got)
+	}
+}
+
+func TestAppendValue_Signed(t *testing.T) {
+	got := AppendValue(nil, 103, -1.5) // temperature ×10, signed 16-bit
+	if len(got) != 2 || got[0] != 0xFF || got[1] != 0xF1 {
+		t.Errorf("AppendValue(-1.5) = % X, want FF F1", got)
+	}
+	if got := AppendValue(nil, 136, 1); got != nil {
+		t.Errorf("AppendValue(GPS) = % X, want nothing", got)
+	}
+}
+
+func TestMemoryHistory_Wraps(t *testing.T) {
+	h := NewMemoryHistory(3)
+	for i := uint32(1); i <= 5; i++ {
+		_ = h.Add([]Sample{sample(i*10, 1, 103, float64(i), 0)})
+	}
+	if h.Count() != 3 {
+		t.Fatalf("Count = %d, want 3", h.Count())
+	}
+	got := h.Query(0, 100)
+	if len(got) != 3 || got[0].Timestamp != 30 || got[2].Timestamp != 50 {
+		t.Errorf("Query = %+v, want timestamps 30..50", got)
+	}
+	if got := h.Query(35, 45); len(got) != 1 || got[0].Timestamp != 40 {
+		t.Errorf("Query(35,45) = %+v, want single sample at 40", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	samples := []Sample{
+		sample(1, 1, 103, 10, 0),
+		sample(1, 2, 104, 40, PermEnvironment),
+		sample(2, 1, 103, 20, 0),
+		sample(3, 1, 103, 30, 0),
+		sample(3, 2, 104, 60, PermEnvironment),
+	}
+
+	got := Summarize(samples, 0)
+	if len(got) != 1 {
+		t.Fatalf("base-only summary = %+v, want one entry", got)
+	}
+	if m := got[0]; m.Min != 10 || m.Max != 30 || m.Avg != 20 || m.Count != 3 {
+		t.Errorf("summary = %+v, want min 10 max 30 avg 20 over 3", m)
+	}
+
+	got = Summarize(samples, 0xFF)
+	if len(got) != 2 || got[1].Channel != 2 || got[1].Avg != 50 {
+		t.Errorf("full summary = %+v, want environment entry with avg 50", got)
+	}
+}
+
+func TestSummarize_CapsEntries(t *testing.T) {
+	var samples []Sample
+	for ch := uint8(0); ch < MaxMinMaxAvgEntries+2; ch++ {
+		samples = append(samples, sample(1, ch, 103, 1, 0))
+	}
+	if got := Summarize(samples, 0xFF); len(got) != MaxMinMaxAvgEntries {
+		t.Errorf("len = %d, want %d", len(got), MaxMinMaxAvgEntries)
+	}
+}
+
+func TestMinMaxAvg_EncodeParse(t *testing.T) {
+	in := []MinMaxAvg{
+		{Channel: 1, Type: 103, Min: -5, Max: 25.5, Avg: 10.2},
+		{Channel: 1, Type: LPPVoltage, Min: 3.6, Max: 4.2, Avg: 3.9},
+	}
+	body := EncodeMinMaxAvg(1000, in)
+	if len(body) != 4+2*(2+6) {
+		t.Fatalf("body len = %d, want %d", len(body), 4+2*(2+6))
+	}
+	now, got, err := ParseMinMaxAvg(body)
+	if err != nil || now != 1000 || len(got) != 2 {
+		t.Fatalf("ParseMinMaxAvg = %d, %+v, %v", now, got, err)
+	}
+	for i := range in {
+		if math.Abs(got[i].Min-in[i].Min) > 1e-9 || math.Abs(got[i].Max-in[i].Max) > 1e-9 ||
+			math.Abs(got[i].Avg-in[i].Avg) > 1e-9 {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], in[i])
+		}
+	}
+}
+
+func TestQueryMinMaxAvg(t *testing.T) {
+	h := NewMemoryHistory(0)
+	_ = h.Add([]Sample{
+		sample(900, 1, 103, 10, 0),
+		sample(950, 1, 103, 20, 0),
+		sample(950, 3, 104, 50, PermEnvironment),
+		sample(990, 1, 103, 99, 0), // outside the window
+	})
+	req := BuildMinMaxAvgRequest(100, 20) // [900, 980]
+
+	if _, ok := QueryMinMaxAvg(h, 1000, req, codec.PermACLGuest); ok {
+		t.Error("guest request should be refused")
+	}
+	if _, ok := QueryMinMaxAvg(h, 1000, req[:4], codec.PermACLAdmin); ok {
+		t.Error("short request should be refused")
+	}
+	if _, ok := QueryMinMaxAvg(h, 1000, req[:8], codec.PermACLAdmin); ok {
+		t.Error("request without the reserved bytes should be refused")
+	}
+	bad := append([]byte(nil), req...)
+	bad[9] = 1
+	if _, ok := QueryMinMaxAvg(h, 1000, bad, codec.PermACLAdmin); ok {
+		t.Error("request with nonzero reserved bytes should be refused")
+	}
+
+	body, ok := QueryMinMaxAvg(h, 1000, req, codec.PermACLReadOnly)
+	if !ok {
+		t.Fatal("read-only request refused")
+	}
+	_, got, err := ParseMinMaxAvg(body)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("ParseMinMaxAvg = %+v, %v", got, err)
+	}
+	if got[0].Min != 10 || got[0].Max != 20 || got[0].Avg != 15 {
+		t.Errorf("temperature entry = %+v", got[0])
+	}
+}
+
+func TestSampler_TagsCategories(t *testing.T) {
+	p := providerFunc(func(mask uint8, enc cayennelpp.Encoder) {
+		enc.AddAnalogInput(ChannelSelf, 4.1)
+		if mask&PermLocation != 0 {
+			enc.AddGPS(ChannelSelf, 1, 2, 3)
+		}
+		if mask&PermEnvironment != 0 {
+			enc.AddTemperature(2, 21)
+		}
+	})
+	h := NewMemoryHistory(0)
+	clk := clock.New()
+	clk.SetCurrentTime(5000)
+	var notified int
+	s := NewSampler(SamplerConfig{Provider: p, History: h, Clock: clk, OnSample: func(ss []Sample) { notified = len(ss) }})
+
+	got := s.SampleNow()
+	if len(got) != 2 || notified != 2 || h.Count() != 2 {
+		t.Fatalf("SampleNow = %+v (notified %d, stored %d), want 2 samples", got, notified, h.Count())
+	}
+	if got[0].Perm != 0 || got[0].Type != 2 {
+		t.Errorf("base sample = %+v", got[0])
+	}
+	if got[1].Perm != PermEnvironment || got[1].Channel != 2 || got[1].Timestamp != 5000 {
+		t.Errorf("environment sample = %+v", got[1])
+	}
+}
@@ -0,0 +1,73 @@
+// Package linux provides telemetry.Provider implementations that read host
+// health from a Linux system: power supply voltage (sysfs power_supply), CPU
+// temperature (sysfs thermal zones), load average and memory use (procfs), and
+// location from a fixed position or gpsd.
+//
+// Every file-backed provider takes a Root so it can be pointed at a fake sysfs
+// or procfs tree in tests; the zero value reads the live system. A source that
+// is missing or unreadable is skipped silently, so Default works on any board.
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kabili207/meshcore-go/device/telemetry"
+)
+
+// Default channels used by the providers returned from Default. The node's own
+// voltage and temperature share telemetry.ChannelSelf, as in firmware.
+const (
+	ChannelLoad   uint8 = 2
+	ChannelMemory uint8 = 3
+)
+
+// Default returns the standard host-health providers: power supply voltage and
+// CPU temperature on telemetry.ChannelSelf, 1-minute load average on
+// ChannelLoad, and memory use on ChannelMemory. root is the filesystem root
+// ("" for the live system).
+func Default(root string) telemetry.Providers {
+	return telemetry.Providers{
+		&PowerSupply{Root: root},
+		&Thermal{Root: root},
+		&Load{Root: root},
+		&Memory{Root: root},
+	}
+}
+
+// allowed reports whether a reading gated by perm may be included for the
+// requester's mask. A zero perm is base telemetry and is always included.
+func allowed(perm, mask uint8) bool {
+	return perm == 0 || mask&perm != 0
+}
+
+// channelOr returns ch, or telemetry.ChannelSelf if ch is 0.
+func channelOr(ch uint8) uint8 {
+	if ch == 0 {
+		return telemetry.ChannelSelf
+	}
+	return ch
+}
+
+// hostPath joins root and an absolute system path.
+func hostPath(root, path string) string {
+	if root == "" {
+		root = "/"
+	}
+	return filepath.Join(root, path)
+}
+
+// readInt reads a file holding a single integer, as sysfs attributes do.
+func readInt(path string) (int64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
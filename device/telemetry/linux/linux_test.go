@@ -0,0 +1,148 @@
+package linux
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cayennelpp "github.com/TheThingsNetwork/go-cayenne-lib"
+
+	"github.com/kabili207/meshcore-go/device/telemetry"
+)
+
+// fakeRoot builds a sysfs/procfs tree resembling a Raspberry Pi with a UPS HAT.
+func fakeRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"sys/class/power_supply/AC/online":           "1\n",
+		"sys/class/power_supply/battery/voltage_now": "3912000\n",
+		"sys/class/thermal/thermal_zone0/temp":       "48312\n",
+		"proc/loadavg":                               "0.42 0.30 0.25 1/123 4567\n",
+		"proc/meminfo":                               "MemTotal:        1000000 kB\nMemFree:          100000 kB\nMemAvailable:     750000 kB\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func query(t *testing.T, p telemetry.Provider, mask uint8) []telemetry.Reading {
+	t.Helper()
+	enc := telemetry.NewEncoder()
+	p.QuerySensors(mask, enc)
+	readings, err := telemetry.ParseReadings(enc.Bytes())
+	if err != nil {
+		t.Fatalf("ParseReadings: %v", err)
+	}
+	return readings
+}
+
+func TestDefault(t *testing.T) {
+	readings := query(t, Default(fakeRoot(t)), 0)
+	want := []telemetry.Reading{
+		{Channel: telemetry.ChannelSelf, Type: telemetry.LPPVoltage, Value: 3.91},
+		{Channel: telemetry.ChannelSelf, Type: cayennelpp.Temperature, Value: 48.3},
+		{Channel: ChannelLoad, Type: cayennelpp.AnalogInput, Value: 0.42},
+		{Channel: ChannelMemory, Type: cayennelpp.AnalogInput, Value: 25},
+	}
+	if len(readings) != len(want) {
+		t.Fatalf("readings = %+v, want %+v", readings, want)
+	}
+	for i := range want {
+		if readings[i] != want[i] {
+			t.Errorf("reading %d = %+v, want %+v", i, readings[i], want[i])
+		}
+	}
+}
+
+func TestDefault_MissingSourcesSkipped(t *testing.T) {
+	if readings := query(t, Default(t.TempDir()), 0xFF); len(readings) != 0 {
+		t.Errorf("readings = %+v, want none", readings)
+	}
+}
+
+func TestPowerSupply_NamedAndPermGated(t *testing.T) {
+	root := fakeRoot(t)
+	if r := query(t, &PowerSupply{Root: root, Name: "AC"}, 0); len(r) != 0 {
+		t.Errorf("AC has no voltage_now, got %+v", r)
+	}
+	p := &PowerSupply{Root: root, Name: "battery", Channel: 4, Perm: telemetry.PermEnvironment}
+	if r := query(t, p, 0); len(r) != 0 {
+		t.Errorf("reading without permission: %+v", r)
+	}
+	if r := query(t, p, telemetry.PermEnvironment); len(r) != 1 || r[0].Channel != 4 {
+		t.Errorf("readings = %+v, want one on channel 4", r)
+	}
+}
+
+func TestStaticLocation_RequiresPermLocation(t *testing.T) {
+	p := &StaticLocation{Lat: 52.37, Lon: 4.89, Alt: 2}
+	enc := telemetry.NewEncoder()
+	p.QuerySensors(telemetry.PermBase, enc)
+	if len(enc.Bytes()) != 0 {
+		t.Errorf("location reported without PermLocation: %v", enc.Bytes())
+	}
+	p.QuerySensors(telemetry.PermLocation, enc)
+	want := cayennelpp.NewEncoder()
+	want.AddGPS(telemetry.ChannelSelf, 52.37, 4.89, 2)
+	if string(enc.Bytes()) != string(want.Bytes()) {
+		t.Errorf("GPS = %v, want %v", enc.Bytes(), want.Bytes())
+	}
+}
+
+func TestGPSD(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	watched := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		watched <- line
+		conn.Write([]byte(`{"class":"VERSION","release":"3.25"}` + "\n"))
+		conn.Write([]byte(`{"class":"TPV","mode":1}` + "\n"))
+		conn.Write([]byte(`{"class":"TPV","mode":3,"lat":52.5,"lon":13.4,"alt":40.5,"altMSL":34}` + "\n"))
+		time.Sleep(time.Second)
+	}()
+
+	p := &GPSD{Addr: ln.Addr().String()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Start(ctx)
+
+	if got := <-watched; got != gpsdWatch {
+		t.Errorf("watch command = %q", got)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, _, _, ok := p.Fix(); ok || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	lat, lon, alt, ok := p.Fix()
+	if !ok || lat != 52.5 || lon != 13.4 || alt != 34 {
+		t.Fatalf("Fix = %v, %v, %v, %v; want 52.5, 13.4, 34 (MSL)", lat, lon, alt, ok)
+	}
+	if r := query(t, p, telemetry.PermBase); len(r) != 0 {
+		t.Errorf("location reported without PermLocation: %+v", r)
+	}
+}
@@ -0,0 +1,172 @@
+package linux
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	cayennelpp "github.com/TheThingsNetwork/go-cayenne-lib"
+
+	"github.com/kabili207/meshcore-go/device/telemetry"
+)
+
+// DefaultGPSDAddr is the address GPSD connects to when Addr is empty.
+const DefaultGPSDAddr = "localhost:2947"
+
+// gpsdRetryInterval is the delay before reconnecting to gpsd after a failure.
+const gpsdRetryInterval = 5 * time.Second
+
+// gpsdWatch enables JSON reports on a gpsd connection.
+const gpsdWatch = `?WATCH={"enable":true,"json":true}` + "\n"
+
+// Compile-time assertions for the location providers.
+var (
+	_ telemetry.Provider = (*StaticLocation)(nil)
+	_ telemetry.Provider = (*GPSD)(nil)
+)
+
+// StaticLocation reports a fixed position, for a node whose location is known
+// but has no GPS. Like firmware, location is only included when the requester's
+// mask carries telemetry.PermLocation.
+type StaticLocation struct {
+	Lat, Lon float64
+	Alt      float64 // metres
+
+	// Channel is the LPP channel. 0 means telemetry.ChannelSelf.
+	Channel uint8
+}
+
+// QuerySensors implements telemetry.Provider.
+func (p *StaticLocation) QuerySensors(mask uint8, enc cayennelpp.Encoder) {
+	if mask&telemetry.PermLocation == 0 {
+		return
+	}
+	enc.AddGPS(channelOr(p.Channel), p.Lat, p.Lon, p.Alt)
+}
+
+// GPSD reports the latest fix from a gpsd daemon. Start must be running for it
+// to have a fix; until then, and after the connection drops, it reports
+// nothing. Location is only included when the requester's mask carries
+// telemetry.PermLocation.
+type GPSD struct {
+	// Addr is gpsd's host:port. Default: DefaultGPSDAddr.
+	Addr string
+
+	// Channel is the LPP channel. 0 means telemetry.ChannelSelf.
+	Channel uint8
+
+	// Logger for connection errors. Falls back to slog.Default() if nil.
+	Logger *slog.Logger
+
+	mu  sync.Mutex
+	fix *gpsFix
+}
+
+// gpsFix is the subset of a gpsd TPV report used for telemetry.
+type gpsFix struct {
+	Lat, Lon, Alt float64
+}
+
+// tpvReport is a gpsd TPV (time-position-velocity) report.
+type tpvReport struct {
+	Class  string   `json:"class"`
+	Mode   int      `json:"mode"`
+	Lat    *float64 `json:"lat"`
+	Lon    *float64 `json:"lon"`
+	Alt    *float64 `json:"alt"`
+	AltMSL *float64 `json:"altMSL"`
+}
+
+// QuerySensors implements telemetry.Provider.
+func (p *GPSD) QuerySensors(mask uint8, enc cayennelpp.Encoder) {
+	if mask&telemetry.PermLocation == 0 {
+		return
+	}
+	if lat, lon, alt, ok := p.Fix(); ok {
+		enc.AddGPS(channelOr(p.Channel), lat, lon, alt)
+	}
+}
+
+// Fix returns the latest position (latitude, longitude, altitude in metres),
+// or false if there is no current 2D or 3D fix.
+func (p *GPSD) Fix() (lat, lon, alt float64, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fix == nil {
+		return 0, 0, 0, false
+	}
+	return p.fix.Lat, p.fix.Lon, p.fix.Alt, true
+}
+
+// Start connects to gpsd and tracks fixes until ctx is cancelled, reconnecting
+// after failures. It blocks; run it in a goroutine.
+func (p *GPSD) Start(ctx context.Context) {
+	logger := p.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	for {
+		if err := p.watch(ctx); err != nil && ctx.Err() == nil {
+			logger.Debug("gpsd connection failed", "addr", p.addr(), "error", err)
+		}
+		p.setFix(nil)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(gpsdRetryInterval):
+		}
+	}
+}
+
+func (p *GPSD) addr() string {
+	if p.Addr == "" {
+		return DefaultGPSDAddr
+	}
+	return p.Addr
+}
+
+// watch runs one gpsd session, returning when the connection ends.
+func (p *GPSD) watch(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.addr())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if _, err := conn.Write([]byte(gpsdWatch)); err != nil {
+		return err
+	}
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		var r tpvReport
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil || r.Class != "TPV" {
+			continue
+		}
+		if r.Mode < 2 || r.Lat == nil || r.Lon == nil {
+			p.setFix(nil)
+			continue
+		}
+		fix := &gpsFix{Lat: *r.Lat, Lon: *r.Lon}
+		if r.AltMSL != nil {
+			fix.Alt = *r.AltMSL
+		} else if r.Alt != nil {
+			fix.Alt = *r.Alt
+		}
+		p.setFix(fix)
+	}
+	return sc.Err()
+}
+
+func (p *GPSD) setFix(fix *gpsFix) {
+	p.mu.Lock()
+	p.fix = fix
+	p.mu.Unlock()
+}
@@ -0,0 +1,59 @@
+package linux
+
+import (
+	"os"
+	"path/filepath"
+
+	cayennelpp "github.com/TheThingsNetwork/go-cayenne-lib"
+
+	"github.com/kabili207/meshcore-go/device/telemetry"
+)
+
+// Compile-time assertion that PowerSupply implements telemetry.Provider.
+var _ telemetry.Provider = (*PowerSupply)(nil)
+
+// PowerSupply reports a supply's voltage from
+// /sys/class/power_supply/<Name>/voltage_now (microvolts) as an LPP voltage
+// reading, the firmware's battery voltage.
+type PowerSupply struct {
+	// Root is the filesystem root. Empty means "/".
+	Root string
+
+	// Name is the power_supply entry to read (e.g. "battery", "BAT0"). Empty
+	// uses the first entry that exposes voltage_now.
+	Name string
+
+	// Channel is the LPP channel. 0 means telemetry.ChannelSelf.
+	Channel uint8
+
+	// Perm is the permission bit required to see the reading. 0 means base
+	// telemetry, always included.
+	Perm uint8
+}
+
+// QuerySensors implements telemetry.Provider.
+func (p *PowerSupply) QuerySensors(mask uint8, enc cayennelpp.Encoder) {
+	if !allowed(p.Perm, mask) {
+		return
+	}
+	if uv, ok := p.read(); ok {
+		telemetry.AddVoltage(enc, channelOr(p.Channel), float64(uv)/1e6)
+	}
+}
+
+func (p *PowerSupply) read() (int64, bool) {
+	dir := hostPath(p.Root, "sys/class/power_supply")
+	if p.Name != "" {
+		return readInt(filepath.Join(dir, p.Name, "voltage_now"))
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, false
+	}
+	for _, e := range entries {
+		if uv, ok := readInt(filepath.Join(dir, e.Name(), "voltage_now")); ok {
+			return uv, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,115 @@
+package linux
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+
+	cayennelpp "github.com/TheThingsNetwork/go-cayenne-lib"
+
+	"github.com/kabili207/meshcore-go/device/telemetry"
+)
+
+// Compile-time assertions for the procfs providers.
+var (
+	_ telemetry.Provider = (*Load)(nil)
+	_ telemetry.Provider = (*Memory)(nil)
+)
+
+// Load reports the 1-minute load average from /proc/loadavg as an analog
+// input.
+type Load struct {
+	// Root is the filesystem root. Empty means "/".
+	Root string
+
+	// Channel is the LPP channel. Default: ChannelLoad.
+	Channel uint8
+
+	// Perm is the permission bit required to see the reading. 0 means base
+	// telemetry, always included.
+	Perm uint8
+}
+
+// QuerySensors implements telemetry.Provider.
+func (p *Load) QuerySensors(mask uint8, enc cayennelpp.Encoder) {
+	if !allowed(p.Perm, mask) {
+		return
+	}
+	data, err := os.ReadFile(hostPath(p.Root, "proc/loadavg"))
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return
+	}
+	ch := p.Channel
+	if ch == 0 {
+		ch = ChannelLoad
+	}
+	enc.AddAnalogInput(ch, load)
+}
+
+// Memory reports memory in use, as a percentage of MemTotal, from
+// /proc/meminfo as an analog input. "In use" is MemTotal - MemAvailable.
+type Memory struct {
+	// Root is the filesystem root. Empty means "/".
+	Root string
+
+	// Channel is the LPP channel. Default: ChannelMemory.
+	Channel uint8
+
+	// Perm is the permission bit required to see the reading. 0 means base
+	// telemetry, always included.
+	Perm uint8
+}
+
+// QuerySensors implements telemetry.Provider.
+func (p *Memory) QuerySensors(mask uint8, enc cayennelpp.Encoder) {
+	if !allowed(p.Perm, mask) {
+		return
+	}
+	total, avail, ok := readMeminfo(hostPath(p.Root, "proc/meminfo"))
+	if !ok || total == 0 {
+		return
+	}
+	ch := p.Channel
+	if ch == 0 {
+		ch = ChannelMemory
+	}
+	enc.AddAnalogInput(ch, float64(total-avail)*100/float64(total))
+}
+
+// readMeminfo returns MemTotal and MemAvailable in kB.
+func readMeminfo(path string) (total, avail int64, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+
+	var haveTotal, haveAvail bool
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total, haveTotal = v, true
+		case "MemAvailable:":
+			avail, haveAvail = v, true
+		}
+	}
+	return total, avail, haveTotal && haveAvail && avail <= total
+}
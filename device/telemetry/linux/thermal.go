@@ -0,0 +1,47 @@
+package linux
+
+import (
+	"path/filepath"
+
+	cayennelpp "github.com/TheThingsNetwork/go-cayenne-lib"
+
+	"github.com/kabili207/meshcore-go/device/telemetry"
+)
+
+// DefaultThermalZone is the zone read when Thermal.Zone is empty. On a
+// Raspberry Pi it is the SoC (CPU) sensor.
+const DefaultThermalZone = "thermal_zone0"
+
+// Compile-time assertion that Thermal implements telemetry.Provider.
+var _ telemetry.Provider = (*Thermal)(nil)
+
+// Thermal reports a thermal zone's temperature from
+// /sys/class/thermal/<Zone>/temp (millidegrees Celsius).
+type Thermal struct {
+	// Root is the filesystem root. Empty means "/".
+	Root string
+
+	// Zone is the thermal zone directory. Default: DefaultThermalZone.
+	Zone string
+
+	// Channel is the LPP channel. 0 means telemetry.ChannelSelf.
+	Channel uint8
+
+	// Perm is the permission bit required to see the reading. 0 means base
+	// telemetry, always included.
+	Perm uint8
+}
+
+// QuerySensors implements telemetry.Provider.
+func (p *Thermal) QuerySensors(mask uint8, enc cayennelpp.Encoder) {
+	if !allowed(p.Perm, mask) {
+		return
+	}
+	zone := p.Zone
+	if zone == "" {
+		zone = DefaultThermalZone
+	}
+	if mc, ok := readInt(hostPath(p.Root, filepath.Join("sys/class/thermal", zone, "temp"))); ok {
+		enc.AddTemperature(channelOr(p.Channel), float64(mc)/1000)
+	}
+}
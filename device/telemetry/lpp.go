@@ -0,0 +1,154 @@
+package telemetry
+
+import (
+	"errors"
+	"math"
+)
+
+// CayenneLPP data types beyond those exported by go-cayenne-lib. The firmware
+// uses the extended ElectronicCats CayenneLPP set; these are the scalar types
+// that can appear in a MeshCore telemetry response.
+const (
+	LPPGenericSensor uint8 = 100
+	LPPVoltage       uint8 = 116
+	LPPCurrent       uint8 = 117
+	LPPFrequency     uint8 = 118
+	LPPPercentage    uint8 = 120
+	LPPAltitude      uint8 = 121
+	LPPConcentration uint8 = 125
+	LPPPower         uint8 = 128
+	LPPDistance      uint8 = 130
+	LPPEnergy        uint8 = 131
+	LPPDirection     uint8 = 132
+	LPPUnixTime      uint8 = 133
+	LPPColour        uint8 = 135
+	LPPSwitch        uint8 = 142
+)
+
+// ErrLPPTruncated is returned by ParseReadings when a data point is cut short or
+// carries an unknown type (whose size, and so the rest of the buffer, cannot be
+// determined).
+var ErrLPPTruncated = errors.New("telemetry: truncated or unknown LPP data")
+
+// lppType describes a CayenneLPP data type: its encoded size, the multiplier
+// applied to the value, and whether the integer is signed. scalar is false for
+// multi-value types (accelerometer, gyrometer, GPS, colour), which are skipped
+// when recording history.
+type lppType struct {
+	size       int
+	multiplier float64
+	signed     bool
+	scalar     bool
+}
+
+// lppTypes mirrors the firmware CayenneLPP getTypeSize/getTypeMultiplier/
+// getTypeSigned tables.
+var lppTypes = map[uint8]lppType{
+	0:                {1, 1, false, true},     // digital input
+	1:                {1, 1, false, true},     // digital output
+	2:                {2, 100, true, true},    // analog input
+	3:                {2, 100, true, true},    // analog output
+	LPPGenericSensor: {4, 1, false, true},     // generic sensor
+	101:              {2, 1, false, true},     // luminosity
+	102:              {1, 1, false, true},     // presence
+	103:              {2, 10, true, true},     // temperature
+	104:              {1, 2, false, true},     // relative humidity
+	113:              {6, 1000, true, false},  // accelerometer
+	115:              {2, 10, false, true},    // barometric pressure
+	LPPVoltage:       {2, 100, false, true},   // voltage
+	LPPCurrent:       {2, 1000, false, true},  // current
+	LPPFrequency:     {4, 1, false, true},     // frequency
+	LPPPercentage:    {1, 1, false, true},     // percentage
+	LPPAltitude:      {2, 1, true, true},      // altitude
+	LPPConcentration: {2, 1, false, true},     // concentration
+	LPPPower:         {2, 1, false, true},     // power
+	LPPDistance:      {4, 1000, false, true},  // distance
+	LPPEnergy:        {4, 1000, false, true},  // energy
+	LPPDirection:     {2, 1, false, true},     // direction
+	LPPUnixTime:      {4, 1, false, true},     // unix time
+	134:              {6, 100, true, false},   // gyrometer
+	LPPColour:        {3, 1, false, false},    // colour
+	136:              {9, 10000, true, false}, // GPS
+	LPPSwitch:        {1, 1, false, true},     // switch
+}
+
+// Reading is one scalar CayenneLPP data point.
+type Reading struct {
+	Channel uint8
+	Type    uint8
+	Value   float64
+}
+
+// ParseReadings decodes a CayenneLPP buffer into its scalar readings. Multi-value
+// types are skipped. Parsing stops with ErrLPPTruncated at the first data point
+// that is cut short or has an unknown type; the readings decoded up to that point
+// are still returned.
+func ParseReadings(buf []byte) ([]Reading, error) {
+	var out []Reading
+	for len(buf) > 0 {
+		if len(buf) < 2 {
+			return out, ErrLPPTruncated
+		}
+		ch, typ := buf[0], buf[1]
+		info, ok := lppTypes[typ]
+		if !ok || len(buf) < 2+info.size {
+			return out, ErrLPPTruncated
+		}
+		if info.scalar {
+			out = append(out, Reading{Channel: ch, Type: typ, Value: getValue(buf[2:2+info.size], info)})
+		}
+		buf = buf[2+info.size:]
+	}
+	return out, nil
+}
+
+// IsScalarType reports whether t is a known single-value CayenneLPP type that
+// can be summarized by GET_MIN_MAX_AVG.
+func IsScalarType(t uint8) bool {
+	info, ok := lppTypes[t]
+	return ok && info.scalar
+}
+
+// AppendValue appends v encoded as a bare CayenneLPP data point of type t (no
+// channel or type header), matching the firmware's addLPPDataPoint. Unknown and
+// multi-value types append nothing.
+func AppendValue(dst []byte, t uint8, v float64) []byte {
+	info, ok := lppTypes[t]
+	if !ok || !info.scalar {
+		return dst
+	}
+	return putValue(dst, v, info)
+}
+
+// putValue encodes value big-endian in info.size bytes, wrapping negative values
+// into two's complement for signed types (firmware putFloat).
+func putValue(dst []byte, value float64, info lppType) []byte {
+	neg := value < 0
+	if neg {
+		value = -value
+	}
+	v := uint64(math.Round(value * info.multiplier))
+	bits := uint(info.size * 8)
+	mask := uint64(1)<<bits - 1
+	v &= mask
+	if info.signed && neg {
+		v = (mask - v + 1) & mask
+	}
+	for i := info.size - 1; i >= 0; i-- {
+		dst = append(dst, byte(v>>(uint(i)*8)))
+	}
+	return dst
+}
+
+// getValue decodes a big-endian CayenneLPP integer and applies the multiplier.
+func getValue(b []byte, info lppType) float64 {
+	var v uint64
+	for _, x := range b {
+		v = v<<8 | uint64(x)
+	}
+	bits := uint(len(b) * 8)
+	if info.signed && v&(1<<(bits-1)) != 0 {
+		return float64(int64(v)-int64(1)<<bits) / info.multiplier
+	}
+	return float64(v) / info.multiplier
+}
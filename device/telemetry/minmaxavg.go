@@ -0,0 +1,155 @@
+package telemetry
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+)
+
+const (
+	// MaxMinMaxAvgEntries caps the per-channel entries in a GET_MIN_MAX_AVG
+	// reply (firmware queries into a MinMaxAvg[8] array).
+	MaxMinMaxAvgEntries = 8
+
+	// minMaxAvgRequestSize is start_secs_ago(4) + end_secs_ago(4) + two
+	// reserved bytes, which must be zero.
+	minMaxAvgRequestSize = 10
+)
+
+// ErrMinMaxAvgTruncated is returned by ParseMinMaxAvg for a malformed reply.
+var ErrMinMaxAvgTruncated = errors.New("telemetry: truncated min/max/avg reply")
+
+// MinMaxAvg summarizes one channel/type series over a time window.
+type MinMaxAvg struct {
+	Channel uint8
+	Type    uint8
+	Min     float64
+	Max     float64
+	Avg     float64
+
+	// Count is the number of samples summarized. It is not sent on the wire.
+	Count int
+}
+
+// Summarize groups samples visible under mask by (channel, type) and computes
+// the min, max, and mean of each series. Series are returned in the order they
+// first appear, capped at MaxMinMaxAvgEntries.
+func Summarize(samples []Sample, mask uint8) []MinMaxAvg {
+	type key struct{ ch, typ uint8 }
+	index := make(map[key]int)
+	var out []MinMaxAvg
+	for i := range samples {
+		s := &samples[i]
+		if !s.visible(mask) || !IsScalarType(s.Type) {
+			continue
+		}
+		k := key{s.Channel, s.Type}
+		idx, ok := index[k]
+		if !ok {
+			if len(out) >= MaxMinMaxAvgEntries {
+				continue
+			}
+			index[k] = len(out)
+			out = append(out, MinMaxAvg{Channel: s.Channel, Type: s.Type, Min: s.Value, Max: s.Value})
+			idx = len(out) - 1
+		}
+		m := &out[idx]
+		if s.Value < m.Min {
+			m.Min = s.Value
+		}
+		if s.Value > m.Max {
+			m.Max = s.Value
+		}
+		m.Avg += s.Value // running sum until the final pass
+		m.Count++
+	}
+	for i := range out {
+		out[i].Avg /= float64(out[i].Count)
+	}
+	return out
+}
+
+// EncodeMinMaxAvg builds a GET_MIN_MAX_AVG reply body (without the request tag):
+// now(4) followed by [channel][type][min][max][avg] per entry, each value a bare
+// CayenneLPP data point of the entry's type. This matches firmware SensorMesh.
+func EncodeMinMaxAvg(now uint32, stats []MinMaxAvg) []byte {
+	out := make([]byte, 4, 4+len(stats)*8)
+	binary.LittleEndian.PutUint32(out[0:4], now)
+	for _, m := range stats {
+		out = append(out, m.Channel, m.Type)
+		out = AppendValue(out, m.Type, m.Min)
+		out = AppendValue(out, m.Type, m.Max)
+		out = AppendValue(out, m.Type, m.Avg)
+	}
+	return out
+}
+
+// ParseMinMaxAvg decodes a GET_MIN_MAX_AVG reply body (after the tag) into the
+// responder's clock and the per-channel summaries.
+func ParseMinMaxAvg(data []byte) (uint32, []MinMaxAvg, error) {
+	if len(data) < 4 {
+		return 0, nil, ErrMinMaxAvgTruncated
+	}
+	now := binary.LittleEndian.Uint32(data[0:4])
+	data = data[4:]
+	var out []MinMaxAvg
+	for len(data) >= 2 {
+		ch, typ := data[0], data[1]
+		info, ok := lppTypes[typ]
+		if !ok || !info.scalar || len(data) < 2+3*info.size {
+			return now, out, ErrMinMaxAvgTruncated
+		}
+		v := data[2:]
+		out = append(out, MinMaxAvg{
+			Channel: ch,
+			Type:    typ,
+			Min:     getValue(v[0:info.size], info),
+			Max:     getValue(v[info.size:2*info.size], info),
+			Avg:     getValue(v[2*info.size:3*info.size], info),
+		})
+		data = data[2+3*info.size:]
+	}
+	return now, out, nil
+}
+
+// QueryMinMaxAvg answers a GET_MIN_MAX_AVG request from h. requestData is
+// start_secs_ago(4) + end_secs_ago(4), both relative to now, and two reserved
+// bytes. Firmware only answers read-only or better clients, and ignores
+// requests whose reserved bytes are not zero; ok is false for guests and
+// malformed requests. The body is masked the same way as GET_TELEMETRY (see Mask).
+func QueryMinMaxAvg(h History, now uint32, requestData []byte, permissions uint8) (body []byte, ok bool) {
+	if h == nil || len(requestData) < minMaxAvgRequestSize {
+		return nil, false
+	}
+	if requestData[8] != 0 || requestData[9] != 0 {
+		return nil, false // reserved
+	}
+	if permissions&codec.PermACLRoleMask < codec.PermACLReadOnly {
+		return nil, false
+	}
+	startAgo := binary.LittleEndian.Uint32(requestData[0:4])
+	endAgo := binary.LittleEndian.Uint32(requestData[4:8])
+	from, to := secsAgo(now, startAgo), secsAgo(now, endAgo)
+	if from > to {
+		from, to = to, from
+	}
+	stats := Summarize(h.Query(from, to), Mask(nil, permissions))
+	return EncodeMinMaxAvg(now, stats), true
+}
+
+// BuildMinMaxAvgRequest builds the request data for a GET_MIN_MAX_AVG query
+// covering the window from startSecsAgo to endSecsAgo before the peer's now.
+func BuildMinMaxAvgRequest(startSecsAgo, endSecsAgo uint32) []byte {
+	data := make([]byte, minMaxAvgRequestSize) // reserved bytes left zero
+	binary.LittleEndian.PutUint32(data[0:4], startSecsAgo)
+	binary.LittleEndian.PutUint32(data[4:8], endSecsAgo)
+	return data
+}
+
+func secsAgo(now, ago uint32) uint32 {
+	if ago > now {
+		return 0
+	}
+	return now - ago
+}
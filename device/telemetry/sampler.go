@@ -0,0 +1,126 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core/clock"
+)
+
+// DefaultSampleInterval is the default interval between history samples.
+const DefaultSampleInterval = time.Minute
+
+// SamplerConfig configures a Sampler.
+type SamplerConfig struct {
+	// Provider supplies the readings to record. Required.
+	Provider Provider
+
+	// History receives the recorded samples. Required.
+	History History
+
+	// Clock timestamps the samples. Falls back to a fresh clock if nil.
+	Clock *clock.Clock
+
+	// Interval between samples. Default: DefaultSampleInterval.
+	Interval time.Duration
+
+	// OnSample, if set, is called with each batch after it has been recorded
+	// (e.g. to evaluate alert thresholds).
+	OnSample func(samples []Sample)
+
+	// Logger for sampler events. Falls back to slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// Sampler periodically polls a Provider and records its scalar readings into a
+// History. Each reading is tagged with the permission category it was gated by,
+// so later GET_MIN_MAX_AVG queries can apply the same mask as GET_TELEMETRY.
+type Sampler struct {
+	cfg SamplerConfig
+	clk *clock.Clock
+	log *slog.Logger
+}
+
+// NewSampler creates a sampler. Call Start to begin polling.
+func NewSampler(cfg SamplerConfig) *Sampler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultSampleInterval
+	}
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.New()
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Sampler{cfg: cfg, clk: clk, log: logger.WithGroup("telemetry")}
+}
+
+// Start takes a sample immediately and then every Interval. It blocks until the
+// context is cancelled. Typically called in a goroutine:
+//
+//	go sampler.Start(ctx)
+func (s *Sampler) Start(ctx context.Context) {
+	s.SampleNow()
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.SampleNow()
+		}
+	}
+}
+
+// SampleNow polls the provider once, records the readings, and returns them.
+//
+// The provider is queried once with the base mask and once per optional
+// category; a reading that only appears when a category bit is set is tagged
+// with that bit.
+func (s *Sampler) SampleNow() []Sample {
+	if s.cfg.Provider == nil || s.cfg.History == nil {
+		return nil
+	}
+	now := s.clk.GetCurrentTime()
+
+	type key struct{ ch, typ uint8 }
+	seen := make(map[key]bool)
+	var samples []Sample
+	for _, perm := range []uint8{0, PermLocation, PermEnvironment} {
+		for _, r := range s.query(perm) {
+			k := key{r.Channel, r.Type}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			samples = append(samples, Sample{Timestamp: now, Reading: r, Perm: perm})
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	if err := s.cfg.History.Add(samples); err != nil {
+		s.log.Warn("failed to record telemetry samples", "error", err)
+		return nil
+	}
+	if s.cfg.OnSample != nil {
+		s.cfg.OnSample(samples)
+	}
+	return samples
+}
+
+func (s *Sampler) query(mask uint8) []Reading {
+	enc := NewEncoder()
+	s.cfg.Provider.QuerySensors(mask, enc)
+	readings, err := ParseReadings(enc.Bytes())
+	if err != nil {
+		s.log.Debug("unparseable telemetry reading", "error", err)
+	}
+	return readings
+}
@@ -50,7 +50,20 @@ func Encode(p Provider, requestData []byte, permissions uint8) []byte {
 	if p == nil {
 		return nil
 	}
-	enc := cayennelpp.NewEncoder()
+	enc := NewEncoder()
 	p.QuerySensors(Mask(requestData, permissions), enc)
 	return enc.Bytes()
 }
+
+// Providers combines several providers into one, queried in order. A nil entry
+// is skipped.
+type Providers []Provider
+
+// QuerySensors runs each provider against the same encoder.
+func (ps Providers) QuerySensors(permissions uint8, enc cayennelpp.Encoder) {
+	for _, p := range ps {
+		if p != nil {
+			p.QuerySensors(permissions, enc)
+		}
+	}
+}
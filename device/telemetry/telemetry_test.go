@@ -1,6 +1,7 @@
 package telemetry
 
 import (
+	"bytes"
 	"testing"
 
 	cayennelpp "github.com/TheThingsNetwork/go-cayenne-lib"
@@ -55,3 +56,64 @@ func TestEncode_Provider(t *testing.T) {
 		t.Errorf("Encode body = %v, want %v", got, want.Bytes())
 	}
 }
+
+func TestEncoder_MatchesLibAndAddsVoltage(t *testing.T) {
+	enc := NewEncoder()
+	enc.AddTemperature(ChannelSelf, 20.5)
+	AddVoltage(enc, ChannelSelf, 3.71)
+	enc.AddGPS(2, 52.1, 4.3, 10)
+
+	lib := cayennelpp.NewEncoder()
+	lib.AddTemperature(ChannelSelf, 20.5)
+	if got := enc.Bytes()[:len(lib.Bytes())]; string(got) != string(lib.Bytes()) {
+		t.Errorf("temperature bytes = %v, want %v", got, lib.Bytes())
+	}
+
+	readings, err := ParseReadings(enc.Bytes())
+	if err != nil || len(readings) != 2 {
+		t.Fatalf("ParseReadings = %+v, %v", readings, err)
+	}
+	if r := readings[1]; r.Type != LPPVoltage || r.Value != 3.71 {
+		t.Errorf("voltage reading = %+v, want 3.71V", r)
+	}
+}
+
+func TestEncoder_WriteToKeepsBuffer(t *testing.T) {
+	enc := NewEncoder()
+	enc.AddTemperature(ChannelSelf, 20.5)
+	want := string(enc.Bytes())
+
+	var buf bytes.Buffer
+	if n, err := enc.WriteTo(&buf); err != nil || n != int64(len(want)) {
+		t.Fatalf("WriteTo = %d, %v", n, err)
+	}
+	if buf.String() != want || string(enc.Bytes()) != want {
+		t.Errorf("WriteTo wrote %x and left %x, want %x both", buf.Bytes(), enc.Bytes(), want)
+	}
+	enc.Reset()
+	if len(enc.Bytes()) != 0 {
+		t.Error("Reset left data")
+	}
+}
+
+func TestAddVoltage_FallsBackToAnalog(t *testing.T) {
+	lib := cayennelpp.NewEncoder()
+	AddVoltage(lib, ChannelSelf, 3.7)
+	readings, _ := ParseReadings(lib.Bytes())
+	if len(readings) != 1 || readings[0].Type != cayennelpp.AnalogInput {
+		t.Errorf("readings = %+v, want one analog input", readings)
+	}
+}
+
+func TestProviders(t *testing.T) {
+	var order []int
+	ps := Providers{
+		providerFunc(func(uint8, cayennelpp.Encoder) { order = append(order, 1) }),
+		nil,
+		providerFunc(func(uint8, cayennelpp.Encoder) { order = append(order, 2) }),
+	}
+	ps.QuerySensors(0, NewEncoder())
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Errorf("order = %v, want [1 2]", order)
+	}
+}
@@ -10,6 +10,9 @@
 //	go run ./examples/companion -listen 127.0.0.1:5000 -name demo
 //	go run ./examples/companion -serial /dev/ttyUSB0 -name demo
 //
+// With -capture it also records every packet heard and sent to a capture file,
+// which transport/replay can later feed back into a node.
+//
 // Then add a MeshCore source in MeshMonitor with connection type TCP, host
 // 127.0.0.1, port 5000.
 package main
@@ -37,6 +40,7 @@ import (
 	"github.com/kabili207/meshcore-go/device/event"
 	"github.com/kabili207/meshcore-go/device/node"
 	"github.com/kabili207/meshcore-go/transport"
+	"github.com/kabili207/meshcore-go/transport/capture"
 	mqtttransport "github.com/kabili207/meshcore-go/transport/mqtt"
 	serialtransport "github.com/kabili207/meshcore-go/transport/serial"
 )
@@ -68,6 +72,8 @@ func run() error {
 		bw   = flag.Float64("bw", 250, "radio bandwidth in kHz (reported to the app)")
 		sf   = flag.Int("sf", 11, "radio spreading factor (reported to the app)")
 		cr   = flag.Int("cr", 5, "radio coding rate (reported to the app)")
+
+		capturePath = flag.String("capture", "", "append every packet heard and sent to this capture file (optional)")
 	)
 	flag.Parse()
 
@@ -102,6 +108,17 @@ func run() error {
 		return err
 	}
 
+	if *capturePath != "" {
+		f, err := os.OpenFile(*capturePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			slog.Error("Failed to open capture file", "error", err)
+			return err
+		}
+		cw := capture.NewWriter(f)
+		defer cw.Close()
+		comp.Base().Router.SetPacketMonitor(cw.Monitor)
+	}
+
 	comp.OnEvent(func(evt any) {
 		switch e := evt.(type) {
 		case *event.TextMessageReceived:
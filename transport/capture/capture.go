@@ -0,0 +1,294 @@
+// Package capture records mesh packets to a file and reads them back.
+//
+// A capture is JSON lines: one object per packet, in the order seen.
+//
+//	{"ts":"2026-01-02T15:04:05.123456Z","dir":"rx","source":"mqtt","transport":"mqtt:meshcore/bridge","snr":-6.25,"rssi":-97,"raw":"1102a1b2..."}
+//
+// The fields are:
+//
+//   - ts: when the packet was seen, RFC 3339 with sub-second precision.
+//   - dir: "rx" for a reception, "tx" for a packet this node sent.
+//   - source: the transport it came from ("mqtt", "serial"), or "local" for
+//     transmissions.
+//   - transport: the name of the transport instance (codec.RxMeta.Transport),
+//     if known.
+//   - snr: the reception SNR in dB, if measured.
+//   - rssi: the reception RSSI in dBm, if measured.
+//   - freq: the frequency in MHz it was heard on, if known.
+//   - raw: the packet as on the wire (codec.Packet.WriteTo), in hex.
+//
+// Readers ignore unknown fields, so the format can grow. Writer.Monitor
+// records from a router's packet monitor; package replay feeds a capture back
+// into a router.
+package capture
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/transport"
+)
+
+// Direction is whether a captured packet was received or sent.
+type Direction string
+
+const (
+	// DirRX is a packet received from a transport.
+	DirRX Direction = "rx"
+
+	// DirTX is a packet this node sent.
+	DirTX Direction = "tx"
+)
+
+// Record is one captured packet.
+type Record struct {
+	Time      time.Time
+	Direction Direction
+	Source    transport.PacketSource
+	Transport string  // transport instance name, if known
+	SNR       float32 // dB, valid if HasSNR
+	HasSNR    bool
+	RSSI      int16 // dBm, valid if HasRSSI
+	HasRSSI   bool
+	FreqMHz   float64 // 0 if unknown
+	Raw       []byte  // wire bytes
+}
+
+// Packet decodes the record's wire bytes. Its receive metadata is taken from
+// the record, including the captured time.
+func (r *Record) Packet() (*codec.Packet, error) {
+	var pkt codec.Packet
+	if err := pkt.ReadFrom(r.Raw); err != nil {
+		return nil, err
+	}
+	pkt.Rx = &codec.RxMeta{
+		Time:      r.Time,
+		Transport: r.Transport,
+		RSSI:      r.RSSI,
+		HasRSSI:   r.HasRSSI,
+		FreqMHz:   r.FreqMHz,
+	}
+	if r.HasSNR {
+		pkt.SetSNR(r.SNR)
+	}
+	return &pkt, nil
+}
+
+// line is the JSON form of a Record.
+type line struct {
+	Time      time.Time `json:"ts"`
+	Dir       Direction `json:"dir"`
+	Source    string    `json:"source"`
+	Transport string    `json:"transport,omitempty"`
+	SNR       *float32  `json:"snr,omitempty"`
+	RSSI      *int16    `json:"rssi,omitempty"`
+	Freq      float64   `json:"freq,omitempty"`
+	Raw       string    `json:"raw"`
+}
+
+// Writer appends records to a capture. It is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	enc *json.Encoder
+	c   io.Closer // closed by Close, if the underlying writer is one
+	err error     // first write error
+
+	now func() time.Time
+}
+
+// NewWriter creates a Writer on w. Output is buffered; call Flush or Close.
+// If w is an io.Closer, Close closes it.
+func NewWriter(w io.Writer) *Writer {
+	bw := bufio.NewWriter(w)
+	cw := &Writer{w: bw, enc: json.NewEncoder(bw), now: time.Now}
+	cw.c, _ = w.(io.Closer)
+	return cw
+}
+
+// Write appends a record.
+func (w *Writer) Write(rec *Record) error {
+	l := line{
+		Time:      rec.Time.UTC(),
+		Dir:       rec.Direction,
+		Source:    rec.Source.String(),
+		Transport: rec.Transport,
+		Freq:      rec.FreqMHz,
+		Raw:       hex.EncodeToString(rec.Raw),
+	}
+	if rec.HasSNR {
+		snr := rec.SNR
+		l.SNR = &snr
+	}
+	if rec.HasRSSI {
+		rssi := rec.RSSI
+		l.RSSI = &rssi
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.enc.Encode(l); err != nil {
+		w.err = err
+	}
+	return w.err
+}
+
+// Monitor records a packet as seen by a router's packet monitor, which
+// reports transmissions with source transport.PacketSourceLocal. Receptions
+// are recorded with their receive metadata. It has the signature of
+// router.PacketMonitor:
+//
+//	r.SetPacketMonitor(w.Monitor)
+//
+// Write errors are kept and returned by Flush and Close.
+func (w *Writer) Monitor(pkt *codec.Packet, src transport.PacketSource) {
+	rec := &Record{
+		Time:      w.now(),
+		Direction: DirTX,
+		Source:    src,
+		Raw:       pkt.WriteTo(),
+	}
+	if src != transport.PacketSourceLocal {
+		rec.Direction = DirRX
+		rec.SNR, rec.HasSNR = pkt.GetSNR(), pkt.SNRKnown()
+		if rx := pkt.Rx; rx != nil {
+			if !rx.Time.IsZero() {
+				rec.Time = rx.Time
+			}
+			rec.Transport = rx.Transport
+			if rx.HasSNR {
+				rec.SNR = rx.SNR
+			}
+			rec.RSSI, rec.HasRSSI = rx.RSSI, rx.HasRSSI
+			rec.FreqMHz = rx.FreqMHz
+		}
+	}
+	_ = w.Write(rec)
+}
+
+// Flush writes buffered records to the underlying writer.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+	return w.err
+}
+
+// Close flushes and, if the underlying writer is an io.Closer, closes it.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if w.c != nil {
+		if cerr := w.c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Reader reads records from a capture.
+type Reader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+// NewReader creates a Reader on r.
+func NewReader(r io.Reader) *Reader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 4096), 64*1024)
+	return &Reader{sc: sc}
+}
+
+// Next returns the next record, or io.EOF at the end of the capture. Blank
+// lines are skipped.
+func (r *Reader) Next() (*Record, error) {
+	for r.sc.Scan() {
+		r.line++
+		data := r.sc.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var l line
+		if err := json.Unmarshal(data, &l); err != nil {
+			return nil, fmt.Errorf("capture line %d: %w", r.line, err)
+		}
+		rec, err := l.record()
+		if err != nil {
+			return nil, fmt.Errorf("capture line %d: %w", r.line, err)
+		}
+		return rec, nil
+	}
+	if err := r.sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ReadAll reads every remaining record.
+func (r *Reader) ReadAll() ([]*Record, error) {
+	var out []*Record
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, rec)
+	}
+}
+
+func (l *line) record() (*Record, error) {
+	if l.Dir != DirRX && l.Dir != DirTX {
+		return nil, fmt.Errorf("unknown direction %q", l.Dir)
+	}
+	src, err := parseSource(l.Source)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(l.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("raw: %w", err)
+	}
+	rec := &Record{
+		Time:      l.Time,
+		Direction: l.Dir,
+		Source:    src,
+		Transport: l.Transport,
+		FreqMHz:   l.Freq,
+		Raw:       raw,
+	}
+	if l.SNR != nil {
+		rec.SNR, rec.HasSNR = *l.SNR, true
+	}
+	if l.RSSI != nil {
+		rec.RSSI, rec.HasRSSI = *l.RSSI, true
+	}
+	return rec, nil
+}
+
+// parseSource is the inverse of transport.PacketSource.String.
+func parseSource(s string) (transport.PacketSource, error) {
+	for _, src := range []transport.PacketSource{
+		transport.PacketSourceMQTT,
+		transport.PacketSourceSerial,
+		transport.PacketSourceLocal,
+	} {
+		if src.String() == s {
+			return src, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown source %q", s)
+}
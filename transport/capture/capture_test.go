@@ -0,0 +1,114 @@
+package capture
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/transport"
+)
+
+func TestWriterReaderRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	t0 := time.Date(2026, 1, 2, 15, 4, 5, 123456000, time.UTC)
+	w.now = func() time.Time { return t0 }
+
+	rx := codec.NewPacket(codec.PayloadTypeAck, codec.RouteTypeFlood, codec.BuildAckPayload(0xdeadbeef))
+	rx.PathLen, rx.Path = 1, []byte{0xa1}
+	rx.SNR = -25 // -6.25 dB
+	w.Monitor(rx, transport.PacketSourceSerial)
+
+	tx := codec.NewPacket(codec.PayloadTypeAck, codec.RouteTypeDirect, codec.BuildAckPayload(1))
+	tx.SNR = 12 // stale; not meaningful for a transmission
+	w.Monitor(tx, transport.PacketSourceLocal)
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	first, _, _ := strings.Cut(buf.String(), "\n")
+	want := `{"ts":"2026-01-02T15:04:05.123456Z","dir":"rx","source":"serial","snr":-6.25,"raw":"` +
+		"0d01a1efbeadde" + `"}`
+	if first != want {
+		t.Errorf("line 1:\n got %s\nwant %s", first, want)
+	}
+
+	recs, err := NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("got %d records", len(recs))
+	}
+	r := recs[0]
+	if !r.Time.Equal(t0) || r.Direction != DirRX || r.Source != transport.PacketSourceSerial || r.SNR != -6.25 {
+		t.Errorf("rx record = %+v", r)
+	}
+	pkt, err := r.Packet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkt.SNR != -25 || !bytes.Equal(pkt.Path, []byte{0xa1}) || !bytes.Equal(pkt.Payload, rx.Payload) {
+		t.Errorf("decoded packet = %+v", pkt)
+	}
+	if r := recs[1]; r.Direction != DirTX || r.Source != transport.PacketSourceLocal || r.HasSNR {
+		t.Errorf("tx record = %+v", r)
+	}
+}
+
+func TestMonitorRxMeta(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	heard := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	pkt := codec.NewPacket(codec.PayloadTypeAck, codec.RouteTypeDirect, codec.BuildAckPayload(7))
+	pkt.Rx = &codec.RxMeta{Time: heard, Transport: "mqtt:mesh", RSSI: -97, HasRSSI: true, FreqMHz: 915}
+	w.Monitor(pkt, transport.PacketSourceMQTT)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), `"snr"`) {
+		t.Errorf("unmeasured SNR written: %s", buf.String())
+	}
+
+	rec, err := NewReader(&buf).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Time.Equal(heard) || rec.Transport != "mqtt:mesh" || rec.HasSNR ||
+		!rec.HasRSSI || rec.RSSI != -97 || rec.FreqMHz != 915 {
+		t.Fatalf("record = %+v", rec)
+	}
+	got, err := rec.Packet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rx := got.Rx; rx == nil || *rx != *pkt.Rx {
+		t.Errorf("Rx = %+v, want %+v", got.Rx, pkt.Rx)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		name, line string
+	}{
+		{"json", `{"dir":`},
+		{"direction", `{"dir":"up","source":"serial","raw":"00"}`},
+		{"source", `{"dir":"rx","source":"wifi","raw":"00"}`},
+		{"raw", `{"dir":"rx","source":"serial","raw":"zz"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := "\n" + `{"dir":"rx","source":"mqtt","raw":"00"}` + "\n" + tt.line + "\n"
+			r := NewReader(strings.NewReader(in))
+			if _, err := r.Next(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Next(); err == nil || !strings.Contains(err.Error(), "line 3") {
+				t.Fatalf("err = %v, want error on line 3", err)
+			}
+		})
+	}
+}
@@ -2,14 +2,28 @@
 //
 // MeshCore packets are transmitted directly over MQTT topics as raw bytes.
 // A single topic is used for both publishing and subscribing.
+//
+// Received messages may instead be a JSON envelope, as published by observer
+// uploaders that report signal data with each packet:
+//
+//	{"raw":"1102a1b2...","snr":-6.25,"rssi":-97,"freq":915.0}
+//
+// Only raw is required. Keys match case-insensitively and numbers may be
+// quoted, so both {"SNR":"12.5"} and {"snr":12.5} work. The signal values are
+// reported in the packet's codec.RxMeta.
 package mqtt
 
 import (
 	"context"
 	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -183,13 +197,80 @@ func (t *Transport) handleMessage(_ paho.Client, message paho.Message) {
 		return
 	}
 
-	var packet codec.Packet
-	if err := packet.ReadFrom(message.Payload()); err != nil {
+	packet, err := decodeMessage(message.Payload())
+	if err != nil {
 		t.log.Debug("failed to parse MeshCore packet", "error", err)
 		return
 	}
+	packet.Rx.Time = time.Now()
+	packet.Rx.Transport = "mqtt:" + t.cfg.Topic
+
+	handler(packet, transport.PacketSourceMQTT)
+}
+
+// envelope is the JSON form of a received message (see the package comment).
+type envelope struct {
+	Raw  string     `json:"raw"`
+	SNR  flexNumber `json:"snr"`
+	RSSI flexNumber `json:"rssi"`
+	Freq flexNumber `json:"freq"`
+}
+
+// flexNumber is a JSON number that may also be written as a string.
+type flexNumber struct {
+	v  float64
+	ok bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *flexNumber) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	n.v, n.ok = v, true
+	return nil
+}
+
+// decodeMessage parses a received message, bare packet or JSON envelope, into
+// a packet with its signal data in Rx. A '{' first byte would be a reserved
+// payload type, so it cannot start a valid bare packet.
+func decodeMessage(data []byte) (*codec.Packet, error) {
+	var packet codec.Packet
+	packet.Rx = &codec.RxMeta{}
+	if len(data) == 0 || data[0] != '{' {
+		if err := packet.ReadFrom(data); err != nil {
+			return nil, err
+		}
+		return &packet, nil
+	}
 
-	handler(&packet, transport.PacketSourceMQTT)
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("envelope: %w", err)
+	}
+	raw, err := hex.DecodeString(env.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("envelope raw: %w", err)
+	}
+	if err := packet.ReadFrom(raw); err != nil {
+		return nil, err
+	}
+	if env.SNR.ok {
+		packet.SetSNR(float32(env.SNR.v))
+	}
+	if env.RSSI.ok {
+		packet.Rx.RSSI = int16(math.Round(env.RSSI.v))
+		packet.Rx.HasRSSI = true
+	}
+	if env.Freq.ok {
+		packet.Rx.FreqMHz = env.Freq.v
+	}
+	return &packet, nil
 }
 
 func (t *Transport) onConnected(_ paho.Client) {
@@ -81,3 +81,55 @@ func TestIsConnected_Default(t *testing.T) {
 		t.Error("expected not connected initially")
 	}
 }
+
+func TestDecodeMessage(t *testing.T) {
+	pkt := codec.NewPacket(codec.PayloadTypeAck, codec.RouteTypeDirect, codec.BuildAckPayload(0xdeadbeef))
+	raw := pkt.WriteTo()
+	rawHex := "0e00efbeadde"
+
+	got, err := decodeMessage(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Rx == nil || got.Rx.HasSNR || got.Rx.HasRSSI {
+		t.Errorf("bare packet Rx = %+v, want no signal data", got.Rx)
+	}
+
+	tests := []struct {
+		name string
+		msg  string
+	}{
+		{"numbers", `{"raw":"` + rawHex + `","snr":-6.25,"rssi":-97,"freq":915.0}`},
+		{"quoted", `{"RAW":"` + rawHex + `","SNR":"-6.25","RSSI":"-97","Freq":"915"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeMessage([]byte(tt.msg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got.WriteTo()) != string(raw) {
+				t.Errorf("packet = %x, want %x", got.WriteTo(), raw)
+			}
+			rx := got.Rx
+			if !rx.HasSNR || rx.SNR != -6.25 || got.SNR != -25 ||
+				!rx.HasRSSI || rx.RSSI != -97 || rx.FreqMHz != 915 {
+				t.Errorf("Rx = %+v, SNR field %d", rx, got.SNR)
+			}
+		})
+	}
+
+	got, err = decodeMessage([]byte(`{"raw":"` + rawHex + `"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Rx.HasSNR || got.Rx.HasRSSI {
+		t.Errorf("envelope without signal data: Rx = %+v", got.Rx)
+	}
+
+	for _, bad := range []string{`{"raw":"zz"}`, `{"raw":"` + rawHex + `","snr":"loud"}`, `{`} {
+		if _, err := decodeMessage([]byte(bad)); err == nil {
+			t.Errorf("decodeMessage(%s): expected error", bad)
+		}
+	}
+}
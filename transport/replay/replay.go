@@ -0,0 +1,231 @@
+// Package replay provides a transport that plays a recorded capture (see
+// package capture) back into a router, to reproduce field problems in tests
+// or on the bench.
+//
+// Received packets are delivered with their recorded source and SNR, spaced
+// as they were captured, optionally sped up. Packets the node sends are not
+// put on any network; they go to Config.OnSend if it is set.
+//
+//	f, _ := os.Open("field.jsonl")
+//	rt := replay.New(capture.NewReader(f), replay.Config{Speed: 10})
+//	r.AddTransport(rt, transport.PacketSourceSerial)
+//	rt.Start(ctx)
+//	<-rt.Done()
+package replay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/transport"
+	"github.com/kabili207/meshcore-go/transport/capture"
+)
+
+// Compile-time interface check.
+var _ transport.Transport = (*Transport)(nil)
+
+// Config holds the configuration for a replay transport.
+type Config struct {
+	// Speed scales playback: 1 keeps the captured timing, 10 plays ten times
+	// faster. Zero or less delivers packets back to back.
+	Speed float64
+	// IncludeTX also replays packets the capturing node sent, as receptions.
+	// By default they are skipped, since the node under test sends its own.
+	IncludeTX bool
+	// OnSend, if set, is called with each packet sent through the transport.
+	OnSend func(pkt *codec.Packet)
+	// Logger is the logger to use. If nil, slog.Default() is used.
+	Logger *slog.Logger
+}
+
+// Transport implements transport.Transport by playing back a capture. It
+// stays connected from Start until Stop, after playback has finished, so the
+// node can still send replies.
+type Transport struct {
+	cfg Config
+	src *capture.Reader
+	log *slog.Logger
+
+	mu            sync.RWMutex
+	connected     bool
+	started       bool
+	err           error
+	cancel        context.CancelFunc
+	done          chan struct{}
+	packetHandler transport.PacketHandler
+	stateHandler  transport.StateHandler
+}
+
+// New creates a replay transport reading from src.
+func New(src *capture.Reader, cfg Config) *Transport {
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &Transport{
+		cfg:  cfg,
+		src:  src,
+		log:  cfg.Logger.WithGroup("replay"),
+		done: make(chan struct{}),
+	}
+}
+
+// Start begins playback. A transport plays its capture once; starting it
+// again is an error.
+func (t *Transport) Start(ctx context.Context) error {
+	t.mu.Lock()
+	if t.started {
+		t.mu.Unlock()
+		return errors.New("replay already started")
+	}
+	t.started = true
+	t.connected = true
+	handler := t.stateHandler
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.mu.Unlock()
+
+	go t.playLoop(ctx)
+
+	if handler != nil {
+		handler(t, transport.EventConnected)
+	}
+	return nil
+}
+
+// Stop ends playback and disconnects.
+func (t *Transport) Stop() error {
+	t.mu.Lock()
+	handler := t.stateHandler
+	cancel := t.cancel
+	started := t.started
+	t.connected = false
+	t.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	cancel()
+	<-t.done
+
+	if handler != nil {
+		handler(t, transport.EventDisconnected)
+	}
+	return nil
+}
+
+// Done is closed when playback ends: the capture is exhausted, it could not
+// be read, or the transport was stopped.
+func (t *Transport) Done() <-chan struct{} {
+	return t.done
+}
+
+// Err returns the error that ended playback early, if any. It is valid once
+// Done is closed.
+func (t *Transport) Err() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.err
+}
+
+// IsConnected returns true between Start and Stop.
+func (t *Transport) IsConnected() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.connected
+}
+
+// SetPacketHandler sets the callback for replayed packets.
+func (t *Transport) SetPacketHandler(fn transport.PacketHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.packetHandler = fn
+}
+
+// SetStateHandler sets the callback for transport state changes.
+func (t *Transport) SetStateHandler(fn transport.StateHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stateHandler = fn
+}
+
+// SendPacket hands the packet to Config.OnSend, if set.
+func (t *Transport) SendPacket(pkt *codec.Packet) error {
+	if !t.IsConnected() {
+		return errors.New("replay transport not running")
+	}
+	if t.cfg.OnSend != nil {
+		t.cfg.OnSend(pkt)
+	}
+	return nil
+}
+
+// playLoop delivers the capture's records until it runs out or ctx ends.
+func (t *Transport) playLoop(ctx context.Context) {
+	defer close(t.done)
+
+	var prev time.Time
+	for n := 0; ; n++ {
+		rec, err := t.src.Next()
+		if errors.Is(err, io.EOF) {
+			t.log.Info("replay finished", "records", n)
+			return
+		}
+		if err != nil {
+			t.fail(err)
+			return
+		}
+		if rec.Direction == capture.DirTX && !t.cfg.IncludeTX {
+			continue
+		}
+
+		if t.cfg.Speed > 0 && !prev.IsZero() {
+			if gap := rec.Time.Sub(prev); gap > 0 {
+				timer := time.NewTimer(time.Duration(float64(gap) / t.cfg.Speed))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+		}
+		prev = rec.Time
+		if ctx.Err() != nil {
+			return
+		}
+
+		pkt, err := rec.Packet()
+		if err != nil {
+			t.log.Warn("skipping malformed packet", "error", err)
+			continue
+		}
+		src := rec.Source
+		if src == transport.PacketSourceLocal {
+			// A replayed transmission arrives like any reception would.
+			src = transport.PacketSourceSerial
+		}
+
+		t.mu.RLock()
+		handler := t.packetHandler
+		t.mu.RUnlock()
+		if handler != nil {
+			handler(pkt, src)
+		}
+	}
+}
+
+// fail records the error that ended playback and reports it.
+func (t *Transport) fail(err error) {
+	t.log.Error("replay failed", "error", err)
+	t.mu.Lock()
+	t.err = err
+	handler := t.stateHandler
+	t.mu.Unlock()
+	if handler != nil {
+		handler(t, transport.EventError)
+	}
+}
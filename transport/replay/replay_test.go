@@ -0,0 +1,157 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kabili207/meshcore-go/core"
+	"github.com/kabili207/meshcore-go/core/codec"
+	"github.com/kabili207/meshcore-go/device/router"
+	"github.com/kabili207/meshcore-go/transport"
+	"github.com/kabili207/meshcore-go/transport/capture"
+)
+
+// record builds a capture holding an ACK per checksum, gap apart, with the
+// given directions.
+func record(t *testing.T, gap time.Duration, dirs ...capture.Direction) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	w := capture.NewWriter(&buf)
+	t0 := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	for i, dir := range dirs {
+		src := transport.PacketSourceSerial
+		if dir == capture.DirTX {
+			src = transport.PacketSourceLocal
+		}
+		pkt := codec.NewPacket(codec.PayloadTypeAck, codec.RouteTypeDirect, codec.BuildAckPayload(uint32(i)))
+		err := w.Write(&capture.Record{
+			Time:      t0.Add(time.Duration(i) * gap),
+			Direction: dir,
+			Source:    src,
+			SNR:       float32(i) + 0.25,
+			HasSNR:    true,
+			Raw:       pkt.WriteTo(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+type seen struct {
+	mu   sync.Mutex
+	pkts []*codec.Packet
+	srcs []transport.PacketSource
+}
+
+func (s *seen) monitor(pkt *codec.Packet, src transport.PacketSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pkts = append(s.pkts, pkt)
+	s.srcs = append(s.srcs, src)
+}
+
+func TestReplayIntoRouter(t *testing.T) {
+	buf := record(t, time.Second, capture.DirRX, capture.DirTX, capture.DirRX)
+
+	var sent []*codec.Packet
+	rt := New(capture.NewReader(buf), Config{OnSend: func(p *codec.Packet) { sent = append(sent, p) }})
+
+	r := router.New(router.Config{SelfID: core.MeshCoreID{0x42}})
+	var s seen
+	r.SetPacketMonitor(s.monitor)
+	r.AddTransport(rt, transport.PacketSourceSerial)
+
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+	<-rt.Done()
+	if err := rt.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The TX record is skipped; the two receptions arrive with their SNR.
+	if len(s.pkts) != 2 {
+		t.Fatalf("router saw %d packets, want 2", len(s.pkts))
+	}
+	for i, want := range []struct {
+		checksum uint32
+		snr      float32
+	}{{0, 0.25}, {2, 2.25}} {
+		ack, err := codec.ParseAckPayload(s.pkts[i].Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ack.Checksum != want.checksum || s.pkts[i].GetSNR() != want.snr || s.srcs[i] != transport.PacketSourceSerial {
+			t.Errorf("packet %d: checksum %d, snr %v, src %v", i, ack.Checksum, s.pkts[i].GetSNR(), s.srcs[i])
+		}
+	}
+
+	// Still connected after playback, so replies reach OnSend.
+	r.SendZeroHop(codec.NewPacket(codec.PayloadTypeAck, codec.RouteTypeDirect, codec.BuildAckPayload(9)))
+	if len(sent) != 1 {
+		t.Fatalf("OnSend got %d packets, want 1", len(sent))
+	}
+}
+
+func TestReplayIncludeTX(t *testing.T) {
+	buf := record(t, 0, capture.DirRX, capture.DirTX)
+	var s seen
+	rt := New(capture.NewReader(buf), Config{IncludeTX: true})
+	rt.SetPacketHandler(s.monitor)
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-rt.Done()
+	rt.Stop()
+	if len(s.pkts) != 2 || s.srcs[1] != transport.PacketSourceSerial {
+		t.Fatalf("got %d packets, sources %v", len(s.pkts), s.srcs)
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	buf := record(t, time.Second, capture.DirRX, capture.DirRX, capture.DirRX)
+	rt := New(capture.NewReader(buf), Config{Speed: 20})
+	start := time.Now()
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-rt.Done()
+	rt.Stop()
+	// Two one-second gaps at 20x.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond || elapsed > time.Second {
+		t.Errorf("playback took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestReplayStop(t *testing.T) {
+	buf := record(t, time.Hour, capture.DirRX, capture.DirRX)
+	var s seen
+	rt := New(capture.NewReader(buf), Config{Speed: 1})
+	rt.SetPacketHandler(s.monitor)
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-rt.Done():
+	default:
+		t.Fatal("Done not closed after Stop")
+	}
+	if rt.IsConnected() {
+		t.Error("still connected after Stop")
+	}
+	if err := rt.Start(context.Background()); err == nil {
+		t.Error("restart succeeded")
+	}
+}
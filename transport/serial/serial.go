@@ -2,7 +2,9 @@
 //
 // MeshCore devices communicate over serial using RS232 framing with Fletcher-16
 // checksums. This transport handles the frame assembly from raw serial data and
-// exposes the same Transport interface as the MQTT transport.
+// exposes the same Transport interface as the MQTT transport. Bridge frames
+// carry only the packet, so received packets have a receive time but no SNR or
+// RSSI in their codec.RxMeta.
 package serial
 
 import (
@@ -12,6 +14,7 @@ import (
 	"io"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/kabili207/meshcore-go/core/codec"
 	"github.com/kabili207/meshcore-go/transport"
@@ -245,6 +248,8 @@ func (t *Transport) processFrames(data []byte) []byte {
 			t.log.Debug("failed to parse MeshCore packet from frame", "error", err)
 			continue
 		}
+		// Bridge frames carry only the packet, so there is no signal data.
+		packet.Rx = &codec.RxMeta{Time: time.Now(), Transport: "serial:" + t.cfg.Port}
 
 		t.mu.RLock()
 		handler := t.packetHandler
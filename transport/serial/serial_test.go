@@ -59,6 +59,9 @@ func TestProcessFrames_SingleFrame(t *testing.T) {
 	if received[0].PayloadType() != pkt.PayloadType() {
 		t.Errorf("payload type mismatch: got %d, want %d", received[0].PayloadType(), pkt.PayloadType())
 	}
+	if rx := received[0].Rx; rx == nil || rx.Time.IsZero() || rx.Transport != "serial:"+tr.cfg.Port || rx.HasSNR {
+		t.Errorf("Rx = %+v, want a receive time and no signal data", rx)
+	}
 }
 
 func TestProcessFrames_MultipleFrames(t *testing.T) {